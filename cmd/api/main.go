@@ -14,6 +14,7 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/karima-store/internal/config"
 	"github.com/karima-store/internal/database"
+	apperrors "github.com/karima-store/internal/errors"
 	"github.com/karima-store/internal/handlers"
 	"github.com/karima-store/internal/komerce"
 	"github.com/karima-store/internal/middleware"
@@ -95,6 +96,9 @@ func main() {
 			if e, ok := err.(*fiber.Error); ok {
 				code = e.Code
 			}
+			if e, ok := err.(*apperrors.AppError); ok {
+				return utils.SendError(c, e.StatusCode, e.Message, e.Details)
+			}
 			return utils.SendError(c, code, err.Error(), nil)
 		},
 	})
@@ -120,11 +124,21 @@ func main() {
 	categoryRepo := repository.NewCategoryRepository(db.DB())
 	flashSaleRepo := repository.NewFlashSaleRepository(db.DB())
 	couponRepo := repository.NewCouponRepository(db.DB())
+	promotionRepo := repository.NewPromotionRepository(db.DB())
+	discountTierRepo := repository.NewDiscountTierRepository(db.DB())
+	resellerApplicationRepo := repository.NewResellerApplicationRepository(db.DB())
+	priceScheduleRepo := repository.NewPriceScheduleRepository(db.DB())
 	shippingZoneRepo := repository.NewShippingZoneRepository(db.DB())
+	taxRepo := repository.NewTaxRepository(db.DB())
 	mediaRepo := repository.NewMediaRepository(db.DB())
 	orderRepo := repository.NewOrderRepository(db.DB())
 	stockLogRepo := repository.NewStockLogRepository(db.DB())
 	userRepo := repository.NewUserRepository(db.DB())
+	cartRepo := repository.NewCartRepository(db.DB())
+	cartRecoveryRepo := repository.NewCartRecoveryRepository(db.DB())
+	wishlistRepo := repository.NewWishlistRepository(db.DB())
+	restockEventRepo := repository.NewRestockEventRepository(db.DB())
+	reviewRepo := repository.NewReviewRepository(db.DB())
 
 	// Initialize services
 	authService := services.NewAuthService(userRepo)
@@ -132,10 +146,97 @@ func main() {
 	orderService := services.NewOrderService(orderRepo) // Added OrderService
 	variantService := services.NewVariantService(variantRepo, productRepo)
 	categoryService := services.NewCategoryService(categoryRepo)
-	pricingService := services.NewPricingService(productRepo, variantRepo, flashSaleRepo, couponRepo, shippingZoneRepo)
+	// Flash sale prices are served from memory; the index reloads on flash sale events from any
+	// instance and at sale start and end times
+	promotionIndex := services.NewPromotionIndex(
+		flashSaleRepo,
+		redis,
+		services.PromotionIndexConfig{
+			MaxAge: time.Duration(cfg.PromotionIndexMaxAgeSeconds) * time.Second,
+		},
+	)
+	promotionIndex.Start()
+	defer promotionIndex.Stop()
+	// Reseller and bulk tier tables are served from memory too and reload on tier changes
+	discountTierIndex := services.NewDiscountTierIndex(
+		discountTierRepo,
+		redis,
+		services.DiscountTierIndexConfig{
+			MaxAge: time.Duration(cfg.DiscountTierIndexMaxAgeSeconds) * time.Second,
+		},
+	)
+	discountTierIndex.Start()
+	defer discountTierIndex.Stop()
+	taxService := services.NewTaxService(taxRepo)
+	pricingService := services.NewPricingService(productRepo, variantRepo, promotionIndex, discountTierIndex, couponRepo, promotionRepo, shippingZoneRepo, taxService)
 	mediaService := services.NewMediaService(mediaRepo, productRepo, cfg)
 	notificationService := services.NewNotificationService(db, redis, cfg)
 	userService := services.NewUserService(userRepo)
+	wishlistService := services.NewWishlistService(wishlistRepo, productRepo, variantRepo, pricingService)
+	// Moves flash sales between upcoming, active and ended at their start and end times
+	flashSaleScheduler := services.NewFlashSaleScheduler(
+		flashSaleRepo,
+		redis,
+		services.FlashSaleSchedulerConfig{
+			Interval: time.Duration(cfg.FlashSaleSchedulerIntervalSeconds) * time.Second,
+		},
+	)
+	flashSaleScheduler.Start()
+	defer flashSaleScheduler.Stop()
+	flashSaleService := services.NewFlashSaleService(flashSaleRepo, productRepo, variantRepo, redis, flashSaleScheduler)
+	couponService := services.NewCouponService(couponRepo)
+	promotionService := services.NewPromotionService(promotionRepo, productRepo)
+	discountTierService := services.NewDiscountTierService(discountTierRepo, productRepo, discountTierIndex, redis)
+	resellerService := services.NewResellerService(resellerApplicationRepo)
+	// Applies scheduled price changes at their effective time and records them in the price history
+	priceScheduler := services.NewPriceScheduler(
+		priceScheduleRepo,
+		productService,
+		redis,
+		services.PriceSchedulerConfig{
+			Interval: time.Duration(cfg.PriceSchedulerIntervalSeconds) * time.Second,
+		},
+	)
+	priceScheduler.Start()
+	defer priceScheduler.Stop()
+	priceScheduleService := services.NewPriceScheduleService(priceScheduleRepo, productRepo, variantRepo, priceScheduler)
+	shippingZoneService := services.NewShippingZoneService(shippingZoneRepo)
+	reviewService := services.NewReviewService(reviewRepo, productRepo, productService, mediaService)
+
+	// Stock updates that bring a product back from zero queue restock events;
+	// this job turns them into WhatsApp alerts for wishlisters
+	backInStockService := services.NewBackInStockService(
+		restockEventRepo,
+		wishlistRepo,
+		productRepo,
+		variantRepo,
+		notificationService,
+		redis,
+		services.BackInStockConfig{
+			Interval: time.Duration(cfg.BackInStockIntervalMinutes) * time.Minute,
+			Cooldown: time.Duration(cfg.BackInStockCooldownHours) * time.Hour,
+		},
+	)
+	backInStockService.Start()
+	defer backInStockService.Stop()
+
+	// Reprices wishlisted products so lower prices, bigger discounts and
+	// flash sales reach the users who wanted them
+	priceDropService := services.NewPriceDropService(
+		wishlistRepo,
+		productRepo,
+		variantRepo,
+		pricingService,
+		notificationService,
+		redis,
+		services.PriceDropConfig{
+			Interval:       time.Duration(cfg.PriceDropIntervalMinutes) * time.Minute,
+			MinDropPercent: float64(cfg.PriceDropMinPercent),
+			DailyCap:       cfg.PriceDropDailyCap,
+		},
+	)
+	priceDropService.Start()
+	defer priceDropService.Stop()
 
 	// Initialize Ory Kratos middleware for authentication (MOVED AFTER SERVICES)
 	authMiddleware := middleware.NewKratosMiddleware(cfg.KratosPublicURL, cfg.KratosAdminURL, authService)
@@ -152,6 +253,26 @@ func main() {
 		IsProduction: getEnvAsBool("MIDTRANS_IS_PRODUCTION", false),
 	}
 
+	// Flash sale stock is reserved atomically in Redis during checkout
+	flashSaleStockService := services.NewFlashSaleStockService(flashSaleRepo, redis)
+
+	// Abandoned cart reminders over WhatsApp; the job only runs when enabled,
+	// but conversions are always attributed to reminders already sent
+	abandonedCartService := services.NewAbandonedCartService(
+		cartRecoveryRepo,
+		couponRepo,
+		notificationService,
+		redis,
+		services.AbandonedCartConfig{
+			IdleAfter:         time.Duration(cfg.AbandonedCartAfterHours) * time.Hour,
+			MaxAge:            time.Duration(cfg.AbandonedCartMaxAgeHours) * time.Hour,
+			Interval:          time.Duration(cfg.AbandonedCartIntervalMinutes) * time.Minute,
+			BatchSize:         cfg.AbandonedCartBatchSize,
+			CouponPercent:     float64(cfg.AbandonedCartCouponPercent),
+			CouponValidFor:    time.Duration(cfg.AbandonedCartCouponValidHours) * time.Hour,
+			AttributionWindow: time.Duration(cfg.AbandonedCartAttributionDays) * 24 * time.Hour,
+		},
+	)
 	// Initialize checkout service with all dependencies
 	checkoutService := services.NewCheckoutService(
 		db,
@@ -159,10 +280,39 @@ func main() {
 		productRepo,
 		variantRepo,
 		stockLogRepo,
+		couponRepo,
 		pricingService,
+		komerceService,
+		cfg.KomerceShipperDestinationID,
+		flashSaleStockService,
 		notificationService,
+		abandonedCartService,
 		midtransConfig,
 	)
+	if cfg.AbandonedCartEnabled {
+		abandonedCartService.Start()
+		defer abandonedCartService.Stop()
+	}
+
+	cartService := services.NewCartService(
+		cartRepo,
+		productRepo,
+		variantRepo,
+		userRepo,
+		pricingService,
+		checkoutService,
+		redis,
+		time.Duration(cfg.GuestCartTTLHours)*time.Hour,
+	)
+
+	// Guest carts are keyed by a signed cookie and merged into the user cart on login
+	guestCartCookie := utils.NewSignedCookie(
+		"guest_cart_id",
+		cfg.GuestCartSecret,
+		time.Duration(cfg.GuestCartTTLHours)*time.Hour,
+		cfg.AppEnv == "production",
+	)
+	authMiddleware.EnableGuestCartMerge(cartService, guestCartCookie)
 
 	// Initialize handlers
 	productHandler := handlers.NewProductHandler(productService, mediaService)
@@ -171,6 +321,17 @@ func main() {
 	pricingHandler := handlers.NewPricingHandler(pricingService, redis)
 	mediaHandler := handlers.NewMediaHandler(mediaService)
 	checkoutHandler := handlers.NewCheckoutHandler(checkoutService)
+	cartHandler := handlers.NewCartHandler(cartService, guestCartCookie)
+	abandonedCartHandler := handlers.NewAbandonedCartHandler(abandonedCartService)
+	wishlistHandler := handlers.NewWishlistHandler(wishlistService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	flashSaleHandler := handlers.NewFlashSaleHandler(flashSaleService)
+	couponHandler := handlers.NewCouponHandler(couponService)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+	discountTierHandler := handlers.NewDiscountTierHandler(discountTierService)
+	resellerHandler := handlers.NewResellerHandler(resellerService)
+	priceScheduleHandler := handlers.NewPriceScheduleHandler(priceScheduleService)
+	shippingZoneHandler := handlers.NewShippingZoneHandler(shippingZoneService)
 	komerceHandler := handlers.NewKomerceHandler(komerceService)
 	orderHandler := handlers.NewOrderHandler(orderService) // Added OrderHandler
 	whatsappHandler := handlers.NewWhatsAppHandler(notificationService)
@@ -190,6 +351,17 @@ func main() {
 		pricingHandler,
 		mediaHandler,
 		checkoutHandler,
+		cartHandler,
+		abandonedCartHandler,
+		wishlistHandler,
+		reviewHandler,
+		flashSaleHandler,
+		couponHandler,
+		promotionHandler,
+		discountTierHandler,
+		resellerHandler,
+		priceScheduleHandler,
+		shippingZoneHandler,
 		komerceHandler,
 		orderHandler,
 		whatsappHandler,
@@ -76,6 +76,9 @@ type Config struct {
 	// Komerce
 	KomerceAPIKey  string
 	KomerceBaseURL string
+	// KomerceShipperDestinationID is the destination parcels ship from, used to quote courier
+	// rates at checkout
+	KomerceShipperDestinationID string
 
 	// Fonnte
 	FonnteToken string
@@ -88,6 +91,42 @@ type Config struct {
 	KratosPublicURL string
 	KratosAdminURL  string
 	KratosUIURL     string
+
+	// Guest Cart
+	GuestCartSecret   string
+	GuestCartTTLHours int
+
+	// Storefront
+	StoreURL string
+
+	// Abandoned Cart Recovery
+	AbandonedCartEnabled          bool
+	AbandonedCartAfterHours       int
+	AbandonedCartMaxAgeHours      int
+	AbandonedCartIntervalMinutes  int
+	AbandonedCartBatchSize        int
+	AbandonedCartCouponPercent    int
+	AbandonedCartCouponValidHours int
+	AbandonedCartAttributionDays  int
+
+	// Back-in-stock Alerts
+	BackInStockIntervalMinutes int
+	BackInStockCooldownHours   int
+
+	// Price-drop Alerts
+	PriceDropIntervalMinutes int
+	PriceDropMinPercent      int
+	PriceDropDailyCap        int
+
+	// Flash Sales
+	FlashSaleSchedulerIntervalSeconds int
+	PromotionIndexMaxAgeSeconds       int
+
+	// Discount Tiers
+	DiscountTierIndexMaxAgeSeconds int
+
+	// Scheduled Price Changes
+	PriceSchedulerIntervalSeconds int
 }
 
 func Load() *Config {
@@ -165,6 +204,8 @@ func Load() *Config {
 		KomerceAPIKey:  getEnv("KOMERCE_API_KEY", ""),
 		KomerceBaseURL: getEnv("KOMERCE_BASE_URL", "https://api-sandbox.collaborator.komerce.id"),
 
+		KomerceShipperDestinationID: getEnv("KOMERCE_SHIPPER_DESTINATION_ID", ""),
+
 		// Fonnte
 		FonnteToken: getEnv("FONNTE_TOKEN", ""),
 		FonnteURL:   getEnv("FONNTE_URL", "https://api.fonnte.com/send"),
@@ -176,6 +217,42 @@ func Load() *Config {
 		KratosPublicURL: getEnv("KRATOS_PUBLIC_URL", "http://127.0.0.1:4433"),
 		KratosAdminURL:  getEnv("KRATOS_ADMIN_URL", "http://127.0.0.1:4434"),
 		KratosUIURL:     getEnv("KRATOS_UI_URL", "http://127.0.0.1:4455"),
+
+		// Guest Cart
+		GuestCartSecret:   getEnv("GUEST_CART_SECRET", ""),
+		GuestCartTTLHours: getEnvAsInt("GUEST_CART_TTL_HOURS", 168),
+
+		// Storefront
+		StoreURL: getEnv("STORE_URL", "https://karimastore.com"),
+
+		// Abandoned Cart Recovery
+		AbandonedCartEnabled:          getEnvAsBool("ABANDONED_CART_ENABLED", false),
+		AbandonedCartAfterHours:       getEnvAsInt("ABANDONED_CART_AFTER_HOURS", 24),
+		AbandonedCartMaxAgeHours:      getEnvAsInt("ABANDONED_CART_MAX_AGE_HOURS", 168),
+		AbandonedCartIntervalMinutes:  getEnvAsInt("ABANDONED_CART_INTERVAL_MINUTES", 30),
+		AbandonedCartBatchSize:        getEnvAsInt("ABANDONED_CART_BATCH_SIZE", 100),
+		AbandonedCartCouponPercent:    getEnvAsInt("ABANDONED_CART_COUPON_PERCENT", 0),
+		AbandonedCartCouponValidHours: getEnvAsInt("ABANDONED_CART_COUPON_VALID_HOURS", 48),
+		AbandonedCartAttributionDays:  getEnvAsInt("ABANDONED_CART_ATTRIBUTION_DAYS", 7),
+
+		// Back-in-stock Alerts
+		BackInStockIntervalMinutes: getEnvAsInt("BACK_IN_STOCK_INTERVAL_MINUTES", 5),
+		BackInStockCooldownHours:   getEnvAsInt("BACK_IN_STOCK_COOLDOWN_HOURS", 24),
+
+		// Price-drop Alerts
+		PriceDropIntervalMinutes: getEnvAsInt("PRICE_DROP_INTERVAL_MINUTES", 15),
+		PriceDropMinPercent:      getEnvAsInt("PRICE_DROP_MIN_PERCENT", 10),
+		PriceDropDailyCap:        getEnvAsInt("PRICE_DROP_DAILY_CAP", 3),
+
+		// Flash Sales
+		FlashSaleSchedulerIntervalSeconds: getEnvAsInt("FLASH_SALE_SCHEDULER_INTERVAL_SECONDS", 60),
+		PromotionIndexMaxAgeSeconds:       getEnvAsInt("PROMOTION_INDEX_MAX_AGE_SECONDS", 300),
+
+		// Discount Tiers
+		DiscountTierIndexMaxAgeSeconds: getEnvAsInt("DISCOUNT_TIER_INDEX_MAX_AGE_SECONDS", 300),
+
+		// Scheduled Price Changes
+		PriceSchedulerIntervalSeconds: getEnvAsInt("PRICE_SCHEDULER_INTERVAL_SECONDS", 60),
 	}
 }
 
@@ -220,6 +297,10 @@ func (c *Config) Validate() {
 			errors = append(errors, "JWT_SECRET is required in production")
 		}
 
+		if c.GuestCartSecret == "" {
+			errors = append(errors, "GUEST_CART_SECRET is required in production")
+		}
+
 		// Validate CORS_ORIGIN is set to specific domains, not wildcard
 		if c.CORSOrigin == "*" || c.CORSOrigin == "" {
 			errors = append(errors, "CORS_ORIGIN must be set to specific domains in production, not wildcard")
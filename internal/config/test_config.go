@@ -25,6 +25,9 @@ func TestConfig() *Config {
 		R2PublicURL:       "",
 		R2Region:          "",
 		JWTSecret:         "test-secret-key-for-testing-only",
+		GuestCartSecret:   "test-guest-cart-secret",
+		GuestCartTTLHours: 168,
+		StoreURL:          "http://localhost:3000",
 	}
 }
 
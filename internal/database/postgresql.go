@@ -134,6 +134,11 @@ func NewPostgreSQL(cfg *config.Config) (*PostgreSQL, error) {
 	return &PostgreSQL{db: db, cfg: cfg}, nil
 }
 
+// NewPostgreSQLFromDB wraps an already open connection, such as one backed by sqlmock in tests
+func NewPostgreSQLFromDB(db *gorm.DB) *PostgreSQL {
+	return &PostgreSQL{db: db}
+}
+
 func (p *PostgreSQL) DB() *gorm.DB {
 	return p.db
 }
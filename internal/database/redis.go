@@ -13,6 +13,7 @@ import (
 
 type RedisClient interface {
 	Get(ctx context.Context, key string) (string, error)
+	GetDel(ctx context.Context, key string) (string, error)
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 	GetJSON(ctx context.Context, key string, dest interface{}) error
 	SetJSON(ctx context.Context, key string, value interface{}, expiration time.Duration) error
@@ -73,6 +74,11 @@ func (r *redisStart) Get(ctx context.Context, key string) (string, error) {
 	return r.client.Get(ctx, key).Result()
 }
 
+// GetDel retrieves a value and deletes its key in one step, so only one caller gets the value
+func (r *redisStart) GetDel(ctx context.Context, key string) (string, error) {
+	return r.client.GetDel(ctx, key).Result()
+}
+
 // Set stores a value in Redis with an expiration time
 func (r *redisStart) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	return r.client.Set(ctx, key, value, expiration).Err()
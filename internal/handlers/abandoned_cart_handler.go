@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/karima-store/internal/services"
+	"github.com/karima-store/internal/utils"
+)
+
+type AbandonedCartHandler struct {
+	abandonedCartService services.AbandonedCartService
+}
+
+func NewAbandonedCartHandler(abandonedCartService services.AbandonedCartService) *AbandonedCartHandler {
+	return &AbandonedCartHandler{
+		abandonedCartService: abandonedCartService,
+	}
+}
+
+// GetStats godoc
+// @Summary Abandoned cart recovery statistics
+// @Description Reminders sent, failed and converted for reminders sent in the period, with the recovery rate and recovered revenue (admin only)
+// @Tags cart-recovery
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date (YYYY-MM-DD, inclusive), defaults to today"
+// @Security KratosSession []
+// @Success 200 {object} models.CartRecoveryStats
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/admin/cart-recovery/stats [get]
+func (h *AbandonedCartHandler) GetStats(c *fiber.Ctx) error {
+	today := time.Now().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -30)
+	to := today
+
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid from date, expected YYYY-MM-DD", nil)
+		}
+		from = parsed
+	}
+	if v := c.Query("to"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid to date, expected YYYY-MM-DD", nil)
+		}
+		to = parsed
+	}
+
+	stats, err := h.abandonedCartService.GetStats(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Failed to get cart recovery stats", err.Error())
+	}
+
+	return utils.SendSuccess(c, stats, "Cart recovery stats retrieved successfully")
+}
+
+// RunNow godoc
+// @Summary Run abandoned cart reminders now
+// @Description Immediately sends reminders for carts that are currently considered abandoned (admin only)
+// @Tags cart-recovery
+// @Produce json
+// @Security KratosSession []
+// @Success 200 {object} services.AbandonedCartRunResult
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/cart-recovery/run [post]
+func (h *AbandonedCartHandler) RunNow(c *fiber.Ctx) error {
+	result, err := h.abandonedCartService.RunOnce()
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to send abandoned cart reminders", err.Error())
+	}
+
+	return utils.SendSuccess(c, result, "Abandoned cart reminders processed")
+}
@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/services"
+	"github.com/karima-store/internal/utils"
+)
+
+type CartHandler struct {
+	cartService     services.CartService
+	guestCartCookie *utils.SignedCookie
+}
+
+func NewCartHandler(cartService services.CartService, guestCartCookie *utils.SignedCookie) *CartHandler {
+	return &CartHandler{
+		cartService:     cartService,
+		guestCartCookie: guestCartCookie,
+	}
+}
+
+// GetCart godoc
+// @Summary Get current user's cart
+// @Description Get the authenticated user's cart. Every line is repriced and checked for availability; changes since the item was added are returned in "warnings" (price_changed, flash_sale_ended, out_of_stock, insufficient_stock, discontinued, unavailable).
+// @Tags cart
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/cart [get]
+func (h *CartHandler) GetCart(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	cart, warnings, err := h.cartService.RevalidateCart(userID)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get cart", err.Error())
+	}
+
+	return utils.SendSuccess(c, cartResponse(cart, warnings), "Cart retrieved successfully")
+}
+
+// AddItem godoc
+// @Summary Add item to cart
+// @Description Add a product (optionally a specific variant) to the cart. Adding an existing product/variant increases its quantity.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param item body models.AddToCartRequest true "Item to add"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Invalid request or insufficient stock"
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 404 {object} map[string]interface{} "Product or variant not found"
+// @Router /api/v1/cart/items [post]
+func (h *CartHandler) AddItem(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	var req models.AddToCartRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	cart, err := h.cartService.AddItem(userID, &req)
+	if err != nil {
+		return sendCartError(c, err)
+	}
+
+	return utils.SendSuccess(c, cartResponse(cart, nil), "Item added to cart")
+}
+
+// UpdateItem godoc
+// @Summary Update cart item quantity
+// @Description Set the quantity of a cart line
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param item_id path int true "Cart item ID"
+// @Param item body models.UpdateCartItemRequest true "New quantity"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Invalid request or insufficient stock"
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 404 {object} map[string]interface{} "Cart item not found"
+// @Router /api/v1/cart/items/{item_id} [put]
+func (h *CartHandler) UpdateItem(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	itemID, err := strconv.ParseUint(c.Params("item_id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cart item ID", nil)
+	}
+
+	var req models.UpdateCartItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	cart, err := h.cartService.UpdateItemQuantity(userID, uint(itemID), req.Quantity)
+	if err != nil {
+		return sendCartError(c, err)
+	}
+
+	return utils.SendSuccess(c, cartResponse(cart, nil), "Cart item updated")
+}
+
+// RemoveItem godoc
+// @Summary Remove item from cart
+// @Description Remove a line from the cart
+// @Tags cart
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param item_id path int true "Cart item ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 404 {object} map[string]interface{} "Cart item not found"
+// @Router /api/v1/cart/items/{item_id} [delete]
+func (h *CartHandler) RemoveItem(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	itemID, err := strconv.ParseUint(c.Params("item_id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cart item ID", nil)
+	}
+
+	cart, err := h.cartService.RemoveItem(userID, uint(itemID))
+	if err != nil {
+		return sendCartError(c, err)
+	}
+
+	return utils.SendSuccess(c, cartResponse(cart, nil), "Cart item removed")
+}
+
+// ClearCart godoc
+// @Summary Clear cart
+// @Description Remove all items from the cart
+// @Tags cart
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/cart [delete]
+func (h *CartHandler) ClearCart(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	if err := h.cartService.ClearCart(userID); err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to clear cart", err.Error())
+	}
+
+	return utils.SendSuccess(c, nil, "Cart cleared")
+}
+
+// Checkout godoc
+// @Summary Checkout from cart
+// @Description Creates an order from the items in the authenticated user's cart, generates a Midtrans Snap payment token and empties the cart. The chosen courier service is quoted again with Komerce for receiver_destination_id.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param checkout body models.CartCheckoutRequest true "Shipping and payment information"
+// @Success 201 {object} map[string]interface{} "Success response with order number, snap token, and payment URL"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or empty cart"
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 500 {object} map[string]interface{} "Server error during order creation or payment token generation"
+// @Router /api/v1/cart/checkout [post]
+func (h *CartHandler) Checkout(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	var req models.CartCheckoutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+	req.Reseller = customerTypeOf(c) == services.CustomerReseller
+
+	response, err := h.cartService.CheckoutFromCart(userID, &req)
+	if err != nil {
+		if err.Error() == "cart is empty" {
+			return utils.SendError(c, fiber.StatusBadRequest, err.Error(), nil)
+		}
+		return utils.SendError(c, fiber.StatusInternalServerError, err.Error(), nil)
+	}
+
+	return utils.SendCreated(c, response, "Order created successfully")
+}
+
+// GetGuestCart godoc
+// @Summary Get guest cart
+// @Description Get the anonymous cart identified by the signed guest cart cookie, revalidated like the user cart. Returns an empty cart when no cookie is present.
+// @Tags cart
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/guest-cart [get]
+func (h *CartHandler) GetGuestCart(c *fiber.Ctx) error {
+	guestID, ok := h.guestCartCookie.Get(c)
+	if !ok {
+		return utils.SendSuccess(c, cartResponse(&models.Cart{Items: []models.CartItem{}}, nil), "Cart retrieved successfully")
+	}
+
+	cart, warnings, err := h.cartService.RevalidateGuestCart(guestID)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get cart", err.Error())
+	}
+
+	return utils.SendSuccess(c, cartResponse(cart, warnings), "Cart retrieved successfully")
+}
+
+// AddGuestItem godoc
+// @Summary Add item to guest cart
+// @Description Add a product to the anonymous cart. A signed guest cart cookie is issued on first use; the cart is merged into the user's cart after login.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param item body models.AddToCartRequest true "Item to add"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Invalid request or insufficient stock"
+// @Failure 404 {object} map[string]interface{} "Product or variant not found"
+// @Router /api/v1/guest-cart/items [post]
+func (h *CartHandler) AddGuestItem(c *fiber.Ctx) error {
+	var req models.AddToCartRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	guestID, ok := h.guestCartCookie.Get(c)
+	if !ok {
+		var err error
+		guestID, err = h.cartService.NewGuestCartID()
+		if err != nil {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to create cart", err.Error())
+		}
+	}
+
+	cart, err := h.cartService.AddGuestItem(guestID, &req)
+	if err != nil {
+		return sendCartError(c, err)
+	}
+
+	// Refresh the cookie so it expires together with the Redis entry
+	h.guestCartCookie.Set(c, guestID)
+
+	return utils.SendSuccess(c, cartResponse(cart, nil), "Item added to cart")
+}
+
+// UpdateGuestItem godoc
+// @Summary Update guest cart item quantity
+// @Description Set the quantity of a line in the anonymous cart
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param item_id path int true "Cart item ID"
+// @Param item body models.UpdateCartItemRequest true "New quantity"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Invalid request or insufficient stock"
+// @Failure 404 {object} map[string]interface{} "Cart item not found"
+// @Router /api/v1/guest-cart/items/{item_id} [put]
+func (h *CartHandler) UpdateGuestItem(c *fiber.Ctx) error {
+	guestID, ok := h.guestCartCookie.Get(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusNotFound, "cart item not found", nil)
+	}
+
+	itemID, err := strconv.ParseUint(c.Params("item_id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cart item ID", nil)
+	}
+
+	var req models.UpdateCartItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	cart, err := h.cartService.UpdateGuestItemQuantity(guestID, uint(itemID), req.Quantity)
+	if err != nil {
+		return sendCartError(c, err)
+	}
+
+	h.guestCartCookie.Set(c, guestID)
+
+	return utils.SendSuccess(c, cartResponse(cart, nil), "Cart item updated")
+}
+
+// RemoveGuestItem godoc
+// @Summary Remove item from guest cart
+// @Description Remove a line from the anonymous cart
+// @Tags cart
+// @Produce json
+// @Param item_id path int true "Cart item ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Cart item not found"
+// @Router /api/v1/guest-cart/items/{item_id} [delete]
+func (h *CartHandler) RemoveGuestItem(c *fiber.Ctx) error {
+	guestID, ok := h.guestCartCookie.Get(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusNotFound, "cart item not found", nil)
+	}
+
+	itemID, err := strconv.ParseUint(c.Params("item_id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cart item ID", nil)
+	}
+
+	cart, err := h.cartService.RemoveGuestItem(guestID, uint(itemID))
+	if err != nil {
+		return sendCartError(c, err)
+	}
+
+	return utils.SendSuccess(c, cartResponse(cart, nil), "Cart item removed")
+}
+
+// cartResponse adds computed totals and revalidation warnings to the cart payload
+func cartResponse(cart *models.Cart, warnings []services.CartWarning) fiber.Map {
+	if warnings == nil {
+		warnings = []services.CartWarning{}
+	}
+	return fiber.Map{
+		"cart":       cart,
+		"subtotal":   cart.Subtotal(),
+		"item_count": cart.ItemCount(),
+		"warnings":   warnings,
+	}
+}
+
+// sendCartError maps cart service errors to HTTP status codes
+func sendCartError(c *fiber.Ctx, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.HasSuffix(msg, "not found"):
+		return utils.SendError(c, fiber.StatusNotFound, msg, nil)
+	case strings.HasPrefix(msg, "failed to"):
+		return utils.SendError(c, fiber.StatusInternalServerError, msg, nil)
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, msg, nil)
+	}
+}
+
+// getLocalUserID returns the local user ID set by the auth middleware
+func getLocalUserID(c *fiber.Ctx) (uint, bool) {
+	userID, ok := c.Locals("local_user_id").(uint)
+	if !ok || userID == 0 {
+		return 0, false
+	}
+	return userID, true
+}
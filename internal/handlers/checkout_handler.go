@@ -19,7 +19,7 @@ func NewCheckoutHandler(checkoutService services.CheckoutService) *CheckoutHandl
 
 // Checkout initiates the checkout process
 // @Summary Create Order and Generate Payment Token
-// @Description Creates a new order with items, calculates pricing including shipping and tax, then generates Midtrans Snap payment token. Returns order details and payment URL.
+// @Description Creates a new order with items, calculates pricing including shipping and tax, then generates Midtrans Snap payment token. Returns order details and payment URL. The chosen courier service is quoted again with Komerce for receiver_destination_id; a service the courier does not offer there, or a destination not found for shipping_postal_code, fails the checkout.
 // @Tags payment
 // @Accept json
 // @Produce json
@@ -32,11 +32,18 @@ func NewCheckoutHandler(checkoutService services.CheckoutService) *CheckoutHandl
 // @Failure 500 {object} map[string]interface{} "Server error during order creation or payment token generation"
 // @Router /api/v1/checkout [post]
 func (h *CheckoutHandler) Checkout(c *fiber.Ctx) error {
-	var req models.CheckoutRequest
+	// The order is placed for the signed-in user, at the prices their customer group gets
+	user, ok := c.Locals("user").(*models.User)
+	if !ok || user == nil {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
 
+	var req models.CheckoutRequest
 	if err := utils.ParseAndValidate(c, &req); err != nil {
 		return err
 	}
+	req.UserID = user.ID
+	req.Reseller = user.IsReseller()
 
 	// Process checkout
 	response, err := h.checkoutService.Checkout(&req)
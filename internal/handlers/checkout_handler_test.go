@@ -31,15 +31,25 @@ func (m *MockCheckoutService) ProcessPaymentNotification(notification *models.Mi
 	return args.Error(0)
 }
 
+// withSessionUser stands in for the auth middleware
+func withSessionUser(user *models.User) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if user != nil {
+			c.Locals("local_user_id", user.ID)
+			c.Locals("user", user)
+		}
+		return c.Next()
+	}
+}
+
 func TestCheckoutHandler_Checkout(t *testing.T) {
 	mockService := new(MockCheckoutService)
 	handler := NewCheckoutHandler(mockService)
 	app := fiber.New()
-	app.Post("/api/v1/checkout", handler.Checkout)
+	app.Post("/api/v1/checkout", withSessionUser(&models.User{ID: 1}), handler.Checkout)
 
 	// Valid request inputs
 	reqBody := models.CheckoutRequest{
-		UserID:        1,
 		Items:         []models.CheckoutItem{{ProductID: 1, Quantity: 1}},
 		PaymentMethod: "midtrans",
 		ShippingCity:  "Jakarta", // Assuming validation requires this
@@ -71,10 +81,9 @@ func TestCheckoutHandler_Checkout_ServiceError(t *testing.T) {
 	mockService := new(MockCheckoutService)
 	handler := NewCheckoutHandler(mockService)
 	app := fiber.New()
-	app.Post("/api/v1/checkout", handler.Checkout)
+	app.Post("/api/v1/checkout", withSessionUser(&models.User{ID: 1}), handler.Checkout)
 
 	reqBody := models.CheckoutRequest{
-		UserID:        1,
 		Items:         []models.CheckoutItem{{ProductID: 1, Quantity: 1}},
 		PaymentMethod: "midtrans",
 		ShippingCity:  "Jakarta",
@@ -93,6 +102,46 @@ func TestCheckoutHandler_Checkout_ServiceError(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestCheckoutHandler_Checkout_UserFromSession(t *testing.T) {
+	mockService := new(MockCheckoutService)
+	handler := NewCheckoutHandler(mockService)
+	app := fiber.New()
+	app.Post("/api/v1/checkout", withSessionUser(&models.User{ID: 7}), handler.Checkout)
+
+	mockService.On("Checkout", mock.MatchedBy(func(req *models.CheckoutRequest) bool {
+		return req.UserID == 7
+	})).Return(&models.CheckoutResponse{OrderNumber: "ORD123"}, nil)
+
+	// No user_id in the body; a forged one is ignored as well
+	for _, body := range []string{
+		`{"items":[{"product_id":1,"quantity":1}],"shipping_name":"Ani","shipping_phone":"0811","shipping_address":"Jl. Mawar 1","shipping_city":"Jakarta","shipping_province":"DKI Jakarta","shipping_postal_code":"12430","receiver_destination_id":"17615","shipping_courier":"JNE","shipping_service":"REG","payment_method":"bank_transfer"}`,
+		`{"user_id":99,"items":[{"product_id":1,"quantity":1}],"shipping_name":"Ani","shipping_phone":"0811","shipping_address":"Jl. Mawar 1","shipping_city":"Jakarta","shipping_province":"DKI Jakarta","shipping_postal_code":"12430","receiver_destination_id":"17615","shipping_courier":"JNE","shipping_service":"REG","payment_method":"bank_transfer"}`,
+	} {
+		req := httptest.NewRequest("POST", "/api/v1/checkout", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 201, resp.StatusCode)
+	}
+	mockService.AssertNumberOfCalls(t, "Checkout", 2)
+}
+
+func TestCheckoutHandler_Checkout_NoSession(t *testing.T) {
+	mockService := new(MockCheckoutService)
+	handler := NewCheckoutHandler(mockService)
+	app := fiber.New()
+	app.Post("/api/v1/checkout", handler.Checkout)
+
+	req := httptest.NewRequest("POST", "/api/v1/checkout", bytes.NewReader([]byte(`{"user_id":1}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+	mockService.AssertNotCalled(t, "Checkout", mock.Anything)
+}
+
 func TestCheckoutHandler_PaymentWebhook(t *testing.T) {
 	mockService := new(MockCheckoutService)
 	handler := NewCheckoutHandler(mockService)
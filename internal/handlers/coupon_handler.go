@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/services"
+	"github.com/karima-store/internal/utils"
+)
+
+type CouponHandler struct {
+	couponService services.CouponService
+}
+
+func NewCouponHandler(couponService services.CouponService) *CouponHandler {
+	return &CouponHandler{
+		couponService: couponService,
+	}
+}
+
+// ListCoupons godoc
+// @Summary List coupons
+// @Description List coupons, newest first, optionally filtered by status, batch or a code/name search (Admin only)
+// @Tags coupons
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param status query string false "Status filter" Enums(active, inactive, expired)
+// @Param batch_id query int false "Only codes from this generated batch"
+// @Param search query string false "Matches code or name"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Router /api/v1/admin/coupons [get]
+func (h *CouponHandler) ListCoupons(c *fiber.Ctx) error {
+	filter := models.CouponListFilter{
+		Status: models.CouponStatus(c.Query("status")),
+		Search: c.Query("search"),
+	}
+	switch filter.Status {
+	case "", models.CouponStatusActive, models.CouponStatusInactive, models.CouponStatusExpired:
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid status", nil)
+	}
+	if raw := c.Query("batch_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid batch ID", nil)
+		}
+		batchID := uint(id)
+		filter.BatchID = &batchID
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	coupons, total, err := h.couponService.ListCoupons(filter, limit, offset)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get coupons", err.Error())
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"coupons": coupons,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	}, "Coupons retrieved successfully")
+}
+
+// GetCoupon godoc
+// @Summary Get a coupon
+// @Description Get a coupon with its usage stats: redemptions, unique customers and total discount given (Admin only)
+// @Tags coupons
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Coupon ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Coupon not found"
+// @Router /api/v1/admin/coupons/{id} [get]
+func (h *CouponHandler) GetCoupon(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid coupon ID", nil)
+	}
+
+	coupon, err := h.couponService.GetCoupon(uint(id))
+	if err != nil {
+		return sendCouponError(c, err)
+	}
+
+	return utils.SendSuccess(c, coupon, "Coupon retrieved successfully")
+}
+
+// CreateCoupon godoc
+// @Summary Create a coupon
+// @Description Create a coupon code. for_retail and for_reseller default to true (Admin only)
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param coupon body models.CreateCouponRequest true "Coupon"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Coupon code already exists"
+// @Router /api/v1/admin/coupons [post]
+func (h *CouponHandler) CreateCoupon(c *fiber.Ctx) error {
+	var req models.CreateCouponRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	coupon, err := h.couponService.CreateCoupon(&req)
+	if err != nil {
+		return sendCouponError(c, err)
+	}
+
+	return utils.SendCreated(c, coupon, "Coupon created successfully")
+}
+
+// UpdateCoupon godoc
+// @Summary Update a coupon
+// @Description Change a coupon. Only the fields sent are changed; the code cannot be changed (Admin only)
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Coupon ID"
+// @Param coupon body models.UpdateCouponRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Coupon not found"
+// @Router /api/v1/admin/coupons/{id} [put]
+func (h *CouponHandler) UpdateCoupon(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid coupon ID", nil)
+	}
+
+	var req models.UpdateCouponRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	coupon, err := h.couponService.UpdateCoupon(uint(id), &req)
+	if err != nil {
+		return sendCouponError(c, err)
+	}
+
+	return utils.SendSuccess(c, coupon, "Coupon updated successfully")
+}
+
+// DeactivateCoupon godoc
+// @Summary Deactivate a coupon
+// @Description Stop a coupon from being redeemed; its usage history is kept (Admin only)
+// @Tags coupons
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Coupon ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Coupon not found"
+// @Router /api/v1/admin/coupons/{id}/deactivate [post]
+func (h *CouponHandler) DeactivateCoupon(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid coupon ID", nil)
+	}
+
+	coupon, err := h.couponService.DeactivateCoupon(uint(id))
+	if err != nil {
+		return sendCouponError(c, err)
+	}
+
+	return utils.SendSuccess(c, coupon, "Coupon deactivated")
+}
+
+// GenerateCoupons godoc
+// @Summary Generate single-use coupon codes
+// @Description Create a batch of unique single-use codes like PREFIX-7KQ2M9XP that share the same discount rules (Admin only)
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param batch body models.GenerateCouponsRequest true "Code template and shared rules"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/coupon-batches [post]
+func (h *CouponHandler) GenerateCoupons(c *fiber.Ctx) error {
+	var req models.GenerateCouponsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	batch, err := h.couponService.GenerateCoupons(&req)
+	if err != nil {
+		return sendCouponError(c, err)
+	}
+
+	return utils.SendCreated(c, batch, fmt.Sprintf("%d coupon codes generated", batch.Quantity))
+}
+
+// ListCouponBatches godoc
+// @Summary List coupon batches
+// @Description List batches created by the code generator, newest first (Admin only)
+// @Tags coupons
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Router /api/v1/admin/coupon-batches [get]
+func (h *CouponHandler) ListCouponBatches(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	batches, total, err := h.couponService.ListBatches(limit, offset)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get coupon batches", err.Error())
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"batches": batches,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	}, "Coupon batches retrieved successfully")
+}
+
+// GetCouponBatch godoc
+// @Summary Get a coupon batch
+// @Description Get a generated batch with the combined usage stats of its codes (Admin only)
+// @Tags coupons
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Batch ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Coupon batch not found"
+// @Router /api/v1/admin/coupon-batches/{id} [get]
+func (h *CouponHandler) GetCouponBatch(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid batch ID", nil)
+	}
+
+	batch, err := h.couponService.GetBatch(uint(id))
+	if err != nil {
+		return sendCouponError(c, err)
+	}
+
+	return utils.SendSuccess(c, batch, "Coupon batch retrieved successfully")
+}
+
+// ExportCouponBatch godoc
+// @Summary Export a coupon batch as CSV
+// @Description Download the codes of a generated batch with their rules and whether they were used (Admin only)
+// @Tags coupons
+// @Produce text/csv
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Batch ID"
+// @Success 200 {file} file "CSV file"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Coupon batch not found"
+// @Router /api/v1/admin/coupon-batches/{id}/export [get]
+func (h *CouponHandler) ExportCouponBatch(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid batch ID", nil)
+	}
+
+	var buf bytes.Buffer
+	if err := h.couponService.ExportBatch(uint(id), &buf); err != nil {
+		return sendCouponError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Attachment(fmt.Sprintf("coupon-batch-%d.csv", id))
+	return c.Send(buf.Bytes())
+}
+
+func sendCouponError(c *fiber.Ctx, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.HasSuffix(msg, "not found"):
+		return utils.SendError(c, fiber.StatusNotFound, msg, nil)
+	case strings.HasSuffix(msg, "already exists"):
+		return utils.SendError(c, fiber.StatusConflict, msg, nil)
+	case strings.HasPrefix(msg, "failed to"):
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update coupon", msg)
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, msg, nil)
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/services"
+	"github.com/karima-store/internal/utils"
+)
+
+type DiscountTierHandler struct {
+	tierService services.DiscountTierService
+}
+
+func NewDiscountTierHandler(tierService services.DiscountTierService) *DiscountTierHandler {
+	return &DiscountTierHandler{
+		tierService: tierService,
+	}
+}
+
+// ListDiscountTiers godoc
+// @Summary List discount tiers
+// @Description List reseller and bulk quantity tiers grouped by table, optionally filtered by kind, scope, category or product (Admin only)
+// @Tags discount-tiers
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param kind query string false "Kind filter" Enums(reseller, bulk)
+// @Param scope query string false "Scope filter" Enums(global, category, product)
+// @Param category query string false "Category filter"
+// @Param product_id query int false "Product filter"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Router /api/v1/admin/discount-tiers [get]
+func (h *DiscountTierHandler) ListDiscountTiers(c *fiber.Ctx) error {
+	filter := models.DiscountTierListFilter{
+		Kind:     models.DiscountTierKind(c.Query("kind")),
+		Scope:    models.DiscountTierScope(c.Query("scope")),
+		Category: models.ProductCategory(c.Query("category")),
+	}
+	switch filter.Kind {
+	case "", models.DiscountTierReseller, models.DiscountTierBulk:
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid kind", nil)
+	}
+	switch filter.Scope {
+	case "", models.DiscountTierScopeGlobal, models.DiscountTierScopeCategory, models.DiscountTierScopeProduct:
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid scope", nil)
+	}
+	if raw := c.Query("product_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid product ID", nil)
+		}
+		productID := uint(id)
+		filter.ProductID = &productID
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	tiers, total, err := h.tierService.ListTiers(filter, limit, offset)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get discount tiers", err.Error())
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"tiers":  tiers,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	}, "Discount tiers retrieved successfully")
+}
+
+// GetDiscountTier godoc
+// @Summary Get a discount tier
+// @Description Get a reseller or bulk quantity tier (Admin only)
+// @Tags discount-tiers
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Discount tier ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Discount tier not found"
+// @Router /api/v1/admin/discount-tiers/{id} [get]
+func (h *DiscountTierHandler) GetDiscountTier(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid discount tier ID", nil)
+	}
+
+	tier, err := h.tierService.GetTier(uint(id))
+	if err != nil {
+		return sendDiscountTierError(c, err)
+	}
+
+	return utils.SendSuccess(c, tier, "Discount tier retrieved successfully")
+}
+
+// CreateDiscountTier godoc
+// @Summary Create a discount tier
+// @Description Add a quantity breakpoint to a reseller or bulk tier table. A product's own tiers replace its category's, which replace the global ones. is_active defaults to true (Admin only)
+// @Tags discount-tiers
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param tier body models.DiscountTierRequest true "Discount tier"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/discount-tiers [post]
+func (h *DiscountTierHandler) CreateDiscountTier(c *fiber.Ctx) error {
+	var req models.DiscountTierRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	tier, err := h.tierService.CreateTier(&req)
+	if err != nil {
+		return sendDiscountTierError(c, err)
+	}
+
+	return utils.SendCreated(c, tier, "Discount tier created successfully")
+}
+
+// UpdateDiscountTier godoc
+// @Summary Replace a discount tier
+// @Description Replace every field of a discount tier (Admin only)
+// @Tags discount-tiers
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Discount tier ID"
+// @Param tier body models.DiscountTierRequest true "Discount tier"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Discount tier not found"
+// @Router /api/v1/admin/discount-tiers/{id} [put]
+func (h *DiscountTierHandler) UpdateDiscountTier(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid discount tier ID", nil)
+	}
+
+	var req models.DiscountTierRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	tier, err := h.tierService.UpdateTier(uint(id), &req)
+	if err != nil {
+		return sendDiscountTierError(c, err)
+	}
+
+	return utils.SendSuccess(c, tier, "Discount tier updated successfully")
+}
+
+// DeleteDiscountTier godoc
+// @Summary Delete a discount tier
+// @Description Delete a discount tier; orders already placed keep their prices (Admin only)
+// @Tags discount-tiers
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Discount tier ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Discount tier not found"
+// @Router /api/v1/admin/discount-tiers/{id} [delete]
+func (h *DiscountTierHandler) DeleteDiscountTier(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid discount tier ID", nil)
+	}
+
+	if err := h.tierService.DeleteTier(uint(id)); err != nil {
+		return sendDiscountTierError(c, err)
+	}
+
+	return utils.SendSuccess(c, nil, "Discount tier deleted successfully")
+}
+
+func sendDiscountTierError(c *fiber.Ctx, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "failed to"):
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update discount tier", msg)
+	case msg == "discount tier not found":
+		return utils.SendError(c, fiber.StatusNotFound, msg, nil)
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, msg, nil)
+	}
+}
@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/services"
+	"github.com/karima-store/internal/utils"
+)
+
+type FlashSaleHandler struct {
+	flashSaleService services.FlashSaleService
+}
+
+func NewFlashSaleHandler(flashSaleService services.FlashSaleService) *FlashSaleHandler {
+	return &FlashSaleHandler{
+		flashSaleService: flashSaleService,
+	}
+}
+
+// GetCurrentFlashSales godoc
+// @Summary Get current flash sales
+// @Description Get flash sales that are running now or scheduled to start, with their products
+// @Tags flash-sales
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/flash-sales [get]
+func (h *FlashSaleHandler) GetCurrentFlashSales(c *fiber.Ctx) error {
+	sales, err := h.flashSaleService.GetCurrentFlashSales()
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get flash sales", err.Error())
+	}
+
+	return utils.SendSuccess(c, sales, "Flash sales retrieved successfully")
+}
+
+// ListFlashSales godoc
+// @Summary List flash sales
+// @Description List all flash sales, optionally filtered by status (Admin only)
+// @Tags flash-sales
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param status query string false "Status filter" Enums(upcoming, active, ended, cancelled)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Router /api/v1/admin/flash-sales [get]
+func (h *FlashSaleHandler) ListFlashSales(c *fiber.Ctx) error {
+	status := models.FlashSaleStatus(c.Query("status"))
+	switch status {
+	case "", models.FlashSaleUpcoming, models.FlashSaleActive, models.FlashSaleEnded, models.FlashSaleCancelled:
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid status", nil)
+	}
+
+	sales, err := h.flashSaleService.ListFlashSales(status)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get flash sales", err.Error())
+	}
+
+	return utils.SendSuccess(c, sales, "Flash sales retrieved successfully")
+}
+
+// GetFlashSale godoc
+// @Summary Get a flash sale
+// @Description Get a flash sale with its products (Admin only)
+// @Tags flash-sales
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Flash sale ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Flash sale not found"
+// @Router /api/v1/admin/flash-sales/{id} [get]
+func (h *FlashSaleHandler) GetFlashSale(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid flash sale ID", nil)
+	}
+
+	sale, err := h.flashSaleService.GetFlashSale(uint(id))
+	if err != nil {
+		return sendFlashSaleError(c, err)
+	}
+
+	return utils.SendSuccess(c, sale, "Flash sale retrieved successfully")
+}
+
+// CreateFlashSale godoc
+// @Summary Create a flash sale
+// @Description Schedule a flash sale. It becomes active at start_time and ends at end_time automatically (Admin only)
+// @Tags flash-sales
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param flash_sale body models.CreateFlashSaleRequest true "Flash sale"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Router /api/v1/admin/flash-sales [post]
+func (h *FlashSaleHandler) CreateFlashSale(c *fiber.Ctx) error {
+	var req models.CreateFlashSaleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	sale, err := h.flashSaleService.CreateFlashSale(&req)
+	if err != nil {
+		return sendFlashSaleError(c, err)
+	}
+
+	return utils.SendCreated(c, sale, "Flash sale created successfully")
+}
+
+// UpdateFlashSale godoc
+// @Summary Update a flash sale
+// @Description Change a flash sale that has not ended. Only the fields sent are changed (Admin only)
+// @Tags flash-sales
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Flash sale ID"
+// @Param flash_sale body models.UpdateFlashSaleRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Flash sale not found"
+// @Failure 409 {object} map[string]interface{} "Flash sale already ended"
+// @Router /api/v1/admin/flash-sales/{id} [put]
+func (h *FlashSaleHandler) UpdateFlashSale(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid flash sale ID", nil)
+	}
+
+	var req models.UpdateFlashSaleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	sale, err := h.flashSaleService.UpdateFlashSale(uint(id), &req)
+	if err != nil {
+		return sendFlashSaleError(c, err)
+	}
+
+	return utils.SendSuccess(c, sale, "Flash sale updated successfully")
+}
+
+// CancelFlashSale godoc
+// @Summary Cancel a flash sale
+// @Description Stop an upcoming or running flash sale; prices return to normal immediately (Admin only)
+// @Tags flash-sales
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Flash sale ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Flash sale not found"
+// @Failure 409 {object} map[string]interface{} "Flash sale already ended"
+// @Router /api/v1/admin/flash-sales/{id}/cancel [post]
+func (h *FlashSaleHandler) CancelFlashSale(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid flash sale ID", nil)
+	}
+
+	sale, err := h.flashSaleService.CancelFlashSale(uint(id))
+	if err != nil {
+		return sendFlashSaleError(c, err)
+	}
+
+	return utils.SendSuccess(c, sale, "Flash sale cancelled")
+}
+
+// DeleteFlashSale godoc
+// @Summary Delete a flash sale
+// @Description Delete a flash sale that is not running (Admin only)
+// @Tags flash-sales
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Flash sale ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Flash sale not found"
+// @Failure 409 {object} map[string]interface{} "Flash sale is active"
+// @Router /api/v1/admin/flash-sales/{id} [delete]
+func (h *FlashSaleHandler) DeleteFlashSale(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid flash sale ID", nil)
+	}
+
+	if err := h.flashSaleService.DeleteFlashSale(uint(id)); err != nil {
+		return sendFlashSaleError(c, err)
+	}
+
+	return utils.SendSuccess(c, nil, "Flash sale deleted successfully")
+}
+
+// GetFlashSaleProducts godoc
+// @Summary Get flash sale products
+// @Description Get the products in a flash sale with their sale price, stock and sold count (Admin only)
+// @Tags flash-sales
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Flash sale ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Flash sale not found"
+// @Router /api/v1/admin/flash-sales/{id}/products [get]
+func (h *FlashSaleHandler) GetFlashSaleProducts(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid flash sale ID", nil)
+	}
+
+	products, err := h.flashSaleService.GetProducts(uint(id))
+	if err != nil {
+		return sendFlashSaleError(c, err)
+	}
+
+	return utils.SendSuccess(c, products, "Flash sale products retrieved successfully")
+}
+
+// AddFlashSaleProduct godoc
+// @Summary Add a product to a flash sale
+// @Description Put a product in a flash sale at a price below its regular price. Set variant_id to discount only one size or color (Admin only)
+// @Tags flash-sales
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Flash sale ID"
+// @Param product body models.FlashSaleProductRequest true "Product and sale terms"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Flash sale or product not found"
+// @Failure 409 {object} map[string]interface{} "Product already in flash sale"
+// @Router /api/v1/admin/flash-sales/{id}/products [post]
+func (h *FlashSaleHandler) AddFlashSaleProduct(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid flash sale ID", nil)
+	}
+
+	var req models.FlashSaleProductRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	item, err := h.flashSaleService.AddProduct(uint(id), &req)
+	if err != nil {
+		return sendFlashSaleError(c, err)
+	}
+
+	return utils.SendCreated(c, item, "Product added to flash sale")
+}
+
+// UpdateFlashSaleProduct godoc
+// @Summary Update a flash sale product
+// @Description Change the sale price or stock of a product, or of one variant's entry, in a flash sale (Admin only)
+// @Tags flash-sales
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Flash sale ID"
+// @Param product_id path int true "Product ID"
+// @Param variant_id query int false "Variant ID, for a variant's own entry"
+// @Param product body models.UpdateFlashSaleProductRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Flash sale product not found"
+// @Router /api/v1/admin/flash-sales/{id}/products/{product_id} [put]
+func (h *FlashSaleHandler) UpdateFlashSaleProduct(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid flash sale ID", nil)
+	}
+	productID, err := strconv.ParseUint(c.Params("product_id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid product ID", nil)
+	}
+
+	variantID, err := queryVariantID(c)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid variant ID", nil)
+	}
+
+	var req models.UpdateFlashSaleProductRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	item, err := h.flashSaleService.UpdateProduct(uint(id), uint(productID), variantID, &req)
+	if err != nil {
+		return sendFlashSaleError(c, err)
+	}
+
+	return utils.SendSuccess(c, item, "Flash sale product updated")
+}
+
+// RemoveFlashSaleProduct godoc
+// @Summary Remove a product from a flash sale
+// @Description Take a product, or one variant's entry, out of a flash sale (Admin only)
+// @Tags flash-sales
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Flash sale ID"
+// @Param product_id path int true "Product ID"
+// @Param variant_id query int false "Variant ID, for a variant's own entry"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Flash sale product not found"
+// @Router /api/v1/admin/flash-sales/{id}/products/{product_id} [delete]
+func (h *FlashSaleHandler) RemoveFlashSaleProduct(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid flash sale ID", nil)
+	}
+	productID, err := strconv.ParseUint(c.Params("product_id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid product ID", nil)
+	}
+
+	variantID, err := queryVariantID(c)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid variant ID", nil)
+	}
+
+	if err := h.flashSaleService.RemoveProduct(uint(id), uint(productID), variantID); err != nil {
+		return sendFlashSaleError(c, err)
+	}
+
+	return utils.SendSuccess(c, nil, "Product removed from flash sale")
+}
+
+func sendFlashSaleError(c *fiber.Ctx, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.HasSuffix(msg, "not found"):
+		return utils.SendError(c, fiber.StatusNotFound, msg, nil)
+	case strings.HasSuffix(msg, "already ended"), strings.HasPrefix(msg, "cannot delete"),
+		strings.HasSuffix(msg, "is already in this flash sale"), strings.HasSuffix(msg, "please retry"):
+		return utils.SendError(c, fiber.StatusConflict, msg, nil)
+	case strings.HasPrefix(msg, "failed to"):
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update flash sale", msg)
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, msg, nil)
+	}
+}
+
+// queryVariantID reads the optional variant_id query parameter
+func queryVariantID(c *fiber.Ctx) (*uint, error) {
+	raw := c.Query("variant_id")
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || id == 0 {
+		return nil, fiber.ErrBadRequest
+	}
+	variantID := uint(id)
+	return &variantID, nil
+}
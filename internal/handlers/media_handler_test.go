@@ -51,6 +51,19 @@ func (m *MockMediaService) ValidateImageFile(fileHeader *multipart.FileHeader) e
 	return args.Error(0)
 }
 
+func (m *MockMediaService) StoreImage(fileHeader *multipart.FileHeader, folder string) (*services.StoredImage, error) {
+	args := m.Called(fileHeader, folder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.StoredImage), args.Error(1)
+}
+
+func (m *MockMediaService) DeleteStoredImage(image *services.StoredImage) error {
+	args := m.Called(image)
+	return args.Error(0)
+}
+
 func TestMediaHandler_UploadImage(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/services"
+	"github.com/karima-store/internal/utils"
+)
+
+type PriceScheduleHandler struct {
+	scheduleService services.PriceScheduleService
+}
+
+func NewPriceScheduleHandler(scheduleService services.PriceScheduleService) *PriceScheduleHandler {
+	return &PriceScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// ListPriceChanges godoc
+// @Summary List scheduled price changes
+// @Description List scheduled price changes, latest effective first, optionally filtered by product or status (Admin only)
+// @Tags price-changes
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param product_id query int false "Product filter"
+// @Param status query string false "Status filter" Enums(pending, applied, cancelled, failed)
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Router /api/v1/admin/price-changes [get]
+func (h *PriceScheduleHandler) ListPriceChanges(c *fiber.Ctx) error {
+	filter := models.PriceChangeListFilter{
+		Status: models.PriceChangeStatus(c.Query("status")),
+	}
+	switch filter.Status {
+	case "", models.PriceChangePending, models.PriceChangeApplied, models.PriceChangeCancelled, models.PriceChangeFailed:
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid status", nil)
+	}
+	if raw := c.Query("product_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid product ID", nil)
+		}
+		productID := uint(id)
+		filter.ProductID = &productID
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	changes, total, err := h.scheduleService.ListPriceChanges(filter, limit, offset)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get price changes", err.Error())
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"price_changes": changes,
+		"total":         total,
+		"limit":         limit,
+		"offset":        offset,
+	}, "Price changes retrieved successfully")
+}
+
+// GetPriceChange godoc
+// @Summary Get a scheduled price change
+// @Description Get a scheduled price change and its status (Admin only)
+// @Tags price-changes
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Price change ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Price change not found"
+// @Router /api/v1/admin/price-changes/{id} [get]
+func (h *PriceScheduleHandler) GetPriceChange(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid price change ID", nil)
+	}
+
+	change, err := h.scheduleService.GetPriceChange(uint(id))
+	if err != nil {
+		return sendPriceScheduleError(c, err)
+	}
+
+	return utils.SendSuccess(c, change, "Price change retrieved successfully")
+}
+
+// SchedulePriceChange godoc
+// @Summary Schedule a price change
+// @Description Schedule a product's price, compare price or discount, or a variant's price, to change at effective_at. Fields left out keep their value. The change is applied by the price scheduler and recorded in the price history (Admin only)
+// @Tags price-changes
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param price_change body models.ScheduledPriceChangeRequest true "Price change"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Product or variant not found"
+// @Router /api/v1/admin/price-changes [post]
+func (h *PriceScheduleHandler) SchedulePriceChange(c *fiber.Ctx) error {
+	var req models.ScheduledPriceChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	var actorID *uint
+	if userID, ok := getLocalUserID(c); ok {
+		actorID = &userID
+	}
+
+	change, err := h.scheduleService.SchedulePriceChange(&req, actorID)
+	if err != nil {
+		return sendPriceScheduleError(c, err)
+	}
+
+	return utils.SendCreated(c, change, "Price change scheduled successfully")
+}
+
+// CancelPriceChange godoc
+// @Summary Cancel a scheduled price change
+// @Description Cancel a price change that has not been applied yet (Admin only)
+// @Tags price-changes
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Price change ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Price change is no longer pending"
+// @Failure 404 {object} map[string]interface{} "Price change not found"
+// @Router /api/v1/admin/price-changes/{id} [delete]
+func (h *PriceScheduleHandler) CancelPriceChange(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid price change ID", nil)
+	}
+
+	if err := h.scheduleService.CancelPriceChange(uint(id)); err != nil {
+		return sendPriceScheduleError(c, err)
+	}
+
+	return utils.SendSuccess(c, nil, "Price change cancelled successfully")
+}
+
+// GetPriceHistory godoc
+// @Summary Get a product's price history
+// @Description Get the price changes applied to a product over the last days days, with the lowest selling price it had in that window. With variant_id the variant's own changes are included and prices are the variant's
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variant_id query int false "Variant ID"
+// @Param days query int false "Window in days (max 365)" default(30)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Product or variant not found"
+// @Router /api/v1/products/{id}/price-history [get]
+func (h *PriceScheduleHandler) GetPriceHistory(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid product ID", nil)
+	}
+
+	var variantID *uint
+	if raw := c.Query("variant_id"); raw != "" {
+		vid, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || vid == 0 {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid variant ID", nil)
+		}
+		v := uint(vid)
+		variantID = &v
+	}
+
+	days, err := strconv.Atoi(c.Query("days", "30"))
+	if err != nil || days <= 0 {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid days", nil)
+	}
+
+	history, err := h.scheduleService.GetPriceHistory(uint(id), variantID, days)
+	if err != nil {
+		return sendPriceScheduleError(c, err)
+	}
+
+	return utils.SendSuccess(c, history, "Price history retrieved successfully")
+}
+
+func sendPriceScheduleError(c *fiber.Ctx, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "failed to"):
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update price change", msg)
+	case strings.HasSuffix(msg, "not found"):
+		return utils.SendError(c, fiber.StatusNotFound, msg, nil)
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, msg, nil)
+	}
+}
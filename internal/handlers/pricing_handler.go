@@ -9,6 +9,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/models"
 	"github.com/karima-store/internal/services"
 )
 
@@ -26,7 +27,7 @@ func NewPricingHandler(pricingService services.PricingService, redis database.Re
 
 // CalculatePrice calculates the price for a product
 // @Summary Calculate product price
-// @Description Calculate the final price based on customer type, quantity, and active flash sales
+// @Description Calculate the final price based on quantity and active flash sales. Signed-in approved resellers get reseller prices; everyone else gets retail prices
 // @Tags pricing
 // @Accept json
 // @Produce json
@@ -47,14 +48,7 @@ func (h *PricingHandler) CalculatePrice(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate customer type
-	if req.CustomerType != services.CustomerRetail && req.CustomerType != services.CustomerReseller {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Invalid customer type. Must be 'retail' or 'reseller'",
-			"code":    400,
-		})
-	}
+	req.CustomerType = customerTypeOf(c)
 
 	response, err := h.pricingService.CalculatePrice(req)
 	if err != nil {
@@ -73,14 +67,13 @@ func (h *PricingHandler) CalculatePrice(c *fiber.Ctx) error {
 
 // CalculatePriceByParams calculates price using query parameters
 // @Summary Calculate product price by parameters
-// @Description Calculate the final price using query parameters instead of JSON body
+// @Description Calculate the final price using query parameters instead of JSON body. The customer type comes from the signed-in user, as for POST
 // @Tags pricing
 // @Accept json
 // @Produce json
 // @Param product_id query int true "Product ID"
 // @Param variant_id query int false "Variant ID"
 // @Param quantity query int true "Quantity"
-// @Param customer_type query string true "Customer type (retail or reseller)"
 // @Success 200 {object} map[string]interface{} "Price calculation response"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 404 {object} map[string]interface{} "Product not found"
@@ -141,30 +134,11 @@ func (h *PricingHandler) CalculatePriceByParams(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse customer_type
-	customerTypeStr := c.Query("customer_type")
-	if customerTypeStr == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "error",
-			"message": "customer_type is required",
-			"code":    400,
-		})
-	}
-
-	customerType := services.CustomerType(customerTypeStr)
-	if customerType != services.CustomerRetail && customerType != services.CustomerReseller {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Invalid customer_type. Must be 'retail' or 'reseller'",
-			"code":    400,
-		})
-	}
-
 	req := services.PriceCalculationRequest{
 		ProductID:    uint(productID),
 		VariantID:    variantID,
 		Quantity:     quantity,
-		CustomerType: customerType,
+		CustomerType: customerTypeOf(c),
 	}
 
 	response, err := h.pricingService.CalculatePrice(req)
@@ -239,7 +213,7 @@ func (h *PricingHandler) CalculateShippingCost(c *fiber.Ctx) error {
 
 // CalculateOrderSummary calculates complete order summary
 // @Summary Calculate order summary
-// @Description Calculate complete order summary including pricing and shipping
+// @Description Calculate complete order summary including pricing and shipping, at reseller prices for signed-in approved resellers. A coupon's per-user limit is checked for the signed-in user
 // @Tags pricing
 // @Accept json
 // @Produce json
@@ -260,15 +234,6 @@ func (h *PricingHandler) CalculateOrderSummary(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate customer type
-	if req.CustomerType != services.CustomerRetail && req.CustomerType != services.CustomerReseller {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Invalid customer type. Must be 'retail' or 'reseller'",
-			"code":    400,
-		})
-	}
-
 	// Validate shipping type
 	validShippingTypes := []string{"jne", "tiki", "pos", "sicepat"}
 	isValid := false
@@ -287,7 +252,8 @@ func (h *PricingHandler) CalculateOrderSummary(c *fiber.Ctx) error {
 		})
 	}
 
-	response, err := h.pricingService.CalculateOrderSummary(req.Items, req.Shipping, req.CustomerType)
+	customerType := customerTypeOf(c)
+	response, err := h.pricingService.CalculateOrderSummary(req.Items, req.Shipping, customerType)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  "error",
@@ -296,6 +262,21 @@ func (h *PricingHandler) CalculateOrderSummary(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.CouponCode != "" {
+		couponReq := services.CouponCalculationRequest{
+			Code:         req.CouponCode,
+			UserID:       userIDOf(c),
+			CustomerType: customerType,
+		}
+		if err := h.pricingService.ApplyCouponToOrderSummary(response, couponReq); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": err.Error(),
+				"code":    400,
+			})
+		}
+	}
+
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data":   response,
@@ -304,16 +285,14 @@ func (h *PricingHandler) CalculateOrderSummary(c *fiber.Ctx) error {
 
 // OrderSummaryRequest represents the request body for order summary calculation
 type OrderSummaryRequest struct {
-	Items        []services.PriceCalculationRequest  `json:"items"`
-	Shipping     services.ShippingCalculationRequest `json:"shipping"`
-	CustomerType services.CustomerType               `json:"customer_type"`
-	CouponCode   string                              `json:"coupon_code,omitempty"`
-	UserID       uint                                `json:"user_id,omitempty"`
+	Items      []services.PriceCalculationRequest  `json:"items"`
+	Shipping   services.ShippingCalculationRequest `json:"shipping"`
+	CouponCode string                              `json:"coupon_code,omitempty"`
 }
 
 // ValidateCoupon validates a coupon code
 // @Summary Validate coupon code
-// @Description Validate if a coupon code is valid and applicable
+// @Description Validate if a coupon code is valid and applicable. Send the cart items to check product and category restrictions; the discount then covers eligible items only and excluded_items explains the rest. A coupon's per-user limit is checked for the signed-in user
 // @Tags pricing
 // @Accept json
 // @Produce json
@@ -336,12 +315,13 @@ func (h *PricingHandler) ValidateCoupon(c *fiber.Ctx) error {
 
 	couponReq := services.CouponCalculationRequest{
 		Code:           req.Code,
-		UserID:         req.UserID,
+		UserID:         userIDOf(c),
 		PurchaseAmount: req.PurchaseAmount,
-		CustomerType:   req.CustomerType,
+		CustomerType:   customerTypeOf(c),
+		Items:          req.Items,
 	}
 
-	discount, couponName, err := h.pricingService.CalculateCouponDiscount(couponReq)
+	coupon, err := h.pricingService.CalculateCouponDiscount(couponReq)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  "error",
@@ -353,28 +333,32 @@ func (h *PricingHandler) ValidateCoupon(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"valid":           true,
-			"coupon_name":     couponName,
-			"discount_amount": discount,
+			"valid":             true,
+			"coupon_name":       coupon.Name,
+			"discount_amount":   coupon.Discount,
+			"eligible_subtotal": coupon.EligibleSubtotal,
+			"lines":             coupon.Lines,
+			"excluded_items":    coupon.ExcludedItems,
 		},
 	})
 }
 
 // CouponValidationRequest represents the request body for coupon validation
 type CouponValidationRequest struct {
-	Code           string                `json:"code"`
-	UserID         uint                  `json:"user_id"`
-	PurchaseAmount float64               `json:"purchase_amount"`
-	CustomerType   services.CustomerType `json:"customer_type"`
+	Code           string       `json:"code"`
+	PurchaseAmount models.Money `json:"purchase_amount"`
+	// Items are the cart lines; a line without subtotal is priced by the server
+	Items []services.CouponLineItem `json:"items,omitempty"`
 }
 
 // GetPricingInfo returns pricing information for a product
 // @Summary Get pricing information
-// @Description Get pricing information including available discounts and tiering
+// @Description Get pricing information including available discounts and tiering. flash_sale_match tells whether a variant's own flash sale entry or the product-level one set the price. The reseller price is only shown to signed-in approved resellers
 // @Tags pricing
 // @Accept json
 // @Produce json
 // @Param product_id path int true "Product ID"
+// @Param variant_id query int false "Variant ID"
 // @Success 200 {object} map[string]interface{} "Pricing information"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 404 {object} map[string]interface{} "Product not found"
@@ -399,8 +383,24 @@ func (h *PricingHandler) GetPricingInfo(c *fiber.Ctx) error {
 		})
 	}
 
-	// Create cache key
+	var variantID *uint
 	cacheKey := fmt.Sprintf("pricing:%d", productID)
+	if raw := c.Query("variant_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || id == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Invalid variant_id",
+				"code":    400,
+			})
+		}
+		vID := uint(id)
+		variantID = &vID
+		cacheKey = fmt.Sprintf("pricing:%d:variant:%d", productID, id)
+	}
+
+	// The cache holds both prices; only resellers get to see theirs
+	showReseller := customerTypeOf(c) == services.CustomerReseller
 
 	// Check cache first
 	val, err := h.redisClient.Get(c.Context(), cacheKey)
@@ -408,6 +408,9 @@ func (h *PricingHandler) GetPricingInfo(c *fiber.Ctx) error {
 		// Cache hit - parse and return
 		var cachedData map[string]interface{}
 		if err := json.Unmarshal([]byte(val), &cachedData); err == nil {
+			if !showReseller {
+				delete(cachedData, "reseller")
+			}
 			return c.JSON(fiber.Map{
 				"status": "success",
 				"data":   cachedData,
@@ -419,12 +422,14 @@ func (h *PricingHandler) GetPricingInfo(c *fiber.Ctx) error {
 	// Get pricing info for both retail and reseller
 	retailReq := services.PriceCalculationRequest{
 		ProductID:    uint(productID),
+		VariantID:    variantID,
 		Quantity:     1,
 		CustomerType: services.CustomerRetail,
 	}
 
 	resellerReq := services.PriceCalculationRequest{
 		ProductID:    uint(productID),
+		VariantID:    variantID,
 		Quantity:     1,
 		CustomerType: services.CustomerReseller,
 	}
@@ -458,8 +463,27 @@ func (h *PricingHandler) GetPricingInfo(c *fiber.Ctx) error {
 		log.Printf("Failed to cache pricing data: %v", err)
 	}
 
+	if !showReseller {
+		delete(response, "reseller")
+	}
+
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data":   response,
 	})
 }
+
+// userIDOf returns the signed-in user's ID, or 0 for guests. Per-user coupon limits are checked
+// against it, so it never comes from the request body.
+func userIDOf(c *fiber.Ctx) uint {
+	if user, ok := c.Locals("user").(*models.User); ok && user != nil {
+		return user.ID
+	}
+	return 0
+}
+
+// customerTypeOf returns the prices the signed-in user buys at; guests pay retail
+func customerTypeOf(c *fiber.Ctx) services.CustomerType {
+	user, _ := c.Locals("user").(*models.User)
+	return services.CustomerTypeFor(user)
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/karima-store/internal/models"
 	"github.com/karima-store/internal/services"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -46,12 +47,15 @@ func (m *MockPricingService) CalculateOrderSummary(items []services.PriceCalcula
 	return args.Get(0).(*services.OrderSummary), args.Error(1)
 }
 
-func (m *MockPricingService) CalculateCouponDiscount(req services.CouponCalculationRequest) (float64, string, error) {
+func (m *MockPricingService) CalculateCouponDiscount(req services.CouponCalculationRequest) (*services.CouponDiscount, error) {
 	args := m.Called(req)
-	return args.Get(0).(float64), args.String(1), args.Error(2)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.CouponDiscount), args.Error(1)
 }
 
-func (m *MockPricingService) CheckFreeShipping(orderAmount float64, regionCode string) (bool, error) {
+func (m *MockPricingService) CheckFreeShipping(orderAmount models.Money, regionCode string) (bool, error) {
 	args := m.Called(orderAmount, regionCode)
 	return args.Bool(0), args.Error(1)
 }
@@ -61,6 +65,11 @@ func (m *MockPricingService) ApplyCouponToPriceCalculation(resp *services.PriceC
 	return args.Error(0)
 }
 
+func (m *MockPricingService) ApplyCouponToOrderSummary(summary *services.OrderSummary, couponReq services.CouponCalculationRequest) error {
+	args := m.Called(summary, couponReq)
+	return args.Error(0)
+}
+
 // MockRedisClient is a mock implementation of RedisClient
 type MockRedisClient struct {
 	mock.Mock
@@ -71,6 +80,11 @@ func (m *MockRedisClient) Get(ctx context.Context, key string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockRedisClient) GetDel(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	args := m.Called(ctx, key, value, expiration)
 	return args.Error(0)
@@ -165,3 +179,81 @@ func TestPricingHandler_CalculatePrice(t *testing.T) {
 		})
 	}
 }
+
+func TestPricingHandler_CalculatePrice_CustomerTypeFromUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     *models.User
+		expected services.CustomerType
+	}{
+		{name: "Guest", expected: services.CustomerRetail},
+		{name: "Retail user", user: &models.User{ID: 1, IsActive: true, CustomerGroup: models.CustomerGroupRetail}, expected: services.CustomerRetail},
+		{name: "Reseller", user: &models.User{ID: 2, IsActive: true, CustomerGroup: models.CustomerGroupReseller}, expected: services.CustomerReseller},
+		{name: "Inactive reseller", user: &models.User{ID: 3, IsActive: false, CustomerGroup: models.CustomerGroupReseller}, expected: services.CustomerRetail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPricingService)
+			mockService.On("CalculatePrice", mock.MatchedBy(func(req services.PriceCalculationRequest) bool {
+				return req.CustomerType == tt.expected
+			})).Return(&services.PriceCalculationResponse{FinalPrice: 20000}, nil)
+
+			handler := NewPricingHandler(mockService, new(MockRedisClient))
+			app := fiber.New()
+			app.Post("/calculate", func(c *fiber.Ctx) error {
+				if tt.user != nil {
+					c.Locals("user", tt.user)
+				}
+				return c.Next()
+			}, handler.CalculatePrice)
+
+			// The customer type in the body is ignored
+			body := []byte(`{"product_id": 1, "quantity": 2, "customer_type": "reseller"}`)
+			req := httptest.NewRequest("POST", "/calculate", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPricingHandler_ValidateCoupon_UserFromSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     *models.User
+		expected uint
+	}{
+		{name: "Guest", expected: 0},
+		{name: "Signed in", user: &models.User{ID: 5, IsActive: true}, expected: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPricingService)
+			mockService.On("CalculateCouponDiscount", mock.MatchedBy(func(req services.CouponCalculationRequest) bool {
+				return req.UserID == tt.expected
+			})).Return(&services.CouponDiscount{Code: "HEMAT10"}, nil)
+
+			handler := NewPricingHandler(mockService, new(MockRedisClient))
+			app := fiber.New()
+			app.Post("/coupons/validate", func(c *fiber.Ctx) error {
+				if tt.user != nil {
+					c.Locals("user", tt.user)
+				}
+				return c.Next()
+			}, handler.ValidateCoupon)
+
+			// The user_id in the body is ignored
+			body := []byte(`{"code": "HEMAT10", "user_id": 99, "purchase_amount": 100000}`)
+			req := httptest.NewRequest("POST", "/coupons/validate", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
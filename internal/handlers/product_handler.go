@@ -180,7 +180,7 @@ func (h *ProductHandler) GetProducts(c *fiber.Ctx) error {
 
 // UpdateProduct updates an existing product
 // @Summary Update product (Admin only)
-// @Description Update an existing product by ID. **Admin only**: Requires authentication with admin role.
+// @Description Update an existing product by ID. Price, compare price and discount changes are recorded in the price history. **Admin only**: Requires authentication with admin role.
 // @Tags products
 // @Accept json
 // @Produce json
@@ -210,7 +210,12 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 		return err
 	}
 
-	if err := h.productService.UpdateProduct(uint(id), &product); err != nil {
+	var actorID *uint
+	if userID, ok := getLocalUserID(c); ok {
+		actorID = &userID
+	}
+
+	if err := h.productService.UpdateProduct(uint(id), &product, actorID); err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			return utils.SendError(c, fiber.StatusNotFound, "Product not found", nil)
 		}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/services"
+	"github.com/karima-store/internal/utils"
+)
+
+type PromotionHandler struct {
+	promotionService services.PromotionService
+}
+
+func NewPromotionHandler(promotionService services.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+	}
+}
+
+// ListPromotions godoc
+// @Summary List promotions
+// @Description List automatic promotions, highest priority first (Admin only)
+// @Tags promotions
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Router /api/v1/admin/promotions [get]
+func (h *PromotionHandler) ListPromotions(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	promotions, total, err := h.promotionService.ListPromotions(limit, offset)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get promotions", err.Error())
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"promotions": promotions,
+		"total":      total,
+		"limit":      limit,
+		"offset":     offset,
+	}, "Promotions retrieved successfully")
+}
+
+// GetPromotion godoc
+// @Summary Get a promotion
+// @Description Get an automatic promotion with its tiers (Admin only)
+// @Tags promotions
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Promotion not found"
+// @Router /api/v1/admin/promotions/{id} [get]
+func (h *PromotionHandler) GetPromotion(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid promotion ID", nil)
+	}
+
+	promotion, err := h.promotionService.GetPromotion(uint(id))
+	if err != nil {
+		return sendPromotionError(c, err)
+	}
+
+	return utils.SendSuccess(c, promotion, "Promotion retrieved successfully")
+}
+
+// CreatePromotion godoc
+// @Summary Create a promotion
+// @Description Create an automatic promotion: buy_x_get_y, tiered_spend, free_shipping or gift. stackable, for_retail and for_reseller default to true (Admin only)
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param promotion body models.PromotionRequest true "Promotion"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/promotions [post]
+func (h *PromotionHandler) CreatePromotion(c *fiber.Ctx) error {
+	var req models.PromotionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	promotion, err := h.promotionService.CreatePromotion(&req)
+	if err != nil {
+		return sendPromotionError(c, err)
+	}
+
+	return utils.SendCreated(c, promotion, "Promotion created successfully")
+}
+
+// UpdatePromotion godoc
+// @Summary Replace a promotion
+// @Description Replace every field of a promotion, tiers included (Admin only)
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Promotion ID"
+// @Param promotion body models.PromotionRequest true "Promotion"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Promotion not found"
+// @Router /api/v1/admin/promotions/{id} [put]
+func (h *PromotionHandler) UpdatePromotion(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid promotion ID", nil)
+	}
+
+	var req models.PromotionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	promotion, err := h.promotionService.UpdatePromotion(uint(id), &req)
+	if err != nil {
+		return sendPromotionError(c, err)
+	}
+
+	return utils.SendSuccess(c, promotion, "Promotion updated successfully")
+}
+
+// DeletePromotion godoc
+// @Summary Delete a promotion
+// @Description Delete an automatic promotion; orders already placed keep their discount (Admin only)
+// @Tags promotions
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Promotion not found"
+// @Router /api/v1/admin/promotions/{id} [delete]
+func (h *PromotionHandler) DeletePromotion(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid promotion ID", nil)
+	}
+
+	if err := h.promotionService.DeletePromotion(uint(id)); err != nil {
+		return sendPromotionError(c, err)
+	}
+
+	return utils.SendSuccess(c, nil, "Promotion deleted successfully")
+}
+
+func sendPromotionError(c *fiber.Ctx, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "failed to"):
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update promotion", msg)
+	case msg == "promotion not found":
+		return utils.SendError(c, fiber.StatusNotFound, msg, nil)
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, msg, nil)
+	}
+}
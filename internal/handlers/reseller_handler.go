@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/services"
+	"github.com/karima-store/internal/utils"
+)
+
+type ResellerHandler struct {
+	resellerService services.ResellerService
+}
+
+func NewResellerHandler(resellerService services.ResellerService) *ResellerHandler {
+	return &ResellerHandler{
+		resellerService: resellerService,
+	}
+}
+
+// Apply godoc
+// @Summary Apply to become a reseller
+// @Description Send a reseller application for the authenticated user. Reseller prices apply once an admin approves it
+// @Tags resellers
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param application body models.ResellerApplicationRequest true "Business details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Invalid request, already a reseller or an application is pending"
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Router /api/v1/users/me/reseller-application [post]
+func (h *ResellerHandler) Apply(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*models.User)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	var req models.ResellerApplicationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	application, err := h.resellerService.Apply(user, &req)
+	if err != nil {
+		return sendResellerError(c, err)
+	}
+
+	return utils.SendCreated(c, application, "Reseller application submitted successfully")
+}
+
+// GetMyApplication godoc
+// @Summary Get my reseller application
+// @Description Get the authenticated user's latest reseller application and its review status
+// @Tags resellers
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 404 {object} map[string]interface{} "No reseller application"
+// @Router /api/v1/users/me/reseller-application [get]
+func (h *ResellerHandler) GetMyApplication(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	application, err := h.resellerService.GetMyApplication(userID)
+	if err != nil {
+		return sendResellerError(c, err)
+	}
+
+	return utils.SendSuccess(c, application, "Reseller application retrieved successfully")
+}
+
+// ListApplications godoc
+// @Summary List reseller applications
+// @Description List reseller applications oldest first, optionally filtered by status (Admin only)
+// @Tags resellers
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param status query string false "Status filter" Enums(pending, approved, rejected)
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Router /api/v1/admin/reseller-applications [get]
+func (h *ResellerHandler) ListApplications(c *fiber.Ctx) error {
+	status := models.ResellerApplicationStatus(c.Query("status"))
+	switch status {
+	case "", models.ResellerApplicationPending, models.ResellerApplicationApproved, models.ResellerApplicationRejected:
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid status", nil)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	applications, total, err := h.resellerService.ListApplications(status, limit, offset)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get reseller applications", err.Error())
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"applications": applications,
+		"total":        total,
+		"limit":        limit,
+		"offset":       offset,
+	}, "Reseller applications retrieved successfully")
+}
+
+// ApproveApplication godoc
+// @Summary Approve a reseller application
+// @Description Approve a pending application; the applicant gets reseller prices from their next request (Admin only)
+// @Tags resellers
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Reseller application ID"
+// @Param review body models.ResellerReviewRequest false "Review note"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Application already reviewed"
+// @Failure 404 {object} map[string]interface{} "Reseller application not found"
+// @Router /api/v1/admin/reseller-applications/{id}/approve [post]
+func (h *ResellerHandler) ApproveApplication(c *fiber.Ctx) error {
+	return h.review(c, h.resellerService.Approve, "Reseller application approved successfully")
+}
+
+// RejectApplication godoc
+// @Summary Reject a reseller application
+// @Description Reject a pending application; the applicant may apply again (Admin only)
+// @Tags resellers
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Reseller application ID"
+// @Param review body models.ResellerReviewRequest false "Review note"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Application already reviewed"
+// @Failure 404 {object} map[string]interface{} "Reseller application not found"
+// @Router /api/v1/admin/reseller-applications/{id}/reject [post]
+func (h *ResellerHandler) RejectApplication(c *fiber.Ctx) error {
+	return h.review(c, h.resellerService.Reject, "Reseller application rejected successfully")
+}
+
+func (h *ResellerHandler) review(c *fiber.Ctx, decide func(id, reviewerID uint, note string) (*models.ResellerApplication, error), message string) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid reseller application ID", nil)
+	}
+	reviewerID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	var req models.ResellerReviewRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+		}
+		if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+			return utils.SendValidationError(c, errs)
+		}
+	}
+
+	application, err := decide(uint(id), reviewerID, req.Note)
+	if err != nil {
+		return sendResellerError(c, err)
+	}
+
+	return utils.SendSuccess(c, application, message)
+}
+
+// RevokeReseller godoc
+// @Summary Revoke reseller prices
+// @Description Move a reseller back to retail prices (Admin only)
+// @Tags resellers
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param user_id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Router /api/v1/admin/resellers/{user_id}/revoke [post]
+func (h *ResellerHandler) RevokeReseller(c *fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Params("user_id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	if err := h.resellerService.Revoke(uint(userID)); err != nil {
+		return sendResellerError(c, err)
+	}
+
+	return utils.SendSuccess(c, nil, "Reseller revoked successfully")
+}
+
+func sendResellerError(c *fiber.Ctx, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "failed to"):
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update reseller", msg)
+	case strings.HasSuffix(msg, "not found"):
+		return utils.SendError(c, fiber.StatusNotFound, msg, nil)
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, msg, nil)
+	}
+}
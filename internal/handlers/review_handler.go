@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/services"
+	"github.com/karima-store/internal/utils"
+)
+
+type ReviewHandler struct {
+	reviewService services.ReviewService
+}
+
+func NewReviewHandler(reviewService services.ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+// CreateReview godoc
+// @Summary Review a product
+// @Description Review a product from a delivered order. Reviews are marked as verified purchases and are published after moderation. Each product can be reviewed once.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param review body models.CreateReviewRequest true "Review"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Product was not delivered to this user"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Failure 409 {object} map[string]interface{} "Product already reviewed"
+// @Router /api/v1/reviews [post]
+func (h *ReviewHandler) CreateReview(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	var req models.CreateReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	review, err := h.reviewService.CreateReview(userID, &req)
+	if err != nil {
+		return sendReviewError(c, err)
+	}
+
+	return utils.SendCreated(c, review, "Review submitted and awaiting moderation")
+}
+
+// GetProductReviews godoc
+// @Summary Get product reviews
+// @Description Get the approved reviews for a product, newest first
+// @Tags reviews
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/products/{id}/reviews [get]
+func (h *ReviewHandler) GetProductReviews(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid product ID", nil)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	reviews, total, err := h.reviewService.GetProductReviews(uint(productID), limit, offset)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get reviews", err.Error())
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"reviews": reviews,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	}, "Reviews retrieved successfully")
+}
+
+// GetMyReviews godoc
+// @Summary Get current user's reviews
+// @Description Get the reviews written by the authenticated user, including those awaiting moderation
+// @Tags reviews
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/reviews/me [get]
+func (h *ReviewHandler) GetMyReviews(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	reviews, total, err := h.reviewService.GetUserReviews(userID, limit, offset)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get reviews", err.Error())
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"reviews": reviews,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	}, "Reviews retrieved successfully")
+}
+
+// VoteReview godoc
+// @Summary Vote on a review
+// @Description Mark a published review as helpful or not helpful. Each user can vote once per review and not on their own review.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Review ID"
+// @Param vote body models.VoteReviewRequest true "Vote"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Own review"
+// @Failure 404 {object} map[string]interface{} "Review not found"
+// @Failure 409 {object} map[string]interface{} "Already voted"
+// @Router /api/v1/reviews/{id}/vote [post]
+func (h *ReviewHandler) VoteReview(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	reviewID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid review ID", nil)
+	}
+
+	var req models.VoteReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	review, err := h.reviewService.VoteReview(userID, uint(reviewID), *req.Helpful)
+	if err != nil {
+		return sendReviewError(c, err)
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"review_id":         review.ID,
+		"helpful_count":     review.HelpfulCount,
+		"not_helpful_count": review.NotHelpfulCount,
+	}, "Vote recorded")
+}
+
+// UploadReviewImage godoc
+// @Summary Add a photo to a review
+// @Description Attach a photo (JPG, PNG, GIF or WebP) to your own review while it is awaiting moderation. Up to 5 photos per review.
+// @Tags reviews
+// @Accept multipart/form-data
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Review ID"
+// @Param image formData file true "Image file"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 404 {object} map[string]interface{} "Review not found"
+// @Failure 409 {object} map[string]interface{} "Review already moderated or photo limit reached"
+// @Router /api/v1/reviews/{id}/images [post]
+func (h *ReviewHandler) UploadReviewImage(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	reviewID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid review ID", nil)
+	}
+
+	file, err := c.FormFile("image")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "No image provided", nil)
+	}
+
+	image, err := h.reviewService.AddReviewImage(userID, uint(reviewID), file)
+	if err != nil {
+		return sendReviewError(c, err)
+	}
+
+	return utils.SendCreated(c, image, "Photo added to review")
+}
+
+// GetPendingReviews godoc
+// @Summary Get reviews awaiting moderation
+// @Description Get reviews that have not been approved or rejected yet, oldest first (Admin only)
+// @Tags reviews
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/reviews/pending [get]
+func (h *ReviewHandler) GetPendingReviews(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	reviews, total, err := h.reviewService.GetPendingReviews(limit, offset)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get pending reviews", err.Error())
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"reviews": reviews,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	}, "Pending reviews retrieved successfully")
+}
+
+// ApproveReview godoc
+// @Summary Approve a review
+// @Description Publish a review and recompute the product rating (Admin only)
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Review ID"
+// @Param moderation body models.ModerateReviewRequest false "Admin notes"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Failure 404 {object} map[string]interface{} "Review not found"
+// @Router /api/v1/admin/reviews/{id}/approve [put]
+func (h *ReviewHandler) ApproveReview(c *fiber.Ctx) error {
+	return h.moderateReview(c, true)
+}
+
+// RejectReview godoc
+// @Summary Reject a review
+// @Description Hide a review and recompute the product rating (Admin only)
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Review ID"
+// @Param moderation body models.ModerateReviewRequest false "Admin notes"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Failure 404 {object} map[string]interface{} "Review not found"
+// @Router /api/v1/admin/reviews/{id}/reject [put]
+func (h *ReviewHandler) RejectReview(c *fiber.Ctx) error {
+	return h.moderateReview(c, false)
+}
+
+func (h *ReviewHandler) moderateReview(c *fiber.Ctx, approve bool) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid review ID", nil)
+	}
+
+	// The body is optional; notes can be left out
+	var req models.ModerateReviewRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+		}
+		if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+			return utils.SendValidationError(c, errs)
+		}
+	}
+
+	var review *models.Review
+	message := "Review approved"
+	if approve {
+		review, err = h.reviewService.ApproveReview(uint(id), req.AdminNotes)
+	} else {
+		review, err = h.reviewService.RejectReview(uint(id), req.AdminNotes)
+		message = "Review rejected"
+	}
+	if err != nil {
+		return sendReviewError(c, err)
+	}
+
+	return utils.SendSuccess(c, review, message)
+}
+
+// BulkModerate godoc
+// @Summary Approve or reject several reviews
+// @Description Apply one moderation decision to up to 100 reviews in a single transaction (Admin only)
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param moderation body models.BulkModerateReviewsRequest true "Reviews and decision"
+// @Success 200 {object} services.BulkModerationResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Router /api/v1/admin/reviews/moderate [post]
+func (h *ReviewHandler) BulkModerate(c *fiber.Ctx) error {
+	var req models.BulkModerateReviewsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	result, err := h.reviewService.BulkModerate(&req)
+	if err != nil {
+		return sendReviewError(c, err)
+	}
+
+	return utils.SendSuccess(c, result, "Reviews moderated")
+}
+
+// DeleteReview godoc
+// @Summary Delete a review
+// @Description Delete a review and recompute the product rating (Admin only)
+// @Tags reviews
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Review ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Failure 404 {object} map[string]interface{} "Review not found"
+// @Router /api/v1/admin/reviews/{id} [delete]
+func (h *ReviewHandler) DeleteReview(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid review ID", nil)
+	}
+
+	if err := h.reviewService.DeleteReview(uint(id)); err != nil {
+		return sendReviewError(c, err)
+	}
+
+	return utils.SendSuccess(c, nil, "Review deleted")
+}
+
+func sendReviewError(c *fiber.Ctx, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.HasSuffix(msg, "not found"):
+		return utils.SendError(c, fiber.StatusNotFound, msg, nil)
+	case strings.HasPrefix(msg, "you have already"), strings.HasPrefix(msg, "photos can only"), strings.HasPrefix(msg, "a review can have"):
+		return utils.SendError(c, fiber.StatusConflict, msg, nil)
+	case strings.HasPrefix(msg, "only customers"), strings.HasPrefix(msg, "you cannot"):
+		return utils.SendError(c, fiber.StatusForbidden, msg, nil)
+	case strings.HasPrefix(msg, "rating must"), strings.HasPrefix(msg, "action must"), strings.HasPrefix(msg, "invalid image"):
+		return utils.SendError(c, fiber.StatusBadRequest, msg, nil)
+	default:
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to process review", msg)
+	}
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/services"
+	"github.com/karima-store/internal/utils"
+)
+
+type ShippingZoneHandler struct {
+	zoneService services.ShippingZoneService
+}
+
+func NewShippingZoneHandler(zoneService services.ShippingZoneService) *ShippingZoneHandler {
+	return &ShippingZoneHandler{
+		zoneService: zoneService,
+	}
+}
+
+// ListShippingZones godoc
+// @Summary List shipping zones
+// @Description List shipping zones with their regions, rates and free shipping thresholds (Admin only)
+// @Tags shipping-zones
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param status query string false "Status filter" Enums(active, inactive)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 403 {object} map[string]interface{} "Forbidden: Admin access required"
+// @Router /api/v1/admin/shipping-zones [get]
+func (h *ShippingZoneHandler) ListShippingZones(c *fiber.Ctx) error {
+	status := models.ShippingZoneStatus(c.Query("status"))
+	switch status {
+	case "", models.ShippingZoneActive, models.ShippingZoneInactive:
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid status", nil)
+	}
+
+	zones, err := h.zoneService.ListZones(status)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get shipping zones", err.Error())
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"zones": zones,
+		"total": len(zones),
+	}, "Shipping zones retrieved successfully")
+}
+
+// GetShippingZone godoc
+// @Summary Get a shipping zone
+// @Description Get a shipping zone with its regions (Admin only)
+// @Tags shipping-zones
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Shipping zone ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Shipping zone not found"
+// @Router /api/v1/admin/shipping-zones/{id} [get]
+func (h *ShippingZoneHandler) GetShippingZone(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid shipping zone ID", nil)
+	}
+
+	zone, err := h.zoneService.GetZone(uint(id))
+	if err != nil {
+		return sendShippingZoneError(c, err)
+	}
+
+	return utils.SendSuccess(c, zone, "Shipping zone retrieved successfully")
+}
+
+// CreateShippingZone godoc
+// @Summary Create a shipping zone
+// @Description Create a shipping zone. Region codes are matched case-insensitively; a region in both lists is excluded. Courier rates and minimum_cost left out get the default rates, and status defaults to active (Admin only)
+// @Tags shipping-zones
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param zone body models.ShippingZoneRequest true "Shipping zone"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/shipping-zones [post]
+func (h *ShippingZoneHandler) CreateShippingZone(c *fiber.Ctx) error {
+	var req models.ShippingZoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	zone, err := h.zoneService.CreateZone(&req)
+	if err != nil {
+		return sendShippingZoneError(c, err)
+	}
+
+	return utils.SendCreated(c, zone, "Shipping zone created successfully")
+}
+
+// UpdateShippingZone godoc
+// @Summary Replace a shipping zone
+// @Description Replace every field and region of a shipping zone (Admin only)
+// @Tags shipping-zones
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Shipping zone ID"
+// @Param zone body models.ShippingZoneRequest true "Shipping zone"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Shipping zone not found"
+// @Router /api/v1/admin/shipping-zones/{id} [put]
+func (h *ShippingZoneHandler) UpdateShippingZone(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid shipping zone ID", nil)
+	}
+
+	var req models.ShippingZoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	zone, err := h.zoneService.UpdateZone(uint(id), &req)
+	if err != nil {
+		return sendShippingZoneError(c, err)
+	}
+
+	return utils.SendSuccess(c, zone, "Shipping zone updated successfully")
+}
+
+// DeleteShippingZone godoc
+// @Summary Delete a shipping zone
+// @Description Delete a shipping zone; its regions fall back to the default rates (Admin only)
+// @Tags shipping-zones
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param id path int true "Shipping zone ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Shipping zone not found"
+// @Router /api/v1/admin/shipping-zones/{id} [delete]
+func (h *ShippingZoneHandler) DeleteShippingZone(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid shipping zone ID", nil)
+	}
+
+	if err := h.zoneService.DeleteZone(uint(id)); err != nil {
+		return sendShippingZoneError(c, err)
+	}
+
+	return utils.SendSuccess(c, nil, "Shipping zone deleted successfully")
+}
+
+func sendShippingZoneError(c *fiber.Ctx, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "failed to"):
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update shipping zone", msg)
+	case msg == "shipping zone not found":
+		return utils.SendError(c, fiber.StatusNotFound, msg, nil)
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, msg, nil)
+	}
+}
@@ -104,6 +104,42 @@ func (h *UserHandler) GetCurrentUser(c *fiber.Ctx) error {
 	}, "Current user retrieved successfully")
 }
 
+// UpdateNotificationPreferences godoc
+// @Summary Update notification preferences
+// @Description Opt in or out of marketing WhatsApp messages such as abandoned cart reminders
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param body body models.UpdateNotificationPreferencesRequest true "Notification preferences"
+// @Security KratosSession
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /users/me/notifications [put]
+func (h *UserHandler) UpdateNotificationPreferences(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	user, err := h.userService.SetWhatsAppOptOut(userID, *req.WhatsAppOptOut)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update notification preferences", err)
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"whatsapp_opt_out": user.WhatsAppOptOut,
+	}, "Notification preferences updated successfully")
+}
+
 // UpdateUserRole godoc
 // @Summary Update user role
 // @Description Update a user's role (admin only)
@@ -154,7 +154,7 @@ func (h *VariantHandler) GetVariantsByProductID(c *fiber.Ctx) error {
 
 // UpdateVariant updates an existing variant
 // @Summary Update variant (Admin only)
-// @Description Update an existing variant by ID. **Admin only**: Requires authentication with admin role.
+// @Description Update an existing variant by ID. Price changes are recorded in the price history. **Admin only**: Requires authentication with admin role.
 // @Tags variants
 // @Accept json
 // @Produce json
@@ -188,7 +188,12 @@ func (h *VariantHandler) UpdateVariant(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.variantService.UpdateVariant(uint(id), &variant); err != nil {
+	var actorID *uint
+	if userID, ok := getLocalUserID(c); ok {
+		actorID = &userID
+	}
+
+	if err := h.variantService.UpdateVariant(uint(id), &variant, actorID); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"status":  "error",
 			"message": err.Error(),
@@ -44,8 +44,8 @@ func (m *MockVariantService) GetVariantsByProductID(productID uint) ([]models.Pr
 	return args.Get(0).([]models.ProductVariant), args.Error(1)
 }
 
-func (m *MockVariantService) UpdateVariant(id uint, variant *models.ProductVariant) error {
-	args := m.Called(id, variant)
+func (m *MockVariantService) UpdateVariant(id uint, variant *models.ProductVariant, actorID *uint) error {
+	args := m.Called(id, variant, actorID)
 	return args.Error(0)
 }
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -38,6 +39,21 @@ func (m *MockNotificationService) SendShippingNotification(order *models.Order,
 	return args.Error(0)
 }
 
+func (m *MockNotificationService) SendAbandonedCartNotification(user *models.User, cart *models.Cart, coupon *models.Coupon) error {
+	args := m.Called(user, cart, coupon)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) SendBackInStockNotification(user *models.User, products []models.Product) error {
+	args := m.Called(user, products)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) SendPriceDropNotification(user *models.User, product *models.Product, oldPrice float64, price *services.PriceCalculationResponse) error {
+	args := m.Called(user, product, oldPrice, price)
+	return args.Error(0)
+}
+
 func (m *MockNotificationService) GetWhatsAppStatus() (string, error) {
 	args := m.Called()
 	return args.String(0), args.Error(1)
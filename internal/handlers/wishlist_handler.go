@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/services"
+	"github.com/karima-store/internal/utils"
+)
+
+type WishlistHandler struct {
+	wishlistService services.WishlistService
+}
+
+func NewWishlistHandler(wishlistService services.WishlistService) *WishlistHandler {
+	return &WishlistHandler{
+		wishlistService: wishlistService,
+	}
+}
+
+// GetWishlist godoc
+// @Summary Get current user's wishlist
+// @Description Get the products the authenticated user has wishlisted, newest first
+// @Tags wishlist
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/wishlist [get]
+func (h *WishlistHandler) GetWishlist(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	items, total, err := h.wishlistService.GetWishlist(userID, limit, offset)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get wishlist", err.Error())
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"items":  items,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	}, "Wishlist retrieved successfully")
+}
+
+// AddItem godoc
+// @Summary Add product to wishlist
+// @Description Wishlist a product. You will get a WhatsApp message when it comes back in stock (unless opted out). Adding a product twice returns the existing entry.
+// @Tags wishlist
+// @Accept json
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param item body models.AddToWishlistRequest true "Product to wishlist"
+// @Success 200 {object} map[string]interface{} "Already in wishlist"
+// @Success 201 {object} map[string]interface{} "Added to wishlist"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Router /api/v1/wishlist/items [post]
+func (h *WishlistHandler) AddItem(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	var req models.AddToWishlistRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if errs := utils.ValidateStruct(&req); len(errs) > 0 {
+		return utils.SendValidationError(c, errs)
+	}
+
+	item, created, err := h.wishlistService.AddItem(userID, req.ProductID)
+	if err != nil {
+		return sendWishlistError(c, err)
+	}
+
+	if created {
+		return utils.SendCreated(c, item, "Product added to wishlist")
+	}
+	return utils.SendSuccess(c, item, "Product is already in wishlist")
+}
+
+// RemoveItem godoc
+// @Summary Remove product from wishlist
+// @Description Remove a product from the authenticated user's wishlist
+// @Tags wishlist
+// @Produce json
+// @Security KratosSession []
+// @Security KratosSessionCookie []
+// @Param product_id path int true "Product ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Unauthorized: No valid session or session expired"
+// @Failure 404 {object} map[string]interface{} "Wishlist item not found"
+// @Router /api/v1/wishlist/items/{product_id} [delete]
+func (h *WishlistHandler) RemoveItem(c *fiber.Ctx) error {
+	userID, ok := getLocalUserID(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "User not authenticated", nil)
+	}
+
+	productID, err := strconv.ParseUint(c.Params("product_id"), 10, 32)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid product ID", nil)
+	}
+
+	if err := h.wishlistService.RemoveItem(userID, uint(productID)); err != nil {
+		return sendWishlistError(c, err)
+	}
+
+	return utils.SendSuccess(c, nil, "Product removed from wishlist")
+}
+
+func sendWishlistError(c *fiber.Ctx, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.HasSuffix(msg, "not found"):
+		return utils.SendError(c, fiber.StatusNotFound, msg, nil)
+	case strings.HasSuffix(msg, "discontinued"):
+		return utils.SendError(c, fiber.StatusBadRequest, msg, nil)
+	default:
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update wishlist", msg)
+	}
+}
@@ -362,6 +362,16 @@ func DefaultSecureFileUploadConfig() SecureFileUploadConfig {
 	}
 }
 
+// ImageUploadConfig returns the secure upload configuration for user-submitted photos:
+// images only, required, with the default size and dimension limits
+func ImageUploadConfig() SecureFileUploadConfig {
+	config := DefaultSecureFileUploadConfig()
+	config.AllowedMimeTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+	config.AllowedExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".webp"}
+	config.Required = true
+	return config
+}
+
 // SecureFileUpload creates a secure file upload middleware
 func SecureFileUpload(fieldName string, config SecureFileUploadConfig) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -10,6 +10,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/karima-store/internal/models"
 	"github.com/karima-store/internal/services"
+	"github.com/karima-store/internal/utils"
 )
 
 // KratosAuthProvider handles authentication via Ory Kratos
@@ -17,6 +18,10 @@ type KratosAuthProvider struct {
 	kratosPublicURL string
 	kratosAdminURL  string
 	authService     services.AuthService
+
+	// Optional: merge anonymous carts into the user cart on login
+	cartService     services.CartService
+	guestCartCookie *utils.SignedCookie
 }
 
 // NewKratosMiddleware creates a new Kratos middleware instance
@@ -28,6 +33,13 @@ func NewKratosMiddleware(publicURL, adminURL string, authService services.AuthSe
 	}
 }
 
+// EnableGuestCartMerge makes authenticated requests merge the browser's guest cart
+// (identified by the signed guest cart cookie) into the user's persistent cart
+func (m *KratosAuthProvider) EnableGuestCartMerge(cartService services.CartService, guestCartCookie *utils.SignedCookie) {
+	m.cartService = cartService
+	m.guestCartCookie = guestCartCookie
+}
+
 // Authenticate validates Kratos session from cookie
 func (m *KratosAuthProvider) Authenticate() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -78,6 +90,8 @@ func (m *KratosAuthProvider) Authenticate() fiber.Handler {
 		c.Locals("session", session)
 		c.Locals("user", user)
 
+		m.mergeGuestCart(c, user.ID)
+
 		return c.Next()
 	}
 }
@@ -124,37 +138,70 @@ func (m *KratosAuthProvider) RequireAdmin() fiber.Handler {
 	return m.RequireRole("admin")
 }
 
-// OptionalAuth validates session if present, but doesn't require it
+// OptionalAuth validates a Bearer token or session cookie if present, but doesn't require it.
+// Requests with a valid session get the same locals as ValidateToken; the rest go on as guests.
 func (m *KratosAuthProvider) OptionalAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		sessionCookie := c.Cookies("ory_kratos_session")
-		if sessionCookie == "" {
+		sessionToken := c.Cookies("ory_kratos_session")
+		if parts := strings.Split(c.Get("Authorization"), " "); len(parts) == 2 && parts[0] == "Bearer" {
+			sessionToken = parts[1]
+		}
+		if sessionToken == "" {
 			return c.Next()
 		}
 
-		session, err := m.validateSession(sessionCookie)
+		session, err := m.validateSession(sessionToken)
 		if err != nil || !session.Active {
 			return c.Next()
 		}
 
 		// Extract user information
 		email, _ := session.Identity.Traits["email"].(string)
-		role, _ := session.Identity.Traits["role"].(string)
 
-		if role == "" {
-			role = "user"
+		user, err := m.authService.SyncUser(&session.Identity, email)
+		if err != nil {
+			fmt.Printf("User sync failed: %v\n", err)
+			return c.Next()
 		}
 
 		// Set user information in context
 		c.Locals("identity_id", session.Identity.ID)
 		c.Locals("user_email", email)
-		c.Locals("user_role", role)
+		c.Locals("user_role", user.Role)
+		c.Locals("local_user_id", user.ID)
 		c.Locals("session", session)
+		c.Locals("user", user)
 
 		return c.Next()
 	}
 }
 
+// mergeGuestCart merges and forgets the guest cart the first time a browser is seen with a session.
+// Failures are logged only; they must never block authentication.
+func (m *KratosAuthProvider) mergeGuestCart(c *fiber.Ctx, userID uint) {
+	if m.cartService == nil || m.guestCartCookie == nil {
+		return
+	}
+
+	guestID, ok := m.guestCartCookie.Get(c)
+	if !ok {
+		return
+	}
+
+	result, err := m.cartService.MergeGuestCart(guestID, userID)
+	if err != nil {
+		fmt.Printf("Guest cart merge failed for user %d: %v\n", userID, err)
+		return
+	}
+
+	if len(result.AdjustedItems) > 0 || len(result.SkippedItems) > 0 {
+		fmt.Printf("Guest cart merged for user %d: %d merged, adjusted %v, skipped %v\n",
+			userID, result.MergedItems, result.AdjustedItems, result.SkippedItems)
+	}
+
+	m.guestCartCookie.Clear(c)
+}
+
 // validateSession calls Kratos whoami endpoint to validate session
 func (m *KratosAuthProvider) validateSession(sessionToken string) (*models.KratosSession, error) {
 	req, err := http.NewRequest("GET", m.kratosPublicURL+"/sessions/whoami", nil)
@@ -252,19 +299,28 @@ func (m *KratosAuthProvider) ValidateToken() fiber.Handler {
 			})
 		}
 
-		// Extract user information
+		// Extract user information from traits
 		email, _ := session.Identity.Traits["email"].(string)
-		role, _ := session.Identity.Traits["role"].(string)
 
-		if role == "" {
-			role = "user"
+		// Sync user with local database so handlers can resolve the local user ID
+		user, err := m.authService.SyncUser(&session.Identity, email)
+		if err != nil {
+			fmt.Printf("User sync failed: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to sync user data",
+				"code":  "INTERNAL_SERVER_ERROR",
+			})
 		}
 
 		// Set user information in context
 		c.Locals("identity_id", session.Identity.ID)
 		c.Locals("user_email", email)
-		c.Locals("user_role", role)
+		c.Locals("user_role", user.Role)
+		c.Locals("local_user_id", user.ID)
 		c.Locals("session", session)
+		c.Locals("user", user)
+
+		m.mergeGuestCart(c, user.ID)
 
 		return c.Next()
 	}
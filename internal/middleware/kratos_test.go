@@ -262,6 +262,45 @@ func TestKratosMiddleware_OptionalAuth(t *testing.T) {
 	assert.Equal(t, fiber.StatusOK, resp3.StatusCode)
 }
 
+func TestKratosMiddleware_OptionalAuth_LoadsUser(t *testing.T) {
+	ts := mockKratosServer()
+	defer ts.Close()
+
+	app := fiber.New()
+	mockAuthService := &MockAuthService{}
+	kratosMiddleware := NewKratosMiddleware(ts.URL, ts.URL, mockAuthService)
+
+	app.Get("/optional", kratosMiddleware.OptionalAuth(), func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(*models.User)
+		if !ok {
+			return c.SendString("guest")
+		}
+		return c.SendString(fmt.Sprintf("user %d", user.ID))
+	})
+
+	for name, tc := range map[string]struct {
+		header, cookie, want string
+	}{
+		"guest":         {want: "guest"},
+		"bearer token":  {header: "Bearer valid-session-token", want: "user 1"},
+		"cookie":        {cookie: "valid-session-token", want: "user 1"},
+		"invalid token": {header: "Bearer invalid-session-token", want: "guest"},
+	} {
+		req := httptest.NewRequest("GET", "/optional", nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		if tc.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "ory_kratos_session", Value: tc.cookie})
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err, name)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode, name)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, tc.want, string(body), name)
+	}
+}
+
 func TestKratosMiddleware_SessionData(t *testing.T) {
 	ts := mockKratosServer()
 	defer ts.Close()
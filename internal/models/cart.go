@@ -40,11 +40,12 @@ type CartItem struct {
 	ProductName  string  `json:"product_name" gorm:"not null;size:200"`
 	ProductSKU   string  `json:"product_sku" gorm:"size:100"`
 	ProductImage string  `json:"product_image" gorm:"size:500"`
-	UnitPrice    float64 `json:"unit_price" gorm:"not null"`
+	UnitPrice    Money   `json:"unit_price" gorm:"not null"`
+	DiscountType string  `json:"discount_type" gorm:"size:20;not null;default:'none'"` // pricing rule behind UnitPrice
 
 	// Cart item details
 	Quantity    int     `json:"quantity" gorm:"not null;default:1"`
-	TotalPrice  float64 `json:"total_price" gorm:"not null"`
+	TotalPrice  Money   `json:"total_price" gorm:"not null"`
 
 	// Variant info (if applicable)
 	VariantName string `json:"variant_name" gorm:"size:100"`
@@ -55,3 +56,59 @@ type CartItem struct {
 func (CartItem) TableName() string {
 	return "cart_items"
 }
+
+// Subtotal returns the sum of all line totals in the cart
+func (c *Cart) Subtotal() Money {
+	var subtotal Money
+	for _, item := range c.Items {
+		subtotal += item.TotalPrice
+	}
+	return subtotal
+}
+
+// ItemCount returns the total quantity of all lines in the cart
+func (c *Cart) ItemCount() int {
+	count := 0
+	for _, item := range c.Items {
+		count += item.Quantity
+	}
+	return count
+}
+
+// AddToCartRequest represents a request to add a product (or variant) to the cart
+type AddToCartRequest struct {
+	ProductID uint `json:"product_id" validate:"required"`
+	VariantID uint `json:"variant_id"`
+	Quantity  int  `json:"quantity" validate:"required,min=1"`
+}
+
+// UpdateCartItemRequest represents a request to change the quantity of a cart line
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity" validate:"required,min=1"`
+}
+
+// CartCheckoutRequest represents a checkout request whose items are taken from the user's cart
+type CartCheckoutRequest struct {
+	// Shipping information
+	ShippingName       string `json:"shipping_name" validate:"required"`
+	ShippingPhone      string `json:"shipping_phone" validate:"required"`
+	ShippingAddress    string `json:"shipping_address" validate:"required"`
+	ShippingCity       string `json:"shipping_city" validate:"required"`
+	ShippingProvince   string `json:"shipping_province" validate:"required"`
+	ShippingPostalCode string `json:"shipping_postal_code" validate:"required"`
+
+	// Courier service picked from /api/v1/shipping/calculate; see CheckoutRequest
+	ReceiverDestinationID string `json:"receiver_destination_id" validate:"required"`
+	ShippingCourier       string `json:"shipping_courier" validate:"required"`
+	ShippingService       string `json:"shipping_service" validate:"required"`
+
+	// Payment method
+	PaymentMethod string `json:"payment_method" validate:"required,oneof=bank_transfer credit_card e_wallet cod"`
+
+	// Optional
+	CustomerNotes string `json:"customer_notes"`
+	CouponCode    string `json:"coupon_code"`
+
+	// Reseller is set from the signed-in user, never from the request body
+	Reseller bool `json:"-"`
+}
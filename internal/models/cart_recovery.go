@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+type CartRecoveryStatus string
+
+const (
+	CartRecoveryStatusSent      CartRecoveryStatus = "sent"
+	CartRecoveryStatusFailed    CartRecoveryStatus = "failed"
+	CartRecoveryStatusConverted CartRecoveryStatus = "converted"
+)
+
+// CartRecovery records an abandoned cart reminder and, if the customer came
+// back and ordered, the order it was converted into
+type CartRecovery struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	CartID uint               `json:"cart_id" gorm:"not null;index"`
+	UserID uint               `json:"user_id" gorm:"not null;index"`
+	Status CartRecoveryStatus `json:"status" gorm:"not null;size:20;default:'sent'"`
+
+	// Reminder details
+	Phone        string    `json:"phone" gorm:"not null;size:20"`
+	ItemCount    int       `json:"item_count"`
+	CartValue    Money     `json:"cart_value"`
+	CouponID     *uint     `json:"coupon_id"`
+	CouponCode   string    `json:"coupon_code,omitempty" gorm:"size:50"`
+	ErrorMessage string    `json:"error_message,omitempty" gorm:"type:text"`
+	SentAt       time.Time `json:"sent_at" gorm:"not null"`
+
+	// Conversion
+	OrderID     *uint      `json:"order_id"`
+	OrderTotal  Money      `json:"order_total"`
+	ConvertedAt *time.Time `json:"converted_at"`
+}
+
+func (CartRecovery) TableName() string {
+	return "cart_recoveries"
+}
+
+// CartRecoveryStats summarises reminder performance over a period
+type CartRecoveryStats struct {
+	From             time.Time `json:"from"`
+	To               time.Time `json:"to"`
+	Sent             int64     `json:"sent"`
+	Failed           int64     `json:"failed"`
+	Converted        int64     `json:"converted"`
+	RecoveryRate     float64   `json:"recovery_rate"` // Converted / delivered reminders, in percent
+	RecoveredRevenue Money     `json:"recovered_revenue"`
+	CouponsIssued    int64     `json:"coupons_issued"`
+}
+
+// UpdateNotificationPreferencesRequest lets a customer manage marketing messages
+type UpdateNotificationPreferencesRequest struct {
+	WhatsAppOptOut *bool `json:"whatsapp_opt_out" validate:"required"`
+}
@@ -13,11 +13,19 @@ type CheckoutRequest struct {
 	ShippingProvince string `json:"shipping_province" validate:"required"`
 	ShippingPostalCode string `json:"shipping_postal_code" validate:"required"`
 
+	// Courier service picked from /api/v1/shipping/calculate for the receiver destination found
+	// with /api/v1/shipping/destination/search. The rate is quoted again at checkout, and the
+	// destination has to be one found for shipping_postal_code; its city picks the tax region.
+	ReceiverDestinationID string `json:"receiver_destination_id" validate:"required"`
+	ShippingCourier       string `json:"shipping_courier" validate:"required"` // shipping_name, e.g. "JNE"
+	ShippingService       string `json:"shipping_service" validate:"required"` // service_name, e.g. "REG"
+
 	// Payment method
 	PaymentMethod string `json:"payment_method" validate:"required,oneof=bank_transfer credit_card e_wallet cod"`
 
-	// Customer info
-	UserID uint `json:"user_id" validate:"required"`
+	// Customer info, set from the signed-in user and never from the request body
+	UserID   uint `json:"-"`
+	Reseller bool `json:"-"`
 
 	// Optional
 	CustomerNotes string `json:"customer_notes"`
@@ -38,7 +46,7 @@ type CheckoutResponse struct {
 	SnapToken      string  `json:"snap_token"`
 	SnapURL        string  `json:"snap_url"`
 	RedirectURL    string  `json:"redirect_url"`
-	Amount         float64 `json:"amount"`
+	Amount         Money   `json:"amount"`
 	ExpiryTime     string  `json:"expiry_time"`
 }
 
@@ -55,7 +63,7 @@ type MidtransPaymentRequest struct {
 // TransactionDetails represents Midtrans transaction details
 type TransactionDetails struct {
 	OrderID     string  `json:"order_id"`
-	GrossAmount  float64 `json:"gross_amount"`
+	GrossAmount  Money  `json:"gross_amount"`
 }
 
 // CustomerDetails represents Midtrans customer details
@@ -82,8 +90,8 @@ type Address struct {
 // ItemDetail represents Midtrans item details
 type ItemDetail struct {
 	ID       string  `json:"id"`
-	Price    float64 `json:"price"`
-	Quantity int     `json:"name"`
+	Price    Money   `json:"price"`
+	Quantity int     `json:"quantity"`
 	Name     string  `json:"name"`
 }
 
@@ -93,7 +101,7 @@ type MidtransPaymentNotification struct {
 	StatusMessage       string  `json:"status_message"`
 	PaymentType        string  `json:"payment_type"`
 	OrderID            string  `json:"order_id"`
-	GrossAmount        float64 `json:"gross_amount"`
+	GrossAmount        Money   `json:"gross_amount"`
 	FraudStatus        string  `json:"fraud_status"`
 	SignatureKey       string  `json:"signature_key"`
 	StatusCode         string  `json:"status_code"`
@@ -48,7 +48,9 @@ type Coupon struct {
 	ValidFrom  *time.Time `json:"valid_from"`
 	ValidUntil *time.Time `json:"valid_until"`
 
-	// Restrictions
+	// Restrictions, stored in coupon_products and coupon_categories and loaded by the repository.
+	// A line is eligible when its product is not excluded and, if any applicable products or
+	// categories are set, it matches one of them.
 	ApplicableProducts   []uint   `json:"applicable_products,omitempty" gorm:"-"`   // Product IDs this coupon applies to
 	ExcludeProducts      []uint   `json:"exclude_products,omitempty" gorm:"-"`      // Product IDs this coupon excludes
 	ApplicableCategories []string `json:"applicable_categories,omitempty" gorm:"-"` // Categories this coupon applies to
@@ -57,9 +59,15 @@ type Coupon struct {
 	ForRetail   bool `json:"for_retail"`
 	ForReseller bool `json:"for_reseller"`
 
+	// Set on coupons issued to one customer, such as abandoned cart reminders; only they can redeem it
+	UserID *uint `json:"user_id,omitempty" gorm:"index"`
+
 	// Statistics
 	TotalDiscountUsed float64 `json:"total_discount_used" gorm:"default:0"`
 	OrderCount        int     `json:"order_count" gorm:"default:0"`
+
+	// Set on codes created by the bulk generator
+	BatchID *uint `json:"batch_id,omitempty" gorm:"index"`
 }
 
 func (Coupon) TableName() string {
@@ -78,9 +86,139 @@ type CouponUsage struct {
 
 	OrderID uint `json:"order_id" gorm:"not null;index"`
 
-	DiscountAmount float64 `json:"discount_amount" gorm:"not null"`
+	DiscountAmount Money `json:"discount_amount" gorm:"not null"`
 }
 
 func (CouponUsage) TableName() string {
 	return "coupon_usages"
 }
+
+// HasRestrictions reports whether the coupon is limited to some products or categories
+func (c *Coupon) HasRestrictions() bool {
+	return len(c.ApplicableProducts) > 0 || len(c.ExcludeProducts) > 0 || len(c.ApplicableCategories) > 0
+}
+
+// CouponProduct limits a coupon to a product, or excludes the product when Excluded is set
+type CouponProduct struct {
+	CouponID  uint `json:"coupon_id" gorm:"primaryKey"`
+	ProductID uint `json:"product_id" gorm:"primaryKey"`
+	Excluded  bool `json:"excluded" gorm:"not null;default:false"`
+}
+
+func (CouponProduct) TableName() string {
+	return "coupon_products"
+}
+
+// CouponCategory limits a coupon to the products of a category
+type CouponCategory struct {
+	CouponID uint   `json:"coupon_id" gorm:"primaryKey"`
+	Category string `json:"category" gorm:"primaryKey;size:50"`
+}
+
+func (CouponCategory) TableName() string {
+	return "coupon_categories"
+}
+
+// CouponBatch is a set of single-use codes generated from one template, e.g. for an influencer
+// campaign
+type CouponBatch struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Name       string `json:"name" gorm:"not null;size:200"`
+	Prefix     string `json:"prefix" gorm:"size:20"`
+	CodeLength int    `json:"code_length" gorm:"not null"`
+	Quantity   int    `json:"quantity" gorm:"not null"`
+}
+
+func (CouponBatch) TableName() string {
+	return "coupon_batches"
+}
+
+// CouponRules are the discount, validity and customer settings shared by CreateCouponRequest
+// and GenerateCouponsRequest
+type CouponRules struct {
+	Name              string     `json:"name" validate:"required,max=200"`
+	Description       string     `json:"description"`
+	Type              CouponType `json:"type" validate:"required,oneof=percentage fixed"`
+	DiscountValue     float64    `json:"discount_value" validate:"required,gt=0"`
+	MaxDiscount       float64    `json:"max_discount" validate:"gte=0"`
+	MinPurchaseAmount float64    `json:"min_purchase_amount" validate:"gte=0"`
+	ValidFrom         *time.Time `json:"valid_from"`
+	ValidUntil        *time.Time `json:"valid_until"`
+	ForRetail         *bool      `json:"for_retail"`   // default true
+	ForReseller       *bool      `json:"for_reseller"` // default true
+
+	ApplicableProducts   []uint   `json:"applicable_products"`
+	ExcludeProducts      []uint   `json:"exclude_products"`
+	ApplicableCategories []string `json:"applicable_categories" validate:"omitempty,dive,oneof=tops bottoms dresses outerwear footwear accessories"`
+}
+
+// CreateCouponRequest represents the admin payload for creating a coupon
+type CreateCouponRequest struct {
+	Code string `json:"code" validate:"required,min=3,max=50"`
+	CouponRules
+	MaxUsageCount   int `json:"max_usage_count" validate:"gte=0"`
+	MaxUsagePerUser int `json:"max_usage_per_user" validate:"gte=0"`
+}
+
+// UpdateCouponRequest changes the given fields of a coupon. The code cannot be changed.
+type UpdateCouponRequest struct {
+	Name              *string       `json:"name" validate:"omitempty,max=200"`
+	Description       *string       `json:"description"`
+	Status            *CouponStatus `json:"status" validate:"omitempty,oneof=active inactive"`
+	DiscountValue     *float64      `json:"discount_value" validate:"omitempty,gt=0"`
+	MaxDiscount       *float64      `json:"max_discount" validate:"omitempty,gte=0"`
+	MinPurchaseAmount *float64      `json:"min_purchase_amount" validate:"omitempty,gte=0"`
+	MaxUsageCount     *int          `json:"max_usage_count" validate:"omitempty,gte=0"`
+	MaxUsagePerUser   *int          `json:"max_usage_per_user" validate:"omitempty,gte=0"`
+	ValidFrom         *time.Time    `json:"valid_from"`
+	ValidUntil        *time.Time    `json:"valid_until"`
+	ForRetail         *bool         `json:"for_retail"`
+	ForReseller       *bool         `json:"for_reseller"`
+
+	// Sending a list replaces the current one; an empty list removes the restriction
+	ApplicableProducts   *[]uint   `json:"applicable_products"`
+	ExcludeProducts      *[]uint   `json:"exclude_products"`
+	ApplicableCategories *[]string `json:"applicable_categories" validate:"omitempty,dive,oneof=tops bottoms dresses outerwear footwear accessories"`
+}
+
+// GenerateCouponsRequest creates Quantity single-use codes like PREFIX-XXXXXXXX that share the
+// same rules
+type GenerateCouponsRequest struct {
+	Prefix     string `json:"prefix" validate:"omitempty,max=20,alphanum"`
+	CodeLength int    `json:"code_length" validate:"omitempty,min=6,max=20"` // random part, default 8
+	Quantity   int    `json:"quantity" validate:"required,min=1,max=5000"`
+	CouponRules
+}
+
+// CouponListFilter narrows the admin coupon list
+type CouponListFilter struct {
+	Status  CouponStatus
+	BatchID *uint
+	Search  string // matches code or name
+}
+
+// CouponUsageStats summarises how a coupon, or every code in a batch, has been redeemed
+type CouponUsageStats struct {
+	Codes           int        `json:"codes"`
+	RedeemedCodes   int        `json:"redeemed_codes"`
+	Redemptions     int        `json:"redemptions"`
+	UniqueCustomers int        `json:"unique_customers"`
+	TotalDiscount   float64    `json:"total_discount"`
+	FirstUsedAt     *time.Time `json:"first_used_at"`
+	LastUsedAt      *time.Time `json:"last_used_at"`
+}
+
+// CouponDetail is a coupon with its usage stats
+type CouponDetail struct {
+	Coupon
+	Stats CouponUsageStats `json:"stats"`
+}
+
+// CouponBatchDetail is a generated batch with the combined stats of its codes
+type CouponBatchDetail struct {
+	CouponBatch
+	Stats CouponUsageStats `json:"stats"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+)
+
+// DiscountTierKind says who a quantity tier is for
+type DiscountTierKind string
+
+const (
+	// DiscountTierReseller tiers price every reseller order
+	DiscountTierReseller DiscountTierKind = "reseller"
+	// DiscountTierBulk tiers price retail orders of larger quantities
+	DiscountTierBulk DiscountTierKind = "bulk"
+)
+
+// DiscountTierScope says which products a tier covers
+type DiscountTierScope string
+
+const (
+	DiscountTierScopeGlobal   DiscountTierScope = "global"
+	DiscountTierScopeCategory DiscountTierScope = "category"
+	DiscountTierScopeProduct  DiscountTierScope = "product"
+)
+
+// DiscountTier is one quantity breakpoint of a reseller or bulk tier table. The tiers of the most
+// specific scope that has any for a product make up its table: product tiers replace category
+// tiers, which replace the global ones. Within the table the highest MinQuantity reached applies.
+type DiscountTier struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Kind     DiscountTierKind  `json:"kind" gorm:"not null;size:20"`
+	Scope    DiscountTierScope `json:"scope" gorm:"not null;size:20"`
+	Category ProductCategory   `json:"category,omitempty" gorm:"size:50"`
+	// ProductID is set for product scoped tiers
+	ProductID *uint `json:"product_id,omitempty" gorm:"index"`
+
+	MinQuantity     int     `json:"min_quantity" gorm:"not null"`
+	DiscountPercent float64 `json:"discount_percent" gorm:"not null"`
+
+	IsActive bool       `json:"is_active" gorm:"not null;default:true"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+func (DiscountTier) TableName() string {
+	return "discount_tiers"
+}
+
+// IsEffective reports whether the tier prices orders at the given time
+func (t *DiscountTier) IsEffective(at time.Time) bool {
+	if !t.IsActive {
+		return false
+	}
+	if t.StartsAt != nil && at.Before(*t.StartsAt) {
+		return false
+	}
+	if t.EndsAt != nil && !at.Before(*t.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// DiscountTierRequest creates a tier or replaces one
+type DiscountTierRequest struct {
+	Kind            DiscountTierKind  `json:"kind" validate:"required,oneof=reseller bulk"`
+	Scope           DiscountTierScope `json:"scope" validate:"required,oneof=global category product"`
+	Category        ProductCategory   `json:"category" validate:"omitempty,oneof=tops bottoms dresses outerwear footwear accessories"`
+	ProductID       *uint             `json:"product_id"`
+	MinQuantity     int               `json:"min_quantity" validate:"gte=1"`
+	DiscountPercent float64           `json:"discount_percent" validate:"gte=0,lt=100"`
+	IsActive        *bool             `json:"is_active"`
+	StartsAt        *time.Time        `json:"starts_at"`
+	EndsAt          *time.Time        `json:"ends_at"`
+}
+
+// DiscountTierListFilter narrows the admin tier list
+type DiscountTierListFilter struct {
+	Kind      DiscountTierKind
+	Scope     DiscountTierScope
+	Category  ProductCategory
+	ProductID *uint
+}
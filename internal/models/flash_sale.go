@@ -61,12 +61,81 @@ type FlashSaleProduct struct {
 	ProductID   uint    `json:"product_id" gorm:"not null;index"`
 	Product     Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
 
+	// Optional: limits the entry to one size or color. An entry without a variant covers every
+	// variant of the product that has no entry of its own.
+	VariantID *uint           `json:"variant_id,omitempty" gorm:"index"`
+	Variant   *ProductVariant `json:"variant,omitempty" gorm:"foreignKey:VariantID"`
+
 	// Flash sale specific settings for this product
 	FlashSalePrice float64 `json:"flash_sale_price" gorm:"not null"` // Special price during flash sale
-	FlashSaleStock int     `json:"flash_sale_stock" gorm:"not null"` // Available stock for flash sale
+	FlashSaleStock int     `json:"flash_sale_stock" gorm:"not null"` // Available stock for flash sale, 0 = unlimited
 	SoldCount     int     `json:"sold_count" gorm:"default:0"`      // How many sold
 }
 
 func (FlashSaleProduct) TableName() string {
 	return "flash_sale_products"
 }
+
+// FlashSaleUsage is how many units of a flash sale are held by orders that are pending payment
+// or paid, used to seed the reservation counters
+type FlashSaleUsage struct {
+	Product int // units of one product or variant entry in the sale
+	Sale    int // units across the whole sale
+	User    int // units bought by one customer
+}
+
+// CreateFlashSaleRequest represents the admin payload for scheduling a flash sale
+type CreateFlashSaleRequest struct {
+	Name               string    `json:"name" validate:"required,max=200"`
+	Description        string    `json:"description"`
+	StartTime          time.Time `json:"start_time" validate:"required"`
+	EndTime            time.Time `json:"end_time" validate:"required"`
+	DiscountPercentage float64   `json:"discount_percentage" validate:"gte=0,lte=100"`
+	MaxQuantityPerUser int       `json:"max_quantity_per_user" validate:"gte=0"`
+	TotalStockLimit    int       `json:"total_stock_limit" validate:"gte=0"`
+}
+
+// UpdateFlashSaleRequest changes the given fields of a flash sale that has not ended
+type UpdateFlashSaleRequest struct {
+	Name               *string    `json:"name" validate:"omitempty,max=200"`
+	Description        *string    `json:"description"`
+	StartTime          *time.Time `json:"start_time"`
+	EndTime            *time.Time `json:"end_time"`
+	DiscountPercentage *float64   `json:"discount_percentage" validate:"omitempty,gte=0,lte=100"`
+	MaxQuantityPerUser *int       `json:"max_quantity_per_user" validate:"omitempty,gte=0"`
+	TotalStockLimit    *int       `json:"total_stock_limit" validate:"omitempty,gte=0"`
+}
+
+// FlashSaleProductRequest adds a product to a flash sale at a special price
+type FlashSaleProductRequest struct {
+	ProductID      uint    `json:"product_id" validate:"required"`
+	VariantID      *uint   `json:"variant_id"`
+	FlashSalePrice float64 `json:"flash_sale_price" validate:"required,gt=0"`
+	FlashSaleStock int     `json:"flash_sale_stock" validate:"gte=0"` // 0 = unlimited
+}
+
+// UpdateFlashSaleProductRequest changes the price or stock of a product in a flash sale
+type UpdateFlashSaleProductRequest struct {
+	FlashSalePrice *float64 `json:"flash_sale_price" validate:"omitempty,gt=0"`
+	FlashSaleStock *int     `json:"flash_sale_stock" validate:"omitempty,gte=0"`
+}
+
+// FlashSaleEventType names a flash sale lifecycle transition
+type FlashSaleEventType string
+
+const (
+	FlashSaleEventStarted FlashSaleEventType = "flash_sale.started"
+	FlashSaleEventEnded   FlashSaleEventType = "flash_sale.ended"
+	FlashSaleEventUpdated FlashSaleEventType = "flash_sale.updated"
+)
+
+// FlashSaleEvent is published when a flash sale changes state
+type FlashSaleEvent struct {
+	Type        FlashSaleEventType `json:"type"`
+	FlashSaleID uint               `json:"flash_sale_id"`
+	Name        string             `json:"name"`
+	StartTime   time.Time          `json:"start_time"`
+	EndTime     time.Time          `json:"end_time"`
+	ProductIDs  []uint             `json:"product_ids"`
+	OccurredAt  time.Time          `json:"occurred_at"`
+}
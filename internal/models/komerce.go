@@ -1,5 +1,7 @@
 package models
 
+import "math"
+
 // Komerce Destination Search Response
 type KomerceDestination struct {
 	ID              string `json:"id"`
@@ -66,6 +68,26 @@ type KomerceOrderDetail struct {
 	Subtotal           int    `json:"subtotal"`
 }
 
+// NewKomerceOrderDetail describes an order line to Komerce. The package size comes from the
+// product's Dimensions, rounded up to whole cm, so Komerce can bill its volumetric weight; the
+// weight is sent in grams.
+func NewKomerceOrderDetail(product *Product, variantName string, unitPrice Money, qty int) KomerceOrderDetail {
+	detail := KomerceOrderDetail{
+		ProductName:        product.Name,
+		ProductVariantName: variantName,
+		ProductPrice:       int(unitPrice),
+		ProductWeight:      int(math.Round(product.Weight * 1000)),
+		Qty:                qty,
+		Subtotal:           int(unitPrice.Times(qty)),
+	}
+	if dimensions, ok := ParseDimensions(product.Dimensions); ok {
+		detail.ProductLength = int(math.Ceil(dimensions.Length))
+		detail.ProductWidth = int(math.Ceil(dimensions.Width))
+		detail.ProductHeight = int(math.Ceil(dimensions.Height))
+	}
+	return detail
+}
+
 // Komerce Create Order Request
 type KomerceCreateOrderRequest struct {
 	OrderDate             string               `json:"order_date"`
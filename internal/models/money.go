@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in whole rupiah.
+//
+// Rupiah is charged in whole units and Midtrans rejects fractions for IDR, so amounts that are
+// charged or stored on orders are integers rather than float64. The rounding rules are:
+//   - an amount derived from a rate (percentage discount, tax) is rounded once, half away from
+//     zero, where it is derived
+//   - per-unit amounts are rounded before they are multiplied by a quantity, so a line total is
+//     always unit price x quantity
+//   - an amount split across lines is split with Allocate, so the parts add up to it exactly
+//
+// Sums and differences of Money are then exact, and the order total is the sum of its parts.
+type Money int64
+
+// NewMoney converts a float amount such as a catalog price, rounding half away from zero
+func NewMoney(amount float64) Money {
+	return Money(math.Round(amount))
+}
+
+// Float64 returns the amount for the catalog and promotion fields that are still float64
+func (m Money) Float64() float64 {
+	return float64(m)
+}
+
+// Times multiplies the amount by a quantity
+func (m Money) Times(quantity int) Money {
+	return m * Money(quantity)
+}
+
+// Percent returns percent% of the amount, e.g. Percent(15) for 15% off
+func (m Money) Percent(percent float64) Money {
+	return NewMoney(float64(m) * percent / 100)
+}
+
+// MulRate returns the amount times a rate kept as a fraction, e.g. MulRate(0.11) for 11% PPN
+func (m Money) MulRate(rate float64) Money {
+	return NewMoney(float64(m) * rate)
+}
+
+// Allocate splits the amount across parts in proportion to their weights. Each part but the last
+// is rounded half away from zero and the last takes what is left, so the parts add up to m.
+func (m Money) Allocate(weights []Money) []Money {
+	parts := make([]Money, len(weights))
+	if len(weights) == 0 {
+		return parts
+	}
+
+	var total Money
+	for _, w := range weights {
+		total += w
+	}
+	if total == 0 {
+		parts[len(parts)-1] = m
+		return parts
+	}
+
+	var allocated Money
+	for i := 0; i < len(weights)-1; i++ {
+		parts[i] = NewMoney(float64(m) * float64(weights[i]) / float64(total))
+		allocated += parts[i]
+	}
+	parts[len(parts)-1] = m - allocated
+	return parts
+}
+
+// String returns the amount in plain digits, e.g. "150000"
+func (m Money) String() string {
+	return strconv.FormatInt(int64(m), 10)
+}
+
+// UnmarshalJSON reads a number or a numeric string, such as the "150000.00" Midtrans sends, and
+// rounds it to whole rupiah
+func (m *Money) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	if raw == "null" || raw == "" {
+		return nil
+	}
+	amount, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return fmt.Errorf("invalid amount %s", data)
+	}
+	*m = NewMoney(amount)
+	return nil
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney_Rounding(t *testing.T) {
+	assert.Equal(t, Money(150000), NewMoney(149999.5))
+	assert.Equal(t, Money(149999), NewMoney(149999.49))
+	assert.Equal(t, Money(-3), NewMoney(-2.5))
+
+	// 11% of 99999 is 10999.89
+	assert.Equal(t, Money(11000), Money(99999).MulRate(0.11))
+	assert.Equal(t, Money(13333), Money(88888).Percent(15))
+	assert.Equal(t, Money(447000), Money(149000).Times(3))
+}
+
+func TestMoney_AllocateAddsUp(t *testing.T) {
+	parts := Money(10000).Allocate([]Money{33333, 33333, 33334})
+	assert.Equal(t, []Money{3333, 3333, 3334}, parts)
+
+	parts = Money(1000).Allocate([]Money{1, 1, 1})
+	assert.Equal(t, Money(1000), parts[0]+parts[1]+parts[2])
+
+	assert.Equal(t, []Money{0, 500}, Money(500).Allocate([]Money{0, 0}))
+	assert.Empty(t, Money(500).Allocate(nil))
+}
+
+func TestMoney_UnmarshalJSON(t *testing.T) {
+	var notification MidtransPaymentNotification
+	assert.NoError(t, json.Unmarshal([]byte(`{"gross_amount": "150000.00"}`), &notification))
+	assert.Equal(t, Money(150000), notification.GrossAmount)
+
+	var amount Money
+	assert.NoError(t, json.Unmarshal([]byte(`99999.5`), &amount))
+	assert.Equal(t, Money(100000), amount)
+
+	assert.Error(t, json.Unmarshal([]byte(`"NaN"`), &amount))
+	assert.Error(t, json.Unmarshal([]byte(`"abc"`), &amount))
+}
@@ -52,12 +52,20 @@ type Order struct {
 	PaymentStatus PaymentStatus `json:"payment_status" gorm:"not null;default:'pending'"`
 	PaymentMethod PaymentMethod `json:"payment_method" gorm:"not null;size:50"`
 
-	// Pricing
-	Subtotal      float64 `json:"subtotal" gorm:"not null"`
-	Discount      float64 `json:"discount" gorm:"default:0"`
-	ShippingCost  float64 `json:"shipping_cost" gorm:"default:0"`
-	Tax           float64 `json:"tax" gorm:"default:0"`
-	TotalAmount   float64 `json:"total_amount" gorm:"not null"`
+	// Pricing, in whole rupiah
+	Subtotal      Money `json:"subtotal" gorm:"not null"`
+	Discount      Money `json:"discount" gorm:"default:0"`
+	ShippingCost  Money `json:"shipping_cost" gorm:"default:0"`
+	Tax           Money `json:"tax" gorm:"default:0"`
+	TotalAmount   Money `json:"total_amount" gorm:"not null"`
+
+	// Coupon redeemed on the order. CouponDiscount comes off on top of Discount.
+	CouponID       *uint   `json:"coupon_id,omitempty" gorm:"index"`
+	CouponCode     string  `json:"coupon_code,omitempty" gorm:"size:50"`
+	CouponDiscount Money   `json:"coupon_discount" gorm:"default:0"`
+
+	// Taxes breaks Tax down per tax charged
+	Taxes []OrderTax `json:"taxes,omitempty" gorm:"foreignKey:OrderID"`
 
 	// Shipping Information
 	ShippingName    string `json:"shipping_name" gorm:"not null;size:100"`
@@ -71,6 +79,12 @@ type Order struct {
 	TrackingNumber string `json:"tracking_number" gorm:"size:100"`
 	ShippingProvider string `json:"shipping_provider" gorm:"size:100"`
 
+	// Courier service chosen at checkout, the Komerce destination it was quoted for and the
+	// courier's delivery estimate. ShippingProvider holds the courier.
+	ShippingService       string `json:"shipping_service" gorm:"size:100"`
+	ShippingETD           string `json:"shipping_etd" gorm:"size:50"`
+	ShippingDestinationID string `json:"shipping_destination_id" gorm:"size:50"`
+
 	// Timestamps
 	ConfirmedAt   *time.Time `json:"confirmed_at"`
 	ShippedAt     *time.Time `json:"shipped_at"`
@@ -105,14 +119,19 @@ type OrderItem struct {
 	ProductImage string  `json:"product_image" gorm:"size:500"`
 
 	// Order details
-	Quantity    int     `json:"quantity" gorm:"not null"`
-	UnitPrice    float64 `json:"unit_price" gorm:"not null"`
-	TotalPrice   float64 `json:"total_price" gorm:"not null"`
+	Quantity   int   `json:"quantity" gorm:"not null"`
+	UnitPrice  Money `json:"unit_price" gorm:"not null"`
+	TotalPrice Money `json:"total_price" gorm:"not null"`
 
 	// Variant info (if applicable)
+	VariantID   *uint  `json:"variant_id,omitempty" gorm:"index"`
 	VariantName string `json:"variant_name" gorm:"size:100"`
 	VariantSize string `json:"variant_size" gorm:"size:50"`
 	VariantColor string `json:"variant_color" gorm:"size:50"`
+
+	// Flash sale the item was bought in, if any, and the product or variant entry it matched
+	FlashSaleID        *uint `json:"flash_sale_id,omitempty" gorm:"index"`
+	FlashSaleProductID *uint `json:"flash_sale_product_id,omitempty" gorm:"index"`
 }
 
 func (OrderItem) TableName() string {
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+)
+
+type PriceChangeStatus string
+
+const (
+	PriceChangePending   PriceChangeStatus = "pending"
+	PriceChangeApplied   PriceChangeStatus = "applied"
+	PriceChangeCancelled PriceChangeStatus = "cancelled"
+	// PriceChangeFailed changes could not be applied, e.g. because the product was deleted
+	PriceChangeFailed PriceChangeStatus = "failed"
+)
+
+// ScheduledPriceChange sets a product's or variant's prices at EffectiveAt. Nil fields are left
+// as they are; variant changes only set Price, since compare price and discount are per product.
+type ScheduledPriceChange struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ProductID uint  `json:"product_id" gorm:"not null;index"`
+	VariantID *uint `json:"variant_id,omitempty" gorm:"index"`
+
+	Price        *float64 `json:"price,omitempty"`
+	ComparePrice *float64 `json:"compare_price,omitempty"`
+	Discount     *float64 `json:"discount,omitempty"`
+
+	EffectiveAt time.Time         `json:"effective_at" gorm:"not null"`
+	Status      PriceChangeStatus `json:"status" gorm:"not null;size:20;default:'pending'"`
+	Reason      string            `json:"reason" gorm:"size:255"`
+	CreatedBy   *uint             `json:"created_by,omitempty"`
+
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Error     string     `json:"error,omitempty" gorm:"size:255"`
+}
+
+func (ScheduledPriceChange) TableName() string {
+	return "scheduled_price_changes"
+}
+
+// PriceHistory records one applied price change with the values before and after it. Variant
+// entries only carry prices; their compare price and discount stay zero.
+type PriceHistory struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ChangedAt time.Time `json:"changed_at" gorm:"not null"`
+
+	ProductID uint  `json:"product_id" gorm:"not null"`
+	VariantID *uint `json:"variant_id,omitempty"`
+
+	OldPrice        float64 `json:"old_price"`
+	NewPrice        float64 `json:"new_price"`
+	OldComparePrice float64 `json:"old_compare_price"`
+	NewComparePrice float64 `json:"new_compare_price"`
+	OldDiscount     float64 `json:"old_discount"`
+	NewDiscount     float64 `json:"new_discount"`
+
+	ChangedBy         *uint  `json:"changed_by,omitempty"`
+	Reason            string `json:"reason"`
+	ScheduledChangeID *uint  `json:"scheduled_change_id,omitempty"`
+}
+
+// PriceChangeReasonManual is the history reason of prices edited on the product or variant itself
+const PriceChangeReasonManual = "manual edit"
+
+func (PriceHistory) TableName() string {
+	return "price_history"
+}
+
+// ScheduledPriceChangeRequest schedules a price change; an effective_at in the past applies it
+// on the scheduler's next run
+type ScheduledPriceChangeRequest struct {
+	ProductID    uint      `json:"product_id" validate:"required"`
+	VariantID    *uint     `json:"variant_id"`
+	Price        *float64  `json:"price" validate:"omitempty,gt=0"`
+	ComparePrice *float64  `json:"compare_price" validate:"omitempty,gte=0"`
+	Discount     *float64  `json:"discount" validate:"omitempty,gte=0,lt=100"`
+	EffectiveAt  time.Time `json:"effective_at" validate:"required"`
+	Reason       string    `json:"reason" validate:"max=255"`
+}
+
+// PriceChangeListFilter narrows the admin price change list
+type PriceChangeListFilter struct {
+	ProductID *uint
+	Status    PriceChangeStatus
+}
@@ -2,6 +2,9 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -109,6 +112,11 @@ func (p *Product) Validate() error {
 	if p.SKU == "" {
 		return errors.New("SKU is required")
 	}
+	if p.Dimensions != "" {
+		if _, ok := ParseDimensions(p.Dimensions); !ok {
+			return ErrInvalidDimensions
+		}
+	}
 	return nil
 }
 
@@ -134,6 +142,52 @@ func (Product) TableName() string {
 	return "products"
 }
 
+// MaxPackageSide is the longest side in cm a package can have, well beyond what couriers carry
+const MaxPackageSide = 500
+
+// ErrInvalidDimensions is returned for dimensions not in the "LxWxH" format
+var ErrInvalidDimensions = fmt.Errorf("product dimensions must be in LxWxH format, in cm, each side at most %d", MaxPackageSide)
+
+// PackageDimensions are the length, width and height of a packed item in cm
+type PackageDimensions struct {
+	Length float64
+	Width  float64
+	Height float64
+}
+
+// ParseDimensions reads the "LxWxH" format of Product.Dimensions, such as "40x30x5" or
+// "40 x 30 x 5 cm". ok is false when the value is empty or malformed, or a side is not a number
+// between 0 and MaxPackageSide.
+func ParseDimensions(value string) (PackageDimensions, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	value = strings.TrimSpace(strings.TrimSuffix(value, "cm"))
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == 'x' || r == '×' || r == '*'
+	})
+	if len(parts) != 3 {
+		return PackageDimensions{}, false
+	}
+
+	var sides [3]float64
+	for i, part := range parts {
+		side, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(part), ",", "."), 64)
+		if err != nil || math.IsNaN(side) || side <= 0 || side > MaxPackageSide {
+			return PackageDimensions{}, false
+		}
+		sides[i] = side
+	}
+	return PackageDimensions{Length: sides[0], Width: sides[1], Height: sides[2]}, true
+}
+
+// VolumetricWeight is the weight in kg couriers bill the package as, given their divisor in
+// cm³ per kg
+func (d PackageDimensions) VolumetricWeight(divisor float64) float64 {
+	if divisor <= 0 {
+		return 0
+	}
+	return d.Length * d.Width * d.Height / divisor
+}
+
 type ProductVariant struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	CreatedAt time.Time `json:"created_at"`
@@ -308,3 +308,44 @@ func TestProduct_IsFeatured(t *testing.T) {
 	}
 }
 
+
+func TestParseDimensions(t *testing.T) {
+	tests := []struct {
+		value string
+		want  PackageDimensions
+		ok    bool
+	}{
+		{"40x30x5", PackageDimensions{Length: 40, Width: 30, Height: 5}, true},
+		{" 40 X 30 X 5 cm", PackageDimensions{Length: 40, Width: 30, Height: 5}, true},
+		{"25,5×20×3.5", PackageDimensions{Length: 25.5, Width: 20, Height: 3.5}, true},
+		{"", PackageDimensions{}, false},
+		{"40x30", PackageDimensions{}, false},
+		{"40x0x5", PackageDimensions{}, false},
+		{"40xabcx5", PackageDimensions{}, false},
+		{"NaNx30x5", PackageDimensions{}, false},
+		{"40xInfx5", PackageDimensions{}, false},
+		{"40x30x501", PackageDimensions{}, false},
+		{"500x30x5", PackageDimensions{Length: 500, Width: 30, Height: 5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := ParseDimensions(tt.value)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Equal(t, 1.0, PackageDimensions{Length: 30, Width: 20, Height: 10}.VolumetricWeight(6000))
+}
+
+func TestNewKomerceOrderDetail(t *testing.T) {
+	product := &Product{Name: "Parka", Weight: 0.85, Dimensions: "40x30.2x15"}
+
+	detail := NewKomerceOrderDetail(product, "L - Olive", 450000, 2)
+	assert.Equal(t, 850, detail.ProductWeight)
+	assert.Equal(t, 40, detail.ProductLength)
+	assert.Equal(t, 31, detail.ProductWidth)
+	assert.Equal(t, 15, detail.ProductHeight)
+	assert.Equal(t, 900000, detail.Subtotal)
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PromotionType string
+
+const (
+	// PromotionBuyXGetY discounts GetQuantity units for every BuyQuantity units bought
+	PromotionBuyXGetY PromotionType = "buy_x_get_y"
+	// PromotionTieredSpend takes a percentage off the order; the highest tier reached applies
+	PromotionTieredSpend PromotionType = "tiered_spend"
+	// PromotionFreeShipping covers the shipping cost once the order reaches MinSubtotal
+	PromotionFreeShipping PromotionType = "free_shipping"
+	// PromotionGift adds a free product once the order reaches MinSubtotal
+	PromotionGift PromotionType = "gift"
+)
+
+type PromotionStatus string
+
+const (
+	PromotionStatusActive   PromotionStatus = "active"
+	PromotionStatusInactive PromotionStatus = "inactive"
+)
+
+// Promotion is a rule applied automatically to every order that meets it, without a code
+type Promotion struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Name        string          `json:"name" gorm:"not null;size:200"`
+	Description string          `json:"description" gorm:"type:text"`
+	Type        PromotionType   `json:"type" gorm:"not null;size:30"`
+	Status      PromotionStatus `json:"status" gorm:"not null;default:'active'"`
+
+	// Promotions are evaluated from the highest priority down. One that is not stackable only
+	// applies when nothing applied before it, and nothing applies after it.
+	Priority  int  `json:"priority" gorm:"not null;default:0"`
+	Stackable bool `json:"stackable" gorm:"not null;default:true"`
+
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	ForRetail   bool       `json:"for_retail" gorm:"default:true"`
+	ForReseller bool       `json:"for_reseller" gorm:"default:true"`
+
+	// Order amount after item discounts needed for free shipping and gifts
+	MinSubtotal float64 `json:"min_subtotal" gorm:"default:0"`
+
+	// Buy X get Y. GetProductID defaults to BuyProductID; GetDiscountPercent 100 makes them free.
+	BuyProductID       *uint   `json:"buy_product_id,omitempty"`
+	BuyQuantity        int     `json:"buy_quantity" gorm:"default:0"`
+	GetProductID       *uint   `json:"get_product_id,omitempty"`
+	GetQuantity        int     `json:"get_quantity" gorm:"default:0"`
+	GetDiscountPercent float64 `json:"get_discount_percent" gorm:"default:0"`
+
+	// Free shipping. 0 = the whole shipping cost.
+	MaxShippingDiscount float64 `json:"max_shipping_discount" gorm:"default:0"`
+
+	// Gift with purchase
+	GiftProductID *uint `json:"gift_product_id,omitempty"`
+	GiftQuantity  int   `json:"gift_quantity" gorm:"default:0"`
+
+	Tiers []PromotionTier `json:"tiers,omitempty" gorm:"foreignKey:PromotionID"`
+}
+
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// IsRunning reports whether the promotion is active at the given time
+func (p *Promotion) IsRunning(at time.Time) bool {
+	if p.Status != PromotionStatusActive {
+		return false
+	}
+	if p.StartsAt != nil && at.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !at.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// PromotionTier is one step of a tiered spend promotion
+type PromotionTier struct {
+	ID              uint    `json:"id" gorm:"primaryKey"`
+	PromotionID     uint    `json:"-" gorm:"not null;index"`
+	MinSubtotal     float64 `json:"min_subtotal" gorm:"not null"`
+	DiscountPercent float64 `json:"discount_percent" gorm:"not null"`
+	MaxDiscount     float64 `json:"max_discount" gorm:"default:0"` // 0 = no cap
+}
+
+func (PromotionTier) TableName() string {
+	return "promotion_tiers"
+}
+
+// PromotionRequest creates a promotion or replaces one. Only the fields of its type are used.
+type PromotionRequest struct {
+	Name        string          `json:"name" validate:"required,max=200"`
+	Description string          `json:"description"`
+	Type        PromotionType   `json:"type" validate:"required,oneof=buy_x_get_y tiered_spend free_shipping gift"`
+	Status      PromotionStatus `json:"status" validate:"omitempty,oneof=active inactive"`
+	Priority    int             `json:"priority"`
+	Stackable   *bool           `json:"stackable"`
+	StartsAt    *time.Time      `json:"starts_at"`
+	EndsAt      *time.Time      `json:"ends_at"`
+	ForRetail   *bool           `json:"for_retail"`
+	ForReseller *bool           `json:"for_reseller"`
+	MinSubtotal float64         `json:"min_subtotal" validate:"gte=0"`
+
+	BuyProductID       *uint   `json:"buy_product_id"`
+	BuyQuantity        int     `json:"buy_quantity" validate:"gte=0"`
+	GetProductID       *uint   `json:"get_product_id"`
+	GetQuantity        int     `json:"get_quantity" validate:"gte=0"`
+	GetDiscountPercent float64 `json:"get_discount_percent" validate:"gte=0,lte=100"`
+
+	MaxShippingDiscount float64 `json:"max_shipping_discount" validate:"gte=0"`
+
+	GiftProductID *uint `json:"gift_product_id"`
+	GiftQuantity  int   `json:"gift_quantity" validate:"gte=0"`
+
+	Tiers []PromotionTierRequest `json:"tiers" validate:"omitempty,dive"`
+}
+
+type PromotionTierRequest struct {
+	MinSubtotal     float64 `json:"min_subtotal" validate:"gt=0"`
+	DiscountPercent float64 `json:"discount_percent" validate:"gt=0,lte=100"`
+	MaxDiscount     float64 `json:"max_discount" validate:"gte=0"`
+}
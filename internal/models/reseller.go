@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+)
+
+// CustomerGroup is the price list a user buys from
+type CustomerGroup string
+
+const (
+	CustomerGroupRetail   CustomerGroup = "retail"
+	CustomerGroupReseller CustomerGroup = "reseller"
+)
+
+type ResellerApplicationStatus string
+
+const (
+	ResellerApplicationPending  ResellerApplicationStatus = "pending"
+	ResellerApplicationApproved ResellerApplicationStatus = "approved"
+	ResellerApplicationRejected ResellerApplicationStatus = "rejected"
+)
+
+// ResellerApplication is a customer's request to buy at reseller prices. Approving it moves the
+// user to the reseller customer group.
+type ResellerApplication struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID uint  `json:"user_id" gorm:"not null;index"`
+	User   *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+
+	BusinessName string `json:"business_name" gorm:"not null;size:200"`
+	BusinessType string `json:"business_type" gorm:"size:100"`
+	TaxNumber    string `json:"tax_number" gorm:"size:30"` // NPWP
+	Phone        string `json:"phone" gorm:"not null;size:20"`
+	City         string `json:"city" gorm:"not null;size:100"`
+	Province     string `json:"province" gorm:"size:100"`
+	Notes        string `json:"notes" gorm:"type:text"`
+
+	Status     ResellerApplicationStatus `json:"status" gorm:"not null;size:20;default:'pending'"`
+	ReviewedBy *uint                     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time                `json:"reviewed_at,omitempty"`
+	ReviewNote string                    `json:"review_note,omitempty" gorm:"type:text"`
+}
+
+func (ResellerApplication) TableName() string {
+	return "reseller_applications"
+}
+
+// ResellerApplicationRequest is what a customer sends to apply as a reseller
+type ResellerApplicationRequest struct {
+	BusinessName string `json:"business_name" validate:"required,max=200"`
+	BusinessType string `json:"business_type" validate:"max=100"`
+	TaxNumber    string `json:"tax_number" validate:"max=30"`
+	Phone        string `json:"phone" validate:"required,max=20"`
+	City         string `json:"city" validate:"required,max=100"`
+	Province     string `json:"province" validate:"max=100"`
+	Notes        string `json:"notes" validate:"max=1000"`
+}
+
+// ResellerReviewRequest carries the admin's note when approving or rejecting an application
+type ResellerReviewRequest struct {
+	Note string `json:"note" validate:"max=500"`
+}
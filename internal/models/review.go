@@ -30,8 +30,9 @@ type Review struct {
 	IsVerified bool `json:"is_verified" gorm:"default:false"` // Verified purchase
 
 	// Moderation
-	IsApproved bool   `json:"is_approved" gorm:"default:false"`
-	AdminNotes string `json:"admin_notes" gorm:"size:500"`
+	IsApproved  bool       `json:"is_approved" gorm:"default:false"`
+	AdminNotes  string     `json:"admin_notes" gorm:"size:500"`
+	ModeratedAt *time.Time `json:"moderated_at"` // nil while waiting for moderation
 
 	// Statistics
 	HelpfulCount int `json:"helpful_count" gorm:"default:0"`
@@ -55,3 +56,51 @@ type ReviewImage struct {
 func (ReviewImage) TableName() string {
 	return "review_images"
 }
+
+// ReviewVote is a user's helpful / not helpful vote on a review; one per user per review
+type ReviewVote struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	ReviewID  uint `json:"review_id" gorm:"not null;uniqueIndex:uq_review_votes_review_user"`
+	UserID    uint `json:"user_id" gorm:"not null;uniqueIndex:uq_review_votes_review_user"`
+	IsHelpful bool `json:"is_helpful" gorm:"not null"`
+}
+
+func (ReviewVote) TableName() string {
+	return "review_votes"
+}
+
+// CreateReviewRequest represents a customer's review of a product they received
+type CreateReviewRequest struct {
+	ProductID uint   `json:"product_id" validate:"required"`
+	Rating    int    `json:"rating" validate:"required,min=1,max=5"`
+	Title     string `json:"title" validate:"max=200"`
+	Comment   string `json:"comment" validate:"max=5000"`
+}
+
+// ReviewModerationAction is an admin decision on a review
+type ReviewModerationAction string
+
+const (
+	ReviewActionApprove ReviewModerationAction = "approve"
+	ReviewActionReject  ReviewModerationAction = "reject"
+)
+
+// ModerateReviewRequest carries the admin notes for approving or rejecting a single review
+type ModerateReviewRequest struct {
+	AdminNotes string `json:"admin_notes" validate:"max=500"`
+}
+
+// BulkModerateReviewsRequest approves or rejects several reviews at once
+type BulkModerateReviewsRequest struct {
+	ReviewIDs  []uint                 `json:"review_ids" validate:"required,min=1,max=100"`
+	Action     ReviewModerationAction `json:"action" validate:"required,oneof=approve reject"`
+	AdminNotes string                 `json:"admin_notes" validate:"max=500"`
+}
+
+// VoteReviewRequest records whether a review was helpful. Helpful is a pointer so that
+// an explicit false is distinguishable from a missing field.
+type VoteReviewRequest struct {
+	Helpful *bool `json:"helpful" validate:"required"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -14,39 +15,79 @@ const (
 )
 
 type ShippingZone struct {
-	ID        uint             `json:"id" gorm:"primaryKey"`
-	CreatedAt time.Time        `json:"created_at"`
-	UpdatedAt time.Time        `json:"updated_at"`
-	DeletedAt gorm.DeletedAt   `json:"-" gorm:"index"`
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Basic Information
 	Name        string             `json:"name" gorm:"not null;size:200"`
 	Description string             `json:"description" gorm:"type:text"`
 	Status      ShippingZoneStatus `json:"status" gorm:"not null;default:'active'"`
 
-	// Zone Definition
-	Regions     []string `json:"regions" gorm:"-"` // List of region codes (e.g., ["ID-JK", "ID-JB"])
-	ExcludeRegions []string `json:"exclude_regions" gorm:"-"`    // Regions to exclude from this zone
+	// Zone Definition, stored in shipping_zone_regions and loaded by the repository. Codes are
+	// normalized with NormalizeRegionCode.
+	Regions        []string `json:"regions" gorm:"-"`         // List of region codes (e.g., ["ID-JK", "ID-JB"])
+	ExcludeRegions []string `json:"exclude_regions" gorm:"-"` // Regions to exclude from this zone
 
 	// Free Shipping
-	FreeShippingEnabled bool    `json:"free_shipping_enabled" gorm:"default:false"`
+	FreeShippingEnabled   bool    `json:"free_shipping_enabled" gorm:"default:false"`
 	FreeShippingThreshold float64 `json:"free_shipping_threshold"` // Minimum order amount for free shipping
 
 	// Shipping Rates (base rates per provider)
-	JNEBaseRate    float64 `json:"jne_base_rate" gorm:"default:15000"`    // IDR per kg
-	TIKIBaseRate   float64 `json:"tiki_base_rate" gorm:"default:16000"`   // IDR per kg
-	POSBaseRate    float64 `json:"pos_base_rate" gorm:"default:14000"`    // IDR per kg
+	JNEBaseRate     float64 `json:"jne_base_rate" gorm:"default:15000"`     // IDR per kg
+	TIKIBaseRate    float64 `json:"tiki_base_rate" gorm:"default:16000"`    // IDR per kg
+	POSBaseRate     float64 `json:"pos_base_rate" gorm:"default:14000"`     // IDR per kg
 	SiCepatBaseRate float64 `json:"sicepat_base_rate" gorm:"default:13000"` // IDR per kg
 
 	// Additional Costs
-	HandlingFee    float64 `json:"handling_fee" gorm:"default:0"`
-	MinimumCost    float64 `json:"minimum_cost" gorm:"default:9000"`
+	HandlingFee float64 `json:"handling_fee" gorm:"default:0"`
+	MinimumCost float64 `json:"minimum_cost" gorm:"default:9000"`
 
 	// Validity
-	ValidFrom   *time.Time `json:"valid_from"`
-	ValidUntil  *time.Time `json:"valid_until"`
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
 }
 
 func (ShippingZone) TableName() string {
 	return "shipping_zones"
 }
+
+// ShippingZoneRegion puts a region in a zone, or keeps it out when Excluded is set
+type ShippingZoneRegion struct {
+	ZoneID     uint   `json:"zone_id" gorm:"primaryKey"`
+	RegionCode string `json:"region_code" gorm:"primaryKey;size:100"`
+	Excluded   bool   `json:"excluded" gorm:"not null;default:false"`
+}
+
+func (ShippingZoneRegion) TableName() string {
+	return "shipping_zone_regions"
+}
+
+// NormalizeRegionCode makes region lookups case and whitespace insensitive
+func NormalizeRegionCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ShippingZoneRequest creates a zone or replaces one. Rates left out get the defaults.
+type ShippingZoneRequest struct {
+	Name        string             `json:"name" validate:"required,max=200"`
+	Description string             `json:"description"`
+	Status      ShippingZoneStatus `json:"status" validate:"omitempty,oneof=active inactive"`
+
+	Regions        []string `json:"regions" validate:"required,min=1,dive,required,max=100"`
+	ExcludeRegions []string `json:"exclude_regions" validate:"omitempty,dive,required,max=100"`
+
+	FreeShippingEnabled   bool    `json:"free_shipping_enabled"`
+	FreeShippingThreshold float64 `json:"free_shipping_threshold" validate:"gte=0"`
+
+	JNEBaseRate     *float64 `json:"jne_base_rate" validate:"omitempty,gt=0"`
+	TIKIBaseRate    *float64 `json:"tiki_base_rate" validate:"omitempty,gt=0"`
+	POSBaseRate     *float64 `json:"pos_base_rate" validate:"omitempty,gt=0"`
+	SiCepatBaseRate *float64 `json:"sicepat_base_rate" validate:"omitempty,gt=0"`
+	HandlingFee     float64  `json:"handling_fee" validate:"gte=0"`
+	MinimumCost     *float64 `json:"minimum_cost" validate:"omitempty,gte=0"`
+
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+}
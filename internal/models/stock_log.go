@@ -1,17 +1,32 @@
-package models
-
-import (
-	"time"
-)
-
-type StockLog struct {
-	ID            uint      `json:"id" gorm:"primaryKey"`
-	ProductID     uint      `json:"product_id"`
-	VariantID     *uint     `json:"variant_id"`
-	ChangeAmount  int       `json:"change_amount"`
-	PreviousStock int       `json:"previous_stock"`
-	NewStock      int       `json:"new_stock"`
-	Reason        string    `json:"reason"`
-	ReferenceID   string    `json:"reference_id"` // E.g., Order Number
-	CreatedAt     time.Time `json:"created_at"`
-}
+package models
+
+import (
+	"time"
+)
+
+type StockLog struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	ProductID     uint      `json:"product_id"`
+	VariantID     *uint     `json:"variant_id"`
+	ChangeAmount  int       `json:"change_amount"`
+	PreviousStock int       `json:"previous_stock"`
+	NewStock      int       `json:"new_stock"`
+	Reason        string    `json:"reason"`
+	ReferenceID   string    `json:"reference_id"` // E.g., Order Number
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// RestockEvent records a product or variant going from no stock to available stock.
+// Events are written alongside the stock update and consumed by the back-in-stock notifier.
+type RestockEvent struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ProductID   uint       `json:"product_id" gorm:"not null"`
+	VariantID   *uint      `json:"variant_id"`
+	NewStock    int        `json:"new_stock"`
+	ProcessedAt *time.Time `json:"processed_at"`
+}
+
+func (RestockEvent) TableName() string {
+	return "restock_events"
+}
@@ -51,3 +51,68 @@ type Tax struct {
 func (Tax) TableName() string {
 	return "taxes"
 }
+
+// AppliesTo reports whether the tax is charged on an order shipped to region at the given time.
+// A tax without applicable regions applies everywhere except its excluded regions. When the
+// region is unknown only default taxes apply.
+func (t *Tax) AppliesTo(region string, at time.Time) bool {
+	if t.Status != TaxStatusActive {
+		return false
+	}
+	if t.ValidFrom != nil && at.Before(*t.ValidFrom) {
+		return false
+	}
+	if t.ValidUntil != nil && !at.Before(*t.ValidUntil) {
+		return false
+	}
+
+	if region == "" {
+		return t.IsDefault
+	}
+	for _, excluded := range t.ExcludeRegions {
+		if excluded == region {
+			return false
+		}
+	}
+	if len(t.ApplicableRegions) == 0 {
+		return true
+	}
+	for _, applicable := range t.ApplicableRegions {
+		if applicable == region {
+			return true
+		}
+	}
+	return false
+}
+
+// TaxRegion limits a tax to a region, or excludes the region when Excluded is set
+type TaxRegion struct {
+	TaxID      uint   `json:"tax_id" gorm:"primaryKey"`
+	RegionCode string `json:"region_code" gorm:"primaryKey;size:20"`
+	Excluded   bool   `json:"excluded" gorm:"not null;default:false"`
+}
+
+func (TaxRegion) TableName() string {
+	return "tax_regions"
+}
+
+// OrderTax is one tax charged on an order, kept as it was at checkout for tax reporting
+type OrderTax struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	OrderID uint    `json:"order_id" gorm:"not null;index"`
+	TaxID   *uint   `json:"tax_id,omitempty" gorm:"index"`
+	Name    string  `json:"name" gorm:"not null;size:200"`
+	Type    TaxType `json:"type" gorm:"not null;size:20"`
+	Rate    float64 `json:"rate" gorm:"not null"`
+
+	// TaxableAmount is what a percentage tax was charged on, shipping included when the tax
+	// applies to it
+	TaxableAmount Money `json:"taxable_amount" gorm:"not null"`
+	Amount        Money `json:"amount" gorm:"not null"`
+}
+
+func (OrderTax) TableName() string {
+	return "order_taxes"
+}
@@ -34,6 +34,13 @@ type User struct {
 	IsVerified bool     `json:"is_verified" gorm:"default:false"`
 	IsActive   bool     `json:"is_active" gorm:"default:true"`
 
+	// CustomerGroup decides the prices the user gets; it only becomes reseller when an admin
+	// approves the user's reseller application
+	CustomerGroup CustomerGroup `json:"customer_group" gorm:"not null;size:20;default:'retail'"`
+
+	// Notification Preferences
+	WhatsAppOptOut bool `json:"whatsapp_opt_out" gorm:"column:whatsapp_opt_out;default:false"` // No marketing messages (cart reminders, alerts)
+
 	// Address Information
 	Address    string `json:"address" gorm:"size:255"`
 	City       string `json:"city" gorm:"size:100"`
@@ -53,3 +60,8 @@ type User struct {
 func (User) TableName() string {
 	return "users"
 }
+
+// IsReseller reports whether the user is an approved reseller
+func (u *User) IsReseller() bool {
+	return u.IsActive && u.CustomerGroup == CustomerGroupReseller
+}
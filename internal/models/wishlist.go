@@ -17,6 +17,12 @@ type Wishlist struct {
 	ProductID uint    `json:"product_id" gorm:"not null;index"`
 	Product   Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
 
+	// Last back-in-stock WhatsApp sent for this product, used to de-duplicate alerts
+	RestockNotifiedAt *time.Time `json:"-"`
+
+	// Unit price the user last saw, price-drop alerts are measured against it
+	PriceBaseline float64 `json:"-"`
+
 	// Unique constraint to prevent duplicate wishlist items
 	// This is handled by uniqueIndex on (user_id, product_id)
 }
@@ -24,3 +30,26 @@ type Wishlist struct {
 func (Wishlist) TableName() string {
 	return "wishlists"
 }
+
+// PriceAlert records a price-drop WhatsApp alert for a wishlisted product
+type PriceAlert struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID       uint    `json:"user_id" gorm:"not null;index"`
+	ProductID    uint    `json:"product_id" gorm:"not null"`
+	WishlistID   *uint   `json:"wishlist_id"`
+	OldPrice     float64 `json:"old_price"`
+	NewPrice     float64 `json:"new_price"`
+	DropPercent  float64 `json:"drop_percent"`
+	DiscountType string  `json:"discount_type" gorm:"size:20"`
+}
+
+func (PriceAlert) TableName() string {
+	return "price_alerts"
+}
+
+// AddToWishlistRequest represents a request to wishlist a product
+type AddToWishlistRequest struct {
+	ProductID uint `json:"product_id" validate:"required"`
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"gorm.io/gorm"
+)
+
+type CartRecoveryRepository interface {
+	FindAbandonedCarts(idleSince, notBefore time.Time, limit int) ([]models.Cart, error)
+	Create(recovery *models.CartRecovery) error
+	MarkConverted(userID, orderID uint, orderTotal models.Money, sentSince time.Time) (bool, error)
+	GetStats(from, to time.Time) (*models.CartRecoveryStats, error)
+}
+
+type cartRecoveryRepository struct {
+	db *gorm.DB
+}
+
+func NewCartRecoveryRepository(db *gorm.DB) CartRecoveryRepository {
+	return &cartRecoveryRepository{db: db}
+}
+
+// FindAbandonedCarts returns non-empty carts whose last change falls between notBefore and idleSince,
+// owned by active users who can receive WhatsApp messages and who have not been reminded since that change.
+func (r *cartRecoveryRepository) FindAbandonedCarts(idleSince, notBefore time.Time, limit int) ([]models.Cart, error) {
+	var carts []models.Cart
+
+	activity := r.db.Model(&models.CartItem{}).
+		Select("cart_id, MAX(updated_at) AS last_activity").
+		Group("cart_id")
+
+	err := r.db.
+		Joins("JOIN (?) AS activity ON activity.cart_id = carts.id", activity).
+		Joins("JOIN users ON users.id = carts.user_id AND users.deleted_at IS NULL").
+		Where("activity.last_activity < ? AND activity.last_activity >= ?", idleSince, notBefore).
+		Where("users.is_active = ? AND users.whatsapp_opt_out = ? AND users.phone <> ''", true, false).
+		Where("NOT EXISTS (SELECT 1 FROM cart_recoveries cr WHERE cr.cart_id = carts.id AND cr.sent_at >= activity.last_activity)").
+		Preload("User").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Order("activity.last_activity ASC").
+		Limit(limit).
+		Find(&carts).Error
+
+	return carts, err
+}
+
+func (r *cartRecoveryRepository) Create(recovery *models.CartRecovery) error {
+	return r.db.Create(recovery).Error
+}
+
+// MarkConverted attributes an order to the user's most recent delivered reminder sent after sentSince.
+// It reports whether a reminder was found.
+func (r *cartRecoveryRepository) MarkConverted(userID, orderID uint, orderTotal models.Money, sentSince time.Time) (bool, error) {
+	latest := r.db.Model(&models.CartRecovery{}).
+		Select("id").
+		Where("user_id = ? AND status = ? AND sent_at >= ?", userID, models.CartRecoveryStatusSent, sentSince).
+		Order("sent_at DESC").
+		Limit(1)
+
+	now := time.Now()
+	result := r.db.Model(&models.CartRecovery{}).
+		Where("id = (?)", latest).
+		Updates(map[string]interface{}{
+			"status":       models.CartRecoveryStatusConverted,
+			"order_id":     orderID,
+			"order_total":  orderTotal,
+			"converted_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *cartRecoveryRepository) GetStats(from, to time.Time) (*models.CartRecoveryStats, error) {
+	var row struct {
+		Sent             int64
+		Failed           int64
+		Converted        int64
+		RecoveredRevenue models.Money
+		CouponsIssued    int64
+	}
+
+	err := r.db.Model(&models.CartRecovery{}).
+		Select(`COUNT(*) FILTER (WHERE status IN ('sent', 'converted')) AS sent,
+			COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+			COUNT(*) FILTER (WHERE status = 'converted') AS converted,
+			COALESCE(SUM(order_total) FILTER (WHERE status = 'converted'), 0) AS recovered_revenue,
+			COUNT(coupon_id) AS coupons_issued`).
+		Where("sent_at >= ? AND sent_at < ?", from, to).
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.CartRecoveryStats{
+		From:             from,
+		To:               to,
+		Sent:             row.Sent,
+		Failed:           row.Failed,
+		Converted:        row.Converted,
+		RecoveredRevenue: row.RecoveredRevenue,
+		CouponsIssued:    row.CouponsIssued,
+	}
+	if row.Sent > 0 {
+		stats.RecoveryRate = float64(row.Converted) / float64(row.Sent) * 100
+	}
+	return stats, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/karima-store/internal/models"
+	"gorm.io/gorm"
+)
+
+type CartRepository interface {
+	GetByUserID(userID uint) (*models.Cart, error)
+	GetOrCreateByUserID(userID uint) (*models.Cart, error)
+	GetItem(cartID, itemID uint) (*models.CartItem, error)
+	FindItem(cartID, productID uint, variantID *uint) (*models.CartItem, error)
+	AddItem(item *models.CartItem) error
+	UpdateItem(item *models.CartItem) error
+	// SaveItems adds new lines and updates existing ones in a single transaction
+	SaveItems(items []*models.CartItem) error
+	RemoveItem(cartID, itemID uint) error
+	Clear(cartID uint) error
+	WithTx(tx *gorm.DB) CartRepository
+}
+
+type cartRepository struct {
+	db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) CartRepository {
+	return &cartRepository{db: db}
+}
+
+func (r *cartRepository) WithTx(tx *gorm.DB) CartRepository {
+	return &cartRepository{db: tx}
+}
+
+func (r *cartRepository) GetByUserID(userID uint) (*models.Cart, error) {
+	var cart models.Cart
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Where("user_id = ?", userID).First(&cart).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// GetOrCreateByUserID returns the user's cart, creating an empty one on first use
+func (r *cartRepository) GetOrCreateByUserID(userID uint) (*models.Cart, error) {
+	cart, err := r.GetByUserID(userID)
+	if err == nil {
+		return cart, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	cart = &models.Cart{UserID: userID}
+	if err := r.db.Create(cart).Error; err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+func (r *cartRepository) GetItem(cartID, itemID uint) (*models.CartItem, error) {
+	var item models.CartItem
+	err := r.db.Where("cart_id = ? AND id = ?", cartID, itemID).First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// FindItem looks up the line for a product/variant pair so repeated adds merge into one line
+func (r *cartRepository) FindItem(cartID, productID uint, variantID *uint) (*models.CartItem, error) {
+	var item models.CartItem
+	query := r.db.Where("cart_id = ? AND product_id = ?", cartID, productID)
+	if variantID != nil {
+		query = query.Where("product_variant_id = ?", *variantID)
+	} else {
+		query = query.Where("product_variant_id IS NULL")
+	}
+
+	if err := query.First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *cartRepository) AddItem(item *models.CartItem) error {
+	return r.db.Omit("Cart", "Product", "ProductVariant").Create(item).Error
+}
+
+func (r *cartRepository) UpdateItem(item *models.CartItem) error {
+	return r.db.Omit("Cart", "Product", "ProductVariant").Save(item).Error
+}
+
+func (r *cartRepository) SaveItems(items []*models.CartItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := r.WithTx(tx)
+		for _, item := range items {
+			var err error
+			if item.ID == 0 {
+				err = txRepo.AddItem(item)
+			} else {
+				err = txRepo.UpdateItem(item)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *cartRepository) RemoveItem(cartID, itemID uint) error {
+	result := r.db.Where("cart_id = ? AND id = ?", cartID, itemID).Delete(&models.CartItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *cartRepository) Clear(cartID uint) error {
+	return r.db.Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error
+}
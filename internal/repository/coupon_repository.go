@@ -5,6 +5,7 @@ import (
 
 	"github.com/karima-store/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CouponRepository interface {
@@ -15,9 +16,23 @@ type CouponRepository interface {
 	GetActive() ([]models.Coupon, error)
 	Update(coupon *models.Coupon) error
 	Delete(id uint) error
-	ValidateCoupon(code string, userID uint, purchaseAmount float64, customerType string) (*models.Coupon, error)
-	RecordUsage(couponID, userID, orderID uint, discountAmount float64) error
+	ValidateCoupon(code string, userID uint, purchaseAmount models.Money, customerType string) (*models.Coupon, error)
+	RecordUsage(couponID, userID, orderID uint, discountAmount models.Money) error
 	GetUserUsageCount(couponID, userID uint) (int, error)
+
+	List(filter models.CouponListFilter, limit, offset int) ([]models.Coupon, int64, error)
+	GetUsageStats(couponID uint) (*models.CouponUsageStats, error)
+	ExistingCodes(codes []string) ([]string, error)
+
+	CreateBatch(batch *models.CouponBatch, coupons []models.Coupon) error
+	GetBatchByID(id uint) (*models.CouponBatch, error)
+	GetBatches(limit, offset int) ([]models.CouponBatch, int64, error)
+	GetBatchCoupons(batchID uint) ([]models.Coupon, error)
+	GetBatchUsageStats(batchID uint) (*models.CouponUsageStats, error)
+
+	LockByCode(code string) (*models.Coupon, error)
+	RevokeUsage(orderID uint) error
+	WithTx(tx *gorm.DB) CouponRepository
 }
 
 type couponRepository struct {
@@ -28,9 +43,18 @@ func NewCouponRepository(db *gorm.DB) CouponRepository {
 	return &couponRepository{db: db}
 }
 
+func (r *couponRepository) WithTx(tx *gorm.DB) CouponRepository {
+	return &couponRepository{db: tx}
+}
+
 func (r *couponRepository) Create(coupon *models.Coupon) error {
-	// Select("*") ensures that zero values (like bool false) are also saved
-	return r.db.Select("*").Create(coupon).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Select("*") ensures that zero values (like bool false) are also saved
+		if err := tx.Select("*").Create(coupon).Error; err != nil {
+			return err
+		}
+		return saveCouponRestrictions(tx, coupon)
+	})
 }
 
 func (r *couponRepository) GetByID(id uint) (*models.Coupon, error) {
@@ -39,6 +63,9 @@ func (r *couponRepository) GetByID(id uint) (*models.Coupon, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := r.loadRestrictions(&coupon); err != nil {
+		return nil, err
+	}
 	return &coupon, nil
 }
 
@@ -48,6 +75,9 @@ func (r *couponRepository) GetByCode(code string) (*models.Coupon, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := r.loadRestrictions(&coupon); err != nil {
+		return nil, err
+	}
 	return &coupon, nil
 }
 
@@ -65,8 +95,11 @@ func (r *couponRepository) GetAll(limit, offset int) ([]models.Coupon, int64, er
 		Limit(limit).
 		Offset(offset).
 		Find(&coupons).Error
+	if err != nil {
+		return nil, 0, err
+	}
 
-	return coupons, total, err
+	return coupons, total, r.loadRestrictions(couponPointers(coupons)...)
 }
 
 func (r *couponRepository) GetActive() ([]models.Coupon, error) {
@@ -78,12 +111,27 @@ func (r *couponRepository) GetActive() ([]models.Coupon, error) {
 		Where("(valid_until IS NULL OR valid_until >= ?)", now).
 		Order("created_at DESC").
 		Find(&coupons).Error
+	if err != nil {
+		return nil, err
+	}
 
-	return coupons, err
+	return coupons, r.loadRestrictions(couponPointers(coupons)...)
 }
 
+// Update saves the coupon and replaces its product and category restrictions
 func (r *couponRepository) Update(coupon *models.Coupon) error {
-	return r.db.Save(coupon).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(coupon).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("coupon_id = ?", coupon.ID).Delete(&models.CouponProduct{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("coupon_id = ?", coupon.ID).Delete(&models.CouponCategory{}).Error; err != nil {
+			return err
+		}
+		return saveCouponRestrictions(tx, coupon)
+	})
 }
 
 func (r *couponRepository) Delete(id uint) error {
@@ -91,7 +139,7 @@ func (r *couponRepository) Delete(id uint) error {
 }
 
 // ValidateCoupon checks if a coupon is valid for use
-func (r *couponRepository) ValidateCoupon(code string, userID uint, purchaseAmount float64, customerType string) (*models.Coupon, error) {
+func (r *couponRepository) ValidateCoupon(code string, userID uint, purchaseAmount models.Money, customerType string) (*models.Coupon, error) {
 	coupon, err := r.GetByCode(code)
 	if err != nil {
 		return nil, err
@@ -112,7 +160,7 @@ func (r *couponRepository) ValidateCoupon(code string, userID uint, purchaseAmou
 	}
 
 	// Check minimum purchase amount
-	if coupon.MinPurchaseAmount > 0 && purchaseAmount < coupon.MinPurchaseAmount {
+	if coupon.MinPurchaseAmount > 0 && purchaseAmount < models.NewMoney(coupon.MinPurchaseAmount) {
 		return nil, gorm.ErrRecordNotFound
 	}
 
@@ -124,6 +172,11 @@ func (r *couponRepository) ValidateCoupon(code string, userID uint, purchaseAmou
 		return nil, gorm.ErrRecordNotFound
 	}
 
+	// Check the coupon was issued to this user
+	if coupon.UserID != nil && *coupon.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
 	// Check usage limits
 	if coupon.MaxUsageCount > 0 && coupon.UsageCount >= coupon.MaxUsageCount {
 		return nil, gorm.ErrRecordNotFound
@@ -144,23 +197,74 @@ func (r *couponRepository) ValidateCoupon(code string, userID uint, purchaseAmou
 }
 
 // RecordUsage records a coupon usage
-func (r *couponRepository) RecordUsage(couponID, userID, orderID uint, discountAmount float64) error {
-	// Create coupon usage record
-	usage := &models.CouponUsage{
-		CouponID:       couponID,
-		UserID:         userID,
-		OrderID:        orderID,
-		DiscountAmount: discountAmount,
-	}
+func (r *couponRepository) RecordUsage(couponID, userID, orderID uint, discountAmount models.Money) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Create coupon usage record
+		usage := &models.CouponUsage{
+			CouponID:       couponID,
+			UserID:         userID,
+			OrderID:        orderID,
+			DiscountAmount: discountAmount,
+		}
 
-	if err := r.db.Create(usage).Error; err != nil {
-		return err
+		if err := tx.Create(usage).Error; err != nil {
+			return err
+		}
+
+		// Update coupon usage count and statistics
+		return tx.Model(&models.Coupon{}).
+			Where("id = ?", couponID).
+			Updates(map[string]interface{}{
+				"usage_count":         gorm.Expr("usage_count + 1"),
+				"order_count":         gorm.Expr("order_count + 1"),
+				"total_discount_used": gorm.Expr("total_discount_used + ?", discountAmount),
+			}).Error
+	})
+}
+
+// LockByCode loads a coupon and locks its row until the surrounding transaction ends, so
+// concurrent checkouts redeeming it are checked against its limits one at a time
+func (r *couponRepository) LockByCode(code string) (*models.Coupon, error) {
+	var coupon models.Coupon
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", code).
+		First(&coupon).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadRestrictions(&coupon); err != nil {
+		return nil, err
 	}
+	return &coupon, nil
+}
 
-	// Update coupon usage count
-	return r.db.Model(&models.Coupon{}).
-		Where("id = ?", couponID).
-		Update("usage_count", gorm.Expr("usage_count + 1")).Error
+// RevokeUsage removes the coupon usage recorded for an order and takes it back out of the
+// coupon's counters, so the redemption can be used again. Revoking an order without usage is a
+// no-op.
+func (r *couponRepository) RevokeUsage(orderID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var usages []models.CouponUsage
+		if err := tx.Where("order_id = ?", orderID).Find(&usages).Error; err != nil {
+			return err
+		}
+
+		for _, usage := range usages {
+			if err := tx.Delete(&usage).Error; err != nil {
+				return err
+			}
+			err := tx.Model(&models.Coupon{}).
+				Where("id = ?", usage.CouponID).
+				Updates(map[string]interface{}{
+					"usage_count":         gorm.Expr("GREATEST(usage_count - 1, 0)"),
+					"order_count":         gorm.Expr("GREATEST(order_count - 1, 0)"),
+					"total_discount_used": gorm.Expr("GREATEST(total_discount_used - ?, 0)", usage.DiscountAmount),
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // GetUserUsageCount gets the number of times a user has used a coupon
@@ -172,3 +276,228 @@ func (r *couponRepository) GetUserUsageCount(couponID, userID uint) (int, error)
 
 	return int(count), err
 }
+
+// List returns coupons matching the filter, newest first
+func (r *couponRepository) List(filter models.CouponListFilter, limit, offset int) ([]models.Coupon, int64, error) {
+	var coupons []models.Coupon
+	var total int64
+
+	query := r.db.Model(&models.Coupon{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.BatchID != nil {
+		query = query.Where("batch_id = ?", *filter.BatchID)
+	}
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		query = query.Where("code ILIKE ? OR name ILIKE ?", pattern, pattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&coupons).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return coupons, total, r.loadRestrictions(couponPointers(coupons)...)
+}
+
+// couponUsageStatsQuery aggregates the usages of the coupons selected by the WHERE clause
+const couponUsageStatsQuery = `
+	SELECT
+		COUNT(DISTINCT c.id) AS codes,
+		COUNT(DISTINCT cu.coupon_id) AS redeemed_codes,
+		COUNT(cu.id) AS redemptions,
+		COUNT(DISTINCT cu.user_id) AS unique_customers,
+		COALESCE(SUM(cu.discount_amount), 0) AS total_discount,
+		MIN(cu.created_at) AS first_used_at,
+		MAX(cu.created_at) AS last_used_at
+	FROM coupons c
+	LEFT JOIN coupon_usages cu ON cu.coupon_id = c.id
+	WHERE c.deleted_at IS NULL AND `
+
+// GetUsageStats summarises the redemptions of one coupon
+func (r *couponRepository) GetUsageStats(couponID uint) (*models.CouponUsageStats, error) {
+	var stats models.CouponUsageStats
+	if err := r.db.Raw(couponUsageStatsQuery+"c.id = ?", couponID).Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// ExistingCodes returns which of the codes are already taken, including by deleted coupons
+// since they still hold the unique index
+func (r *couponRepository) ExistingCodes(codes []string) ([]string, error) {
+	var existing []string
+	if len(codes) == 0 {
+		return existing, nil
+	}
+	err := r.db.Unscoped().Model(&models.Coupon{}).
+		Where("code IN ?", codes).
+		Pluck("code", &existing).Error
+	return existing, err
+}
+
+// CreateBatch saves a batch and its coupons in one transaction
+func (r *couponRepository) CreateBatch(batch *models.CouponBatch, coupons []models.Coupon) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		for i := range coupons {
+			coupons[i].BatchID = &batch.ID
+		}
+		// Select("*") ensures that zero values (like bool false) are also saved
+		if err := tx.Select("*").CreateInBatches(coupons, 500).Error; err != nil {
+			return err
+		}
+		for i := range coupons {
+			if err := saveCouponRestrictions(tx, &coupons[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *couponRepository) GetBatchByID(id uint) (*models.CouponBatch, error) {
+	var batch models.CouponBatch
+	err := r.db.First(&batch, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *couponRepository) GetBatches(limit, offset int) ([]models.CouponBatch, int64, error) {
+	var batches []models.CouponBatch
+	var total int64
+
+	query := r.db.Model(&models.CouponBatch{})
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&batches).Error
+
+	return batches, total, err
+}
+
+// GetBatchCoupons returns every code of a batch in the order they were generated
+func (r *couponRepository) GetBatchCoupons(batchID uint) ([]models.Coupon, error) {
+	var coupons []models.Coupon
+	err := r.db.Where("batch_id = ?", batchID).
+		Order("id ASC").
+		Find(&coupons).Error
+	if err != nil {
+		return nil, err
+	}
+	return coupons, r.loadRestrictions(couponPointers(coupons)...)
+}
+
+// GetBatchUsageStats summarises the redemptions of every code in a batch
+func (r *couponRepository) GetBatchUsageStats(batchID uint) (*models.CouponUsageStats, error) {
+	var stats models.CouponUsageStats
+	if err := r.db.Raw(couponUsageStatsQuery+"c.batch_id = ?", batchID).Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// loadRestrictions fills the product and category restrictions of the given coupons
+func (r *couponRepository) loadRestrictions(coupons ...*models.Coupon) error {
+	if len(coupons) == 0 {
+		return nil
+	}
+
+	byID := make(map[uint]*models.Coupon, len(coupons))
+	ids := make([]uint, 0, len(coupons))
+	for _, coupon := range coupons {
+		coupon.ApplicableProducts = nil
+		coupon.ExcludeProducts = nil
+		coupon.ApplicableCategories = nil
+		byID[coupon.ID] = coupon
+		ids = append(ids, coupon.ID)
+	}
+
+	var products []models.CouponProduct
+	if err := r.db.Where("coupon_id IN ?", ids).Order("product_id").Find(&products).Error; err != nil {
+		return err
+	}
+	for _, p := range products {
+		coupon := byID[p.CouponID]
+		if p.Excluded {
+			coupon.ExcludeProducts = append(coupon.ExcludeProducts, p.ProductID)
+		} else {
+			coupon.ApplicableProducts = append(coupon.ApplicableProducts, p.ProductID)
+		}
+	}
+
+	var categories []models.CouponCategory
+	if err := r.db.Where("coupon_id IN ?", ids).Order("category").Find(&categories).Error; err != nil {
+		return err
+	}
+	for _, c := range categories {
+		coupon := byID[c.CouponID]
+		coupon.ApplicableCategories = append(coupon.ApplicableCategories, c.Category)
+	}
+	return nil
+}
+
+// saveCouponRestrictions inserts the restriction rows of a saved coupon. A product listed both
+// as applicable and excluded is stored as excluded.
+func saveCouponRestrictions(tx *gorm.DB, coupon *models.Coupon) error {
+	excluded := make(map[uint]bool, len(coupon.ExcludeProducts))
+	var products []models.CouponProduct
+	for _, id := range coupon.ExcludeProducts {
+		if !excluded[id] {
+			excluded[id] = true
+			products = append(products, models.CouponProduct{CouponID: coupon.ID, ProductID: id, Excluded: true})
+		}
+	}
+	applicable := make(map[uint]bool, len(coupon.ApplicableProducts))
+	for _, id := range coupon.ApplicableProducts {
+		if !excluded[id] && !applicable[id] {
+			applicable[id] = true
+			products = append(products, models.CouponProduct{CouponID: coupon.ID, ProductID: id})
+		}
+	}
+	if len(products) > 0 {
+		// Select("*") ensures that zero values (like bool false) are also saved
+		if err := tx.Select("*").Create(&products).Error; err != nil {
+			return err
+		}
+	}
+
+	seen := make(map[string]bool, len(coupon.ApplicableCategories))
+	var categories []models.CouponCategory
+	for _, category := range coupon.ApplicableCategories {
+		if !seen[category] {
+			seen[category] = true
+			categories = append(categories, models.CouponCategory{CouponID: coupon.ID, Category: category})
+		}
+	}
+	if len(categories) > 0 {
+		return tx.Create(&categories).Error
+	}
+	return nil
+}
+
+func couponPointers(coupons []models.Coupon) []*models.Coupon {
+	pointers := make([]*models.Coupon, len(coupons))
+	for i := range coupons {
+		pointers[i] = &coupons[i]
+	}
+	return pointers
+}
@@ -311,6 +311,29 @@ func TestCouponRepository_ValidateCoupon_CustomerTypeRestriction(t *testing.T) {
 	assert.Equal(t, coupon.ID, validated.ID)
 }
 
+func TestCouponRepository_ValidateCoupon_UserRestriction(t *testing.T) {
+	db, cleanup := setupCouponTest(t)
+	defer cleanup()
+
+	repo := NewCouponRepository(db)
+
+	// Create a coupon issued to user 1
+	userID := uint(1)
+	coupon := createTestCoupon("USERONLY")
+	coupon.UserID = &userID
+	err := repo.Create(coupon)
+	require.NoError(t, err)
+
+	// Validate for another user - should fail
+	_, err = repo.ValidateCoupon("USERONLY", 2, 150, "retail")
+	assert.Error(t, err)
+
+	// Validate for the owner - should succeed
+	validated, err := repo.ValidateCoupon("USERONLY", 1, 150, "retail")
+	require.NoError(t, err)
+	assert.Equal(t, coupon.ID, validated.ID)
+}
+
 func TestCouponRepository_ValidateCoupon_MaxUsageReached(t *testing.T) {
 	db, cleanup := setupCouponTest(t)
 	defer cleanup()
@@ -364,7 +387,7 @@ func TestCouponRepository_RecordUsage(t *testing.T) {
 	require.NoError(t, err)
 
 	// Record usage
-	err = repo.RecordUsage(coupon.ID, 1, 100, 10.0)
+	err = repo.RecordUsage(coupon.ID, 1, 100, 10)
 	require.NoError(t, err)
 
 	// Verify usage count increased
@@ -388,11 +411,11 @@ func TestCouponRepository_GetUserUsageCount(t *testing.T) {
 	require.NoError(t, err)
 
 	// Record multiple usages by same user
-	err = repo.RecordUsage(coupon.ID, 1, 100, 10.0)
+	err = repo.RecordUsage(coupon.ID, 1, 100, 10)
 	require.NoError(t, err)
-	err = repo.RecordUsage(coupon.ID, 1, 101, 15.0)
+	err = repo.RecordUsage(coupon.ID, 1, 101, 15)
 	require.NoError(t, err)
-	err = repo.RecordUsage(coupon.ID, 2, 102, 20.0) // Different user
+	err = repo.RecordUsage(coupon.ID, 2, 102, 20) // Different user
 	require.NoError(t, err)
 
 	// Get user usage count
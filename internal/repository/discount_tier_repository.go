@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/karima-store/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DiscountTierRepository interface {
+	Create(tier *models.DiscountTier) error
+	Update(tier *models.DiscountTier) error
+	Delete(id uint) error
+	GetByID(id uint) (*models.DiscountTier, error)
+	List(filter models.DiscountTierListFilter, limit, offset int) ([]models.DiscountTier, int64, error)
+	// GetCurrent returns the active tiers that have not ended at the given time, including the
+	// ones that only start later
+	GetCurrent(at time.Time) ([]models.DiscountTier, error)
+}
+
+type discountTierRepository struct {
+	db *gorm.DB
+}
+
+func NewDiscountTierRepository(db *gorm.DB) DiscountTierRepository {
+	return &discountTierRepository{db: db}
+}
+
+func (r *discountTierRepository) Create(tier *models.DiscountTier) error {
+	return r.db.Create(tier).Error
+}
+
+func (r *discountTierRepository) Update(tier *models.DiscountTier) error {
+	return r.db.Save(tier).Error
+}
+
+func (r *discountTierRepository) Delete(id uint) error {
+	return r.db.Delete(&models.DiscountTier{}, id).Error
+}
+
+func (r *discountTierRepository) GetByID(id uint) (*models.DiscountTier, error) {
+	var tier models.DiscountTier
+	if err := r.db.First(&tier, id).Error; err != nil {
+		return nil, err
+	}
+	return &tier, nil
+}
+
+// List returns tiers matching the filter grouped by table, each table in quantity order
+func (r *discountTierRepository) List(filter models.DiscountTierListFilter, limit, offset int) ([]models.DiscountTier, int64, error) {
+	var tiers []models.DiscountTier
+	var total int64
+
+	query := r.db.Model(&models.DiscountTier{})
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.Scope != "" {
+		query = query.Where("scope = ?", filter.Scope)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.ProductID != nil {
+		query = query.Where("product_id = ?", *filter.ProductID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("kind, scope, category, product_id, min_quantity").
+		Limit(limit).
+		Offset(offset).
+		Find(&tiers).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return tiers, total, nil
+}
+
+func (r *discountTierRepository) GetCurrent(at time.Time) ([]models.DiscountTier, error) {
+	var tiers []models.DiscountTier
+	err := r.db.Where("is_active = ?", true).
+		Where("ends_at IS NULL OR ends_at > ?", at).
+		Order("min_quantity ASC").
+		Find(&tiers).Error
+	if err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
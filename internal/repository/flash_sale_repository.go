@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/karima-store/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type FlashSaleRepository interface {
@@ -15,9 +18,18 @@ type FlashSaleRepository interface {
 	Update(flashSale *models.FlashSale) error
 	Delete(id uint) error
 	AddProductToFlashSale(flashSaleProduct *models.FlashSaleProduct) error
-	RemoveProductFromFlashSale(flashSaleID, productID uint) error
+	RemoveProductFromFlashSale(flashSaleID, productID uint, variantID *uint) error
 	GetFlashSaleProducts(flashSaleID uint) ([]models.FlashSaleProduct, error)
 	UpdateFlashSaleProduct(flashSaleProduct *models.FlashSaleProduct) error
+	GetFlashSaleProduct(flashSaleID, productID uint, variantID *uint) (*models.FlashSaleProduct, error)
+	GetFlashSaleProductByID(id uint) (*models.FlashSaleProduct, error)
+	GetByStatus(status models.FlashSaleStatus) ([]models.FlashSale, error)
+	GetDueToStart(now time.Time) ([]models.FlashSale, error)
+	GetDueToEnd(now time.Time) ([]models.FlashSale, error)
+	GetNextTransitionTime(now time.Time) (*time.Time, error)
+	TransitionStatus(id uint, from, to models.FlashSaleStatus) (bool, error)
+	GetUsage(flashSaleID, flashSaleProductID, userID uint) (*models.FlashSaleUsage, error)
+	ReconcileSales(flashSaleID uint) error
 }
 
 type flashSaleRepository struct {
@@ -75,14 +87,14 @@ func (r *flashSaleRepository) GetUpcomingFlashSales() ([]models.FlashSale, error
 	return flashSales, nil
 }
 
-// Create creates a new flash sale
+// Create creates a new flash sale; products are added separately through AddProductToFlashSale
 func (r *flashSaleRepository) Create(flashSale *models.FlashSale) error {
-	return r.db.Create(flashSale).Error
+	return r.db.Omit(clause.Associations).Create(flashSale).Error
 }
 
-// Update updates an existing flash sale
+// Update updates an existing flash sale without touching its products
 func (r *flashSaleRepository) Update(flashSale *models.FlashSale) error {
-	return r.db.Save(flashSale).Error
+	return r.db.Omit(clause.Associations).Save(flashSale).Error
 }
 
 // Delete soft deletes a flash sale
@@ -92,12 +104,13 @@ func (r *flashSaleRepository) Delete(id uint) error {
 
 // AddProductToFlashSale adds a product to a flash sale
 func (r *flashSaleRepository) AddProductToFlashSale(flashSaleProduct *models.FlashSaleProduct) error {
-	return r.db.Create(flashSaleProduct).Error
+	return r.db.Omit(clause.Associations).Create(flashSaleProduct).Error
 }
 
-// RemoveProductFromFlashSale removes a product from a flash sale
-func (r *flashSaleRepository) RemoveProductFromFlashSale(flashSaleID, productID uint) error {
-	return r.db.Where("flash_sale_id = ? AND product_id = ?", flashSaleID, productID).
+// RemoveProductFromFlashSale removes a product entry, or one variant's entry, from a flash sale
+func (r *flashSaleRepository) RemoveProductFromFlashSale(flashSaleID, productID uint, variantID *uint) error {
+	return r.db.Scopes(flashSaleVariant(variantID)).
+		Where("flash_sale_id = ? AND product_id = ?", flashSaleID, productID).
 		Delete(&models.FlashSaleProduct{}).Error
 }
 
@@ -106,6 +119,7 @@ func (r *flashSaleRepository) GetFlashSaleProducts(flashSaleID uint) ([]models.F
 	var flashSaleProducts []models.FlashSaleProduct
 	err := r.db.Preload("FlashSale").
 		Preload("Product").
+		Preload("Variant").
 		Where("flash_sale_id = ?", flashSaleID).
 		Find(&flashSaleProducts).Error
 	if err != nil {
@@ -116,5 +130,158 @@ func (r *flashSaleRepository) GetFlashSaleProducts(flashSaleID uint) ([]models.F
 
 // UpdateFlashSaleProduct updates a product in a flash sale
 func (r *flashSaleRepository) UpdateFlashSaleProduct(flashSaleProduct *models.FlashSaleProduct) error {
-	return r.db.Save(flashSaleProduct).Error
+	return r.db.Omit(clause.Associations).Save(flashSaleProduct).Error
+}
+
+// GetFlashSaleProduct retrieves the product-level entry of a flash sale, or a variant's entry
+// when variantID is set
+func (r *flashSaleRepository) GetFlashSaleProduct(flashSaleID, productID uint, variantID *uint) (*models.FlashSaleProduct, error) {
+	var flashSaleProduct models.FlashSaleProduct
+	err := r.db.Preload("Product").
+		Preload("Variant").
+		Scopes(flashSaleVariant(variantID)).
+		Where("flash_sale_id = ? AND product_id = ?", flashSaleID, productID).
+		First(&flashSaleProduct).Error
+	if err != nil {
+		return nil, err
+	}
+	return &flashSaleProduct, nil
+}
+
+// GetFlashSaleProductByID retrieves a flash sale entry by its own ID
+func (r *flashSaleRepository) GetFlashSaleProductByID(id uint) (*models.FlashSaleProduct, error) {
+	var flashSaleProduct models.FlashSaleProduct
+	err := r.db.Preload("Product").Preload("Variant").First(&flashSaleProduct, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &flashSaleProduct, nil
+}
+
+func flashSaleVariant(variantID *uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if variantID == nil {
+			return db.Where("variant_id IS NULL")
+		}
+		return db.Where("variant_id = ?", *variantID)
+	}
+}
+
+// GetByStatus retrieves flash sales with the given status, soonest first
+func (r *flashSaleRepository) GetByStatus(status models.FlashSaleStatus) ([]models.FlashSale, error) {
+	var flashSales []models.FlashSale
+	err := r.db.Preload("Products").
+		Where("status = ?", status).
+		Order("start_time ASC").
+		Find(&flashSales).Error
+	if err != nil {
+		return nil, err
+	}
+	return flashSales, nil
+}
+
+// GetDueToStart retrieves upcoming flash sales whose start time has passed and that have not ended yet
+func (r *flashSaleRepository) GetDueToStart(now time.Time) ([]models.FlashSale, error) {
+	var flashSales []models.FlashSale
+	err := r.db.Preload("Products").
+		Where("status = ? AND start_time <= ? AND end_time > ?", models.FlashSaleUpcoming, now, now).
+		Find(&flashSales).Error
+	if err != nil {
+		return nil, err
+	}
+	return flashSales, nil
+}
+
+// GetDueToEnd retrieves upcoming or active flash sales whose end time has passed
+func (r *flashSaleRepository) GetDueToEnd(now time.Time) ([]models.FlashSale, error) {
+	var flashSales []models.FlashSale
+	err := r.db.Preload("Products").
+		Where("status IN ? AND end_time <= ?", []models.FlashSaleStatus{models.FlashSaleUpcoming, models.FlashSaleActive}, now).
+		Find(&flashSales).Error
+	if err != nil {
+		return nil, err
+	}
+	return flashSales, nil
+}
+
+// GetNextTransitionTime returns the earliest future start or end time of a sale that still has
+// to change status, or nil when nothing is scheduled
+func (r *flashSaleRepository) GetNextTransitionTime(now time.Time) (*time.Time, error) {
+	var next *time.Time
+	err := r.db.Raw(`
+		SELECT MIN(t) FROM (
+			SELECT start_time AS t FROM flash_sales WHERE deleted_at IS NULL AND status = ? AND start_time > ?
+			UNION ALL
+			SELECT end_time AS t FROM flash_sales WHERE deleted_at IS NULL AND status IN ? AND end_time > ?
+		) boundaries`,
+		models.FlashSaleUpcoming, now,
+		[]models.FlashSaleStatus{models.FlashSaleUpcoming, models.FlashSaleActive}, now,
+	).Scan(&next).Error
+	return next, err
+}
+
+// TransitionStatus moves a flash sale from one status to another. It reports false when the
+// sale was no longer in the from status, e.g. because another instance moved it first.
+func (r *flashSaleRepository) TransitionStatus(id uint, from, to models.FlashSaleStatus) (bool, error) {
+	result := r.db.Model(&models.FlashSale{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetUsage sums the flash sale units held by orders that are awaiting payment or paid
+func (r *flashSaleRepository) GetUsage(flashSaleID, flashSaleProductID, userID uint) (*models.FlashSaleUsage, error) {
+	var usage models.FlashSaleUsage
+	err := r.db.Raw(`
+		SELECT
+			COALESCE(SUM(oi.quantity) FILTER (WHERE oi.flash_sale_product_id = ?), 0) AS product,
+			COALESCE(SUM(oi.quantity), 0) AS sale,
+			COALESCE(SUM(oi.quantity) FILTER (WHERE o.user_id = ?), 0) AS "user"
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id AND o.deleted_at IS NULL
+		WHERE oi.flash_sale_id = ? AND o.payment_status IN ?`,
+		flashSaleProductID, userID, flashSaleID,
+		[]models.PaymentStatus{models.PaymentPending, models.PaymentPaid},
+	).Scan(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// ReconcileSales recomputes a flash sale's sold counts and revenue from its paid order items
+func (r *flashSaleRepository) ReconcileSales(flashSaleID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			UPDATE flash_sale_products fsp SET sold_count = COALESCE((
+				SELECT SUM(oi.quantity)
+				FROM order_items oi
+				JOIN orders o ON o.id = oi.order_id AND o.deleted_at IS NULL
+				WHERE oi.flash_sale_product_id = fsp.id AND o.payment_status = ?
+			), 0), updated_at = NOW()
+			WHERE fsp.flash_sale_id = ?`,
+			models.PaymentPaid, flashSaleID,
+		).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			UPDATE flash_sales fs SET
+				total_sold = totals.sold,
+				total_orders = totals.orders,
+				total_revenue = totals.revenue,
+				updated_at = NOW()
+			FROM (
+				SELECT COALESCE(SUM(oi.quantity), 0) AS sold,
+					COUNT(DISTINCT oi.order_id) AS orders,
+					COALESCE(SUM(oi.total_price), 0) AS revenue
+				FROM order_items oi
+				JOIN orders o ON o.id = oi.order_id AND o.deleted_at IS NULL
+				WHERE oi.flash_sale_id = ? AND o.payment_status = ?
+			) totals
+			WHERE fs.id = ?`,
+			flashSaleID, models.PaymentPaid, flashSaleID,
+		).Error
+	})
 }
@@ -305,7 +305,7 @@ func TestFlashSaleRepository_RemoveProductFromFlashSale(t *testing.T) {
 	require.NoError(t, err)
 
 	// Remove product
-	err = repo.RemoveProductFromFlashSale(flashSale.ID, product.ID)
+	err = repo.RemoveProductFromFlashSale(flashSale.ID, product.ID, nil)
 	require.NoError(t, err)
 
 	// Verify removal
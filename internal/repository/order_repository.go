@@ -35,7 +35,7 @@ func (r *orderRepository) Create(order *models.Order) error {
 
 func (r *orderRepository) GetByID(id uint) (*models.Order, error) {
 	var order models.Order
-	err := r.db.Preload("Items").Preload("Items.Product").First(&order, id).Error
+	err := r.db.Preload("Items").Preload("Items.Product").Preload("Taxes").First(&order, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +44,7 @@ func (r *orderRepository) GetByID(id uint) (*models.Order, error) {
 
 func (r *orderRepository) GetByOrderNumber(orderNumber string) (*models.Order, error) {
 	var order models.Order
-	err := r.db.Preload("Items").Preload("Items.Product").Where("order_number = ?", orderNumber).First(&order).Error
+	err := r.db.Preload("Items").Preload("Items.Product").Preload("Taxes").Where("order_number = ?", orderNumber).First(&order).Error
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +63,7 @@ func (r *orderRepository) GetByUserID(userID uint, limit, offset int) ([]models.
 	}
 
 	// Get orders with pagination
-	err := query.Preload("Items").Preload("Items.Product").
+	err := query.Preload("Items").Preload("Items.Product").Preload("Taxes").
 		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/karima-store/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PriceScheduleRepository interface {
+	Create(change *models.ScheduledPriceChange) error
+	GetByID(id uint) (*models.ScheduledPriceChange, error)
+	List(filter models.PriceChangeListFilter, limit, offset int) ([]models.ScheduledPriceChange, int64, error)
+	// Cancel cancels a pending change. It reports false when the change was no longer pending.
+	Cancel(id uint) (bool, error)
+	// GetDue returns pending changes whose effective time has passed, oldest first
+	GetDue(now time.Time, limit int) ([]models.ScheduledPriceChange, error)
+	// GetNextEffectiveTime returns the effective time of the next pending change after now
+	GetNextEffectiveTime(now time.Time) (*time.Time, error)
+	// Apply writes a pending change's prices and its history entry in one transaction. It
+	// returns nil when the change was no longer pending, e.g. because another instance applied it.
+	Apply(change *models.ScheduledPriceChange, at time.Time) (*models.PriceHistory, error)
+	// MarkFailed records why a pending change could not be applied
+	MarkFailed(id uint, reason string) error
+	// GetHistory returns a product's price history since the given time, oldest first. With a
+	// variant it holds the product-level entries and that variant's own.
+	GetHistory(productID uint, variantID *uint, since time.Time) ([]models.PriceHistory, error)
+}
+
+type priceScheduleRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceScheduleRepository(db *gorm.DB) PriceScheduleRepository {
+	return &priceScheduleRepository{db: db}
+}
+
+func (r *priceScheduleRepository) Create(change *models.ScheduledPriceChange) error {
+	return r.db.Create(change).Error
+}
+
+func (r *priceScheduleRepository) GetByID(id uint) (*models.ScheduledPriceChange, error) {
+	var change models.ScheduledPriceChange
+	if err := r.db.First(&change, id).Error; err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// List returns changes matching the filter, next effective first
+func (r *priceScheduleRepository) List(filter models.PriceChangeListFilter, limit, offset int) ([]models.ScheduledPriceChange, int64, error) {
+	var changes []models.ScheduledPriceChange
+	var total int64
+
+	query := r.db.Model(&models.ScheduledPriceChange{})
+	if filter.ProductID != nil {
+		query = query.Where("product_id = ?", *filter.ProductID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("effective_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&changes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return changes, total, nil
+}
+
+func (r *priceScheduleRepository) Cancel(id uint) (bool, error) {
+	result := r.db.Model(&models.ScheduledPriceChange{}).
+		Where("id = ? AND status = ?", id, models.PriceChangePending).
+		Update("status", models.PriceChangeCancelled)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *priceScheduleRepository) GetDue(now time.Time, limit int) ([]models.ScheduledPriceChange, error) {
+	var changes []models.ScheduledPriceChange
+	err := r.db.Where("status = ? AND effective_at <= ?", models.PriceChangePending, now).
+		Order("effective_at ASC, id ASC").
+		Limit(limit).
+		Find(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *priceScheduleRepository) GetNextEffectiveTime(now time.Time) (*time.Time, error) {
+	var next *time.Time
+	err := r.db.Model(&models.ScheduledPriceChange{}).
+		Select("MIN(effective_at)").
+		Where("status = ? AND effective_at > ?", models.PriceChangePending, now).
+		Scan(&next).Error
+	return next, err
+}
+
+func (r *priceScheduleRepository) Apply(change *models.ScheduledPriceChange, at time.Time) (*models.PriceHistory, error) {
+	var entry *models.PriceHistory
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ScheduledPriceChange{}).
+			Where("id = ? AND status = ?", change.ID, models.PriceChangePending).
+			Updates(map[string]interface{}{"status": models.PriceChangeApplied, "applied_at": at})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		history := &models.PriceHistory{
+			ChangedAt:         at,
+			ProductID:         change.ProductID,
+			VariantID:         change.VariantID,
+			ChangedBy:         change.CreatedBy,
+			Reason:            change.Reason,
+			ScheduledChangeID: &change.ID,
+		}
+
+		if change.VariantID != nil {
+			var variant models.ProductVariant
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("product_id = ?", change.ProductID).
+				First(&variant, *change.VariantID).Error; err != nil {
+				return err
+			}
+			history.OldPrice, history.NewPrice = variant.Price, variant.Price
+			if change.Price != nil {
+				history.NewPrice = *change.Price
+			}
+			if err := tx.Model(&variant).Update("price", history.NewPrice).Error; err != nil {
+				return err
+			}
+		} else {
+			var product models.Product
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, change.ProductID).Error; err != nil {
+				return err
+			}
+			history.OldPrice, history.NewPrice = product.Price, product.Price
+			history.OldComparePrice, history.NewComparePrice = product.ComparePrice, product.ComparePrice
+			history.OldDiscount, history.NewDiscount = product.Discount, product.Discount
+			if change.Price != nil {
+				history.NewPrice = *change.Price
+			}
+			if change.ComparePrice != nil {
+				history.NewComparePrice = *change.ComparePrice
+			}
+			if change.Discount != nil {
+				history.NewDiscount = *change.Discount
+			}
+			if err := tx.Model(&product).Updates(map[string]interface{}{
+				"price":         history.NewPrice,
+				"compare_price": history.NewComparePrice,
+				"discount":      history.NewDiscount,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(history).Error; err != nil {
+			return err
+		}
+		entry = history
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		change.Status = models.PriceChangeApplied
+		change.AppliedAt = &at
+	}
+	return entry, nil
+}
+
+func (r *priceScheduleRepository) MarkFailed(id uint, reason string) error {
+	if len(reason) > 255 {
+		reason = reason[:255]
+	}
+	return r.db.Model(&models.ScheduledPriceChange{}).
+		Where("id = ? AND status = ?", id, models.PriceChangePending).
+		Updates(map[string]interface{}{"status": models.PriceChangeFailed, "error": reason}).Error
+}
+
+func (r *priceScheduleRepository) GetHistory(productID uint, variantID *uint, since time.Time) ([]models.PriceHistory, error) {
+	var entries []models.PriceHistory
+
+	query := r.db.Where("product_id = ? AND changed_at >= ?", productID, since)
+	if variantID != nil {
+		query = query.Where("variant_id IS NULL OR variant_id = ?", *variantID)
+	} else {
+		query = query.Where("variant_id IS NULL")
+	}
+
+	err := query.Order("changed_at ASC, id ASC").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -3,9 +3,11 @@ package repository
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/karima-store/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository interface {
@@ -14,6 +16,9 @@ type ProductRepository interface {
 	GetBySlug(slug string) (*models.Product, error)
 	GetAll(limit, offset int, filters map[string]interface{}) ([]models.Product, int64, error)
 	Update(product *models.Product) error
+	// UpdateWithPriceHistory saves the product and, when its price, compare price or discount
+	// changed, records the change in the price history in the same transaction
+	UpdateWithPriceHistory(product *models.Product, changedBy *uint) error
 	Delete(id uint) error
 	UpdateStock(id uint, quantity int) error
 	IncrementViewCount(id uint) error
@@ -99,6 +104,34 @@ func (r *productRepository) Update(product *models.Product) error {
 	return r.db.Save(product).Error
 }
 
+func (r *productRepository) UpdateWithPriceHistory(product *models.Product, changedBy *uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, product.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(product).Error; err != nil {
+			return err
+		}
+		if current.Price == product.Price && current.ComparePrice == product.ComparePrice && current.Discount == product.Discount {
+			return nil
+		}
+
+		return tx.Create(&models.PriceHistory{
+			ChangedAt:       time.Now(),
+			ProductID:       product.ID,
+			OldPrice:        current.Price,
+			NewPrice:        product.Price,
+			OldComparePrice: current.ComparePrice,
+			NewComparePrice: product.ComparePrice,
+			OldDiscount:     current.Discount,
+			NewDiscount:     product.Discount,
+			ChangedBy:       changedBy,
+			Reason:          models.PriceChangeReasonManual,
+		}).Error
+	})
+}
+
 func (r *productRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Product{}, id).Error
 }
@@ -118,9 +151,21 @@ func (r *productRepository) UpdateStock(id uint, quantity int) error {
 		return nil
 	}
 
-	return r.db.Model(&models.Product{}).
+	var product models.Product
+	result := r.db.Model(&product).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "stock"}}}).
 		Where("id = ?", id).
-		Update("stock", gorm.Expr("stock + ?", quantity)).Error
+		Update("stock", gorm.Expr("stock + ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// Record the 0 -> positive transition in the same transaction so wishlisters
+	// are only told about restocks that were actually committed
+	if result.RowsAffected > 0 && quantity > 0 && product.Stock > 0 && product.Stock-quantity <= 0 {
+		return r.db.Create(&models.RestockEvent{ProductID: id, NewStock: product.Stock}).Error
+	}
+	return nil
 }
 
 func (r *productRepository) IncrementViewCount(id uint) error {
@@ -218,6 +218,42 @@ func TestProductRepository_Update(t *testing.T) {
 	assert.Equal(t, 150.00, fetchedProduct.Price)
 }
 
+func TestProductRepository_UpdateWithPriceHistory(t *testing.T) {
+	db, cleanup := setupProductTest(t)
+	defer cleanup()
+	db.Exec("DELETE FROM price_history")
+
+	repo := NewProductRepository(db)
+
+	product := &models.Product{
+		Name:     "Test Product",
+		Price:    100.00,
+		Category: models.CategoryTops,
+		Stock:    10,
+		Status:   models.StatusAvailable,
+		Slug:     "test-product", SKU: "test-product-sku",
+	}
+	require.NoError(t, repo.Create(product))
+
+	// Edits that leave the prices alone are not history
+	product.Name = "Renamed Product"
+	require.NoError(t, repo.UpdateWithPriceHistory(product, nil))
+
+	product.Price = 90.00
+	product.Discount = 10
+	require.NoError(t, repo.UpdateWithPriceHistory(product, nil))
+
+	var entries []models.PriceHistory
+	require.NoError(t, db.Where("product_id = ?", product.ID).Find(&entries).Error)
+	require.Len(t, entries, 1)
+	assert.Nil(t, entries[0].VariantID)
+	assert.Equal(t, 100.00, entries[0].OldPrice)
+	assert.Equal(t, 90.00, entries[0].NewPrice)
+	assert.Equal(t, 0.0, entries[0].OldDiscount)
+	assert.Equal(t, 10.0, entries[0].NewDiscount)
+	assert.Equal(t, models.PriceChangeReasonManual, entries[0].Reason)
+}
+
 func TestProductRepository_Delete(t *testing.T) {
 	db, cleanup := setupProductTest(t)
 	defer cleanup()
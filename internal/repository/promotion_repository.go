@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/karima-store/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PromotionRepository interface {
+	Create(promotion *models.Promotion) error
+	Update(promotion *models.Promotion) error
+	Delete(id uint) error
+	GetByID(id uint) (*models.Promotion, error)
+	List(limit, offset int) ([]models.Promotion, int64, error)
+	// GetRunning returns the promotions active at the given time, highest priority first
+	GetRunning(at time.Time) ([]models.Promotion, error)
+}
+
+type promotionRepository struct {
+	db *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+// Create saves a promotion together with its tiers
+func (r *promotionRepository) Create(promotion *models.Promotion) error {
+	return r.db.Create(promotion).Error
+}
+
+// Update saves a promotion and replaces its tiers with the ones it holds
+func (r *promotionRepository) Update(promotion *models.Promotion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		tiers := promotion.Tiers
+		if err := tx.Omit("Tiers").Save(promotion).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("promotion_id = ?", promotion.ID).Delete(&models.PromotionTier{}).Error; err != nil {
+			return err
+		}
+		for i := range tiers {
+			tiers[i].ID = 0
+			tiers[i].PromotionID = promotion.ID
+		}
+		if len(tiers) > 0 {
+			if err := tx.Create(&tiers).Error; err != nil {
+				return err
+			}
+		}
+		promotion.Tiers = tiers
+		return nil
+	})
+}
+
+func (r *promotionRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Promotion{}, id).Error
+}
+
+func (r *promotionRepository) GetByID(id uint) (*models.Promotion, error) {
+	var promotion models.Promotion
+	err := r.db.Preload("Tiers", tierOrder).First(&promotion, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// List returns promotions, highest priority first
+func (r *promotionRepository) List(limit, offset int) ([]models.Promotion, int64, error) {
+	var promotions []models.Promotion
+	var total int64
+
+	if err := r.db.Model(&models.Promotion{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := r.db.Preload("Tiers", tierOrder).
+		Order("priority DESC, id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&promotions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return promotions, total, nil
+}
+
+func (r *promotionRepository) GetRunning(at time.Time) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := r.db.Preload("Tiers", tierOrder).
+		Where("status = ?", models.PromotionStatusActive).
+		Where("starts_at IS NULL OR starts_at <= ?", at).
+		Where("ends_at IS NULL OR ends_at > ?", at).
+		Order("priority DESC, id ASC").
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func tierOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("min_subtotal ASC")
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"github.com/karima-store/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ResellerApplicationRepository interface {
+	Create(application *models.ResellerApplication) error
+	GetByID(id uint) (*models.ResellerApplication, error)
+	// GetLatestByUser returns the user's most recent application
+	GetLatestByUser(userID uint) (*models.ResellerApplication, error)
+	List(status models.ResellerApplicationStatus, limit, offset int) ([]models.ResellerApplication, int64, error)
+	// Review saves the reviewed application; an approved one moves its user to the reseller
+	// customer group in the same transaction
+	Review(application *models.ResellerApplication) error
+	SetCustomerGroup(userID uint, group models.CustomerGroup) error
+}
+
+type resellerApplicationRepository struct {
+	db *gorm.DB
+}
+
+func NewResellerApplicationRepository(db *gorm.DB) ResellerApplicationRepository {
+	return &resellerApplicationRepository{db: db}
+}
+
+func (r *resellerApplicationRepository) Create(application *models.ResellerApplication) error {
+	return r.db.Create(application).Error
+}
+
+func (r *resellerApplicationRepository) GetByID(id uint) (*models.ResellerApplication, error) {
+	var application models.ResellerApplication
+	if err := r.db.Preload("User").First(&application, id).Error; err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+func (r *resellerApplicationRepository) GetLatestByUser(userID uint) (*models.ResellerApplication, error) {
+	var application models.ResellerApplication
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		First(&application).Error
+	if err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+// List returns applications oldest first, so the review queue is worked in order
+func (r *resellerApplicationRepository) List(status models.ResellerApplicationStatus, limit, offset int) ([]models.ResellerApplication, int64, error) {
+	var applications []models.ResellerApplication
+	var total int64
+
+	query := r.db.Model(&models.ResellerApplication{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("User").
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&applications).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return applications, total, nil
+}
+
+func (r *resellerApplicationRepository) Review(application *models.ResellerApplication) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("User").Save(application).Error; err != nil {
+			return err
+		}
+		if application.Status != models.ResellerApplicationApproved {
+			return nil
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ?", application.UserID).
+			Update("customer_group", models.CustomerGroupReseller).Error
+	})
+}
+
+func (r *resellerApplicationRepository) SetCustomerGroup(userID uint, group models.CustomerGroup) error {
+	result := r.db.Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("customer_group", group)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"gorm.io/gorm"
+)
+
+type RestockEventRepository interface {
+	GetPending(limit int) ([]models.RestockEvent, error)
+	MarkProcessed(ids []uint) error
+}
+
+type restockEventRepository struct {
+	db *gorm.DB
+}
+
+func NewRestockEventRepository(db *gorm.DB) RestockEventRepository {
+	return &restockEventRepository{db: db}
+}
+
+func (r *restockEventRepository) GetPending(limit int) ([]models.RestockEvent, error) {
+	var events []models.RestockEvent
+	err := r.db.Where("processed_at IS NULL").
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *restockEventRepository) MarkProcessed(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.RestockEvent{}).Where("id IN ?", ids).Update("processed_at", time.Now()).Error
+}
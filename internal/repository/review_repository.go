@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewRepository interface {
+	Create(review *models.Review) error
+	GetByID(id uint) (*models.Review, error)
+	FindByUserAndProduct(userID, productID uint) (*models.Review, error)
+	GetApprovedByProductID(productID uint, limit, offset int) ([]models.Review, int64, error)
+	GetByUserID(userID uint, limit, offset int) ([]models.Review, int64, error)
+	HasDeliveredPurchase(userID, productID uint) (bool, error)
+	GetPending(limit, offset int) ([]models.Review, int64, error)
+	Moderate(ids []uint, approve bool, adminNotes string, at time.Time) ([]models.Review, error)
+	Delete(id uint) (*models.Review, error)
+	Vote(reviewID, userID uint, helpful bool) (*models.Review, error)
+	CountImages(reviewID uint) (int64, error)
+	AddImage(image *models.ReviewImage) error
+}
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepository{db: db}
+}
+
+func (r *reviewRepository) Create(review *models.Review) error {
+	return r.db.Omit("Product", "User").Create(review).Error
+}
+
+func (r *reviewRepository) GetByID(id uint) (*models.Review, error) {
+	var review models.Review
+	err := r.db.Preload("Images").First(&review, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// FindByUserAndProduct includes soft-deleted reviews; (user_id, product_id) is unique,
+// so a deleted review still blocks a second one
+func (r *reviewRepository) FindByUserAndProduct(userID, productID uint) (*models.Review, error) {
+	var review models.Review
+	err := r.db.Unscoped().Where("user_id = ? AND product_id = ?", userID, productID).First(&review).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetApprovedByProductID returns the reviews shown on a product page. Only the reviewer's
+// public profile fields are loaded.
+func (r *reviewRepository) GetApprovedByProductID(productID uint, limit, offset int) ([]models.Review, int64, error) {
+	var reviews []models.Review
+	var total int64
+
+	query := r.db.Model(&models.Review{}).Where("product_id = ? AND is_approved = ?", productID, true)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Images").
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "full_name", "avatar")
+		}).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&reviews).Error
+
+	return reviews, total, err
+}
+
+func (r *reviewRepository) GetByUserID(userID uint, limit, offset int) ([]models.Review, int64, error) {
+	var reviews []models.Review
+	var total int64
+
+	query := r.db.Model(&models.Review{}).Where("user_id = ?", userID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Images").
+		Preload("Product").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&reviews).Error
+
+	return reviews, total, err
+}
+
+// HasDeliveredPurchase reports whether the user has an order containing the product
+// that reached the delivered status
+func (r *reviewRepository) HasDeliveredPurchase(userID, productID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Where("orders.user_id = ? AND orders.status = ? AND order_items.product_id = ?", userID, models.StatusDelivered, productID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetPending returns the moderation queue, oldest first
+func (r *reviewRepository) GetPending(limit, offset int) ([]models.Review, int64, error) {
+	var reviews []models.Review
+	var total int64
+
+	query := r.db.Model(&models.Review{}).Where("moderated_at IS NULL")
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Images").
+		Preload("Product").
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "full_name", "email", "avatar")
+		}).
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&reviews).Error
+
+	return reviews, total, err
+}
+
+// Moderate approves or rejects the given reviews and recomputes the rating of every affected
+// product in the same transaction. It returns the reviews that were found and updated.
+func (r *reviewRepository) Moderate(ids []uint, approve bool, adminNotes string, at time.Time) ([]models.Review, error) {
+	var reviews []models.Review
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id IN ?", ids).Order("id ASC").Find(&reviews).Error; err != nil {
+			return err
+		}
+		if len(reviews) == 0 {
+			return nil
+		}
+
+		productIDs := reviewProductIDs(reviews)
+		if err := lockProducts(tx, productIDs); err != nil {
+			return err
+		}
+
+		found := make([]uint, 0, len(reviews))
+		for _, review := range reviews {
+			found = append(found, review.ID)
+		}
+		if err := tx.Model(&models.Review{}).Where("id IN ?", found).Updates(map[string]interface{}{
+			"is_approved":  approve,
+			"admin_notes":  adminNotes,
+			"moderated_at": at,
+		}).Error; err != nil {
+			return err
+		}
+
+		for i := range reviews {
+			reviews[i].IsApproved = approve
+			reviews[i].AdminNotes = adminNotes
+			reviews[i].ModeratedAt = &at
+		}
+
+		return recalculateProductRatings(tx, productIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// Delete soft-deletes a review and recomputes its product's rating in the same transaction
+func (r *reviewRepository) Delete(id uint) (*models.Review, error) {
+	var review models.Review
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&review, id).Error; err != nil {
+			return err
+		}
+		if err := lockProducts(tx, []uint{review.ProductID}); err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Review{}, id).Error; err != nil {
+			return err
+		}
+		return recalculateProductRatings(tx, []uint{review.ProductID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// Vote records a user's vote and bumps the matching counter in one transaction.
+// It returns gorm.ErrDuplicatedKey if the user already voted on the review.
+func (r *reviewRepository) Vote(reviewID, userID uint, helpful bool) (*models.Review, error) {
+	var review models.Review
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		vote := &models.ReviewVote{ReviewID: reviewID, UserID: userID, IsHelpful: helpful}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(vote)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrDuplicatedKey
+		}
+
+		column := "not_helpful_count"
+		if helpful {
+			column = "helpful_count"
+		}
+		if err := tx.Model(&models.Review{}).Where("id = ?", reviewID).
+			UpdateColumn(column, gorm.Expr(column+" + 1")).Error; err != nil {
+			return err
+		}
+
+		return tx.First(&review, reviewID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *reviewRepository) CountImages(reviewID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ReviewImage{}).Where("review_id = ?", reviewID).Count(&count).Error
+	return count, err
+}
+
+func (r *reviewRepository) AddImage(image *models.ReviewImage) error {
+	return r.db.Create(image).Error
+}
+
+func reviewProductIDs(reviews []models.Review) []uint {
+	seen := make(map[uint]bool)
+	var ids []uint
+	for _, review := range reviews {
+		if !seen[review.ProductID] {
+			seen[review.ProductID] = true
+			ids = append(ids, review.ProductID)
+		}
+	}
+	return ids
+}
+
+// lockProducts takes row locks on the products in id order so concurrent moderation of reviews
+// for the same product recomputes the rating one transaction at a time
+func lockProducts(tx *gorm.DB, productIDs []uint) error {
+	var locked []uint
+	return tx.Model(&models.Product{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", productIDs).
+		Order("id ASC").
+		Pluck("id", &locked).Error
+}
+
+// recalculateProductRatings sets products.rating and products.review_count from their approved reviews
+func recalculateProductRatings(tx *gorm.DB, productIDs []uint) error {
+	return tx.Exec(`
+		UPDATE products p SET
+			rating = COALESCE((SELECT ROUND(AVG(r.rating)::numeric, 2) FROM reviews r WHERE r.product_id = p.id AND r.is_approved = TRUE AND r.deleted_at IS NULL), 0),
+			review_count = (SELECT COUNT(*) FROM reviews r WHERE r.product_id = p.id AND r.is_approved = TRUE AND r.deleted_at IS NULL),
+			updated_at = NOW()
+		WHERE p.id IN ?`, productIDs).Error
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/karima-store/internal/models"
 	"gorm.io/gorm"
 )
@@ -23,8 +25,14 @@ func NewShippingZoneRepository(db *gorm.DB) ShippingZoneRepository {
 	return &shippingZoneRepository{db: db}
 }
 
+// Create saves the zone and its regions
 func (r *shippingZoneRepository) Create(zone *models.ShippingZone) error {
-	return r.db.Create(zone).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(zone).Error; err != nil {
+			return err
+		}
+		return saveShippingZoneRegions(tx, zone)
+	})
 }
 
 func (r *shippingZoneRepository) GetByID(id uint) (*models.ShippingZone, error) {
@@ -33,13 +41,16 @@ func (r *shippingZoneRepository) GetByID(id uint) (*models.ShippingZone, error)
 	if err != nil {
 		return nil, err
 	}
-	return &zone, nil
+	return &zone, r.loadRegions(&zone)
 }
 
 func (r *shippingZoneRepository) GetAll() ([]models.ShippingZone, error) {
 	var zones []models.ShippingZone
 	err := r.db.Order("created_at DESC").Find(&zones).Error
-	return zones, err
+	if err != nil {
+		return nil, err
+	}
+	return zones, r.loadRegions(shippingZonePointers(zones)...)
 }
 
 func (r *shippingZoneRepository) GetActive() ([]models.ShippingZone, error) {
@@ -47,46 +58,112 @@ func (r *shippingZoneRepository) GetActive() ([]models.ShippingZone, error) {
 	err := r.db.Where("status = ?", models.ShippingZoneActive).
 		Order("created_at DESC").
 		Find(&zones).Error
-	return zones, err
+	if err != nil {
+		return nil, err
+	}
+	return zones, r.loadRegions(shippingZonePointers(zones)...)
 }
 
+// Update saves the zone and replaces its regions
 func (r *shippingZoneRepository) Update(zone *models.ShippingZone) error {
-	return r.db.Save(zone).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(zone).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("zone_id = ?", zone.ID).Delete(&models.ShippingZoneRegion{}).Error; err != nil {
+			return err
+		}
+		return saveShippingZoneRegions(tx, zone)
+	})
 }
 
 func (r *shippingZoneRepository) Delete(id uint) error {
 	return r.db.Delete(&models.ShippingZone{}, id).Error
 }
 
-// GetByRegion finds the shipping zone that applies to a specific region
+// GetByRegion finds the newest active, currently valid zone that includes the region and does
+// not exclude it. The lookup goes through the region index instead of loading every zone.
 func (r *shippingZoneRepository) GetByRegion(regionCode string) (*models.ShippingZone, error) {
-	var zones []models.ShippingZone
-	err := r.db.Where("status = ?", models.ShippingZoneActive).
-		Order("created_at DESC").
-		Find(&zones).Error
+	regionCode = models.NormalizeRegionCode(regionCode)
+	if regionCode == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	now := time.Now()
+	var zone models.ShippingZone
+	err := r.db.Joins("JOIN shipping_zone_regions ON shipping_zone_regions.zone_id = shipping_zones.id").
+		Where("shipping_zone_regions.region_code = ? AND NOT shipping_zone_regions.excluded", regionCode).
+		Where("shipping_zones.status = ?", models.ShippingZoneActive).
+		Where("(shipping_zones.valid_from IS NULL OR shipping_zones.valid_from <= ?)", now).
+		Where("(shipping_zones.valid_until IS NULL OR shipping_zones.valid_until >= ?)", now).
+		Order("shipping_zones.created_at DESC, shipping_zones.id DESC").
+		First(&zone).Error
 	if err != nil {
 		return nil, err
 	}
+	return &zone, r.loadRegions(&zone)
+}
+
+// loadRegions fills the included and excluded regions of the given zones
+func (r *shippingZoneRepository) loadRegions(zones ...*models.ShippingZone) error {
+	if len(zones) == 0 {
+		return nil
+	}
 
-	// Find the first zone that includes this region and doesn't exclude it
+	byID := make(map[uint]*models.ShippingZone, len(zones))
+	ids := make([]uint, 0, len(zones))
 	for _, zone := range zones {
-		// Check if region is in zone's regions
-		for _, region := range zone.Regions {
-			if region == regionCode {
-				// Check if region is not excluded
-				excluded := false
-				for _, excludeRegion := range zone.ExcludeRegions {
-					if excludeRegion == regionCode {
-						excluded = true
-						break
-					}
-				}
-				if !excluded {
-					return &zone, nil
-				}
-			}
+		zone.Regions = []string{}
+		zone.ExcludeRegions = []string{}
+		byID[zone.ID] = zone
+		ids = append(ids, zone.ID)
+	}
+
+	var regions []models.ShippingZoneRegion
+	if err := r.db.Where("zone_id IN ?", ids).Order("region_code").Find(&regions).Error; err != nil {
+		return err
+	}
+	for _, region := range regions {
+		zone := byID[region.ZoneID]
+		if region.Excluded {
+			zone.ExcludeRegions = append(zone.ExcludeRegions, region.RegionCode)
+		} else {
+			zone.Regions = append(zone.Regions, region.RegionCode)
+		}
+	}
+	return nil
+}
+
+// saveShippingZoneRegions inserts the region rows of a saved zone. A region listed both as
+// included and excluded is stored as excluded.
+func saveShippingZoneRegions(tx *gorm.DB, zone *models.ShippingZone) error {
+	var regions []models.ShippingZoneRegion
+	seen := make(map[string]bool, len(zone.Regions)+len(zone.ExcludeRegions))
+	for _, code := range zone.ExcludeRegions {
+		code = models.NormalizeRegionCode(code)
+		if code != "" && !seen[code] {
+			seen[code] = true
+			regions = append(regions, models.ShippingZoneRegion{ZoneID: zone.ID, RegionCode: code, Excluded: true})
+		}
+	}
+	for _, code := range zone.Regions {
+		code = models.NormalizeRegionCode(code)
+		if code != "" && !seen[code] {
+			seen[code] = true
+			regions = append(regions, models.ShippingZoneRegion{ZoneID: zone.ID, RegionCode: code})
 		}
 	}
+	if len(regions) == 0 {
+		return nil
+	}
+	// Select("*") ensures that zero values (like bool false) are also saved
+	return tx.Select("*").Create(&regions).Error
+}
 
-	return nil, gorm.ErrRecordNotFound
+func shippingZonePointers(zones []models.ShippingZone) []*models.ShippingZone {
+	pointers := make([]*models.ShippingZone, len(zones))
+	for i := range zones {
+		pointers[i] = &zones[i]
+	}
+	return pointers
 }
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/karima-store/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TaxRepository interface {
+	// GetActive returns the active taxes valid at the given time with their regions
+	GetActive(at time.Time) ([]models.Tax, error)
+}
+
+type taxRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRepository(db *gorm.DB) TaxRepository {
+	return &taxRepository{db: db}
+}
+
+func (r *taxRepository) GetActive(at time.Time) ([]models.Tax, error) {
+	var taxes []models.Tax
+	err := r.db.Where("status = ?", models.TaxStatusActive).
+		Where("(valid_from IS NULL OR valid_from <= ?)", at).
+		Where("(valid_until IS NULL OR valid_until > ?)", at).
+		Order("id ASC").
+		Find(&taxes).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(taxes) == 0 {
+		return taxes, nil
+	}
+
+	byID := make(map[uint]*models.Tax, len(taxes))
+	ids := make([]uint, 0, len(taxes))
+	for i := range taxes {
+		byID[taxes[i].ID] = &taxes[i]
+		ids = append(ids, taxes[i].ID)
+	}
+
+	var regions []models.TaxRegion
+	if err := r.db.Where("tax_id IN ?", ids).Order("region_code").Find(&regions).Error; err != nil {
+		return nil, err
+	}
+	for _, region := range regions {
+		tax := byID[region.TaxID]
+		if region.Excluded {
+			tax.ExcludeRegions = append(tax.ExcludeRegions, region.RegionCode)
+		} else {
+			tax.ApplicableRegions = append(tax.ApplicableRegions, region.RegionCode)
+		}
+	}
+	return taxes, nil
+}
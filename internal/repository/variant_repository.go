@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"github.com/karima-store/internal/models"
 )
 
@@ -11,6 +14,9 @@ type VariantRepository interface {
 	GetBySKU(sku string) (*models.ProductVariant, error)
 	GetByProductID(productID uint) ([]models.ProductVariant, error)
 	Update(variant *models.ProductVariant) error
+	// UpdateWithPriceHistory saves the variant and, when its price changed, records the change in
+	// the price history in the same transaction
+	UpdateWithPriceHistory(variant *models.ProductVariant, changedBy *uint) error
 	Delete(id uint) error
 	UpdateStock(id uint, quantity int) error
 }
@@ -55,12 +61,48 @@ func (r *variantRepository) Update(variant *models.ProductVariant) error {
 	return r.db.Save(variant).Error
 }
 
+func (r *variantRepository) UpdateWithPriceHistory(variant *models.ProductVariant, changedBy *uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.ProductVariant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, variant.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(variant).Error; err != nil {
+			return err
+		}
+		if current.Price == variant.Price {
+			return nil
+		}
+
+		return tx.Create(&models.PriceHistory{
+			ChangedAt: time.Now(),
+			ProductID: variant.ProductID,
+			VariantID: &variant.ID,
+			OldPrice:  current.Price,
+			NewPrice:  variant.Price,
+			ChangedBy: changedBy,
+			Reason:    models.PriceChangeReasonManual,
+		}).Error
+	})
+}
+
 func (r *variantRepository) Delete(id uint) error {
 	return r.db.Delete(&models.ProductVariant{}, id).Error
 }
 
 func (r *variantRepository) UpdateStock(id uint, quantity int) error {
-	return r.db.Model(&models.ProductVariant{}).
+	var variant models.ProductVariant
+	result := r.db.Model(&variant).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "product_id"}, {Name: "stock"}}}).
 		Where("id = ?", id).
-		Update("stock", gorm.Expr("stock + ?", quantity)).Error
+		Update("stock", gorm.Expr("stock + ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// A variant coming back into stock makes its product available to wishlisters again
+	if result.RowsAffected > 0 && quantity > 0 && variant.Stock > 0 && variant.Stock-quantity <= 0 {
+		return r.db.Create(&models.RestockEvent{ProductID: variant.ProductID, VariantID: &id, NewStock: variant.Stock}).Error
+	}
+	return nil
 }
@@ -216,6 +216,29 @@ func TestVariantRepository_Update(t *testing.T) {
 	assert.Equal(t, "Blue", fetched.Color)
 }
 
+func TestVariantRepository_UpdateWithPriceHistory(t *testing.T) {
+	db, product, cleanup := setupVariantTest(t)
+	defer cleanup()
+	db.Exec("DELETE FROM price_history")
+
+	repo := NewVariantRepository(db)
+
+	variant := createTestVariant(product.ID, "History", "VAR-HISTORY")
+	require.NoError(t, repo.Create(variant))
+
+	variant.Stock = 5
+	require.NoError(t, repo.UpdateWithPriceHistory(variant, nil))
+	variant.Price = 99.00
+	require.NoError(t, repo.UpdateWithPriceHistory(variant, nil))
+
+	var entries []models.PriceHistory
+	require.NoError(t, db.Where("product_id = ?", product.ID).Find(&entries).Error)
+	require.Len(t, entries, 1)
+	assert.Equal(t, variant.ID, *entries[0].VariantID)
+	assert.Equal(t, 110.00, entries[0].OldPrice)
+	assert.Equal(t, 99.00, entries[0].NewPrice)
+}
+
 func TestVariantRepository_Delete(t *testing.T) {
 	db, product, cleanup := setupVariantTest(t)
 	defer cleanup()
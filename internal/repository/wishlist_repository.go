@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"gorm.io/gorm"
+)
+
+type WishlistRepository interface {
+	GetByUserID(userID uint, limit, offset int) ([]models.Wishlist, int64, error)
+	Find(userID, productID uint) (*models.Wishlist, error)
+	Create(wishlist *models.Wishlist) error
+	Delete(userID, productID uint) error
+	GetRestockRecipients(productIDs []uint, notifiedBefore time.Time) ([]models.Wishlist, error)
+	MarkRestockNotified(ids []uint, at time.Time) error
+	GetWishlistedProductIDs() ([]uint, error)
+	GetByProductID(productID uint) ([]models.Wishlist, error)
+	UpdatePriceBaseline(ids []uint, price float64) error
+	CreatePriceAlert(alert *models.PriceAlert) error
+	CountPriceAlertsSince(userID uint, since time.Time) (int64, error)
+}
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) WishlistRepository {
+	return &wishlistRepository{db: db}
+}
+
+func (r *wishlistRepository) GetByUserID(userID uint, limit, offset int) ([]models.Wishlist, int64, error) {
+	var wishlists []models.Wishlist
+	var total int64
+
+	query := r.db.Model(&models.Wishlist{}).Where("user_id = ?", userID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Product").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&wishlists).Error
+
+	return wishlists, total, err
+}
+
+func (r *wishlistRepository) Find(userID, productID uint) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	err := r.db.Where("user_id = ? AND product_id = ?", userID, productID).First(&wishlist).Error
+	if err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (r *wishlistRepository) Create(wishlist *models.Wishlist) error {
+	return r.db.Omit("User", "Product").Create(wishlist).Error
+}
+
+// Delete removes the entry permanently; (user_id, product_id) is unique, so a soft-deleted
+// row would block the product from being wishlisted again
+func (r *wishlistRepository) Delete(userID, productID uint) error {
+	result := r.db.Unscoped().Where("user_id = ? AND product_id = ?", userID, productID).Delete(&models.Wishlist{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetRestockRecipients returns wishlist entries for the given products whose owners can receive
+// WhatsApp messages and have not been told about a restock of that product since notifiedBefore
+func (r *wishlistRepository) GetRestockRecipients(productIDs []uint, notifiedBefore time.Time) ([]models.Wishlist, error) {
+	var wishlists []models.Wishlist
+	err := r.db.
+		Joins("JOIN users ON users.id = wishlists.user_id AND users.deleted_at IS NULL").
+		Where("wishlists.product_id IN ?", productIDs).
+		Where("(wishlists.restock_notified_at IS NULL OR wishlists.restock_notified_at < ?)", notifiedBefore).
+		Where("users.is_active = ? AND users.whatsapp_opt_out = ? AND users.phone <> ''", true, false).
+		Preload("User").
+		Order("wishlists.user_id ASC, wishlists.id ASC").
+		Find(&wishlists).Error
+	return wishlists, err
+}
+
+func (r *wishlistRepository) MarkRestockNotified(ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.Wishlist{}).Where("id IN ?", ids).UpdateColumn("restock_notified_at", at).Error
+}
+
+func (r *wishlistRepository) GetWishlistedProductIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Wishlist{}).Distinct("product_id").Order("product_id").Pluck("product_id", &ids).Error
+	return ids, err
+}
+
+func (r *wishlistRepository) GetByProductID(productID uint) ([]models.Wishlist, error) {
+	var wishlists []models.Wishlist
+	err := r.db.Preload("User").Where("product_id = ?", productID).Order("id ASC").Find(&wishlists).Error
+	return wishlists, err
+}
+
+func (r *wishlistRepository) UpdatePriceBaseline(ids []uint, price float64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.Wishlist{}).Where("id IN ?", ids).UpdateColumn("price_baseline", price).Error
+}
+
+func (r *wishlistRepository) CreatePriceAlert(alert *models.PriceAlert) error {
+	return r.db.Create(alert).Error
+}
+
+func (r *wishlistRepository) CountPriceAlertsSince(userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PriceAlert{}).Where("user_id = ? AND created_at >= ?", userID, since).Count(&count).Error
+	return count, err
+}
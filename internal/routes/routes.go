@@ -17,6 +17,17 @@ func RegisterRoutes(app *fiber.App,
 	pricingHandler *handlers.PricingHandler,
 	mediaHandler *handlers.MediaHandler,
 	checkoutHandler *handlers.CheckoutHandler,
+	cartHandler *handlers.CartHandler,
+	abandonedCartHandler *handlers.AbandonedCartHandler,
+	wishlistHandler *handlers.WishlistHandler,
+	reviewHandler *handlers.ReviewHandler,
+	flashSaleHandler *handlers.FlashSaleHandler,
+	couponHandler *handlers.CouponHandler,
+	promotionHandler *handlers.PromotionHandler,
+	discountTierHandler *handlers.DiscountTierHandler,
+	resellerHandler *handlers.ResellerHandler,
+	priceScheduleHandler *handlers.PriceScheduleHandler,
+	shippingZoneHandler *handlers.ShippingZoneHandler,
 	komerceHandler *handlers.KomerceHandler,
 	orderHandler *handlers.OrderHandler,
 	whatsappHandler *handlers.WhatsAppHandler,
@@ -83,13 +94,13 @@ func RegisterRoutes(app *fiber.App,
 	// Documentation
 	app.Get("/swagger/*", swaggerHandler.ServeSwagger)
 
-	// Pricing routes (Public - Read-only calculations)
-	app.Post("/api/v1/pricing/calculate", pricingHandler.CalculatePrice)
-	app.Get("/api/v1/pricing/calculate", pricingHandler.CalculatePriceByParams)
+	// Pricing routes (Public - Read-only calculations; signed-in resellers get reseller prices)
+	app.Post("/api/v1/pricing/calculate", auth.OptionalAuth(), pricingHandler.CalculatePrice)
+	app.Get("/api/v1/pricing/calculate", auth.OptionalAuth(), pricingHandler.CalculatePriceByParams)
 	app.Post("/api/v1/pricing/shipping", pricingHandler.CalculateShippingCost)
-	app.Post("/api/v1/pricing/order-summary", pricingHandler.CalculateOrderSummary)
-	app.Post("/api/v1/pricing/coupons/validate", pricingHandler.ValidateCoupon)
-	app.Get("/api/v1/pricing/products/:product_id", pricingHandler.GetPricingInfo)
+	app.Post("/api/v1/pricing/order-summary", auth.OptionalAuth(), pricingHandler.CalculateOrderSummary)
+	app.Post("/api/v1/pricing/coupons/validate", auth.OptionalAuth(), pricingHandler.ValidateCoupon)
+	app.Get("/api/v1/pricing/products/:product_id", auth.OptionalAuth(), pricingHandler.GetPricingInfo)
 
 	// Product browsing (Public - Read-only)
 	app.Get("/api/v1/products", productHandler.GetProducts)
@@ -100,11 +111,16 @@ func RegisterRoutes(app *fiber.App,
 	app.Get("/api/v1/products/featured", productHandler.GetFeaturedProducts)
 	app.Get("/api/v1/products/bestsellers", productHandler.GetBestSellers)
 	app.Get("/api/v1/products/:id/media", productHandler.GetProductMedia)
+	app.Get("/api/v1/products/:id/reviews", reviewHandler.GetProductReviews)
+	app.Get("/api/v1/products/:id/price-history", priceScheduleHandler.GetPriceHistory)
 
 	// Variant browsing (Public - Read-only)
 	app.Get("/api/v1/variants/:id", variantHandler.GetVariantByID)
 	app.Get("/api/v1/products/:product_id/variants", variantHandler.GetVariantsByProductID)
 
+	// Flash sales (Public - Read-only)
+	app.Get("/api/v1/flash-sales", flashSaleHandler.GetCurrentFlashSales)
+
 	// Category browsing (Public - Read-only)
 	app.Get("/api/v1/categories", categoryHandler.GetAllCategories)
 
@@ -112,6 +128,12 @@ func RegisterRoutes(app *fiber.App,
 	app.Get("/api/v1/shipping/destination/search", komerceHandler.SearchDestination)
 	app.Get("/api/v1/shipping/calculate", komerceHandler.CalculateShippingCost)
 
+	// Guest cart (Public - identified by signed cookie, merged into the user cart on login)
+	app.Get("/api/v1/guest-cart", cartHandler.GetGuestCart)
+	app.Post("/api/v1/guest-cart/items", cartHandler.AddGuestItem)
+	app.Put("/api/v1/guest-cart/items/:item_id", cartHandler.UpdateGuestItem)
+	app.Delete("/api/v1/guest-cart/items/:item_id", cartHandler.RemoveGuestItem)
+
 	// Order tracking (Public with order number)
 	app.Get("/api/v1/orders/track", orderHandler.TrackOrder)
 
@@ -138,6 +160,9 @@ func RegisterRoutes(app *fiber.App,
 	app.Get("/api/v1/users", auth.ValidateToken(), auth.RequireAdmin(), userHandler.GetUsers)
 	app.Get("/api/v1/users/stats", auth.ValidateToken(), auth.RequireAdmin(), userHandler.GetUserStats)
 	app.Get("/api/v1/users/me", auth.ValidateToken(), userHandler.GetCurrentUser) // Any authenticated user
+	app.Put("/api/v1/users/me/notifications", auth.ValidateToken(), userHandler.UpdateNotificationPreferences)
+	app.Post("/api/v1/users/me/reseller-application", auth.ValidateToken(), resellerHandler.Apply)
+	app.Get("/api/v1/users/me/reseller-application", auth.ValidateToken(), resellerHandler.GetMyApplication)
 	app.Get("/api/v1/users/:id", auth.ValidateToken(), auth.RequireAdmin(), userHandler.GetUser)
 	app.Put("/api/v1/users/:id/role", auth.ValidateToken(), auth.RequireAdmin(), userHandler.UpdateUserRole)
 	app.Put("/api/v1/users/:id/deactivate", auth.ValidateToken(), auth.RequireAdmin(), userHandler.DeactivateUser)
@@ -150,6 +175,25 @@ func RegisterRoutes(app *fiber.App,
 	// Checkout (Authenticated users)
 	app.Post("/api/v1/checkout", auth.ValidateToken(), checkoutHandler.Checkout)
 
+	// Cart management (Authenticated users - own cart only)
+	app.Get("/api/v1/cart", auth.ValidateToken(), cartHandler.GetCart)
+	app.Delete("/api/v1/cart", auth.ValidateToken(), cartHandler.ClearCart)
+	app.Post("/api/v1/cart/items", auth.ValidateToken(), cartHandler.AddItem)
+	app.Put("/api/v1/cart/items/:item_id", auth.ValidateToken(), cartHandler.UpdateItem)
+	app.Delete("/api/v1/cart/items/:item_id", auth.ValidateToken(), cartHandler.RemoveItem)
+	app.Post("/api/v1/cart/checkout", auth.ValidateToken(), cartHandler.Checkout)
+
+	// Wishlist management (Authenticated users - own wishlist only)
+	app.Get("/api/v1/wishlist", auth.ValidateToken(), wishlistHandler.GetWishlist)
+	app.Post("/api/v1/wishlist/items", auth.ValidateToken(), wishlistHandler.AddItem)
+	app.Delete("/api/v1/wishlist/items/:product_id", auth.ValidateToken(), wishlistHandler.RemoveItem)
+
+	// Reviews (Authenticated users - verified purchases only)
+	app.Post("/api/v1/reviews", auth.ValidateToken(), reviewHandler.CreateReview)
+	app.Get("/api/v1/reviews/me", auth.ValidateToken(), reviewHandler.GetMyReviews)
+	app.Post("/api/v1/reviews/:id/vote", auth.ValidateToken(), reviewHandler.VoteReview)
+	app.Post("/api/v1/reviews/:id/images", auth.ValidateToken(), middleware.SecureFileUpload("image", middleware.ImageUploadConfig()), reviewHandler.UploadReviewImage)
+
 	// Order management (Authenticated users - own orders only)
 	app.Get("/api/v1/orders", auth.ValidateToken(), orderHandler.GetOrders)
 	app.Get("/api/v1/orders/:id", auth.ValidateToken(), orderHandler.GetOrder)
@@ -171,6 +215,73 @@ func RegisterRoutes(app *fiber.App,
 	app.Delete("/api/v1/variants/:id", auth.ValidateToken(), auth.RequireAdmin(), variantHandler.DeleteVariant)
 	app.Patch("/api/v1/variants/:id/stock", auth.ValidateToken(), auth.RequireAdmin(), variantHandler.UpdateVariantStock)
 
+	// Abandoned cart recovery (Admin only)
+	app.Get("/api/v1/admin/cart-recovery/stats", auth.ValidateToken(), auth.RequireAdmin(), abandonedCartHandler.GetStats)
+	app.Post("/api/v1/admin/cart-recovery/run", auth.ValidateToken(), auth.RequireAdmin(), abandonedCartHandler.RunNow)
+
+	// Flash sale management (Admin only)
+	app.Get("/api/v1/admin/flash-sales", auth.ValidateToken(), auth.RequireAdmin(), flashSaleHandler.ListFlashSales)
+	app.Post("/api/v1/admin/flash-sales", auth.ValidateToken(), auth.RequireAdmin(), flashSaleHandler.CreateFlashSale)
+	app.Get("/api/v1/admin/flash-sales/:id", auth.ValidateToken(), auth.RequireAdmin(), flashSaleHandler.GetFlashSale)
+	app.Put("/api/v1/admin/flash-sales/:id", auth.ValidateToken(), auth.RequireAdmin(), flashSaleHandler.UpdateFlashSale)
+	app.Delete("/api/v1/admin/flash-sales/:id", auth.ValidateToken(), auth.RequireAdmin(), flashSaleHandler.DeleteFlashSale)
+	app.Post("/api/v1/admin/flash-sales/:id/cancel", auth.ValidateToken(), auth.RequireAdmin(), flashSaleHandler.CancelFlashSale)
+	app.Get("/api/v1/admin/flash-sales/:id/products", auth.ValidateToken(), auth.RequireAdmin(), flashSaleHandler.GetFlashSaleProducts)
+	app.Post("/api/v1/admin/flash-sales/:id/products", auth.ValidateToken(), auth.RequireAdmin(), flashSaleHandler.AddFlashSaleProduct)
+	app.Put("/api/v1/admin/flash-sales/:id/products/:product_id", auth.ValidateToken(), auth.RequireAdmin(), flashSaleHandler.UpdateFlashSaleProduct)
+	app.Delete("/api/v1/admin/flash-sales/:id/products/:product_id", auth.ValidateToken(), auth.RequireAdmin(), flashSaleHandler.RemoveFlashSaleProduct)
+
+	// Coupon management (Admin only)
+	app.Get("/api/v1/admin/coupons", auth.ValidateToken(), auth.RequireAdmin(), couponHandler.ListCoupons)
+	app.Post("/api/v1/admin/coupons", auth.ValidateToken(), auth.RequireAdmin(), couponHandler.CreateCoupon)
+	app.Get("/api/v1/admin/coupons/:id", auth.ValidateToken(), auth.RequireAdmin(), couponHandler.GetCoupon)
+	app.Put("/api/v1/admin/coupons/:id", auth.ValidateToken(), auth.RequireAdmin(), couponHandler.UpdateCoupon)
+	app.Post("/api/v1/admin/coupons/:id/deactivate", auth.ValidateToken(), auth.RequireAdmin(), couponHandler.DeactivateCoupon)
+	app.Get("/api/v1/admin/coupon-batches", auth.ValidateToken(), auth.RequireAdmin(), couponHandler.ListCouponBatches)
+	app.Post("/api/v1/admin/coupon-batches", auth.ValidateToken(), auth.RequireAdmin(), couponHandler.GenerateCoupons)
+	app.Get("/api/v1/admin/coupon-batches/:id", auth.ValidateToken(), auth.RequireAdmin(), couponHandler.GetCouponBatch)
+	app.Get("/api/v1/admin/coupon-batches/:id/export", auth.ValidateToken(), auth.RequireAdmin(), couponHandler.ExportCouponBatch)
+
+	// Automatic promotions (Admin only)
+	app.Get("/api/v1/admin/promotions", auth.ValidateToken(), auth.RequireAdmin(), promotionHandler.ListPromotions)
+	app.Post("/api/v1/admin/promotions", auth.ValidateToken(), auth.RequireAdmin(), promotionHandler.CreatePromotion)
+	app.Get("/api/v1/admin/promotions/:id", auth.ValidateToken(), auth.RequireAdmin(), promotionHandler.GetPromotion)
+	app.Put("/api/v1/admin/promotions/:id", auth.ValidateToken(), auth.RequireAdmin(), promotionHandler.UpdatePromotion)
+	app.Delete("/api/v1/admin/promotions/:id", auth.ValidateToken(), auth.RequireAdmin(), promotionHandler.DeletePromotion)
+
+	// Reseller and bulk discount tiers (Admin only)
+	app.Get("/api/v1/admin/discount-tiers", auth.ValidateToken(), auth.RequireAdmin(), discountTierHandler.ListDiscountTiers)
+	app.Post("/api/v1/admin/discount-tiers", auth.ValidateToken(), auth.RequireAdmin(), discountTierHandler.CreateDiscountTier)
+	app.Get("/api/v1/admin/discount-tiers/:id", auth.ValidateToken(), auth.RequireAdmin(), discountTierHandler.GetDiscountTier)
+	app.Put("/api/v1/admin/discount-tiers/:id", auth.ValidateToken(), auth.RequireAdmin(), discountTierHandler.UpdateDiscountTier)
+	app.Delete("/api/v1/admin/discount-tiers/:id", auth.ValidateToken(), auth.RequireAdmin(), discountTierHandler.DeleteDiscountTier)
+
+	// Reseller applications (Admin only)
+	app.Get("/api/v1/admin/reseller-applications", auth.ValidateToken(), auth.RequireAdmin(), resellerHandler.ListApplications)
+	app.Post("/api/v1/admin/reseller-applications/:id/approve", auth.ValidateToken(), auth.RequireAdmin(), resellerHandler.ApproveApplication)
+	app.Post("/api/v1/admin/reseller-applications/:id/reject", auth.ValidateToken(), auth.RequireAdmin(), resellerHandler.RejectApplication)
+	app.Post("/api/v1/admin/resellers/:user_id/revoke", auth.ValidateToken(), auth.RequireAdmin(), resellerHandler.RevokeReseller)
+
+	// Scheduled price changes (Admin only)
+	app.Get("/api/v1/admin/price-changes", auth.ValidateToken(), auth.RequireAdmin(), priceScheduleHandler.ListPriceChanges)
+	app.Post("/api/v1/admin/price-changes", auth.ValidateToken(), auth.RequireAdmin(), priceScheduleHandler.SchedulePriceChange)
+	app.Get("/api/v1/admin/price-changes/:id", auth.ValidateToken(), auth.RequireAdmin(), priceScheduleHandler.GetPriceChange)
+	app.Delete("/api/v1/admin/price-changes/:id", auth.ValidateToken(), auth.RequireAdmin(), priceScheduleHandler.CancelPriceChange)
+
+	// Shipping zones (Admin only)
+	app.Get("/api/v1/admin/shipping-zones", auth.ValidateToken(), auth.RequireAdmin(), shippingZoneHandler.ListShippingZones)
+	app.Post("/api/v1/admin/shipping-zones", auth.ValidateToken(), auth.RequireAdmin(), shippingZoneHandler.CreateShippingZone)
+	app.Get("/api/v1/admin/shipping-zones/:id", auth.ValidateToken(), auth.RequireAdmin(), shippingZoneHandler.GetShippingZone)
+	app.Put("/api/v1/admin/shipping-zones/:id", auth.ValidateToken(), auth.RequireAdmin(), shippingZoneHandler.UpdateShippingZone)
+	app.Delete("/api/v1/admin/shipping-zones/:id", auth.ValidateToken(), auth.RequireAdmin(), shippingZoneHandler.DeleteShippingZone)
+
+	// Review moderation (Admin only)
+	app.Get("/api/v1/admin/reviews/pending", auth.ValidateToken(), auth.RequireAdmin(), reviewHandler.GetPendingReviews)
+	app.Post("/api/v1/admin/reviews/moderate", auth.ValidateToken(), auth.RequireAdmin(), reviewHandler.BulkModerate)
+	app.Put("/api/v1/admin/reviews/:id/approve", auth.ValidateToken(), auth.RequireAdmin(), reviewHandler.ApproveReview)
+	app.Put("/api/v1/admin/reviews/:id/reject", auth.ValidateToken(), auth.RequireAdmin(), reviewHandler.RejectReview)
+	app.Delete("/api/v1/admin/reviews/:id", auth.ValidateToken(), auth.RequireAdmin(), reviewHandler.DeleteReview)
+
 	// WhatsApp admin operations (Admin only)
 	app.Post("/api/v1/whatsapp/send", auth.ValidateToken(), auth.RequireAdmin(), whatsappHandler.SendWhatsAppMessage)
 	app.Get("/api/v1/whatsapp/order-created/:order_id", auth.ValidateToken(), auth.RequireAdmin(), whatsappHandler.SendOrderCreatedNotification)
@@ -201,34 +312,6 @@ func RegisterRoutes(app *fiber.App,
 	// app.Put("/api/v1/users/:id", auth.ValidateToken(), auth.RequireAdmin(), handlers.NewUserHandler(handlers.UserService{}).UpdateUser)
 	// app.Delete("/api/v1/users/:id", auth.ValidateToken(), auth.RequireAdmin(), handlers.NewUserHandler(handlers.UserService{}).DeleteUser)
 
-	// Cart management (Authenticated users - commented out for now)
-	// app.Post("/api/v1/carts", auth.ValidateToken(), handlers.NewCartHandler(handlers.CartService{}).CreateCart)
-	// app.Get("/api/v1/carts", auth.ValidateToken(), handlers.NewCartHandler(handlers.CartService{}).GetCart)
-	// app.Put("/api/v1/carts/:id", auth.ValidateToken(), handlers.NewCartHandler(handlers.CartService{}).UpdateCart)
-	// app.Delete("/api/v1/carts/:id", auth.ValidateToken(), handlers.NewCartHandler(handlers.CartService{}).DeleteCart)
-	// app.Post("/api/v1/carts/:id/items", auth.ValidateToken(), handlers.NewCartHandler(handlers.CartService{}).AddCartItem)
-	// app.Delete("/api/v1/carts/:id/items/:item_id", auth.ValidateToken(), handlers.NewCartHandler(handlers.CartService{}).RemoveCartItem)
-
-	// Wishlist management (Authenticated users - commented out for now)
-	// app.Post("/api/v1/wishlists", auth.ValidateToken(), handlers.NewWishlistHandler(handlers.WishlistService{}).CreateWishlist)
-	// app.Get("/api/v1/wishlists", auth.ValidateToken(), handlers.NewWishlistHandler(handlers.WishlistService{}).GetWishlists)
-	// app.Put("/api/v1/wishlists/:id", auth.ValidateToken(), handlers.NewWishlistHandler(handlers.WishlistService{}).UpdateWishlist)
-	// app.Delete("/api/v1/wishlists/:id", auth.ValidateToken(), handlers.NewWishlistHandler(handlers.WishlistService{}).DeleteWishlist)
-	// app.Post("/api/v1/wishlists/:id/items", auth.ValidateToken(), handlers.NewWishlistHandler(handlers.WishlistService{}).AddWishlistItem)
-	// app.Delete("/api/v1/wishlists/:id/items/:item_id", auth.ValidateToken(), handlers.NewWishlistHandler(handlers.WishlistService{}).RemoveWishlistItem)
-
-	// Flash sale management (Admin only - commented out for now)
-	// app.Post("/api/v1/flash-sales", auth.ValidateToken(), auth.RequireAdmin(), handlers.NewFlashSaleHandler(handlers.FlashSaleService{}).CreateFlashSale)
-	// app.Get("/api/v1/flash-sales", handlers.NewFlashSaleHandler(handlers.FlashSaleService{}).GetFlashSales)
-	// app.Put("/api/v1/flash-sales/:id", auth.ValidateToken(), auth.RequireAdmin(), handlers.NewFlashSaleHandler(handlers.FlashSaleService{}).UpdateFlashSale)
-	// app.Delete("/api/v1/flash-sales/:id", auth.ValidateToken(), auth.RequireAdmin(), handlers.NewFlashSaleHandler(handlers.FlashSaleService{}).DeleteFlashSale)
-
-	// Review management (Authenticated users can create, admin can moderate)
-	// app.Post("/api/v1/reviews", auth.ValidateToken(), handlers.NewReviewHandler(handlers.ReviewService{}).CreateReview)
-	// app.Get("/api/v1/reviews", handlers.NewReviewHandler(handlers.ReviewService{}).GetReviews)
-	// app.Put("/api/v1/reviews/:id", auth.ValidateToken(), handlers.NewReviewHandler(handlers.ReviewService{}).UpdateReview)
-	// app.Delete("/api/v1/reviews/:id", auth.ValidateToken(), auth.RequireAdmin(), handlers.NewReviewHandler(handlers.ReviewService{}).DeleteReview)
-
 	// Komerce integration (Admin only - commented out for now)
 	// app.Post("/api/v1/komerce/orders", auth.ValidateToken(), auth.RequireAdmin(), komerceHandler.CreateOrder)
 	// app.Put("/api/v1/komerce/orders/cancel", auth.ValidateToken(), auth.RequireAdmin(), komerceHandler.CancelOrder)
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+)
+
+const abandonedCartLockKey = "lock:abandoned_cart_job"
+
+// AbandonedCartConfig controls when carts count as abandoned and what the reminder offers
+type AbandonedCartConfig struct {
+	IdleAfter         time.Duration // cart untouched for at least this long
+	MaxAge            time.Duration // carts idle for longer than this are left alone
+	Interval          time.Duration // how often the job runs
+	BatchSize         int           // carts handled per run
+	CouponPercent     float64       // 0 disables the one-time coupon
+	CouponValidFor    time.Duration
+	AttributionWindow time.Duration // orders within this window of a reminder count as recovered
+}
+
+// AbandonedCartRunResult summarises a single job run
+type AbandonedCartRunResult struct {
+	Candidates int `json:"candidates"`
+	Sent       int `json:"sent"`
+	Failed     int `json:"failed"`
+}
+
+// AbandonedCartService sends WhatsApp reminders for carts left behind and tracks how many come back
+type AbandonedCartService interface {
+	Start()
+	Stop()
+	RunOnce() (*AbandonedCartRunResult, error)
+	RecordConversion(userID, orderID uint, orderTotal models.Money) error
+	GetStats(from, to time.Time) (*models.CartRecoveryStats, error)
+}
+
+type abandonedCartService struct {
+	recoveryRepo        repository.CartRecoveryRepository
+	couponRepo          repository.CouponRepository
+	notificationService NotificationService
+	redis               database.RedisClient
+	cfg                 AbandonedCartConfig
+
+	ticker   *time.Ticker
+	done     chan struct{}
+	stopOnce sync.Once
+	running  sync.Mutex
+}
+
+func NewAbandonedCartService(
+	recoveryRepo repository.CartRecoveryRepository,
+	couponRepo repository.CouponRepository,
+	notificationService NotificationService,
+	redis database.RedisClient,
+	cfg AbandonedCartConfig,
+) AbandonedCartService {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Minute
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+
+	return &abandonedCartService{
+		recoveryRepo:        recoveryRepo,
+		couponRepo:          couponRepo,
+		notificationService: notificationService,
+		redis:               redis,
+		cfg:                 cfg,
+		done:                make(chan struct{}),
+	}
+}
+
+// Start runs the reminder job on a ticker until Stop is called
+func (s *abandonedCartService) Start() {
+	s.ticker = time.NewTicker(s.cfg.Interval)
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				result, err := s.RunOnce()
+				if err != nil {
+					log.Printf("[AbandonedCart] Run failed: %v", err)
+					continue
+				}
+				if result.Candidates > 0 {
+					log.Printf("[AbandonedCart] Reminders sent: %d, failed: %d", result.Sent, result.Failed)
+				}
+			case <-s.done:
+				return
+			}
+		}
+	}()
+
+	log.Printf("[AbandonedCart] Job started (every %s, carts idle for %s)", s.cfg.Interval, s.cfg.IdleAfter)
+}
+
+// Stop stops the reminder job
+func (s *abandonedCartService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+		if s.ticker != nil {
+			s.ticker.Stop()
+		}
+	})
+}
+
+// RunOnce finds abandoned carts and sends one reminder per cart.
+// A Redis lock keeps several API instances from reminding the same carts.
+func (s *abandonedCartService) RunOnce() (*AbandonedCartRunResult, error) {
+	if !s.running.TryLock() {
+		return &AbandonedCartRunResult{}, nil
+	}
+	defer s.running.Unlock()
+
+	ctx := context.Background()
+	lockToken, ok := acquireJobLock(ctx, s.redis, abandonedCartLockKey, s.cfg.Interval)
+	if !ok {
+		return &AbandonedCartRunResult{}, nil
+	}
+	defer releaseJobLock(ctx, s.redis, abandonedCartLockKey, lockToken)
+
+	now := time.Now()
+	carts, err := s.recoveryRepo.FindAbandonedCarts(now.Add(-s.cfg.IdleAfter), now.Add(-s.cfg.MaxAge), s.cfg.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find abandoned carts: %w", err)
+	}
+
+	result := &AbandonedCartRunResult{Candidates: len(carts)}
+	for i := range carts {
+		cart := &carts[i]
+		if cart.User.WhatsAppOptOut || cart.User.Phone == "" || len(cart.Items) == 0 {
+			continue
+		}
+
+		recovery, err := s.remind(cart)
+		if err != nil {
+			return result, err
+		}
+		if recovery.Status == models.CartRecoveryStatusSent {
+			result.Sent++
+		} else {
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
+
+// remind sends the reminder for one cart and records the attempt
+func (s *abandonedCartService) remind(cart *models.Cart) (*models.CartRecovery, error) {
+	recovery := &models.CartRecovery{
+		CartID:    cart.ID,
+		UserID:    cart.UserID,
+		Status:    models.CartRecoveryStatusSent,
+		Phone:     cart.User.Phone,
+		ItemCount: cart.ItemCount(),
+		CartValue: cart.Subtotal(),
+		SentAt:    time.Now(),
+	}
+
+	coupon, err := s.issueCoupon(cart)
+	if err != nil {
+		// Still remind the customer, just without the incentive
+		log.Printf("[AbandonedCart] Failed to create coupon for cart %d: %v", cart.ID, err)
+	}
+	if coupon != nil {
+		recovery.CouponID = &coupon.ID
+		recovery.CouponCode = coupon.Code
+	}
+
+	if err := s.notificationService.SendAbandonedCartNotification(&cart.User, cart, coupon); err != nil {
+		recovery.Status = models.CartRecoveryStatusFailed
+		recovery.ErrorMessage = err.Error()
+		if coupon != nil {
+			coupon.Status = models.CouponStatusInactive
+			if err := s.couponRepo.Update(coupon); err != nil {
+				log.Printf("[AbandonedCart] Failed to deactivate coupon %s: %v", coupon.Code, err)
+			}
+		}
+	}
+
+	if err := s.recoveryRepo.Create(recovery); err != nil {
+		return nil, fmt.Errorf("failed to record reminder for cart %d: %w", cart.ID, err)
+	}
+	return recovery, nil
+}
+
+// issueCoupon creates a single-use percentage coupon for the cart's owner when the incentive is enabled
+func (s *abandonedCartService) issueCoupon(cart *models.Cart) (*models.Coupon, error) {
+	if s.cfg.CouponPercent <= 0 {
+		return nil, nil
+	}
+
+	code, err := generateCouponCode("BACK", defaultCouponCodeLength)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	validUntil := now.Add(s.cfg.CouponValidFor)
+	userID := cart.UserID
+	coupon := &models.Coupon{
+		Code:            code,
+		Name:            "Abandoned cart reminder",
+		Description:     fmt.Sprintf("One-time coupon for user %d, cart %d", cart.UserID, cart.ID),
+		Type:            models.CouponTypePercentage,
+		Status:          models.CouponStatusActive,
+		DiscountValue:   s.cfg.CouponPercent,
+		MaxUsageCount:   1,
+		MaxUsagePerUser: 1,
+		ValidFrom:       &now,
+		ValidUntil:      &validUntil,
+		ForRetail:       true,
+		ForReseller:     true,
+		UserID:          &userID,
+	}
+
+	if err := s.couponRepo.Create(coupon); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// RecordConversion attributes an order to the user's latest reminder inside the attribution window
+func (s *abandonedCartService) RecordConversion(userID, orderID uint, orderTotal models.Money) error {
+	converted, err := s.recoveryRepo.MarkConverted(userID, orderID, orderTotal, time.Now().Add(-s.cfg.AttributionWindow))
+	if err != nil {
+		return fmt.Errorf("failed to record cart recovery: %w", err)
+	}
+	if converted {
+		log.Printf("[AbandonedCart] Order %d recovered from reminder for user %d", orderID, userID)
+	}
+	return nil
+}
+
+func (s *abandonedCartService) GetStats(from, to time.Time) (*models.CartRecoveryStats, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+	return s.recoveryRepo.GetStats(from, to)
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCartRecoveryRepository for testing
+type MockCartRecoveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockCartRecoveryRepository) FindAbandonedCarts(idleSince, notBefore time.Time, limit int) ([]models.Cart, error) {
+	args := m.Called(idleSince, notBefore, limit)
+	return args.Get(0).([]models.Cart), args.Error(1)
+}
+
+func (m *MockCartRecoveryRepository) Create(recovery *models.CartRecovery) error {
+	return m.Called(recovery).Error(0)
+}
+
+func (m *MockCartRecoveryRepository) MarkConverted(userID, orderID uint, orderTotal models.Money, sentSince time.Time) (bool, error) {
+	args := m.Called(userID, orderID, orderTotal, sentSince)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCartRecoveryRepository) GetStats(from, to time.Time) (*models.CartRecoveryStats, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CartRecoveryStats), args.Error(1)
+}
+
+// MockNotificationService for testing
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) SendWhatsAppMessage(order *models.Order, message string, recipient string) error {
+	return m.Called(order, message, recipient).Error(0)
+}
+
+func (m *MockNotificationService) SendOrderCreatedNotification(order *models.Order) error {
+	return m.Called(order).Error(0)
+}
+
+func (m *MockNotificationService) SendPaymentSuccessNotification(order *models.Order) error {
+	return m.Called(order).Error(0)
+}
+
+func (m *MockNotificationService) SendShippingNotification(order *models.Order, trackingNumber string) error {
+	return m.Called(order, trackingNumber).Error(0)
+}
+
+func (m *MockNotificationService) SendAbandonedCartNotification(user *models.User, cart *models.Cart, coupon *models.Coupon) error {
+	return m.Called(user, cart, coupon).Error(0)
+}
+
+func (m *MockNotificationService) SendBackInStockNotification(user *models.User, products []models.Product) error {
+	return m.Called(user, products).Error(0)
+}
+
+func (m *MockNotificationService) SendPriceDropNotification(user *models.User, product *models.Product, oldPrice float64, price *PriceCalculationResponse) error {
+	return m.Called(user, product, oldPrice, price).Error(0)
+}
+
+func (m *MockNotificationService) GetWhatsAppStatus() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockNotificationService) SendTestWhatsAppMessage(phoneNumber string, message string) error {
+	return m.Called(phoneNumber, message).Error(0)
+}
+
+func (m *MockNotificationService) ProcessWhatsAppWebhook(data map[string]interface{}) error {
+	return m.Called(data).Error(0)
+}
+
+func (m *MockNotificationService) GetWhatsAppWebhookURL() string {
+	return m.Called().String(0)
+}
+
+func (m *MockNotificationService) GetDB() interface{} {
+	return m.Called().Get(0)
+}
+
+func newTestAbandonedCartService(couponPercent float64) (*abandonedCartService, *MockCartRecoveryRepository, *MockCouponRepository, *MockNotificationService) {
+	recoveryRepo := new(MockCartRecoveryRepository)
+	couponRepo := new(MockCouponRepository)
+	notifier := new(MockNotificationService)
+	service := NewAbandonedCartService(recoveryRepo, couponRepo, notifier, nil, AbandonedCartConfig{
+		IdleAfter:         24 * time.Hour,
+		MaxAge:            7 * 24 * time.Hour,
+		CouponPercent:     couponPercent,
+		CouponValidFor:    48 * time.Hour,
+		AttributionWindow: 7 * 24 * time.Hour,
+	}).(*abandonedCartService)
+	return service, recoveryRepo, couponRepo, notifier
+}
+
+func abandonedTestCart() models.Cart {
+	return models.Cart{
+		ID:     10,
+		UserID: 1,
+		User:   models.User{ID: 1, FullName: "Sari", Phone: "081234567890", IsActive: true},
+		Items: []models.CartItem{
+			{ID: 1, ProductName: "Linen Shirt", Quantity: 2, UnitPrice: 150000, TotalPrice: 300000},
+		},
+	}
+}
+
+func TestAbandonedCartService_RunOnce_SendsReminderWithCoupon(t *testing.T) {
+	service, recoveryRepo, couponRepo, notifier := newTestAbandonedCartService(10)
+
+	recoveryRepo.On("FindAbandonedCarts", mock.Anything, mock.Anything, 100).Return([]models.Cart{abandonedTestCart()}, nil)
+	couponRepo.On("Create", mock.MatchedBy(func(c *models.Coupon) bool {
+		return strings.HasPrefix(c.Code, "BACK-") && c.MaxUsageCount == 1 && c.MaxUsagePerUser == 1 && c.DiscountValue == 10 &&
+			c.UserID != nil && *c.UserID == 1
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Coupon).ID = 5
+	}).Return(nil)
+	notifier.On("SendAbandonedCartNotification", mock.Anything, mock.Anything, mock.AnythingOfType("*models.Coupon")).Return(nil)
+	recoveryRepo.On("Create", mock.MatchedBy(func(r *models.CartRecovery) bool {
+		return r.Status == models.CartRecoveryStatusSent && r.CartID == 10 && r.CouponID != nil && *r.CouponID == 5 &&
+			r.ItemCount == 2 && r.CartValue == 300000
+	})).Return(nil)
+
+	result, err := service.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, &AbandonedCartRunResult{Candidates: 1, Sent: 1}, result)
+	recoveryRepo.AssertExpectations(t)
+}
+
+func TestAbandonedCartService_RunOnce_SendFailureDeactivatesCoupon(t *testing.T) {
+	service, recoveryRepo, couponRepo, notifier := newTestAbandonedCartService(10)
+
+	recoveryRepo.On("FindAbandonedCarts", mock.Anything, mock.Anything, 100).Return([]models.Cart{abandonedTestCart()}, nil)
+	couponRepo.On("Create", mock.Anything).Return(nil)
+	notifier.On("SendAbandonedCartNotification", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("device offline"))
+	couponRepo.On("Update", mock.MatchedBy(func(c *models.Coupon) bool {
+		return c.Status == models.CouponStatusInactive
+	})).Return(nil)
+	recoveryRepo.On("Create", mock.MatchedBy(func(r *models.CartRecovery) bool {
+		return r.Status == models.CartRecoveryStatusFailed && r.ErrorMessage == "device offline"
+	})).Return(nil)
+
+	result, err := service.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	couponRepo.AssertExpectations(t)
+}
+
+func TestAbandonedCartService_RunOnce_SkipsOptedOutUser(t *testing.T) {
+	service, recoveryRepo, _, notifier := newTestAbandonedCartService(0)
+
+	cart := abandonedTestCart()
+	cart.User.WhatsAppOptOut = true
+	recoveryRepo.On("FindAbandonedCarts", mock.Anything, mock.Anything, 100).Return([]models.Cart{cart}, nil)
+
+	result, err := service.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Sent)
+	notifier.AssertNotCalled(t, "SendAbandonedCartNotification", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAbandonedCartService_RecordConversion_UsesAttributionWindow(t *testing.T) {
+	service, recoveryRepo, _, _ := newTestAbandonedCartService(0)
+
+	recoveryRepo.On("MarkConverted", uint(1), uint(42), models.Money(300000), mock.MatchedBy(func(since time.Time) bool {
+		return time.Since(since) >= 7*24*time.Hour && time.Since(since) < 7*24*time.Hour+time.Minute
+	})).Return(true, nil)
+
+	assert.NoError(t, service.RecordConversion(1, 42, 300000))
+	recoveryRepo.AssertExpectations(t)
+}
+
+func TestBuildAbandonedCartMessage(t *testing.T) {
+	cart := abandonedTestCart()
+	validUntil := time.Date(2025, 1, 2, 15, 4, 0, 0, time.UTC)
+	coupon := &models.Coupon{Code: "BACK-ABCD2345", DiscountValue: 10, ValidUntil: &validUntil}
+
+	message := buildAbandonedCartMessage(&cart.User, &cart, coupon, "https://karimastore.com/")
+
+	assert.Contains(t, message, "Halo Sari")
+	assert.Contains(t, message, "Linen Shirt x2")
+	assert.Contains(t, message, "Rp 300000")
+	assert.Contains(t, message, "*BACK-ABCD2345* untuk diskon 10%")
+	assert.Contains(t, message, "https://karimastore.com/cart")
+}
@@ -40,14 +40,15 @@ func (s *authService) SyncUser(kratosIdentity *models.KratosIdentity, email stri
 	// 3. User still not found? Create new user
 	if user == nil {
 		newUser := &models.User{
-			KratosID:   kratosIdentity.ID,
-			Email:      email,
-			Role:       models.RoleCustomer, // Default role
-			CreatedAt:  time.Now(),
-			UpdatedAt:  time.Now(),
-			IsActive:   true,
-			IsVerified: true,   // Assumed verified if Kratos lets them login (simplification)
-			FullName:   "User", // Placeholder, ideally get from traits
+			KratosID:      kratosIdentity.ID,
+			Email:         email,
+			Role:          models.RoleCustomer, // Default role
+			CustomerGroup: models.CustomerGroupRetail,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+			IsActive:      true,
+			IsVerified:    true,   // Assumed verified if Kratos lets them login (simplification)
+			FullName:      "User", // Placeholder, ideally get from traits
 		}
 
 		// Attempt to extract name from traits if available
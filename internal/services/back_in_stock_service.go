@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+)
+
+const backInStockLockKey = "lock:back_in_stock_job"
+
+// BackInStockConfig controls how often restock events are processed and how often
+// the same user may hear about the same product
+type BackInStockConfig struct {
+	Interval  time.Duration
+	Cooldown  time.Duration
+	BatchSize int
+}
+
+// BackInStockRunResult summarises a single notifier run
+type BackInStockRunResult struct {
+	Events   int `json:"events"`
+	Products int `json:"products"`
+	Notified int `json:"notified"`
+	Failed   int `json:"failed"`
+}
+
+// BackInStockService tells users over WhatsApp when a product on their wishlist can be bought again
+type BackInStockService interface {
+	Start()
+	Stop()
+	RunOnce() (*BackInStockRunResult, error)
+}
+
+type backInStockService struct {
+	restockRepo         repository.RestockEventRepository
+	wishlistRepo        repository.WishlistRepository
+	productRepo         repository.ProductRepository
+	variantRepo         repository.VariantRepository
+	notificationService NotificationService
+	redis               database.RedisClient
+	cfg                 BackInStockConfig
+
+	ticker   *time.Ticker
+	done     chan struct{}
+	stopOnce sync.Once
+	running  sync.Mutex
+}
+
+func NewBackInStockService(
+	restockRepo repository.RestockEventRepository,
+	wishlistRepo repository.WishlistRepository,
+	productRepo repository.ProductRepository,
+	variantRepo repository.VariantRepository,
+	notificationService NotificationService,
+	redis database.RedisClient,
+	cfg BackInStockConfig,
+) BackInStockService {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 200
+	}
+
+	return &backInStockService{
+		restockRepo:         restockRepo,
+		wishlistRepo:        wishlistRepo,
+		productRepo:         productRepo,
+		variantRepo:         variantRepo,
+		notificationService: notificationService,
+		redis:               redis,
+		cfg:                 cfg,
+		done:                make(chan struct{}),
+	}
+}
+
+// Start processes restock events on a ticker until Stop is called
+func (s *backInStockService) Start() {
+	s.ticker = time.NewTicker(s.cfg.Interval)
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				result, err := s.RunOnce()
+				if err != nil {
+					log.Printf("[BackInStock] Run failed: %v", err)
+					continue
+				}
+				if result.Events > 0 {
+					log.Printf("[BackInStock] %d products restocked, users notified: %d, failed: %d", result.Products, result.Notified, result.Failed)
+				}
+			case <-s.done:
+				return
+			}
+		}
+	}()
+
+	log.Printf("[BackInStock] Job started (every %s)", s.cfg.Interval)
+}
+
+// Stop stops the notifier
+func (s *backInStockService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+		if s.ticker != nil {
+			s.ticker.Stop()
+		}
+	})
+}
+
+// RunOnce consumes pending restock events and sends each affected user a single message
+// listing every restocked product on their wishlist. A user is not told about the same
+// product again until the cooldown has passed, so stock flapping around zero stays quiet.
+func (s *backInStockService) RunOnce() (*BackInStockRunResult, error) {
+	if !s.running.TryLock() {
+		return &BackInStockRunResult{}, nil
+	}
+	defer s.running.Unlock()
+
+	ctx := context.Background()
+	lockToken, ok := acquireJobLock(ctx, s.redis, backInStockLockKey, s.cfg.Interval)
+	if !ok {
+		return &BackInStockRunResult{}, nil
+	}
+	defer releaseJobLock(ctx, s.redis, backInStockLockKey, lockToken)
+
+	events, err := s.restockRepo.GetPending(s.cfg.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load restock events: %w", err)
+	}
+
+	result := &BackInStockRunResult{Events: len(events)}
+	if len(events) == 0 {
+		return result, nil
+	}
+
+	eventIDs := make([]uint, 0, len(events))
+	products := make(map[uint]*models.Product)
+	for _, event := range events {
+		eventIDs = append(eventIDs, event.ID)
+		if _, seen := products[event.ProductID]; seen {
+			continue
+		}
+		if product := s.restockedProduct(event); product != nil {
+			products[event.ProductID] = product
+		}
+	}
+	result.Products = len(products)
+
+	if len(products) > 0 {
+		if err := s.notifyWishlisters(products, result); err != nil {
+			return result, err
+		}
+	}
+
+	// Events are consumed even when a send fails; the next restock will try again
+	if err := s.restockRepo.MarkProcessed(eventIDs); err != nil {
+		return result, fmt.Errorf("failed to mark restock events processed: %w", err)
+	}
+	return result, nil
+}
+
+// restockedProduct returns the product if it is still purchasable when the event is processed
+func (s *backInStockService) restockedProduct(event models.RestockEvent) *models.Product {
+	product, err := s.productRepo.GetByID(event.ProductID)
+	if err != nil || product.Status != models.StatusAvailable {
+		return nil
+	}
+
+	if event.VariantID != nil {
+		variant, err := s.variantRepo.GetByID(*event.VariantID)
+		if err != nil || variant.Stock <= 0 {
+			return nil
+		}
+		return product
+	}
+
+	if product.Stock <= 0 {
+		return nil
+	}
+	return product
+}
+
+func (s *backInStockService) notifyWishlisters(products map[uint]*models.Product, result *BackInStockRunResult) error {
+	productIDs := make([]uint, 0, len(products))
+	for id := range products {
+		productIDs = append(productIDs, id)
+	}
+
+	now := time.Now()
+	entries, err := s.wishlistRepo.GetRestockRecipients(productIDs, now.Add(-s.cfg.Cooldown))
+	if err != nil {
+		return fmt.Errorf("failed to load wishlist recipients: %w", err)
+	}
+
+	// Entries are ordered by user, so each user gets exactly one message
+	for start := 0; start < len(entries); {
+		end := start
+		for end < len(entries) && entries[end].UserID == entries[start].UserID {
+			end++
+		}
+
+		user := entries[start].User
+		var restocked []models.Product
+		var wishlistIDs []uint
+		for _, entry := range entries[start:end] {
+			restocked = append(restocked, *products[entry.ProductID])
+			wishlistIDs = append(wishlistIDs, entry.ID)
+		}
+		start = end
+
+		if err := s.notificationService.SendBackInStockNotification(&user, restocked); err != nil {
+			log.Printf("[BackInStock] Failed to notify user %d: %v", user.ID, err)
+			result.Failed++
+			continue
+		}
+		if err := s.wishlistRepo.MarkRestockNotified(wishlistIDs, now); err != nil {
+			return fmt.Errorf("failed to record restock notification for user %d: %w", user.ID, err)
+		}
+		result.Notified++
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRestockEventRepository for testing
+type MockRestockEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockRestockEventRepository) GetPending(limit int) ([]models.RestockEvent, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]models.RestockEvent), args.Error(1)
+}
+
+func (m *MockRestockEventRepository) MarkProcessed(ids []uint) error {
+	return m.Called(ids).Error(0)
+}
+
+func newTestBackInStockService() (*backInStockService, *MockRestockEventRepository, *MockWishlistRepository, *MockProductRepository, *MockVariantRepository, *MockNotificationService) {
+	restockRepo := new(MockRestockEventRepository)
+	wishlistRepo := new(MockWishlistRepository)
+	productRepo := new(MockProductRepository)
+	variantRepo := new(MockVariantRepository)
+	notifier := new(MockNotificationService)
+	service := NewBackInStockService(restockRepo, wishlistRepo, productRepo, variantRepo, notifier, nil, BackInStockConfig{
+		Cooldown: 24 * time.Hour,
+	}).(*backInStockService)
+	return service, restockRepo, wishlistRepo, productRepo, variantRepo, notifier
+}
+
+func TestBackInStockService_RunOnce_OneMessagePerUser(t *testing.T) {
+	service, restockRepo, wishlistRepo, productRepo, variantRepo, notifier := newTestBackInStockService()
+
+	variantID := uint(21)
+	restockRepo.On("GetPending", 200).Return([]models.RestockEvent{
+		{ID: 1, ProductID: 3, NewStock: 5},
+		{ID: 2, ProductID: 4, VariantID: &variantID, NewStock: 2},
+		{ID: 3, ProductID: 3, NewStock: 7},
+	}, nil)
+	productRepo.On("GetByID", uint(3)).Return(&models.Product{ID: 3, Name: "Linen Shirt", Status: models.StatusAvailable, Stock: 7}, nil)
+	productRepo.On("GetByID", uint(4)).Return(&models.Product{ID: 4, Name: "Denim Jacket", Status: models.StatusAvailable}, nil)
+	variantRepo.On("GetByID", uint(21)).Return(&models.ProductVariant{ID: 21, ProductID: 4, Stock: 2}, nil)
+
+	alice := models.User{ID: 1, FullName: "Alice", Phone: "0811"}
+	bob := models.User{ID: 2, FullName: "Bob", Phone: "0812"}
+	wishlistRepo.On("GetRestockRecipients", mock.MatchedBy(func(ids []uint) bool { return len(ids) == 2 }), mock.Anything).Return([]models.Wishlist{
+		{ID: 10, UserID: 1, User: alice, ProductID: 3},
+		{ID: 11, UserID: 1, User: alice, ProductID: 4},
+		{ID: 12, UserID: 2, User: bob, ProductID: 3},
+	}, nil)
+
+	notifier.On("SendBackInStockNotification", mock.MatchedBy(func(u *models.User) bool { return u.ID == 1 }), mock.MatchedBy(func(p []models.Product) bool {
+		return len(p) == 2
+	})).Return(nil).Once()
+	notifier.On("SendBackInStockNotification", mock.MatchedBy(func(u *models.User) bool { return u.ID == 2 }), mock.Anything).Return(errors.New("device offline")).Once()
+	wishlistRepo.On("MarkRestockNotified", []uint{10, 11}, mock.Anything).Return(nil).Once()
+	restockRepo.On("MarkProcessed", []uint{1, 2, 3}).Return(nil)
+
+	result, err := service.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, &BackInStockRunResult{Events: 3, Products: 2, Notified: 1, Failed: 1}, result)
+	notifier.AssertExpectations(t)
+	wishlistRepo.AssertExpectations(t)
+	restockRepo.AssertExpectations(t)
+}
+
+func TestBackInStockService_RunOnce_SkipsProductSoldOutAgain(t *testing.T) {
+	service, restockRepo, wishlistRepo, productRepo, _, notifier := newTestBackInStockService()
+
+	restockRepo.On("GetPending", 200).Return([]models.RestockEvent{{ID: 1, ProductID: 3, NewStock: 1}}, nil)
+	productRepo.On("GetByID", uint(3)).Return(&models.Product{ID: 3, Status: models.StatusAvailable, Stock: 0}, nil)
+	restockRepo.On("MarkProcessed", []uint{1}).Return(nil)
+
+	result, err := service.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Products)
+	wishlistRepo.AssertNotCalled(t, "GetRestockRecipients", mock.Anything, mock.Anything)
+	notifier.AssertNotCalled(t, "SendBackInStockNotification", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,760 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// MaxCartItemQuantity caps a single cart line regardless of available stock
+const MaxCartItemQuantity = 99
+
+type CartService interface {
+	GetCart(userID uint) (*models.Cart, error)
+	AddItem(userID uint, req *models.AddToCartRequest) (*models.Cart, error)
+	UpdateItemQuantity(userID, itemID uint, quantity int) (*models.Cart, error)
+	RemoveItem(userID, itemID uint) (*models.Cart, error)
+	ClearCart(userID uint) error
+	CheckoutFromCart(userID uint, req *models.CartCheckoutRequest) (*models.CheckoutResponse, error)
+
+	// Revalidation reprices every line and reports what changed since it was added
+	RevalidateCart(userID uint) (*models.Cart, []CartWarning, error)
+	RevalidateGuestCart(guestID string) (*models.Cart, []CartWarning, error)
+
+	// Guest carts live in Redis until the shopper logs in
+	NewGuestCartID() (string, error)
+	GetGuestCart(guestID string) (*models.Cart, error)
+	AddGuestItem(guestID string, req *models.AddToCartRequest) (*models.Cart, error)
+	UpdateGuestItemQuantity(guestID string, itemID uint, quantity int) (*models.Cart, error)
+	RemoveGuestItem(guestID string, itemID uint) (*models.Cart, error)
+	MergeGuestCart(guestID string, userID uint) (*CartMergeResult, error)
+}
+
+// CartWarningType identifies why a cart line needs the customer's attention
+type CartWarningType string
+
+const (
+	CartWarningPriceChanged      CartWarningType = "price_changed"
+	CartWarningFlashSaleEnded    CartWarningType = "flash_sale_ended"
+	CartWarningOutOfStock        CartWarningType = "out_of_stock"
+	CartWarningInsufficientStock CartWarningType = "insufficient_stock"
+	CartWarningDiscontinued      CartWarningType = "discontinued"
+	CartWarningUnavailable       CartWarningType = "unavailable"
+)
+
+// CartWarning is a structured notice about a single cart line found during revalidation
+type CartWarning struct {
+	ItemID         uint            `json:"item_id"`
+	ProductID      uint            `json:"product_id"`
+	VariantID      *uint           `json:"variant_id,omitempty"`
+	ProductName    string          `json:"product_name"`
+	Type           CartWarningType `json:"type"`
+	Message        string          `json:"message"`
+	OldPrice       models.Money    `json:"old_price,omitempty"`       // unit price in the snapshot
+	NewPrice       models.Money    `json:"new_price,omitempty"`       // current unit price
+	AvailableStock *int            `json:"available_stock,omitempty"` // set for stock warnings
+}
+
+// CartMergeResult describes what happened to each guest line when merged into a user cart
+type CartMergeResult struct {
+	MergedItems   int      `json:"merged_items"`
+	AdjustedItems []string `json:"adjusted_items,omitempty"` // quantity reduced to available stock
+	SkippedItems  []string `json:"skipped_items,omitempty"`  // no longer purchasable
+}
+
+type cartService struct {
+	cartRepo        repository.CartRepository
+	productRepo     repository.ProductRepository
+	variantRepo     repository.VariantRepository
+	userRepo        repository.UserRepository // optional, without it every cart is priced retail
+	pricingService  PricingService
+	checkoutService CheckoutService
+	redis           database.RedisClient
+	guestCartTTL    time.Duration
+}
+
+func NewCartService(
+	cartRepo repository.CartRepository,
+	productRepo repository.ProductRepository,
+	variantRepo repository.VariantRepository,
+	userRepo repository.UserRepository,
+	pricingService PricingService,
+	checkoutService CheckoutService,
+	redis database.RedisClient,
+	guestCartTTL time.Duration,
+) CartService {
+	return &cartService{
+		cartRepo:        cartRepo,
+		productRepo:     productRepo,
+		variantRepo:     variantRepo,
+		userRepo:        userRepo,
+		pricingService:  pricingService,
+		checkoutService: checkoutService,
+		redis:           redis,
+		guestCartTTL:    guestCartTTL,
+	}
+}
+
+func (s *cartService) GetCart(userID uint) (*models.Cart, error) {
+	return s.cartRepo.GetOrCreateByUserID(userID)
+}
+
+func (s *cartService) AddItem(userID uint, req *models.AddToCartRequest) (*models.Cart, error) {
+	if req.Quantity <= 0 {
+		return nil, errors.New("quantity must be greater than 0")
+	}
+
+	cart, err := s.cartRepo.GetOrCreateByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var variantID *uint
+	if req.VariantID != 0 {
+		vID := req.VariantID
+		variantID = &vID
+	}
+
+	customerType, err := s.customerType(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge into an existing line for the same product/variant
+	item, err := s.cartRepo.FindItem(cart.ID, req.ProductID, variantID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if item == nil {
+		item = &models.CartItem{
+			CartID:           cart.ID,
+			ProductID:        req.ProductID,
+			ProductVariantID: variantID,
+		}
+	}
+
+	if err := s.fillItem(item, item.Quantity+req.Quantity, customerType); err != nil {
+		return nil, err
+	}
+
+	if item.ID == 0 {
+		err = s.cartRepo.AddItem(item)
+	} else {
+		err = s.cartRepo.UpdateItem(item)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.cartRepo.GetByUserID(userID)
+}
+
+func (s *cartService) UpdateItemQuantity(userID, itemID uint, quantity int) (*models.Cart, error) {
+	if quantity <= 0 {
+		return nil, errors.New("quantity must be greater than 0")
+	}
+
+	cart, err := s.cartRepo.GetOrCreateByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.cartRepo.GetItem(cart.ID, itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cart item not found")
+		}
+		return nil, err
+	}
+
+	customerType, err := s.customerType(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillItem(item, quantity, customerType); err != nil {
+		return nil, err
+	}
+
+	if err := s.cartRepo.UpdateItem(item); err != nil {
+		return nil, err
+	}
+
+	return s.cartRepo.GetByUserID(userID)
+}
+
+func (s *cartService) RemoveItem(userID, itemID uint) (*models.Cart, error) {
+	cart, err := s.cartRepo.GetOrCreateByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cartRepo.RemoveItem(cart.ID, itemID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cart item not found")
+		}
+		return nil, err
+	}
+
+	return s.cartRepo.GetByUserID(userID)
+}
+
+func (s *cartService) ClearCart(userID uint) error {
+	cart, err := s.cartRepo.GetOrCreateByUserID(userID)
+	if err != nil {
+		return err
+	}
+	return s.cartRepo.Clear(cart.ID)
+}
+
+// CheckoutFromCart turns the user's cart into a checkout request and empties the cart on success
+func (s *cartService) CheckoutFromCart(userID uint, req *models.CartCheckoutRequest) (*models.CheckoutResponse, error) {
+	cart, err := s.cartRepo.GetOrCreateByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cart.Items) == 0 {
+		return nil, errors.New("cart is empty")
+	}
+
+	checkoutReq := &models.CheckoutRequest{
+		ShippingName:          req.ShippingName,
+		ShippingPhone:         req.ShippingPhone,
+		ShippingAddress:       req.ShippingAddress,
+		ShippingCity:          req.ShippingCity,
+		ShippingProvince:      req.ShippingProvince,
+		ShippingPostalCode:    req.ShippingPostalCode,
+		ReceiverDestinationID: req.ReceiverDestinationID,
+		ShippingCourier:       req.ShippingCourier,
+		ShippingService:       req.ShippingService,
+		PaymentMethod:         req.PaymentMethod,
+		UserID:                userID,
+		CustomerNotes:         req.CustomerNotes,
+		CouponCode:            req.CouponCode,
+		Reseller:              req.Reseller,
+	}
+
+	for _, item := range cart.Items {
+		checkoutItem := models.CheckoutItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+		if item.ProductVariantID != nil {
+			checkoutItem.VariantID = *item.ProductVariantID
+		}
+		checkoutReq.Items = append(checkoutReq.Items, checkoutItem)
+	}
+
+	response, err := s.checkoutService.Checkout(checkoutReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// The order already exists at this point, so a failure here must not fail the checkout
+	if err := s.cartRepo.Clear(cart.ID); err != nil {
+		log.Printf("Failed to clear cart %d after order %s: %v", cart.ID, response.OrderNumber, err)
+	}
+
+	return response, nil
+}
+
+// RevalidateCart reprices the user's cart, persists refreshed snapshots and returns warnings
+// for every line whose price, flash sale or availability changed since it was added.
+func (s *cartService) RevalidateCart(userID uint) (*models.Cart, []CartWarning, error) {
+	cart, err := s.cartRepo.GetOrCreateByUserID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	customerType, err := s.customerType(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings, changed := s.revalidateItems(cart.Items, customerType)
+	for _, idx := range changed {
+		if err := s.cartRepo.UpdateItem(&cart.Items[idx]); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return cart, warnings, nil
+}
+
+// RevalidateGuestCart is RevalidateCart for carts stored in Redis
+func (s *cartService) RevalidateGuestCart(guestID string) (*models.Cart, []CartWarning, error) {
+	ctx := context.Background()
+	cart, err := s.loadGuestCart(ctx, guestID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	warnings, changed := s.revalidateItems(cart.Items, CustomerRetail)
+	if len(changed) > 0 {
+		if err := s.saveGuestCart(ctx, guestID, cart); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return cart, warnings, nil
+}
+
+// revalidateItems checks each line against current product data and pricing.
+// Lines that can still be priced get a fresh snapshot; the indexes of those lines are returned
+// so the caller can persist them. Unavailable lines are left untouched for the customer to remove.
+func (s *cartService) revalidateItems(items []models.CartItem, customerType CustomerType) ([]CartWarning, []int) {
+	warnings := []CartWarning{}
+	var changed []int
+
+	for i := range items {
+		item := &items[i]
+		warn := func(warningType CartWarningType, message string) *CartWarning {
+			warnings = append(warnings, CartWarning{
+				ItemID:      item.ID,
+				ProductID:   item.ProductID,
+				VariantID:   item.ProductVariantID,
+				ProductName: item.ProductName,
+				Type:        warningType,
+				Message:     message,
+			})
+			return &warnings[len(warnings)-1]
+		}
+
+		product, err := s.productRepo.GetByID(item.ProductID)
+		if err != nil {
+			warn(CartWarningUnavailable, "This product is no longer available")
+			continue
+		}
+
+		switch product.Status {
+		case models.StatusAvailable:
+		case models.StatusDiscontinued:
+			warn(CartWarningDiscontinued, "This product has been discontinued")
+			continue
+		case models.StatusOutOfStock:
+			zero := 0
+			warn(CartWarningOutOfStock, "This product is out of stock").AvailableStock = &zero
+			continue
+		default:
+			warn(CartWarningUnavailable, "This product is currently unavailable")
+			continue
+		}
+
+		availableStock := product.Stock
+		var variant *models.ProductVariant
+		if item.ProductVariantID != nil {
+			variant, err = s.variantRepo.GetByID(*item.ProductVariantID)
+			if err != nil || variant.ProductID != product.ID {
+				warn(CartWarningUnavailable, "This variant is no longer available")
+				continue
+			}
+			availableStock = variant.Stock
+		}
+
+		if availableStock <= 0 {
+			zero := 0
+			warn(CartWarningOutOfStock, "This item is out of stock").AvailableStock = &zero
+			continue
+		}
+		if availableStock < item.Quantity {
+			stock := availableStock
+			warn(CartWarningInsufficientStock,
+				fmt.Sprintf("Only %d left in stock", availableStock)).AvailableStock = &stock
+		}
+
+		oldPrice := item.UnitPrice
+		oldDiscountType := item.DiscountType
+		if err := s.snapshotItem(item, product, variant, item.Quantity, customerType); err != nil {
+			log.Printf("Failed to reprice cart item %d: %v", item.ID, err)
+			continue
+		}
+		changed = append(changed, i)
+
+		switch {
+		case oldDiscountType == "flash_sale" && item.DiscountType != "flash_sale":
+			w := warn(CartWarningFlashSaleEnded, "The flash sale for this item has ended")
+			w.OldPrice = oldPrice
+			w.NewPrice = item.UnitPrice
+		case item.UnitPrice != oldPrice:
+			w := warn(CartWarningPriceChanged, fmt.Sprintf("Price changed from Rp %s to Rp %s", oldPrice, item.UnitPrice))
+			w.OldPrice = oldPrice
+			w.NewPrice = item.UnitPrice
+		}
+	}
+
+	return warnings, changed
+}
+
+// NewGuestCartID generates a random identifier for an anonymous cart
+func (s *cartService) NewGuestCartID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate guest cart ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *cartService) GetGuestCart(guestID string) (*models.Cart, error) {
+	return s.loadGuestCart(context.Background(), guestID)
+}
+
+func (s *cartService) AddGuestItem(guestID string, req *models.AddToCartRequest) (*models.Cart, error) {
+	if req.Quantity <= 0 {
+		return nil, errors.New("quantity must be greater than 0")
+	}
+
+	ctx := context.Background()
+	cart, err := s.loadGuestCart(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	var variantID *uint
+	if req.VariantID != 0 {
+		vID := req.VariantID
+		variantID = &vID
+	}
+
+	idx := findCartLine(cart.Items, req.ProductID, variantID)
+	if idx >= 0 {
+		if err := s.fillItem(&cart.Items[idx], cart.Items[idx].Quantity+req.Quantity, CustomerRetail); err != nil {
+			return nil, err
+		}
+	} else {
+		item := models.CartItem{
+			ID:               nextGuestItemID(cart.Items),
+			ProductID:        req.ProductID,
+			ProductVariantID: variantID,
+			CreatedAt:        time.Now(),
+		}
+		if err := s.fillItem(&item, req.Quantity, CustomerRetail); err != nil {
+			return nil, err
+		}
+		cart.Items = append(cart.Items, item)
+	}
+
+	if err := s.saveGuestCart(ctx, guestID, cart); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+func (s *cartService) UpdateGuestItemQuantity(guestID string, itemID uint, quantity int) (*models.Cart, error) {
+	if quantity <= 0 {
+		return nil, errors.New("quantity must be greater than 0")
+	}
+
+	ctx := context.Background()
+	cart, err := s.loadGuestCart(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range cart.Items {
+		if cart.Items[i].ID != itemID {
+			continue
+		}
+		if err := s.fillItem(&cart.Items[i], quantity, CustomerRetail); err != nil {
+			return nil, err
+		}
+		if err := s.saveGuestCart(ctx, guestID, cart); err != nil {
+			return nil, err
+		}
+		return cart, nil
+	}
+
+	return nil, errors.New("cart item not found")
+}
+
+func (s *cartService) RemoveGuestItem(guestID string, itemID uint) (*models.Cart, error) {
+	ctx := context.Background()
+	cart, err := s.loadGuestCart(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range cart.Items {
+		if cart.Items[i].ID != itemID {
+			continue
+		}
+		cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+		if err := s.saveGuestCart(ctx, guestID, cart); err != nil {
+			return nil, err
+		}
+		return cart, nil
+	}
+
+	return nil, errors.New("cart item not found")
+}
+
+// MergeGuestCart moves a guest cart into the user's persistent cart.
+// Lines for the same product/variant are combined; the combined quantity is
+// capped at available stock (and MaxCartItemQuantity) instead of failing, and
+// lines that can no longer be bought are dropped.
+//
+// The guest cart is claimed by deleting it from Redis as it is read, so concurrent or repeated
+// merges of the same guest cart find nothing to merge. Every line is then saved in one
+// transaction; when that fails the guest cart is put back so the merge can be retried.
+func (s *cartService) MergeGuestCart(guestID string, userID uint) (*CartMergeResult, error) {
+	ctx := context.Background()
+	guestCart, err := s.claimGuestCart(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CartMergeResult{}
+	if len(guestCart.Items) == 0 {
+		return result, nil
+	}
+
+	if err := s.mergeGuestLines(guestCart, userID, result); err != nil {
+		if restoreErr := s.saveGuestCart(ctx, guestID, guestCart); restoreErr != nil {
+			log.Printf("Failed to restore guest cart %s after failed merge: %v", guestID, restoreErr)
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// mergeGuestLines combines the guest lines with the user's cart and saves them together
+func (s *cartService) mergeGuestLines(guestCart *models.Cart, userID uint, result *CartMergeResult) error {
+	cart, err := s.cartRepo.GetOrCreateByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	customerType, err := s.customerType(userID)
+	if err != nil {
+		return err
+	}
+
+	items := make([]*models.CartItem, 0, len(guestCart.Items))
+	for _, guestItem := range guestCart.Items {
+		product, variant, availableStock, err := s.loadLine(guestItem.ProductID, guestItem.ProductVariantID)
+		if err != nil || availableStock <= 0 {
+			result.SkippedItems = append(result.SkippedItems, guestLineName(guestItem))
+			continue
+		}
+
+		item, err := s.cartRepo.FindItem(cart.ID, guestItem.ProductID, guestItem.ProductVariantID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if item == nil {
+			item = &models.CartItem{
+				CartID:           cart.ID,
+				ProductID:        guestItem.ProductID,
+				ProductVariantID: guestItem.ProductVariantID,
+			}
+		}
+
+		quantity := item.Quantity + guestItem.Quantity
+		limit := availableStock
+		if limit > MaxCartItemQuantity {
+			limit = MaxCartItemQuantity
+		}
+		if quantity > limit {
+			quantity = limit
+			result.AdjustedItems = append(result.AdjustedItems, guestLineName(guestItem))
+		}
+
+		if err := s.snapshotItem(item, product, variant, quantity, customerType); err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+	if err := s.cartRepo.SaveItems(items); err != nil {
+		return fmt.Errorf("failed to merge guest cart: %w", err)
+	}
+	result.MergedItems = len(items)
+	return nil
+}
+
+// claimGuestCart reads the guest cart and deletes it in one step
+func (s *cartService) claimGuestCart(ctx context.Context, guestID string) (*models.Cart, error) {
+	if guestID == "" {
+		return nil, errors.New("guest cart ID is required")
+	}
+
+	var cart models.Cart
+	raw, err := s.redis.GetDel(ctx, guestCartKey(guestID))
+	if err != nil || json.Unmarshal([]byte(raw), &cart) != nil {
+		// Missing or unreadable carts have nothing to merge
+		return &models.Cart{Items: []models.CartItem{}}, nil
+	}
+	return &cart, nil
+}
+
+func (s *cartService) loadGuestCart(ctx context.Context, guestID string) (*models.Cart, error) {
+	if guestID == "" {
+		return nil, errors.New("guest cart ID is required")
+	}
+
+	var cart models.Cart
+	if err := s.redis.GetJSON(ctx, guestCartKey(guestID), &cart); err != nil {
+		// Missing carts start empty
+		if errors.Is(err, redis.Nil) {
+			return &models.Cart{Items: []models.CartItem{}}, nil
+		}
+		return nil, fmt.Errorf("failed to load guest cart: %w", err)
+	}
+	return &cart, nil
+}
+
+func (s *cartService) saveGuestCart(ctx context.Context, guestID string, cart *models.Cart) error {
+	cart.UpdatedAt = time.Now()
+	if err := s.redis.SetJSON(ctx, guestCartKey(guestID), cart, s.guestCartTTL); err != nil {
+		return fmt.Errorf("failed to save guest cart: %w", err)
+	}
+	return nil
+}
+
+func guestCartKey(guestID string) string {
+	return fmt.Sprintf("cart:guest:%s", guestID)
+}
+
+// findCartLine returns the index of the line for a product/variant pair, or -1
+func findCartLine(items []models.CartItem, productID uint, variantID *uint) int {
+	for i, item := range items {
+		if item.ProductID != productID {
+			continue
+		}
+		if (item.ProductVariantID == nil) != (variantID == nil) {
+			continue
+		}
+		if variantID == nil || *item.ProductVariantID == *variantID {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextGuestItemID assigns line IDs inside a guest cart so lines can be updated and removed
+func nextGuestItemID(items []models.CartItem) uint {
+	var maxID uint
+	for _, item := range items {
+		if item.ID > maxID {
+			maxID = item.ID
+		}
+	}
+	return maxID + 1
+}
+
+func guestLineName(item models.CartItem) string {
+	if item.VariantName != "" {
+		return fmt.Sprintf("%s (%s)", item.ProductName, item.VariantName)
+	}
+	return item.ProductName
+}
+
+// fillItem validates availability for the requested quantity and refreshes the line snapshot
+func (s *cartService) fillItem(item *models.CartItem, quantity int, customerType CustomerType) error {
+	product, variant, availableStock, err := s.loadLine(item.ProductID, item.ProductVariantID)
+	if err != nil {
+		return err
+	}
+
+	if quantity > MaxCartItemQuantity {
+		return fmt.Errorf("quantity cannot exceed %d per item", MaxCartItemQuantity)
+	}
+	if quantity > availableStock {
+		return fmt.Errorf("insufficient stock for product %s. Available: %d, Requested: %d",
+			product.Name, availableStock, quantity)
+	}
+
+	return s.snapshotItem(item, product, variant, quantity, customerType)
+}
+
+// customerType returns the prices the user's cart is shown at; guest carts are always retail
+func (s *cartService) customerType(userID uint) (CustomerType, error) {
+	if s.userRepo == nil {
+		return CustomerRetail, nil
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	return CustomerTypeFor(user), nil
+}
+
+// loadLine fetches the product (and variant) behind a cart line and returns the stock that limits it
+func (s *cartService) loadLine(productID uint, variantID *uint) (*models.Product, *models.ProductVariant, int, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, 0, errors.New("product not found")
+		}
+		return nil, nil, 0, err
+	}
+
+	if product.Status != models.StatusAvailable {
+		return nil, nil, 0, fmt.Errorf("product %s is not available", product.Name)
+	}
+
+	if variantID == nil {
+		return product, nil, product.Stock, nil
+	}
+
+	variant, err := s.variantRepo.GetByID(*variantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, 0, errors.New("variant not found")
+		}
+		return nil, nil, 0, err
+	}
+	if variant.ProductID != product.ID {
+		return nil, nil, 0, errors.New("variant does not belong to the specified product")
+	}
+
+	return product, variant, variant.Stock, nil
+}
+
+// snapshotItem prices the line at the customer's type and copies product/variant details onto it
+func (s *cartService) snapshotItem(item *models.CartItem, product *models.Product, variant *models.ProductVariant, quantity int, customerType CustomerType) error {
+	price, err := s.pricingService.CalculatePrice(PriceCalculationRequest{
+		ProductID:    item.ProductID,
+		VariantID:    item.ProductVariantID,
+		Quantity:     quantity,
+		CustomerType: customerType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to calculate price: %w", err)
+	}
+
+	item.DiscountType = price.DiscountType
+	item.ProductName = product.Name
+	item.ProductSKU = product.SKU
+	item.ProductImage = product.Thumbnail
+	item.VariantName = ""
+	item.VariantSize = ""
+	item.VariantColor = ""
+	if variant != nil {
+		item.ProductSKU = variant.SKU
+		item.VariantName = variant.Name
+		item.VariantSize = variant.Size
+		item.VariantColor = variant.Color
+	}
+
+	// FinalPrice is already multiplied by quantity
+	item.Quantity = quantity
+	item.UnitPrice = price.FinalPrice / models.Money(quantity)
+	item.TotalPrice = price.FinalPrice
+
+	return nil
+}
@@ -0,0 +1,483 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockCartRepository for testing
+type MockCartRepository struct {
+	mock.Mock
+}
+
+func (m *MockCartRepository) GetByUserID(userID uint) (*models.Cart, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) GetOrCreateByUserID(userID uint) (*models.Cart, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) GetItem(cartID, itemID uint) (*models.CartItem, error) {
+	args := m.Called(cartID, itemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CartItem), args.Error(1)
+}
+
+func (m *MockCartRepository) FindItem(cartID, productID uint, variantID *uint) (*models.CartItem, error) {
+	args := m.Called(cartID, productID, variantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CartItem), args.Error(1)
+}
+
+func (m *MockCartRepository) AddItem(item *models.CartItem) error {
+	return m.Called(item).Error(0)
+}
+
+func (m *MockCartRepository) UpdateItem(item *models.CartItem) error {
+	return m.Called(item).Error(0)
+}
+
+func (m *MockCartRepository) RemoveItem(cartID, itemID uint) error {
+	return m.Called(cartID, itemID).Error(0)
+}
+
+func (m *MockCartRepository) SaveItems(items []*models.CartItem) error {
+	return m.Called(items).Error(0)
+}
+
+func (m *MockCartRepository) Clear(cartID uint) error {
+	return m.Called(cartID).Error(0)
+}
+
+func (m *MockCartRepository) WithTx(tx *gorm.DB) repository.CartRepository {
+	args := m.Called(tx)
+	return args.Get(0).(repository.CartRepository)
+}
+
+// stubCheckoutService records the last checkout request
+type stubCheckoutService struct {
+	lastRequest *models.CheckoutRequest
+	err         error
+}
+
+func (s *stubCheckoutService) Checkout(req *models.CheckoutRequest) (*models.CheckoutResponse, error) {
+	s.lastRequest = req
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.CheckoutResponse{OrderNumber: "ORD1", OrderID: 1}, nil
+}
+
+func (s *stubCheckoutService) ProcessPaymentNotification(notification *models.MidtransPaymentNotification) error {
+	return nil
+}
+
+// memoryRedis is an in-memory RedisClient for tests that only need key/value storage
+type memoryRedis struct {
+	data map[string]string
+}
+
+func newMemoryRedis() *memoryRedis {
+	return &memoryRedis{data: make(map[string]string)}
+}
+
+func (r *memoryRedis) Get(ctx context.Context, key string) (string, error) {
+	val, ok := r.data[key]
+	if !ok {
+		return "", redis.Nil
+	}
+	return val, nil
+}
+
+func (r *memoryRedis) GetDel(ctx context.Context, key string) (string, error) {
+	val, err := r.Get(ctx, key)
+	delete(r.data, key)
+	return val, err
+}
+
+func (r *memoryRedis) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	r.data[key] = value.(string)
+	return nil
+}
+
+func (r *memoryRedis) GetJSON(ctx context.Context, key string, dest interface{}) error {
+	val, err := r.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(val), dest)
+}
+
+func (r *memoryRedis) SetJSON(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	r.data[key] = string(bytes)
+	return nil
+}
+
+func (r *memoryRedis) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(r.data, key)
+	}
+	return nil
+}
+
+func (r *memoryRedis) Exists(ctx context.Context, keys ...string) (int64, error) {
+	var count int64
+	for _, key := range keys {
+		if _, ok := r.data[key]; ok {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryRedis) FlushDB(ctx context.Context) error {
+	r.data = make(map[string]string)
+	return nil
+}
+
+func (r *memoryRedis) DeleteByPattern(ctx context.Context, pattern string) error { return nil }
+func (r *memoryRedis) HealthCheck(ctx context.Context) error                     { return nil }
+func (r *memoryRedis) PoolStats() map[string]interface{}                         { return nil }
+func (r *memoryRedis) Client() *redis.Client                                     { return nil }
+func (r *memoryRedis) Close() error                                              { return nil }
+
+func newTestCartService() (*cartService, *MockCartRepository, *MockProductRepository, *MockVariantRepository, *MockFlashSaleRepository, *stubCheckoutService) {
+	cartRepo := new(MockCartRepository)
+	productRepo := new(MockProductRepository)
+	variantRepo := new(MockVariantRepository)
+	flashSaleRepo := new(MockFlashSaleRepository)
+	checkout := &stubCheckoutService{}
+
+	pricing := NewPricingService(productRepo, variantRepo, NewPromotionIndex(flashSaleRepo, nil, PromotionIndexConfig{}), newTestDiscountTiers(), new(MockCouponRepository), nil, new(MockShippingZoneRepository), nil)
+	service := NewCartService(cartRepo, productRepo, variantRepo, nil, pricing, checkout, newMemoryRedis(), time.Hour).(*cartService)
+
+	return service, cartRepo, productRepo, variantRepo, flashSaleRepo, checkout
+}
+
+func TestCartService_AddItem_NewLine(t *testing.T) {
+	service, cartRepo, productRepo, _, flashSaleRepo, _ := newTestCartService()
+
+	cart := &models.Cart{ID: 10, UserID: 1}
+	product := &models.Product{ID: 5, Name: "Kemeja", SKU: "KMJ-1", Price: 100000, Stock: 10, Status: models.StatusAvailable}
+
+	cartRepo.On("GetOrCreateByUserID", uint(1)).Return(cart, nil)
+	cartRepo.On("FindItem", uint(10), uint(5), (*uint)(nil)).Return(nil, gorm.ErrRecordNotFound)
+	productRepo.On("GetByID", uint(5)).Return(product, nil)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil)
+	cartRepo.On("AddItem", mock.MatchedBy(func(item *models.CartItem) bool {
+		return item.CartID == 10 && item.Quantity == 2 && item.UnitPrice == 100000 && item.TotalPrice == 200000 && item.ProductName == "Kemeja"
+	})).Return(nil)
+	cartRepo.On("GetByUserID", uint(1)).Return(cart, nil)
+
+	_, err := service.AddItem(1, &models.AddToCartRequest{ProductID: 5, Quantity: 2})
+	assert.NoError(t, err)
+	cartRepo.AssertExpectations(t)
+}
+
+func TestCartService_AddItem_ResellerPrice(t *testing.T) {
+	service, cartRepo, productRepo, _, flashSaleRepo, _ := newTestCartService()
+	userRepo := new(MockUserRepository)
+	service.userRepo = userRepo
+
+	cart := &models.Cart{ID: 10, UserID: 1}
+	product := &models.Product{ID: 5, Name: "Kemeja", Price: 100000, Stock: 50, Status: models.StatusAvailable}
+
+	userRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1, IsActive: true, CustomerGroup: models.CustomerGroupReseller}, nil)
+	cartRepo.On("GetOrCreateByUserID", uint(1)).Return(cart, nil)
+	cartRepo.On("FindItem", uint(10), uint(5), (*uint)(nil)).Return(nil, gorm.ErrRecordNotFound)
+	productRepo.On("GetByID", uint(5)).Return(product, nil)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil)
+	// 20 pieces reach the 20% reseller tier
+	cartRepo.On("AddItem", mock.MatchedBy(func(item *models.CartItem) bool {
+		return item.Quantity == 20 && item.UnitPrice == 80000 && item.DiscountType == "reseller"
+	})).Return(nil)
+	cartRepo.On("GetByUserID", uint(1)).Return(cart, nil)
+
+	_, err := service.AddItem(1, &models.AddToCartRequest{ProductID: 5, Quantity: 20})
+	assert.NoError(t, err)
+	cartRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+}
+
+func TestCartService_AddItem_MergesExistingLine(t *testing.T) {
+	service, cartRepo, productRepo, _, flashSaleRepo, _ := newTestCartService()
+
+	cart := &models.Cart{ID: 10, UserID: 1}
+	existing := &models.CartItem{ID: 3, CartID: 10, ProductID: 5, Quantity: 3}
+	product := &models.Product{ID: 5, Name: "Kemeja", Price: 100000, Stock: 10, Status: models.StatusAvailable}
+
+	cartRepo.On("GetOrCreateByUserID", uint(1)).Return(cart, nil)
+	cartRepo.On("FindItem", uint(10), uint(5), (*uint)(nil)).Return(existing, nil)
+	productRepo.On("GetByID", uint(5)).Return(product, nil)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil)
+	cartRepo.On("UpdateItem", mock.MatchedBy(func(item *models.CartItem) bool {
+		return item.ID == 3 && item.Quantity == 5
+	})).Return(nil)
+	cartRepo.On("GetByUserID", uint(1)).Return(cart, nil)
+
+	_, err := service.AddItem(1, &models.AddToCartRequest{ProductID: 5, Quantity: 2})
+	assert.NoError(t, err)
+	cartRepo.AssertExpectations(t)
+}
+
+func TestCartService_AddItem_InsufficientVariantStock(t *testing.T) {
+	service, cartRepo, productRepo, variantRepo, _, _ := newTestCartService()
+
+	variantID := uint(7)
+	cart := &models.Cart{ID: 10, UserID: 1}
+	product := &models.Product{ID: 5, Name: "Kemeja", Price: 100000, Stock: 50, Status: models.StatusAvailable}
+	variant := &models.ProductVariant{ID: 7, ProductID: 5, Name: "M - Putih", Price: 110000, Stock: 1}
+
+	cartRepo.On("GetOrCreateByUserID", uint(1)).Return(cart, nil)
+	cartRepo.On("FindItem", uint(10), uint(5), &variantID).Return(nil, gorm.ErrRecordNotFound)
+	productRepo.On("GetByID", uint(5)).Return(product, nil)
+	variantRepo.On("GetByID", uint(7)).Return(variant, nil)
+
+	_, err := service.AddItem(1, &models.AddToCartRequest{ProductID: 5, VariantID: 7, Quantity: 2})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "insufficient stock")
+	cartRepo.AssertNotCalled(t, "AddItem", mock.Anything)
+}
+
+func TestCartService_AddItem_UnavailableProduct(t *testing.T) {
+	service, cartRepo, productRepo, _, _, _ := newTestCartService()
+
+	cart := &models.Cart{ID: 10, UserID: 1}
+	product := &models.Product{ID: 5, Name: "Kemeja", Price: 100000, Stock: 10, Status: models.StatusDiscontinued}
+
+	cartRepo.On("GetOrCreateByUserID", uint(1)).Return(cart, nil)
+	cartRepo.On("FindItem", uint(10), uint(5), (*uint)(nil)).Return(nil, gorm.ErrRecordNotFound)
+	productRepo.On("GetByID", uint(5)).Return(product, nil)
+
+	_, err := service.AddItem(1, &models.AddToCartRequest{ProductID: 5, Quantity: 1})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not available")
+}
+
+func TestCartService_RemoveItem_NotFound(t *testing.T) {
+	service, cartRepo, _, _, _, _ := newTestCartService()
+
+	cartRepo.On("GetOrCreateByUserID", uint(1)).Return(&models.Cart{ID: 10, UserID: 1}, nil)
+	cartRepo.On("RemoveItem", uint(10), uint(99)).Return(gorm.ErrRecordNotFound)
+
+	_, err := service.RemoveItem(1, 99)
+	assert.EqualError(t, err, "cart item not found")
+}
+
+func TestCartService_CheckoutFromCart(t *testing.T) {
+	t.Run("Empty cart", func(t *testing.T) {
+		service, cartRepo, _, _, _, checkout := newTestCartService()
+		cartRepo.On("GetOrCreateByUserID", uint(1)).Return(&models.Cart{ID: 10, UserID: 1}, nil)
+
+		_, err := service.CheckoutFromCart(1, &models.CartCheckoutRequest{})
+		assert.EqualError(t, err, "cart is empty")
+		assert.Nil(t, checkout.lastRequest)
+	})
+
+	t.Run("Builds request and clears cart", func(t *testing.T) {
+		service, cartRepo, _, _, _, checkout := newTestCartService()
+
+		variantID := uint(7)
+		cart := &models.Cart{ID: 10, UserID: 1, Items: []models.CartItem{
+			{ID: 1, ProductID: 5, Quantity: 2},
+			{ID: 2, ProductID: 6, ProductVariantID: &variantID, Quantity: 1},
+		}}
+		cartRepo.On("GetOrCreateByUserID", uint(1)).Return(cart, nil)
+		cartRepo.On("Clear", uint(10)).Return(nil)
+
+		resp, err := service.CheckoutFromCart(1, &models.CartCheckoutRequest{ShippingCity: "Bandung", PaymentMethod: "bank_transfer"})
+		assert.NoError(t, err)
+		assert.Equal(t, "ORD1", resp.OrderNumber)
+		assert.Equal(t, uint(1), checkout.lastRequest.UserID)
+		assert.Len(t, checkout.lastRequest.Items, 2)
+		assert.Equal(t, uint(7), checkout.lastRequest.Items[1].VariantID)
+		cartRepo.AssertCalled(t, "Clear", uint(10))
+	})
+
+	t.Run("Checkout failure keeps cart", func(t *testing.T) {
+		service, cartRepo, _, _, _, checkout := newTestCartService()
+		checkout.err = errors.New("stock reservation failed")
+
+		cart := &models.Cart{ID: 10, UserID: 1, Items: []models.CartItem{{ID: 1, ProductID: 5, Quantity: 2}}}
+		cartRepo.On("GetOrCreateByUserID", uint(1)).Return(cart, nil)
+
+		_, err := service.CheckoutFromCart(1, &models.CartCheckoutRequest{})
+		assert.Error(t, err)
+		cartRepo.AssertNotCalled(t, "Clear", mock.Anything)
+	})
+}
+
+func TestCartService_GuestCart_AddAndMerge(t *testing.T) {
+	service, cartRepo, productRepo, _, flashSaleRepo, _ := newTestCartService()
+
+	product := &models.Product{ID: 5, Name: "Kemeja", Price: 100000, Stock: 4, Status: models.StatusAvailable}
+	discontinued := &models.Product{ID: 6, Name: "Rok", Price: 80000, Stock: 10, Status: models.StatusDiscontinued}
+	productRepo.On("GetByID", uint(5)).Return(product, nil)
+	productRepo.On("GetByID", uint(6)).Return(discontinued, nil)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil)
+
+	guestID, err := service.NewGuestCartID()
+	assert.NoError(t, err)
+
+	cart, err := service.AddGuestItem(guestID, &models.AddToCartRequest{ProductID: 5, Quantity: 3})
+	assert.NoError(t, err)
+	assert.Len(t, cart.Items, 1)
+	assert.Equal(t, uint(1), cart.Items[0].ID)
+
+	// Product 6 was available when added, then discontinued before login
+	guestCart, _ := service.GetGuestCart(guestID)
+	guestCart.Items = append(guestCart.Items, models.CartItem{ID: 2, ProductID: 6, ProductName: "Rok", Quantity: 1})
+	assert.NoError(t, service.saveGuestCart(context.Background(), guestID, guestCart))
+
+	// User already has 2 of product 5; 2 + 3 exceeds stock of 4
+	userCart := &models.Cart{ID: 10, UserID: 1}
+	existing := &models.CartItem{ID: 7, CartID: 10, ProductID: 5, Quantity: 2}
+	cartRepo.On("GetOrCreateByUserID", uint(1)).Return(userCart, nil)
+	cartRepo.On("FindItem", uint(10), uint(5), (*uint)(nil)).Return(existing, nil)
+	cartRepo.On("SaveItems", mock.MatchedBy(func(items []*models.CartItem) bool {
+		return len(items) == 1 && items[0].ID == 7 && items[0].Quantity == 4
+	})).Return(nil).Once()
+
+	result, err := service.MergeGuestCart(guestID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.MergedItems)
+	assert.Equal(t, []string{"Kemeja"}, result.AdjustedItems)
+	assert.Equal(t, []string{"Rok"}, result.SkippedItems)
+	cartRepo.AssertExpectations(t)
+
+	// Guest cart is gone after merge, so merging again adds nothing
+	cart, err = service.GetGuestCart(guestID)
+	assert.NoError(t, err)
+	assert.Empty(t, cart.Items)
+
+	result, err = service.MergeGuestCart(guestID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.MergedItems)
+	cartRepo.AssertNumberOfCalls(t, "SaveItems", 1)
+}
+
+func TestCartService_GuestCart_MergeFailureKeepsGuestCart(t *testing.T) {
+	service, cartRepo, productRepo, _, flashSaleRepo, _ := newTestCartService()
+
+	product := &models.Product{ID: 5, Name: "Kemeja", Price: 100000, Stock: 10, Status: models.StatusAvailable}
+	productRepo.On("GetByID", uint(5)).Return(product, nil)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil)
+
+	guestID, err := service.NewGuestCartID()
+	assert.NoError(t, err)
+	_, err = service.AddGuestItem(guestID, &models.AddToCartRequest{ProductID: 5, Quantity: 2})
+	assert.NoError(t, err)
+
+	cartRepo.On("GetOrCreateByUserID", uint(1)).Return(&models.Cart{ID: 10, UserID: 1}, nil)
+	cartRepo.On("FindItem", uint(10), uint(5), (*uint)(nil)).Return(nil, gorm.ErrRecordNotFound)
+	cartRepo.On("SaveItems", mock.Anything).Return(errors.New("connection reset"))
+
+	_, err = service.MergeGuestCart(guestID, 1)
+	assert.EqualError(t, err, "failed to merge guest cart: connection reset")
+
+	// Nothing was saved, so the guest cart is kept for the next attempt
+	cart, err := service.GetGuestCart(guestID)
+	assert.NoError(t, err)
+	assert.Len(t, cart.Items, 1)
+	assert.Equal(t, 2, cart.Items[0].Quantity)
+}
+
+func TestCartService_GuestCart_RemoveMissingItem(t *testing.T) {
+	service, _, _, _, _, _ := newTestCartService()
+
+	_, err := service.RemoveGuestItem("abc", 1)
+	assert.EqualError(t, err, "cart item not found")
+}
+
+func TestCartService_GuestCart_UnreadableCart(t *testing.T) {
+	service, _, _, _, _, _ := newTestCartService()
+	service.redis.(*memoryRedis).data[guestCartKey("abc")] = "{not json"
+
+	// A cart that exists but can't be read must not be replaced with an empty one
+	_, err := service.GetGuestCart("abc")
+	assert.Error(t, err)
+
+	_, err = service.AddGuestItem("abc", &models.AddToCartRequest{ProductID: 1, Quantity: 1})
+	assert.Error(t, err)
+	assert.Equal(t, "{not json", service.redis.(*memoryRedis).data[guestCartKey("abc")])
+}
+
+func TestCartService_RevalidateCart(t *testing.T) {
+	service, cartRepo, productRepo, variantRepo, flashSaleRepo, _ := newTestCartService()
+
+	variantID := uint(9)
+	cart := &models.Cart{ID: 10, UserID: 1, Items: []models.CartItem{
+		// Price went up from 90,000 to 100,000
+		{ID: 1, ProductID: 1, ProductName: "Kemeja", Quantity: 1, UnitPrice: 90000, TotalPrice: 90000, DiscountType: "none"},
+		// Was bought at flash sale price, sale is over
+		{ID: 2, ProductID: 2, ProductName: "Celana", Quantity: 1, UnitPrice: 50000, TotalPrice: 50000, DiscountType: "flash_sale"},
+		// Discontinued
+		{ID: 3, ProductID: 3, ProductName: "Rok", Quantity: 1, UnitPrice: 80000, TotalPrice: 80000, DiscountType: "none"},
+		// Variant sold out
+		{ID: 4, ProductID: 4, ProductVariantID: &variantID, ProductName: "Dress", Quantity: 1, UnitPrice: 150000, TotalPrice: 150000, DiscountType: "none"},
+		// Unchanged, but only 1 left for a quantity of 2
+		{ID: 5, ProductID: 5, ProductName: "Topi", Quantity: 2, UnitPrice: 30000, TotalPrice: 60000, DiscountType: "none"},
+	}}
+
+	cartRepo.On("GetOrCreateByUserID", uint(1)).Return(cart, nil)
+	productRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1, Name: "Kemeja", Price: 100000, Stock: 5, Status: models.StatusAvailable}, nil)
+	productRepo.On("GetByID", uint(2)).Return(&models.Product{ID: 2, Name: "Celana", Price: 70000, Stock: 5, Status: models.StatusAvailable}, nil)
+	productRepo.On("GetByID", uint(3)).Return(&models.Product{ID: 3, Name: "Rok", Price: 80000, Stock: 5, Status: models.StatusDiscontinued}, nil)
+	productRepo.On("GetByID", uint(4)).Return(&models.Product{ID: 4, Name: "Dress", Price: 150000, Stock: 5, Status: models.StatusAvailable}, nil)
+	productRepo.On("GetByID", uint(5)).Return(&models.Product{ID: 5, Name: "Topi", Price: 30000, Stock: 1, Status: models.StatusAvailable}, nil)
+	variantRepo.On("GetByID", uint(9)).Return(&models.ProductVariant{ID: 9, ProductID: 4, Price: 150000, Stock: 0}, nil)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil)
+	cartRepo.On("UpdateItem", mock.Anything).Return(nil)
+
+	_, warnings, err := service.RevalidateCart(1)
+	assert.NoError(t, err)
+
+	byItem := make(map[uint][]CartWarningType)
+	for _, w := range warnings {
+		byItem[w.ItemID] = append(byItem[w.ItemID], w.Type)
+	}
+
+	assert.Equal(t, []CartWarningType{CartWarningPriceChanged}, byItem[1])
+	assert.Equal(t, []CartWarningType{CartWarningFlashSaleEnded}, byItem[2])
+	assert.Equal(t, []CartWarningType{CartWarningDiscontinued}, byItem[3])
+	assert.Equal(t, []CartWarningType{CartWarningOutOfStock}, byItem[4])
+	assert.Equal(t, []CartWarningType{CartWarningInsufficientStock}, byItem[5])
+
+	for _, w := range warnings {
+		if w.Type == CartWarningFlashSaleEnded {
+			assert.Equal(t, models.Money(50000), w.OldPrice)
+			assert.Equal(t, models.Money(70000), w.NewPrice)
+		}
+	}
+
+	// Repriced lines are persisted, unavailable ones are left alone
+	assert.Equal(t, models.Money(100000), cart.Items[0].UnitPrice)
+	cartRepo.AssertNumberOfCalls(t, "UpdateItem", 3)
+}
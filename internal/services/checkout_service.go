@@ -1,10 +1,14 @@
 package services
 
 import (
+	"crypto/rand"
 	"crypto/sha512"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/karima-store/internal/database"
@@ -25,9 +29,16 @@ type checkoutService struct {
 	productRepo         repository.ProductRepository
 	variantRepo         repository.VariantRepository
 	stockLogRepo        repository.StockLogRepository
+	couponRepo          repository.CouponRepository
 	pricingService      PricingService
+	komerceService      KomerceService
+	flashSaleStock      FlashSaleStockService
 	notificationService NotificationService
+	recovery            AbandonedCartService // optional, attributes paid orders to cart reminders
 	midtransConfig      *MidtransConfig
+
+	// shipperDestinationID is the Komerce destination parcels ship from
+	shipperDestinationID string
 }
 
 type MidtransConfig struct {
@@ -46,19 +57,29 @@ func NewCheckoutService(
 	productRepo repository.ProductRepository,
 	variantRepo repository.VariantRepository,
 	stockLogRepo repository.StockLogRepository,
+	couponRepo repository.CouponRepository,
 	pricingService PricingService,
+	komerceService KomerceService,
+	shipperDestinationID string,
+	flashSaleStock FlashSaleStockService,
 	notificationService NotificationService,
+	recovery AbandonedCartService,
 	midtransConfig *MidtransConfig,
 ) CheckoutService {
 	return &checkoutService{
-		db:                  db,
-		orderRepo:           orderRepo,
-		productRepo:         productRepo,
-		variantRepo:         variantRepo,
-		stockLogRepo:        stockLogRepo,
-		pricingService:      pricingService,
-		notificationService: notificationService,
-		midtransConfig:      midtransConfig,
+		db:                   db,
+		orderRepo:            orderRepo,
+		productRepo:          productRepo,
+		variantRepo:          variantRepo,
+		stockLogRepo:         stockLogRepo,
+		couponRepo:           couponRepo,
+		pricingService:       pricingService,
+		komerceService:       komerceService,
+		flashSaleStock:       flashSaleStock,
+		notificationService:  notificationService,
+		recovery:             recovery,
+		midtransConfig:       midtransConfig,
+		shipperDestinationID: shipperDestinationID,
 	}
 }
 
@@ -81,44 +102,108 @@ func (s *checkoutService) Checkout(req *models.CheckoutRequest) (*models.Checkou
 	}
 
 	var shippingItems []ShippingItem
+	products := make(map[uint]*models.Product)
+	variants := make(map[uint]*models.ProductVariant)
 	for _, priceReq := range priceReqItems {
 		product, err := s.productRepo.GetByID(priceReq.ProductID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get product %d: %w", priceReq.ProductID, err)
 		}
+		products[product.ID] = product
+		if priceReq.VariantID != nil {
+			variant, err := s.variantRepo.GetByID(*priceReq.VariantID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get variant %d: %w", *priceReq.VariantID, err)
+			}
+			variants[variant.ID] = variant
+		}
 		shippingItems = append(shippingItems, ShippingItem{
-			Weight:   product.Weight,
-			Quantity: priceReq.Quantity,
+			ProductID:  product.ID,
+			VariantID:  priceReq.VariantID,
+			Weight:     product.Weight,
+			Dimensions: product.Dimensions,
+			Quantity:   priceReq.Quantity,
 		})
 	}
 
+	destination, err := s.receiverDestination(req)
+	if err != nil {
+		return nil, err
+	}
+
+	customerType := CustomerRetail
+	if req.Reseller {
+		customerType = CustomerReseller
+	}
+
+	// Price the goods first, so Komerce quotes insurance and the COD fee on what the customer pays.
+	// The rate comes from the quote; the destination it was quoted for picks the tax region.
 	shippingReq := ShippingCalculationRequest{
-		Items:        shippingItems,
-		Destination:  req.ShippingCity,
-		ShippingType: "jne",
+		Items:       shippingItems,
+		Destination: destination.CityName,
+		QuotedCost:  new(models.Money),
+	}
+	orderSummary, err := s.summarizeOrder(req, priceReqItems, shippingReq, customerType)
+	if err != nil {
+		return nil, err
 	}
 
-	customerType := CustomerRetail // Default to retail
-	orderSummary, err := s.pricingService.CalculateOrderSummary(priceReqItems, shippingReq, customerType)
+	quote, err := s.quoteShipping(req, shippingItems, orderSummary.goodsAmount())
 	if err != nil {
-		return nil, fmt.Errorf("failed to calculate order summary: %w", err)
+		return nil, err
 	}
 
-	orderNumber := s.generateOrderNumber()
+	// Price again with the quoted rate, since shipping discounts and taxes depend on it
+	shippingReq.ShippingType = strings.ToLower(quote.Courier)
+	shippingReq.QuotedCost = &quote.Cost
+	orderSummary, err = s.summarizeOrder(req, priceReqItems, shippingReq, customerType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gifts from promotions are order lines too, so their stock is reserved with the rest
+	for _, promotion := range orderSummary.Promotions {
+		for _, gift := range promotion.Gifts {
+			if _, ok := products[gift.ProductID]; ok {
+				continue
+			}
+			product, err := s.productRepo.GetByID(gift.ProductID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get product %d: %w", gift.ProductID, err)
+			}
+			products[product.ID] = product
+		}
+	}
+
+	orderNumber, err := generateOrderNumber()
+	if err != nil {
+		return nil, err
+	}
 	order := &models.Order{
 		OrderNumber:      orderNumber,
 		UserID:           req.UserID,
 		PaymentMethod:    models.PaymentMethod(req.PaymentMethod),
 		Subtotal:         orderSummary.Subtotal,
-		Discount:         orderSummary.TotalDiscount,
-		ShippingCost:     orderSummary.ShippingCost,
+		Discount:         orderSummary.TotalDiscount + orderSummary.PromotionDiscount,
+		ShippingCost:     orderSummary.ShippingCost - orderSummary.ShippingDiscount,
 		Tax:              orderSummary.TaxAmount,
 		TotalAmount:      orderSummary.Total,
+		CouponDiscount:   orderSummary.CouponDiscount,
 		ShippingName:     req.ShippingName,
-		ShippingProvider: "JNE",
+		ShippingProvider: quote.Courier,
 		Status:           models.StatusPending,
 		PaymentStatus:    models.PaymentPending,
-		Items:            s.createOrderItems(priceReqItems, orderSummary),
+		Items:            s.createOrderItems(orderSummary, products, variants),
+		Taxes:            createOrderTaxes(orderSummary),
+
+		ShippingService:       quote.Service,
+		ShippingETD:           quote.ETD,
+		ShippingDestinationID: req.ReceiverDestinationID,
+	}
+
+	// Take flash sale units before touching the database, so an oversold sale fails fast
+	if err := s.reserveFlashSaleStock(order); err != nil {
+		return nil, err
 	}
 
 	// 2. Execution Phase: DB Transaction (Write)
@@ -129,6 +214,20 @@ func (s *checkoutService) Checkout(req *models.CheckoutRequest) (*models.Checkou
 		txProductRepo := s.productRepo.WithTx(tx)
 		txStockLogRepo := s.stockLogRepo.WithTx(tx)
 		txOrderRepo := s.orderRepo.WithTx(tx)
+		txCouponRepo := s.couponRepo.WithTx(tx)
+
+		// Lock the coupon first and check its limits again, so two checkouts cannot both take
+		// its last redemption
+		var coupon *models.Coupon
+		if orderSummary.CouponApplied {
+			var err error
+			coupon, err = s.lockCouponWithTx(txCouponRepo, orderSummary, req.UserID, customerType)
+			if err != nil {
+				return err
+			}
+			order.CouponID = &coupon.ID
+			order.CouponCode = coupon.Code
+		}
 
 		// A. Deduct Stock (Reservation)
 		// We use the same method 'reduceStockWithTx' but must ensure it checks for negative stock.
@@ -141,6 +240,12 @@ func (s *checkoutService) Checkout(req *models.CheckoutRequest) (*models.Checkou
 			return fmt.Errorf("failed to create order: %w", err)
 		}
 
+		if coupon != nil {
+			if err := txCouponRepo.RecordUsage(coupon.ID, req.UserID, order.ID, order.CouponDiscount); err != nil {
+				return fmt.Errorf("failed to record coupon usage: %w", err)
+			}
+		}
+
 		// C. Generate Snap Token (External API Call)
 		// If this fails, the entire transaction (stock deduction + order creation) will be rolled back
 		snapToken, err = s.generateSnapToken(order, priceReqItems, req)
@@ -152,6 +257,7 @@ func (s *checkoutService) Checkout(req *models.CheckoutRequest) (*models.Checkou
 	})
 
 	if err != nil {
+		s.releaseFlashSaleStock(order.OrderNumber)
 		return nil, err
 	}
 
@@ -174,10 +280,98 @@ func (s *checkoutService) Checkout(req *models.CheckoutRequest) (*models.Checkou
 	}, nil
 }
 
+// summarizeOrder prices the checkout items and applies the request's coupon
+func (s *checkoutService) summarizeOrder(req *models.CheckoutRequest, items []PriceCalculationRequest, shippingReq ShippingCalculationRequest, customerType CustomerType) (*OrderSummary, error) {
+	orderSummary, err := s.pricingService.CalculateOrderSummary(items, shippingReq, customerType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate order summary: %w", err)
+	}
+
+	if req.CouponCode != "" {
+		couponReq := CouponCalculationRequest{
+			Code:         req.CouponCode,
+			UserID:       req.UserID,
+			CustomerType: customerType,
+		}
+		if err := s.pricingService.ApplyCouponToOrderSummary(orderSummary, couponReq); err != nil {
+			return nil, err
+		}
+	}
+	return orderSummary, nil
+}
+
+// receiverDestination looks up the Komerce destination the order is quoted for by the shipping
+// postal code, so the address the client sends cannot put the order in another tax region
+func (s *checkoutService) receiverDestination(req *models.CheckoutRequest) (*models.KomerceDestination, error) {
+	if req.ReceiverDestinationID == "" {
+		return nil, errors.New("receiver_destination_id, shipping_courier and shipping_service are required")
+	}
+	if s.komerceService == nil {
+		return nil, errors.New("failed to quote shipping: shipper destination is not configured")
+	}
+
+	destinations, err := s.komerceService.SearchDestination(req.ShippingPostalCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up receiver destination: %w", err)
+	}
+	for i := range destinations {
+		if destinations[i].ID == req.ReceiverDestinationID {
+			return &destinations[i], nil
+		}
+	}
+	return nil, errors.New("receiver_destination_id does not match the shipping postal code")
+}
+
+// courierQuote is the Komerce rate of the courier service a customer picked
+type courierQuote struct {
+	Courier string
+	Service string
+	ETD     string
+	Cost    models.Money
+}
+
+// quoteShipping quotes the customer's courier service with Komerce again, so the order is never
+// charged a rate sent by the client
+func (s *checkoutService) quoteShipping(req *models.CheckoutRequest, items []ShippingItem, itemValue models.Money) (*courierQuote, error) {
+	if req.ReceiverDestinationID == "" || req.ShippingCourier == "" || req.ShippingService == "" {
+		return nil, errors.New("receiver_destination_id, shipping_courier and shipping_service are required")
+	}
+	if s.komerceService == nil || s.shipperDestinationID == "" {
+		return nil, errors.New("failed to quote shipping: shipper destination is not configured")
+	}
+
+	// Komerce takes whole kg, so round the chargeable weight up rather than under-quote
+	weight := math.Max(1, math.Ceil(weighParcel(items, req.ShippingCourier).chargeable))
+	cod := "no"
+	if req.PaymentMethod == "cod" {
+		cod = "yes"
+	}
+
+	resp, err := s.komerceService.CalculateShippingCost(s.shipperDestinationID, req.ReceiverDestinationID, weight, int(itemValue), cod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote shipping: %w", err)
+	}
+
+	for _, options := range [][]models.KomerceShippingOption{resp.Data.CalculateReguler, resp.Data.CalculateCargo} {
+		for _, option := range options {
+			if strings.EqualFold(option.ShippingName, req.ShippingCourier) && strings.EqualFold(option.ServiceName, req.ShippingService) {
+				return &courierQuote{
+					Courier: option.ShippingName,
+					Service: option.ServiceName,
+					ETD:     option.ETD,
+					Cost:    models.Money(option.ShippingCost),
+				}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("shipping service %s %s is not available for this destination", req.ShippingCourier, req.ShippingService)
+}
+
 // verifySignature verifies Midtrans webhook signature
 func (s *checkoutService) verifySignature(notification *models.MidtransPaymentNotification) bool {
-	// Signature format: SHA512(order_id + status_code + gross_amount + server_key)
-	data := fmt.Sprintf("%s%s%.2f%s",
+	// Signature format: SHA512(order_id + status_code + gross_amount + server_key). Midtrans
+	// formats IDR amounts with two decimals, which are always zero.
+	data := fmt.Sprintf("%s%s%s.00%s",
 		notification.OrderID,
 		notification.StatusCode,
 		notification.GrossAmount,
@@ -199,13 +393,19 @@ func (s *checkoutService) ProcessPaymentNotification(notification *models.Midtra
 	// Get DB instance for transaction
 	db := s.db.DB()
 
-	return db.Transaction(func(tx *gorm.DB) error {
+	// Flash sale bookkeeping and conversion tracking happen once the order update is committed
+	var releaseFlashSale bool
+	var reconcileFlashSales []uint
+	var paidOrder *models.Order
+
+	err := db.Transaction(func(tx *gorm.DB) error {
 		// Create transaction-aware repositories
 		txOrderRepo := s.orderRepo.WithTx(tx)
 		// txProductRepo is only needed for restore
 		// txStockLogRepo is only needed for restore
 		txProductRepo := s.productRepo.WithTx(tx)
 		txStockLogRepo := s.stockLogRepo.WithTx(tx)
+		txCouponRepo := s.couponRepo.WithTx(tx)
 
 		// Get order by order number with transaction (using FOR UPDATE if needed, but simple Get here is mostly fine unless high concurrency on same order)
 		order, err := txOrderRepo.GetByOrderNumber(notification.OrderID)
@@ -213,8 +413,12 @@ func (s *checkoutService) ProcessPaymentNotification(notification *models.Midtra
 			return fmt.Errorf("order not found: %s", notification.OrderID)
 		}
 
-		// Idempotency check: if status is already final, ignore
-		if order.PaymentStatus == models.PaymentPaid || order.Status == models.StatusCancelled {
+		// Idempotency check: cancelled and refunded orders are final, and a paid order only
+		// changes again when it is refunded
+		if order.Status == models.StatusCancelled || order.PaymentStatus == models.PaymentRefunded {
+			return nil
+		}
+		if order.PaymentStatus == models.PaymentPaid && notification.TransactionStatus != "refund" {
 			return nil
 		}
 
@@ -234,6 +438,8 @@ func (s *checkoutService) ProcessPaymentNotification(notification *models.Midtra
 				}
 
 				// NOTE: Stock already deducted at Checkout. No need to deduct here.
+				reconcileFlashSales = orderFlashSaleIDs(order)
+				paidOrder = order
 
 				// Send payment success notification
 				defer func() {
@@ -261,6 +467,10 @@ func (s *checkoutService) ProcessPaymentNotification(notification *models.Midtra
 				if err := s.restoreStockWithTx(txProductRepo, txStockLogRepo, order); err != nil {
 					return err
 				}
+				if err := s.revokeCouponWithTx(txCouponRepo, order); err != nil {
+					return err
+				}
+				releaseFlashSale = true
 			}
 		case "refund":
 			// Payment refunded
@@ -276,6 +486,11 @@ func (s *checkoutService) ProcessPaymentNotification(notification *models.Midtra
 				if err := s.restoreStockWithTx(txProductRepo, txStockLogRepo, order); err != nil {
 					return err
 				}
+				if err := s.revokeCouponWithTx(txCouponRepo, order); err != nil {
+					return err
+				}
+				releaseFlashSale = true
+				reconcileFlashSales = orderFlashSaleIDs(order)
 			}
 		default:
 			// Just return, no error to avoid retry storm from webhook
@@ -284,11 +499,72 @@ func (s *checkoutService) ProcessPaymentNotification(notification *models.Midtra
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if releaseFlashSale {
+		s.releaseFlashSaleStock(notification.OrderID)
+	}
+	if len(reconcileFlashSales) > 0 && s.flashSaleStock != nil {
+		if err := s.flashSaleStock.Reconcile(reconcileFlashSales); err != nil {
+			log.Printf("Failed to reconcile flash sales for order %s: %v", notification.OrderID, err)
+		}
+	}
+	// An order only counts as recovered by a cart reminder once it is paid
+	if paidOrder != nil && s.recovery != nil {
+		if err := s.recovery.RecordConversion(paidOrder.UserID, paidOrder.ID, paidOrder.TotalAmount); err != nil {
+			log.Printf("Failed to record cart recovery for order %s: %v", paidOrder.OrderNumber, err)
+		}
+	}
+	return nil
+}
+
+// lockCouponWithTx locks the summary's coupon row and validates it again while the lock is held.
+// Usage recorded by other checkouts is only visible once they commit, so the limits hold.
+func (s *checkoutService) lockCouponWithTx(
+	couponRepo repository.CouponRepository,
+	summary *OrderSummary,
+	userID uint,
+	customerType CustomerType,
+) (*models.Coupon, error) {
+	code := *summary.CouponCode
+	if _, err := couponRepo.LockByCode(code); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("coupon not found")
+		}
+		return nil, fmt.Errorf("failed to lock coupon: %w", err)
+	}
+
+	coupon, err := couponRepo.ValidateCoupon(code, userID, summary.Subtotal-summary.TotalDiscount, string(customerType))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("coupon %s is no longer available", code)
+		}
+		return nil, fmt.Errorf("failed to validate coupon: %w", err)
+	}
+	return coupon, nil
 }
 
-// generateOrderNumber generates a unique order number
-func (s *checkoutService) generateOrderNumber() string {
-	return "ORD" + time.Now().Format("20060102150405")
+// revokeCouponWithTx gives the order's coupon redemption back when the order will not be paid
+func (s *checkoutService) revokeCouponWithTx(couponRepo repository.CouponRepository, order *models.Order) error {
+	if order.CouponID == nil {
+		return nil
+	}
+	if err := couponRepo.RevokeUsage(order.ID); err != nil {
+		return fmt.Errorf("failed to revoke coupon usage: %w", err)
+	}
+	return nil
+}
+
+// generateOrderNumber generates a unique order number. The random suffix keeps checkouts in the
+// same second apart, since flash sale reservations are keyed by the order number too.
+func generateOrderNumber() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate order number: %w", err)
+	}
+	return "ORD" + time.Now().Format("20060102150405") + "-" + strings.ToUpper(hex.EncodeToString(b)), nil
 }
 
 // reduceStockWithTx reduces stock and logs changes
@@ -322,7 +598,7 @@ func (s *checkoutService) reduceStockWithTx(
 		// Create log
 		log := &models.StockLog{
 			ProductID:     item.ProductID,
-			VariantID:     nil, // OrderItem might need VariantID if we track variants
+			VariantID:     item.VariantID,
 			ChangeAmount:  changeAmount,
 			PreviousStock: previousStock,
 			NewStock:      newStock,
@@ -362,7 +638,7 @@ func (s *checkoutService) restoreStockWithTx(
 		// Create log
 		log := &models.StockLog{
 			ProductID:     item.ProductID,
-			VariantID:     nil,
+			VariantID:     item.VariantID,
 			ChangeAmount:  changeAmount,
 			PreviousStock: previousStock,
 			NewStock:      newStock,
@@ -377,21 +653,114 @@ func (s *checkoutService) restoreStockWithTx(
 	return nil
 }
 
-// createOrderItems creates order items from checkout items
-func (s *checkoutService) createOrderItems(items []PriceCalculationRequest, orderSummary *OrderSummary) []models.OrderItem {
+// createOrderItems creates order items from the priced lines of the order summary, followed by
+// the free gifts of its promotions
+func (s *checkoutService) createOrderItems(
+	orderSummary *OrderSummary,
+	products map[uint]*models.Product,
+	variants map[uint]*models.ProductVariant,
+) []models.OrderItem {
 	var orderItems []models.OrderItem
-	for _, item := range items {
+	for _, item := range orderSummary.Items {
 		orderItem := models.OrderItem{
-			ProductID:  item.ProductID,
-			Quantity:   item.Quantity,
-			UnitPrice:  0, // Placeholder
-			TotalPrice: 0, // Placeholder
+			ProductID:   item.ProductID,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			TotalPrice:  item.TotalPrice,
+			FlashSaleID: item.FlashSaleID,
+
+			FlashSaleProductID: item.FlashSaleProductID,
+		}
+		if product, ok := products[item.ProductID]; ok {
+			orderItem.ProductName = product.Name
+			orderItem.ProductSKU = product.SKU
+		}
+		if item.VariantID != nil {
+			orderItem.VariantID = item.VariantID
+			if variant, ok := variants[*item.VariantID]; ok {
+				orderItem.VariantName = variant.Name
+				orderItem.VariantSize = variant.Size
+				orderItem.VariantColor = variant.Color
+			}
 		}
 		orderItems = append(orderItems, orderItem)
 	}
+
+	for _, promotion := range orderSummary.Promotions {
+		for _, gift := range promotion.Gifts {
+			orderItem := models.OrderItem{
+				ProductID:   gift.ProductID,
+				ProductName: gift.Name,
+				Quantity:    gift.Quantity,
+			}
+			if product, ok := products[gift.ProductID]; ok {
+				orderItem.ProductSKU = product.SKU
+			}
+			orderItems = append(orderItems, orderItem)
+		}
+	}
 	return orderItems
 }
 
+// createOrderTaxes keeps the summary's tax breakdown on the order
+func createOrderTaxes(orderSummary *OrderSummary) []models.OrderTax {
+	orderTaxes := make([]models.OrderTax, 0, len(orderSummary.Taxes))
+	for _, tax := range orderSummary.Taxes {
+		taxID := tax.TaxID
+		orderTaxes = append(orderTaxes, models.OrderTax{
+			TaxID:         &taxID,
+			Name:          tax.Name,
+			Type:          tax.Type,
+			Rate:          tax.Rate,
+			TaxableAmount: tax.TaxableAmount,
+			Amount:        tax.Amount,
+		})
+	}
+	return orderTaxes
+}
+
+// reserveFlashSaleStock reserves the order's flash sale items against the sale's limits
+func (s *checkoutService) reserveFlashSaleStock(order *models.Order) error {
+	if s.flashSaleStock == nil {
+		return nil
+	}
+
+	var items []FlashSaleReservationItem
+	for _, item := range order.Items {
+		if item.FlashSaleID == nil || item.FlashSaleProductID == nil {
+			continue
+		}
+		items = append(items, FlashSaleReservationItem{
+			FlashSaleID:        *item.FlashSaleID,
+			FlashSaleProductID: *item.FlashSaleProductID,
+			ProductName:        item.ProductName,
+			Quantity:           item.Quantity,
+		})
+	}
+	return s.flashSaleStock.Reserve(order.OrderNumber, order.UserID, items)
+}
+
+// releaseFlashSaleStock gives an order's flash sale units back to the sale
+func (s *checkoutService) releaseFlashSaleStock(orderNumber string) {
+	if s.flashSaleStock == nil {
+		return
+	}
+	if err := s.flashSaleStock.Release(orderNumber); err != nil {
+		log.Printf("Failed to release flash sale stock for order %s: %v", orderNumber, err)
+	}
+}
+
+// orderFlashSaleIDs lists the flash sales an order bought from
+func orderFlashSaleIDs(order *models.Order) []uint {
+	var ids []uint
+	for _, item := range order.Items {
+		if item.FlashSaleID != nil {
+			ids = append(ids, *item.FlashSaleID)
+		}
+	}
+	return ids
+}
+
 // generateSnapToken generates Midtrans Snap token (Stubbed)
 func (s *checkoutService) generateSnapToken(order *models.Order, items []PriceCalculationRequest, req *models.CheckoutRequest) (*models.MidtransSnapResponse, error) {
 	// Ensure server key is set
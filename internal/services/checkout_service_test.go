@@ -3,12 +3,20 @@ package services
 import (
 	"crypto/sha512"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/komerce"
 	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -196,33 +204,269 @@ func TestCheckoutService_PaymentNotificationIdempotency(t *testing.T) {
 			notificationStatus:   "failed",
 			shouldProcessUpdate:  false,
 		},
+		{
+			name:                 "Refund after settlement",
+			initialPaymentStatus: models.PaymentPaid,
+			initialOrderStatus:   models.StatusConfirmed,
+			notificationStatus:   "refund",
+			shouldProcessUpdate:  true,
+		},
+		{
+			name:                 "Duplicate refund notification",
+			initialPaymentStatus: models.PaymentRefunded,
+			initialOrderStatus:   models.StatusRefunded,
+			notificationStatus:   "refund",
+			shouldProcessUpdate:  false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Verify idempotency logic: final states should not be updated
-			if tt.initialPaymentStatus == models.PaymentPaid || tt.initialOrderStatus == models.StatusCancelled {
-				if tt.shouldProcessUpdate {
-					t.Error("Expected no update for final status")
-				}
+			// Verify idempotency logic: final states should not be updated, and paid orders only by a refund
+			final := tt.initialOrderStatus == models.StatusCancelled || tt.initialPaymentStatus == models.PaymentRefunded ||
+				(tt.initialPaymentStatus == models.PaymentPaid && tt.notificationStatus != "refund")
+			if final && tt.shouldProcessUpdate {
+				t.Error("Expected no update for final status")
 			}
 		})
 	}
 }
 
+// MockStockLogRepository
+type MockStockLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockLogRepository) Create(log *models.StockLog) error { return m.Called(log).Error(0) }
+func (m *MockStockLogRepository) WithTx(tx *gorm.DB) repository.StockLogRepository {
+	args := m.Called(tx)
+	return args.Get(0).(repository.StockLogRepository)
+}
+
+// MockAbandonedCartService
+type MockAbandonedCartService struct {
+	mock.Mock
+}
+
+func (m *MockAbandonedCartService) Start() {}
+func (m *MockAbandonedCartService) Stop()  {}
+func (m *MockAbandonedCartService) RunOnce() (*AbandonedCartRunResult, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*AbandonedCartRunResult), args.Error(1)
+}
+func (m *MockAbandonedCartService) RecordConversion(userID, orderID uint, orderTotal models.Money) error {
+	return m.Called(userID, orderID, orderTotal).Error(0)
+}
+func (m *MockAbandonedCartService) GetStats(from, to time.Time) (*models.CartRecoveryStats, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CartRecoveryStats), args.Error(1)
+}
+
+func signedNotification(serverKey, orderID, status string, grossAmount models.Money) *models.MidtransPaymentNotification {
+	data := fmt.Sprintf("%s%s%s.00%s", orderID, "200", grossAmount, serverKey)
+	hash := sha512.Sum512([]byte(data))
+	return &models.MidtransPaymentNotification{
+		OrderID:           orderID,
+		StatusCode:        "200",
+		GrossAmount:       grossAmount,
+		TransactionStatus: status,
+		SignatureKey:      hex.EncodeToString(hash[:]),
+	}
+}
+
+func TestCheckoutService_ProcessPaymentNotification_RefundAfterSettlement(t *testing.T) {
+	sqlDB, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer sqlDB.Close()
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, DriverName: "postgres"}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+
+	orderRepo := new(MockOrderRepository)
+	productRepo := new(MockProductRepository)
+	stockLogRepo := new(MockStockLogRepository)
+	couponRepo := new(MockCouponRepository)
+	orderRepo.On("WithTx", mock.Anything).Return(orderRepo)
+	productRepo.On("WithTx", mock.Anything).Return(productRepo)
+	stockLogRepo.On("WithTx", mock.Anything).Return(stockLogRepo)
+	couponRepo.On("WithTx", mock.Anything).Return(couponRepo)
+
+	couponID := uint(9)
+	order := &models.Order{
+		ID:            42,
+		UserID:        7,
+		OrderNumber:   "ORD-REFUND",
+		TotalAmount:   models.NewMoney(150000),
+		Status:        models.StatusPending,
+		PaymentStatus: models.PaymentPending,
+		CouponID:      &couponID,
+		Items:         []models.OrderItem{{ProductID: 5, Quantity: 2}},
+	}
+	orderRepo.On("GetByOrderNumber", "ORD-REFUND").Return(order, nil)
+	orderRepo.On("Update", order).Return(nil)
+	productRepo.On("GetByID", uint(5)).Return(&models.Product{ID: 5, Stock: 8}, nil)
+	productRepo.On("UpdateStock", uint(5), 2).Return(nil).Once()
+	stockLogRepo.On("Create", mock.AnythingOfType("*models.StockLog")).Return(nil).Once()
+	couponRepo.On("RevokeUsage", uint(42)).Return(nil).Once()
+	// The conversion is recorded when the payment settles, and only then
+	recovery := new(MockAbandonedCartService)
+	recovery.On("RecordConversion", uint(7), uint(42), models.Money(150000)).Return(nil).Once()
+
+	config := &MidtransConfig{ServerKey: "test-server-key"}
+	service := NewCheckoutService(database.NewPostgreSQLFromDB(gormDB), orderRepo, productRepo, nil, stockLogRepo, couponRepo, nil, nil, "", nil, nil, recovery, config)
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+	err = service.ProcessPaymentNotification(signedNotification(config.ServerKey, "ORD-REFUND", "settlement", 150000))
+	assert.NoError(t, err)
+	assert.Equal(t, models.PaymentPaid, order.PaymentStatus)
+
+	// A repeated settlement is ignored
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+	err = service.ProcessPaymentNotification(signedNotification(config.ServerKey, "ORD-REFUND", "settlement", 150000))
+	assert.NoError(t, err)
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+	err = service.ProcessPaymentNotification(signedNotification(config.ServerKey, "ORD-REFUND", "refund", 150000))
+	assert.NoError(t, err)
+	assert.Equal(t, models.PaymentRefunded, order.PaymentStatus)
+	assert.Equal(t, models.StatusRefunded, order.Status)
+
+	// A repeated refund is ignored
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+	err = service.ProcessPaymentNotification(signedNotification(config.ServerKey, "ORD-REFUND", "refund", 150000))
+	assert.NoError(t, err)
+
+	couponRepo.AssertExpectations(t)
+	productRepo.AssertExpectations(t)
+	stockLogRepo.AssertExpectations(t)
+	recovery.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestCheckoutService_CreateOrderItems_Variant(t *testing.T) {
+	service := &checkoutService{}
+	variantID := uint(7)
+	summary := &OrderSummary{Items: []OrderSummaryItem{
+		{ProductID: 1, VariantID: &variantID, Quantity: 2, UnitPrice: 90000, TotalPrice: 180000},
+		{ProductID: 2, Quantity: 1, UnitPrice: 50000, TotalPrice: 50000},
+	}}
+	products := map[uint]*models.Product{
+		1: {ID: 1, Name: "Kemeja", SKU: "KMJ"},
+		2: {ID: 2, Name: "Topi", SKU: "TOP"},
+	}
+	variants := map[uint]*models.ProductVariant{
+		7: {ID: 7, ProductID: 1, Name: "L - Navy", Size: "L", Color: "Navy"},
+	}
+
+	items := service.createOrderItems(summary, products, variants)
+
+	assert.Len(t, items, 2)
+	assert.Equal(t, &variantID, items[0].VariantID)
+	assert.Equal(t, "L - Navy", items[0].VariantName)
+	assert.Equal(t, "L", items[0].VariantSize)
+	assert.Equal(t, "Navy", items[0].VariantColor)
+	assert.Nil(t, items[1].VariantID)
+	assert.Empty(t, items[1].VariantName)
+}
+
 // TestCheckoutService_OrderNumberUniqueness tests order number generation
 func TestCheckoutService_OrderNumberUniqueness(t *testing.T) {
 	orderNumbers := make(map[string]bool)
 
-	// Generate order numbers
+	// Generate order numbers within the same second
 	for i := 0; i < 100; i++ {
-		orderNum := "ORD" + time.Now().Format("20060102150405")
+		orderNum, err := generateOrderNumber()
+		assert.NoError(t, err)
+		assert.Regexp(t, `^ORD\d{14}-[0-9A-F]{10}$`, orderNum)
 		orderNumbers[orderNum] = true
-		time.Sleep(1 * time.Microsecond)
 	}
 
-	t.Logf("Generated %d unique order numbers out of 100 attempts", len(orderNumbers))
+	assert.Len(t, orderNumbers, 100)
+}
+
+func TestCheckoutService_QuoteShipping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "101", query.Get("shipper_destination_id"))
+		assert.Equal(t, "202", query.Get("receiver_destination_id"))
+		// 2 jackets of 0.8 kg billed as 3 kg each by volume
+		assert.Equal(t, "6", query.Get("weight"))
+		assert.Equal(t, "900000", query.Get("item_value"))
+		assert.Equal(t, "no", query.Get("cod"))
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"meta": map[string]interface{}{"status": "success"},
+			"data": map[string]interface{}{
+				"calculate_reguler": []interface{}{
+					map[string]interface{}{"shipping_name": "JNE", "service_name": "REG", "shipping_cost": 54000, "etd": "2-3 day"},
+					map[string]interface{}{"shipping_name": "SICEPAT", "service_name": "REG", "shipping_cost": 48000, "etd": "1-2 day"},
+				},
+				"calculate_cargo": []interface{}{
+					map[string]interface{}{"shipping_name": "JNE", "service_name": "JTR", "shipping_cost": 40000, "etd": "4-7 day"},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	service := &checkoutService{
+		komerceService:       NewKomerceService(komerce.NewClient("test-key", server.URL)),
+		shipperDestinationID: "101",
+	}
+	items := []ShippingItem{{ProductID: 1, Quantity: 2, Weight: 0.8, Dimensions: "40x30x15"}}
+	req := &models.CheckoutRequest{ReceiverDestinationID: "202", ShippingCourier: "jne", ShippingService: "jtr", PaymentMethod: "bank_transfer"}
+
+	quote, err := service.quoteShipping(req, items, 900000)
+	assert.NoError(t, err)
+	assert.Equal(t, &courierQuote{Courier: "JNE", Service: "JTR", ETD: "4-7 day", Cost: 40000}, quote)
+
+	// The client can't pick a service the courier doesn't offer there
+	req.ShippingService = "YES"
+	_, err = service.quoteShipping(req, items, 900000)
+	assert.EqualError(t, err, "shipping service jne YES is not available for this destination")
+
+	req.ReceiverDestinationID = ""
+	_, err = service.quoteShipping(req, items, 900000)
+	assert.EqualError(t, err, "receiver_destination_id, shipping_courier and shipping_service are required")
+}
+
+func TestCheckoutService_ReceiverDestination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "12430", r.URL.Query().Get("keyword"))
+		// Komerce sends destination IDs as numbers
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"meta": map[string]interface{}{"status": "success"},
+			"data": []interface{}{
+				map[string]interface{}{"id": 17615, "city_name": "JAKARTA SELATAN", "zip_code": "12430"},
+				map[string]interface{}{"id": 17616, "city_name": "JAKARTA SELATAN", "zip_code": "12430"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	service := &checkoutService{komerceService: NewKomerceService(komerce.NewClient("test-key", server.URL))}
+	req := &models.CheckoutRequest{ReceiverDestinationID: "17616", ShippingPostalCode: "12430", ShippingCity: "Bandung"}
+
+	// The tax region comes from the destination, not the city the client sent
+	destination, err := service.receiverDestination(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "17616", destination.ID)
+	assert.Equal(t, "JAKARTA SELATAN", destination.CityName)
 
-	//  Note: In production, add random suffix or use database sequence
-	// to guarantee uniqueness with concurrent requests
+	req.ReceiverDestinationID = "202"
+	_, err = service.receiverDestination(req)
+	assert.EqualError(t, err, "receiver_destination_id does not match the shipping postal code")
 }
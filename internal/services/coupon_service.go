@@ -0,0 +1,422 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// couponCodeAlphabet leaves out 0/O and 1/I so codes can be read back over the phone
+	couponCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	defaultCouponCodeLength = 8
+	// couponCodeAttempts is how many rounds the generator redraws codes that are already taken
+	couponCodeAttempts = 5
+)
+
+// CouponService manages coupons and bulk-generated batches of single-use codes
+type CouponService interface {
+	ListCoupons(filter models.CouponListFilter, limit, offset int) ([]models.Coupon, int64, error)
+	GetCoupon(id uint) (*models.CouponDetail, error)
+	CreateCoupon(req *models.CreateCouponRequest) (*models.Coupon, error)
+	UpdateCoupon(id uint, req *models.UpdateCouponRequest) (*models.Coupon, error)
+	DeactivateCoupon(id uint) (*models.Coupon, error)
+
+	GenerateCoupons(req *models.GenerateCouponsRequest) (*models.CouponBatch, error)
+	ListBatches(limit, offset int) ([]models.CouponBatch, int64, error)
+	GetBatch(id uint) (*models.CouponBatchDetail, error)
+	// ExportBatch writes the codes of a batch to w as CSV
+	ExportBatch(id uint, w io.Writer) error
+}
+
+type couponService struct {
+	couponRepo repository.CouponRepository
+}
+
+func NewCouponService(couponRepo repository.CouponRepository) CouponService {
+	return &couponService{
+		couponRepo: couponRepo,
+	}
+}
+
+func (s *couponService) ListCoupons(filter models.CouponListFilter, limit, offset int) ([]models.Coupon, int64, error) {
+	limit, offset = clampCouponPage(limit, offset)
+	filter.Search = strings.TrimSpace(filter.Search)
+	return s.couponRepo.List(filter, limit, offset)
+}
+
+// GetCoupon returns a coupon with its usage stats
+func (s *couponService) GetCoupon(id uint) (*models.CouponDetail, error) {
+	coupon, err := s.getCoupon(id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.couponRepo.GetUsageStats(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load coupon stats: %w", err)
+	}
+
+	return &models.CouponDetail{Coupon: *coupon, Stats: *stats}, nil
+}
+
+func (s *couponService) CreateCoupon(req *models.CreateCouponRequest) (*models.Coupon, error) {
+	code := strings.TrimSpace(req.Code)
+	if code == "" || strings.ContainsAny(code, " \t\r\n") {
+		return nil, errors.New("coupon code must not contain spaces")
+	}
+
+	coupon, err := couponFromRules(&req.CouponRules)
+	if err != nil {
+		return nil, err
+	}
+	coupon.Code = code
+	coupon.MaxUsageCount = req.MaxUsageCount
+	coupon.MaxUsagePerUser = req.MaxUsagePerUser
+
+	taken, err := s.couponRepo.ExistingCodes([]string{code})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check coupon code: %w", err)
+	}
+	if len(taken) > 0 {
+		return nil, errors.New("coupon code already exists")
+	}
+
+	if err := s.couponRepo.Create(coupon); err != nil {
+		return nil, fmt.Errorf("failed to create coupon: %w", err)
+	}
+	return coupon, nil
+}
+
+// UpdateCoupon changes the given fields. Redemptions already made keep the discount they got.
+func (s *couponService) UpdateCoupon(id uint, req *models.UpdateCouponRequest) (*models.Coupon, error) {
+	coupon, err := s.getCoupon(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("coupon name is required")
+		}
+		coupon.Name = name
+	}
+	if req.Description != nil {
+		coupon.Description = *req.Description
+	}
+	if req.Status != nil {
+		coupon.Status = *req.Status
+	}
+	if req.DiscountValue != nil {
+		coupon.DiscountValue = *req.DiscountValue
+	}
+	if req.MaxDiscount != nil {
+		coupon.MaxDiscount = *req.MaxDiscount
+	}
+	if req.MinPurchaseAmount != nil {
+		coupon.MinPurchaseAmount = *req.MinPurchaseAmount
+	}
+	if req.MaxUsageCount != nil {
+		coupon.MaxUsageCount = *req.MaxUsageCount
+	}
+	if req.MaxUsagePerUser != nil {
+		coupon.MaxUsagePerUser = *req.MaxUsagePerUser
+	}
+	if req.ValidFrom != nil {
+		coupon.ValidFrom = req.ValidFrom
+	}
+	if req.ValidUntil != nil {
+		coupon.ValidUntil = req.ValidUntil
+	}
+	if req.ForRetail != nil {
+		coupon.ForRetail = *req.ForRetail
+	}
+	if req.ForReseller != nil {
+		coupon.ForReseller = *req.ForReseller
+	}
+	if req.ApplicableProducts != nil {
+		coupon.ApplicableProducts = *req.ApplicableProducts
+	}
+	if req.ExcludeProducts != nil {
+		coupon.ExcludeProducts = *req.ExcludeProducts
+	}
+	if req.ApplicableCategories != nil {
+		coupon.ApplicableCategories = *req.ApplicableCategories
+	}
+
+	if err := validateCoupon(coupon); err != nil {
+		return nil, err
+	}
+
+	if err := s.couponRepo.Update(coupon); err != nil {
+		return nil, fmt.Errorf("failed to update coupon: %w", err)
+	}
+	return coupon, nil
+}
+
+// DeactivateCoupon stops a coupon from being redeemed. Its usage history is kept.
+func (s *couponService) DeactivateCoupon(id uint) (*models.Coupon, error) {
+	coupon, err := s.getCoupon(id)
+	if err != nil {
+		return nil, err
+	}
+	if coupon.Status == models.CouponStatusInactive {
+		return coupon, nil
+	}
+
+	coupon.Status = models.CouponStatusInactive
+	if err := s.couponRepo.Update(coupon); err != nil {
+		return nil, fmt.Errorf("failed to deactivate coupon: %w", err)
+	}
+	return coupon, nil
+}
+
+// GenerateCoupons creates a batch of single-use codes sharing the same rules. Codes that clash
+// with existing ones are redrawn, so the batch always has exactly req.Quantity codes.
+func (s *couponService) GenerateCoupons(req *models.GenerateCouponsRequest) (*models.CouponBatch, error) {
+	template, err := couponFromRules(&req.CouponRules)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.ToUpper(strings.TrimSpace(req.Prefix))
+	length := req.CodeLength
+	if length <= 0 {
+		length = defaultCouponCodeLength
+	}
+
+	codes, err := s.uniqueCodes(prefix, length, req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	coupons := make([]models.Coupon, len(codes))
+	for i, code := range codes {
+		coupons[i] = *template
+		coupons[i].Code = code
+		coupons[i].MaxUsageCount = 1
+		coupons[i].MaxUsagePerUser = 1
+	}
+
+	batch := &models.CouponBatch{
+		Name:       template.Name,
+		Prefix:     prefix,
+		CodeLength: length,
+		Quantity:   len(coupons),
+	}
+	if err := s.couponRepo.CreateBatch(batch, coupons); err != nil {
+		return nil, fmt.Errorf("failed to create coupon batch: %w", err)
+	}
+	return batch, nil
+}
+
+// uniqueCodes draws quantity distinct codes that no coupon uses yet
+func (s *couponService) uniqueCodes(prefix string, length, quantity int) ([]string, error) {
+	codes := make([]string, 0, quantity)
+	seen := make(map[string]bool, quantity)
+
+	for attempt := 0; attempt < couponCodeAttempts && len(codes) < quantity; attempt++ {
+		var drawn []string
+		for len(codes)+len(drawn) < quantity {
+			code, err := generateCouponCode(prefix, length)
+			if err != nil {
+				return nil, err
+			}
+			if seen[code] {
+				continue
+			}
+			seen[code] = true
+			drawn = append(drawn, code)
+		}
+
+		taken, err := s.couponRepo.ExistingCodes(drawn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check coupon codes: %w", err)
+		}
+		exists := make(map[string]bool, len(taken))
+		for _, code := range taken {
+			exists[code] = true
+		}
+		for _, code := range drawn {
+			if !exists[code] {
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	if len(codes) < quantity {
+		return nil, errors.New("could not generate enough unique codes, use a longer code length")
+	}
+	return codes, nil
+}
+
+func (s *couponService) ListBatches(limit, offset int) ([]models.CouponBatch, int64, error) {
+	limit, offset = clampCouponPage(limit, offset)
+	return s.couponRepo.GetBatches(limit, offset)
+}
+
+// GetBatch returns a batch with the combined usage stats of its codes
+func (s *couponService) GetBatch(id uint) (*models.CouponBatchDetail, error) {
+	batch, err := s.getBatch(id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.couponRepo.GetBatchUsageStats(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load coupon batch stats: %w", err)
+	}
+
+	return &models.CouponBatchDetail{CouponBatch: *batch, Stats: *stats}, nil
+}
+
+func (s *couponService) ExportBatch(id uint, w io.Writer) error {
+	if _, err := s.getBatch(id); err != nil {
+		return err
+	}
+
+	coupons, err := s.couponRepo.GetBatchCoupons(id)
+	if err != nil {
+		return fmt.Errorf("failed to load coupon batch: %w", err)
+	}
+
+	out := csv.NewWriter(w)
+	out.Write([]string{"code", "status", "type", "discount_value", "max_discount", "min_purchase_amount", "valid_from", "valid_until", "used"})
+	for _, coupon := range coupons {
+		out.Write([]string{
+			coupon.Code,
+			string(coupon.Status),
+			string(coupon.Type),
+			strconv.FormatFloat(coupon.DiscountValue, 'f', -1, 64),
+			strconv.FormatFloat(coupon.MaxDiscount, 'f', -1, 64),
+			strconv.FormatFloat(coupon.MinPurchaseAmount, 'f', -1, 64),
+			formatCouponTime(coupon.ValidFrom),
+			formatCouponTime(coupon.ValidUntil),
+			strconv.FormatBool(coupon.UsageCount > 0),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func (s *couponService) getCoupon(id uint) (*models.Coupon, error) {
+	coupon, err := s.couponRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("coupon not found")
+		}
+		return nil, err
+	}
+	return coupon, nil
+}
+
+func (s *couponService) getBatch(id uint) (*models.CouponBatch, error) {
+	batch, err := s.couponRepo.GetBatchByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("coupon batch not found")
+		}
+		return nil, err
+	}
+	return batch, nil
+}
+
+// couponFromRules builds an active coupon from the shared rules of a create or generate request
+func couponFromRules(rules *models.CouponRules) (*models.Coupon, error) {
+	name := strings.TrimSpace(rules.Name)
+	if name == "" {
+		return nil, errors.New("coupon name is required")
+	}
+
+	coupon := &models.Coupon{
+		Name:              name,
+		Description:       rules.Description,
+		Type:              rules.Type,
+		Status:            models.CouponStatusActive,
+		DiscountValue:     rules.DiscountValue,
+		MaxDiscount:       rules.MaxDiscount,
+		MinPurchaseAmount: rules.MinPurchaseAmount,
+		ValidFrom:         rules.ValidFrom,
+		ValidUntil:        rules.ValidUntil,
+		ForRetail:         rules.ForRetail == nil || *rules.ForRetail,
+		ForReseller:       rules.ForReseller == nil || *rules.ForReseller,
+
+		ApplicableProducts:   rules.ApplicableProducts,
+		ExcludeProducts:      rules.ExcludeProducts,
+		ApplicableCategories: rules.ApplicableCategories,
+	}
+	if err := validateCoupon(coupon); err != nil {
+		return nil, err
+	}
+	if coupon.ValidUntil != nil && !coupon.ValidUntil.After(time.Now()) {
+		return nil, errors.New("valid until must be in the future")
+	}
+	return coupon, nil
+}
+
+func validateCoupon(coupon *models.Coupon) error {
+	if coupon.Type == models.CouponTypePercentage && coupon.DiscountValue > 100 {
+		return errors.New("percentage discount cannot be more than 100")
+	}
+	if coupon.ValidFrom != nil && coupon.ValidUntil != nil && !coupon.ValidUntil.After(*coupon.ValidFrom) {
+		return errors.New("valid until must be after valid from")
+	}
+	if !coupon.ForRetail && !coupon.ForReseller {
+		return errors.New("coupon must be usable by retail or reseller customers")
+	}
+
+	applicable := make(map[uint]bool, len(coupon.ApplicableProducts))
+	for _, id := range coupon.ApplicableProducts {
+		applicable[id] = true
+	}
+	for _, id := range coupon.ExcludeProducts {
+		if applicable[id] {
+			return fmt.Errorf("product %d cannot be both applicable and excluded", id)
+		}
+	}
+	return nil
+}
+
+// generateCouponCode returns a random code like PREFIX-7KQ2M9XP, or just the random part when
+// prefix is empty
+func generateCouponCode(prefix string, length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate coupon code: %w", err)
+	}
+	for i := range b {
+		b[i] = couponCodeAlphabet[int(b[i])%len(couponCodeAlphabet)]
+	}
+	if prefix == "" {
+		return string(b), nil
+	}
+	return prefix + "-" + string(b), nil
+}
+
+func formatCouponTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func clampCouponPage(limit, offset int) (int, int) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
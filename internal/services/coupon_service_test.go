@@ -0,0 +1,96 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCouponService_GenerateCoupons_RedrawsTakenCodes(t *testing.T) {
+	couponRepo := new(MockCouponRepository)
+	service := NewCouponService(couponRepo)
+
+	// The first round clashes with one existing code, the second round replaces it
+	var clash string
+	firstRound := couponRepo.On("ExistingCodes", mock.MatchedBy(func(codes []string) bool { return len(codes) == 25 })).Once()
+	firstRound.Run(func(args mock.Arguments) {
+		clash = args.Get(0).([]string)[3]
+		firstRound.ReturnArguments = mock.Arguments{[]string{clash}, nil}
+	})
+	couponRepo.On("ExistingCodes", mock.MatchedBy(func(codes []string) bool { return len(codes) == 1 })).
+		Return([]string{}, nil).Once()
+
+	var saved []models.Coupon
+	couponRepo.On("CreateBatch", mock.AnythingOfType("*models.CouponBatch"), mock.Anything).
+		Run(func(args mock.Arguments) { saved = args.Get(1).([]models.Coupon) }).
+		Return(nil).Once()
+
+	batch, err := service.GenerateCoupons(&models.GenerateCouponsRequest{
+		Prefix:   "rina",
+		Quantity: 25,
+		CouponRules: models.CouponRules{
+			Name:          "Rina x Karima",
+			Type:          models.CouponTypePercentage,
+			DiscountValue: 15,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 25, batch.Quantity)
+	assert.Equal(t, "RINA", batch.Prefix)
+
+	seen := make(map[string]bool)
+	for _, coupon := range saved {
+		assert.Regexp(t, `^RINA-[A-HJ-NP-Z2-9]{8}$`, coupon.Code)
+		assert.NotEqual(t, clash, coupon.Code)
+		assert.False(t, seen[coupon.Code])
+		seen[coupon.Code] = true
+		assert.Equal(t, 1, coupon.MaxUsageCount)
+		assert.Equal(t, 1, coupon.MaxUsagePerUser)
+		assert.True(t, coupon.ForRetail && coupon.ForReseller)
+	}
+	assert.Len(t, saved, 25)
+	couponRepo.AssertExpectations(t)
+}
+
+func TestCouponService_CreateCoupon_Validation(t *testing.T) {
+	couponRepo := new(MockCouponRepository)
+	service := NewCouponService(couponRepo)
+	past := time.Now().Add(-time.Hour)
+
+	_, err := service.CreateCoupon(&models.CreateCouponRequest{Code: "BIG", CouponRules: models.CouponRules{Name: "Big", Type: models.CouponTypePercentage, DiscountValue: 150}})
+	assert.EqualError(t, err, "percentage discount cannot be more than 100")
+
+	_, err = service.CreateCoupon(&models.CreateCouponRequest{Code: "OLD", CouponRules: models.CouponRules{Name: "Old", Type: models.CouponTypeFixed, DiscountValue: 10000, ValidUntil: &past}})
+	assert.EqualError(t, err, "valid until must be in the future")
+
+	couponRepo.On("ExistingCodes", []string{"PROMO10"}).Return([]string{"PROMO10"}, nil).Once()
+	_, err = service.CreateCoupon(&models.CreateCouponRequest{Code: " PROMO10 ", CouponRules: models.CouponRules{Name: "Promo", Type: models.CouponTypeFixed, DiscountValue: 10000}})
+	assert.EqualError(t, err, "coupon code already exists")
+	couponRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCouponService_ExportBatch_WritesCSV(t *testing.T) {
+	couponRepo := new(MockCouponRepository)
+	service := NewCouponService(couponRepo)
+
+	couponRepo.On("GetBatchByID", uint(4)).Return(&models.CouponBatch{ID: 4}, nil)
+	couponRepo.On("GetBatchCoupons", uint(4)).Return([]models.Coupon{
+		{Code: "RINA-AAAA2222", Status: models.CouponStatusActive, Type: models.CouponTypePercentage, DiscountValue: 15},
+		{Code: "RINA-BBBB3333", Status: models.CouponStatusActive, Type: models.CouponTypePercentage, DiscountValue: 15, UsageCount: 1},
+	}, nil)
+
+	var buf bytes.Buffer
+	assert.NoError(t, service.ExportBatch(4, &buf))
+
+	rows, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "code", rows[0][0])
+	assert.Equal(t, []string{"RINA-BBBB3333", "active", "percentage", "15", "0", "0", "", "", "true"}, rows[2])
+}
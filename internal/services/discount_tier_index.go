@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+)
+
+// DiscountTierEventsChannel is the Redis pub/sub channel tier changes are published on, so every
+// instance reloads its tier index
+const DiscountTierEventsChannel = "events:discount_tiers"
+
+// DiscountTierIndexConfig controls how stale the index may get when no change event arrives
+type DiscountTierIndexConfig struct {
+	// MaxAge is the longest the index goes without a reload
+	MaxAge time.Duration
+}
+
+// DiscountTierIndex keeps the reseller and bulk tier tables in memory so pricing an item needs no
+// database query. Tiers with a future start date are loaded too and take effect on their own.
+type DiscountTierIndex interface {
+	// Tier returns the tier that prices quantity units of the product right now, or nil
+	Tier(kind models.DiscountTierKind, productID uint, category models.ProductCategory, quantity int) *models.DiscountTier
+	Refresh() error
+	Start()
+	Stop()
+}
+
+// tierTables holds the tiers of one kind by scope; each slice is sorted by MinQuantity
+type tierTables struct {
+	global     []models.DiscountTier
+	categories map[models.ProductCategory][]models.DiscountTier
+	products   map[uint][]models.DiscountTier
+}
+
+type discountTierIndex struct {
+	tierRepo repository.DiscountTierRepository
+	redis    database.RedisClient
+	cfg      DiscountTierIndexConfig
+
+	mu     sync.RWMutex
+	tables map[models.DiscountTierKind]*tierTables
+	loaded bool
+
+	reload   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewDiscountTierIndex(
+	tierRepo repository.DiscountTierRepository,
+	redis database.RedisClient,
+	cfg DiscountTierIndexConfig,
+) DiscountTierIndex {
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 5 * time.Minute
+	}
+
+	return &discountTierIndex{
+		tierRepo: tierRepo,
+		redis:    redis,
+		cfg:      cfg,
+		tables:   make(map[models.DiscountTierKind]*tierTables),
+		reload:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// Tier uses the most specific table that has tiers in effect for the product: its own, then its
+// category's, then the global one. Within that table the highest MinQuantity reached applies.
+func (d *discountTierIndex) Tier(kind models.DiscountTierKind, productID uint, category models.ProductCategory, quantity int) *models.DiscountTier {
+	d.mu.RLock()
+	loaded := d.loaded
+	d.mu.RUnlock()
+	if !loaded {
+		if err := d.Refresh(); err != nil {
+			log.Printf("[DiscountTiers] Failed to load index: %v", err)
+			return nil
+		}
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	tables, ok := d.tables[kind]
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	for _, table := range [][]models.DiscountTier{tables.products[productID], tables.categories[category], tables.global} {
+		effective := false
+		var reached *models.DiscountTier
+		for i := range table {
+			tier := &table[i]
+			if !tier.IsEffective(now) {
+				continue
+			}
+			effective = true
+			if tier.MinQuantity <= quantity {
+				reached = tier
+			}
+		}
+		if effective {
+			if reached == nil {
+				return nil
+			}
+			match := *reached
+			return &match
+		}
+	}
+	return nil
+}
+
+// Refresh reloads every current tier and swaps them in at once
+func (d *discountTierIndex) Refresh() error {
+	tiers, err := d.tierRepo.GetCurrent(time.Now())
+	if err != nil {
+		return err
+	}
+
+	tables := make(map[models.DiscountTierKind]*tierTables)
+	for _, tier := range tiers {
+		kind, ok := tables[tier.Kind]
+		if !ok {
+			kind = &tierTables{
+				categories: make(map[models.ProductCategory][]models.DiscountTier),
+				products:   make(map[uint][]models.DiscountTier),
+			}
+			tables[tier.Kind] = kind
+		}
+
+		switch tier.Scope {
+		case models.DiscountTierScopeGlobal:
+			kind.global = append(kind.global, tier)
+		case models.DiscountTierScopeCategory:
+			kind.categories[tier.Category] = append(kind.categories[tier.Category], tier)
+		case models.DiscountTierScopeProduct:
+			if tier.ProductID != nil {
+				kind.products[*tier.ProductID] = append(kind.products[*tier.ProductID], tier)
+			}
+		}
+	}
+
+	d.mu.Lock()
+	d.tables = tables
+	d.loaded = true
+	d.mu.Unlock()
+	return nil
+}
+
+// Start keeps the index current until Stop is called: it reloads on every tier event published
+// by any instance and at least every MaxAge
+func (d *discountTierIndex) Start() {
+	if err := d.Refresh(); err != nil {
+		log.Printf("[DiscountTiers] Failed to load index: %v", err)
+	}
+
+	if d.redis != nil && d.redis.Client() != nil {
+		go d.listen()
+	}
+
+	go func() {
+		ticker := time.NewTicker(d.cfg.MaxAge)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-d.reload:
+			case <-d.done:
+				return
+			}
+
+			if err := d.Refresh(); err != nil {
+				log.Printf("[DiscountTiers] Failed to reload index: %v", err)
+			}
+		}
+	}()
+
+	log.Printf("[DiscountTiers] Index started (reloads at least every %s)", d.cfg.MaxAge)
+}
+
+// Stop stops the reload loop and the event subscription
+func (d *discountTierIndex) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.done)
+	})
+}
+
+// listen asks for a reload whenever a tier event comes in
+func (d *discountTierIndex) listen() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pubsub := d.redis.Client().Subscribe(ctx, DiscountTierEventsChannel)
+	defer pubsub.Close()
+
+	go func() {
+		<-d.done
+		cancel()
+	}()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case _, ok := <-messages:
+			if !ok {
+				return
+			}
+			select {
+			case d.reload <- struct{}{}:
+			default:
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
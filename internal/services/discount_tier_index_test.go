@@ -0,0 +1,148 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockDiscountTierRepository
+type MockDiscountTierRepository struct {
+	mock.Mock
+}
+
+func (m *MockDiscountTierRepository) Create(tier *models.DiscountTier) error {
+	args := m.Called(tier)
+	return args.Error(0)
+}
+
+func (m *MockDiscountTierRepository) Update(tier *models.DiscountTier) error {
+	args := m.Called(tier)
+	return args.Error(0)
+}
+
+func (m *MockDiscountTierRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockDiscountTierRepository) GetByID(id uint) (*models.DiscountTier, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DiscountTier), args.Error(1)
+}
+
+func (m *MockDiscountTierRepository) List(filter models.DiscountTierListFilter, limit, offset int) ([]models.DiscountTier, int64, error) {
+	args := m.Called(filter, limit, offset)
+	return args.Get(0).([]models.DiscountTier), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockDiscountTierRepository) GetCurrent(at time.Time) ([]models.DiscountTier, error) {
+	args := m.Called(at)
+	return args.Get(0).([]models.DiscountTier), args.Error(1)
+}
+
+func newTestDiscountTierIndex(tiers ...models.DiscountTier) DiscountTierIndex {
+	tierRepo := new(MockDiscountTierRepository)
+	tierRepo.On("GetCurrent", mock.Anything).Return(tiers, nil)
+	return NewDiscountTierIndex(tierRepo, nil, DiscountTierIndexConfig{})
+}
+
+// newTestDiscountTiers serves the global tiers seeded by the discount tiers migration
+func newTestDiscountTiers() DiscountTierIndex {
+	var tiers []models.DiscountTier
+	for i, seed := range []struct {
+		kind    models.DiscountTierKind
+		min     int
+		percent float64
+	}{
+		{models.DiscountTierReseller, 1, 5},
+		{models.DiscountTierReseller, 5, 10},
+		{models.DiscountTierReseller, 10, 15},
+		{models.DiscountTierReseller, 20, 20},
+		{models.DiscountTierReseller, 50, 25},
+		{models.DiscountTierReseller, 100, 30},
+		{models.DiscountTierBulk, 5, 5},
+		{models.DiscountTierBulk, 10, 10},
+	} {
+		tiers = append(tiers, models.DiscountTier{
+			ID: uint(i + 1), Kind: seed.kind, Scope: models.DiscountTierScopeGlobal,
+			MinQuantity: seed.min, DiscountPercent: seed.percent, IsActive: true,
+		})
+	}
+	return newTestDiscountTierIndex(tiers...)
+}
+
+func TestDiscountTierIndex_MostSpecificTableWins(t *testing.T) {
+	productID := uint(7)
+	index := newTestDiscountTierIndex(
+		models.DiscountTier{ID: 1, Kind: models.DiscountTierBulk, Scope: models.DiscountTierScopeGlobal, MinQuantity: 5, DiscountPercent: 5, IsActive: true},
+		models.DiscountTier{ID: 2, Kind: models.DiscountTierBulk, Scope: models.DiscountTierScopeGlobal, MinQuantity: 10, DiscountPercent: 10, IsActive: true},
+		models.DiscountTier{ID: 3, Kind: models.DiscountTierBulk, Scope: models.DiscountTierScopeCategory, Category: models.CategoryFootwear, MinQuantity: 3, DiscountPercent: 8, IsActive: true},
+		models.DiscountTier{ID: 4, Kind: models.DiscountTierBulk, Scope: models.DiscountTierScopeProduct, ProductID: &productID, MinQuantity: 20, DiscountPercent: 12, IsActive: true},
+	)
+
+	// Global table
+	assert.Nil(t, index.Tier(models.DiscountTierBulk, 1, models.CategoryTops, 4))
+	assert.Equal(t, uint(2), index.Tier(models.DiscountTierBulk, 1, models.CategoryTops, 15).ID)
+
+	// The category table replaces the global one
+	assert.Equal(t, uint(3), index.Tier(models.DiscountTierBulk, 1, models.CategoryFootwear, 15).ID)
+
+	// The product table replaces both, even below its first breakpoint
+	assert.Nil(t, index.Tier(models.DiscountTierBulk, productID, models.CategoryFootwear, 15))
+	assert.Equal(t, uint(4), index.Tier(models.DiscountTierBulk, productID, models.CategoryFootwear, 20).ID)
+
+	// Other kinds have no tiers
+	assert.Nil(t, index.Tier(models.DiscountTierReseller, 1, models.CategoryTops, 100))
+}
+
+func TestDiscountTierIndex_EffectiveDates(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	index := newTestDiscountTierIndex(
+		models.DiscountTier{ID: 1, Kind: models.DiscountTierReseller, Scope: models.DiscountTierScopeGlobal, MinQuantity: 1, DiscountPercent: 5, IsActive: true},
+		// Scheduled category table: not in effect yet, so the global table still applies
+		models.DiscountTier{ID: 2, Kind: models.DiscountTierReseller, Scope: models.DiscountTierScopeCategory, Category: models.CategoryDresses, MinQuantity: 1, DiscountPercent: 20, IsActive: true, StartsAt: &future},
+		// Ended and inactive tiers are ignored
+		models.DiscountTier{ID: 3, Kind: models.DiscountTierReseller, Scope: models.DiscountTierScopeCategory, Category: models.CategoryTops, MinQuantity: 1, DiscountPercent: 20, IsActive: true, EndsAt: &past},
+		models.DiscountTier{ID: 4, Kind: models.DiscountTierReseller, Scope: models.DiscountTierScopeCategory, Category: models.CategoryBottoms, MinQuantity: 1, DiscountPercent: 20, IsActive: false},
+	)
+
+	for _, category := range []models.ProductCategory{models.CategoryDresses, models.CategoryTops, models.CategoryBottoms} {
+		tier := index.Tier(models.DiscountTierReseller, 1, category, 2)
+		if assert.NotNil(t, tier, category) {
+			assert.Equal(t, uint(1), tier.ID, category)
+		}
+	}
+}
+
+func TestPricingService_CalculatePrice_UsesConfiguredTiers(t *testing.T) {
+	productID := uint(3)
+	productRepo := new(MockProductRepository)
+	productRepo.On("GetByID", productID).Return(&models.Product{ID: productID, Price: 100000, Category: models.CategoryAccessories, Status: models.StatusAvailable}, nil)
+	flashSaleRepo := new(MockFlashSaleRepository)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil)
+	tiers := newTestDiscountTierIndex(
+		models.DiscountTier{ID: 9, Kind: models.DiscountTierReseller, Scope: models.DiscountTierScopeCategory, Category: models.CategoryAccessories, MinQuantity: 12, DiscountPercent: 33, IsActive: true},
+	)
+	service := NewPricingService(productRepo, new(MockVariantRepository), NewPromotionIndex(flashSaleRepo, nil, PromotionIndexConfig{}), tiers, new(MockCouponRepository), nil, new(MockShippingZoneRepository), nil)
+
+	resp, err := service.CalculatePrice(PriceCalculationRequest{ProductID: productID, Quantity: 12, CustomerType: CustomerReseller})
+	assert.NoError(t, err)
+	assert.Equal(t, models.Money(67000*12), resp.FinalPrice)
+	assert.Equal(t, "reseller", resp.DiscountType)
+	if assert.NotNil(t, resp.DiscountTierID) {
+		assert.Equal(t, uint(9), *resp.DiscountTierID)
+	}
+
+	resp, err = service.CalculatePrice(PriceCalculationRequest{ProductID: productID, Quantity: 11, CustomerType: CustomerReseller})
+	assert.NoError(t, err)
+	assert.Equal(t, models.Money(100000*11), resp.FinalPrice)
+	assert.Nil(t, resp.DiscountTierID)
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DiscountTierService manages the reseller and bulk tier tables PricingService prices with
+type DiscountTierService interface {
+	ListTiers(filter models.DiscountTierListFilter, limit, offset int) ([]models.DiscountTier, int64, error)
+	GetTier(id uint) (*models.DiscountTier, error)
+	CreateTier(req *models.DiscountTierRequest) (*models.DiscountTier, error)
+	// UpdateTier replaces every field of the tier with the request
+	UpdateTier(id uint, req *models.DiscountTierRequest) (*models.DiscountTier, error)
+	DeleteTier(id uint) error
+}
+
+type discountTierService struct {
+	tierRepo    repository.DiscountTierRepository
+	productRepo repository.ProductRepository
+	index       DiscountTierIndex
+	redis       database.RedisClient
+}
+
+func NewDiscountTierService(
+	tierRepo repository.DiscountTierRepository,
+	productRepo repository.ProductRepository,
+	index DiscountTierIndex,
+	redis database.RedisClient,
+) DiscountTierService {
+	return &discountTierService{
+		tierRepo:    tierRepo,
+		productRepo: productRepo,
+		index:       index,
+		redis:       redis,
+	}
+}
+
+func (s *discountTierService) ListTiers(filter models.DiscountTierListFilter, limit, offset int) ([]models.DiscountTier, int64, error) {
+	limit, offset = clampCouponPage(limit, offset)
+	return s.tierRepo.List(filter, limit, offset)
+}
+
+func (s *discountTierService) GetTier(id uint) (*models.DiscountTier, error) {
+	tier, err := s.tierRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("discount tier not found")
+		}
+		return nil, err
+	}
+	return tier, nil
+}
+
+func (s *discountTierService) CreateTier(req *models.DiscountTierRequest) (*models.DiscountTier, error) {
+	tier := &models.DiscountTier{}
+	if err := s.applyRequest(tier, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.tierRepo.Create(tier); err != nil {
+		return nil, fmt.Errorf("failed to create discount tier: %w", err)
+	}
+	s.tiersChanged()
+	return tier, nil
+}
+
+func (s *discountTierService) UpdateTier(id uint, req *models.DiscountTierRequest) (*models.DiscountTier, error) {
+	tier, err := s.GetTier(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRequest(tier, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.tierRepo.Update(tier); err != nil {
+		return nil, fmt.Errorf("failed to update discount tier: %w", err)
+	}
+	s.tiersChanged()
+	return tier, nil
+}
+
+func (s *discountTierService) DeleteTier(id uint) error {
+	if _, err := s.GetTier(id); err != nil {
+		return err
+	}
+	if err := s.tierRepo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete discount tier: %w", err)
+	}
+	s.tiersChanged()
+	return nil
+}
+
+// applyRequest copies the request onto the tier and checks its scope
+func (s *discountTierService) applyRequest(tier *models.DiscountTier, req *models.DiscountTierRequest) error {
+	if req.MinQuantity < 1 {
+		return errors.New("min_quantity must be at least 1")
+	}
+	if req.DiscountPercent < 0 || req.DiscountPercent >= 100 {
+		return errors.New("discount_percent must be between 0 and 100")
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return errors.New("discount tier must end after it starts")
+	}
+
+	*tier = models.DiscountTier{
+		ID:              tier.ID,
+		CreatedAt:       tier.CreatedAt,
+		Kind:            req.Kind,
+		Scope:           req.Scope,
+		MinQuantity:     req.MinQuantity,
+		DiscountPercent: req.DiscountPercent,
+		IsActive:        boolOr(req.IsActive, true),
+		StartsAt:        req.StartsAt,
+		EndsAt:          req.EndsAt,
+	}
+
+	switch req.Kind {
+	case models.DiscountTierReseller, models.DiscountTierBulk:
+	default:
+		return errors.New("invalid discount tier kind")
+	}
+
+	switch req.Scope {
+	case models.DiscountTierScopeGlobal:
+		if req.Category != "" || req.ProductID != nil {
+			return errors.New("global tiers take no category or product_id")
+		}
+	case models.DiscountTierScopeCategory:
+		if req.Category == "" || req.ProductID != nil {
+			return errors.New("category tiers need a category and no product_id")
+		}
+		tier.Category = req.Category
+	case models.DiscountTierScopeProduct:
+		if req.ProductID == nil || req.Category != "" {
+			return errors.New("product tiers need a product_id and no category")
+		}
+		if _, err := s.productRepo.GetByID(*req.ProductID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("product %d not found", *req.ProductID)
+			}
+			return fmt.Errorf("failed to check product %d: %w", *req.ProductID, err)
+		}
+		tier.ProductID = req.ProductID
+	default:
+		return errors.New("invalid discount tier scope")
+	}
+	return nil
+}
+
+// tiersChanged reloads this instance's index, tells the other instances to reload theirs and
+// drops the cached pricing info, which shows the reseller price
+func (s *discountTierService) tiersChanged() {
+	if s.index != nil {
+		if err := s.index.Refresh(); err != nil {
+			log.Printf("[DiscountTiers] Failed to reload index: %v", err)
+		}
+	}
+
+	if s.redis == nil {
+		return
+	}
+	ctx := context.Background()
+	if err := s.redis.DeleteByPattern(ctx, "pricing:*"); err != nil {
+		log.Printf("Failed to invalidate pricing cache: %v", err)
+	}
+	if s.redis.Client() != nil {
+		if err := s.redis.Client().Publish(ctx, DiscountTierEventsChannel, "changed").Err(); err != nil {
+			log.Printf("Failed to publish discount tier event: %v", err)
+		}
+	}
+}
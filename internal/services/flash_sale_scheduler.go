@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+)
+
+const flashSaleSchedulerLockKey = "lock:flash_sale_scheduler"
+
+// FlashSaleSchedulerConfig controls how often the scheduler checks for due sales. It also
+// sleeps until the next known start or end time, so Interval is only an upper bound.
+type FlashSaleSchedulerConfig struct {
+	Interval time.Duration
+}
+
+// FlashSaleRunResult summarises a single scheduler run
+type FlashSaleRunResult struct {
+	Started int `json:"started"`
+	Ended   int `json:"ended"`
+}
+
+// FlashSaleScheduler moves flash sales from upcoming to active at StartTime and from active
+// to ended at EndTime, invalidating pricing caches and publishing an event for each change
+type FlashSaleScheduler interface {
+	Start()
+	Stop()
+	Wake()
+	RunOnce() (*FlashSaleRunResult, error)
+}
+
+type flashSaleScheduler struct {
+	flashSaleRepo repository.FlashSaleRepository
+	redis         database.RedisClient
+	cfg           FlashSaleSchedulerConfig
+
+	wake     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+	running  sync.Mutex
+}
+
+func NewFlashSaleScheduler(
+	flashSaleRepo repository.FlashSaleRepository,
+	redis database.RedisClient,
+	cfg FlashSaleSchedulerConfig,
+) FlashSaleScheduler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+
+	return &flashSaleScheduler{
+		flashSaleRepo: flashSaleRepo,
+		redis:         redis,
+		cfg:           cfg,
+		wake:          make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler until Stop is called. Sales that became due while the API was down
+// are transitioned on the first run.
+func (s *flashSaleScheduler) Start() {
+	go func() {
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+			case <-s.wake:
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+			case <-s.done:
+				return
+			}
+
+			result, err := s.RunOnce()
+			if err != nil {
+				log.Printf("[FlashSale] Run failed: %v", err)
+			} else if result.Started+result.Ended > 0 {
+				log.Printf("[FlashSale] Sales started: %d, ended: %d", result.Started, result.Ended)
+			}
+			timer.Reset(s.nextWait())
+		}
+	}()
+
+	log.Printf("[FlashSale] Scheduler started (checks at least every %s)", s.cfg.Interval)
+}
+
+// Stop stops the scheduler
+func (s *flashSaleScheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// Wake makes the scheduler run now and recompute its next wake-up, e.g. after an admin
+// changed a sale's start or end time
+func (s *flashSaleScheduler) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// RunOnce ends sales past their EndTime and starts sales past their StartTime. Each change is a
+// conditional status update, so two instances racing on the same sale transition it only once.
+func (s *flashSaleScheduler) RunOnce() (*FlashSaleRunResult, error) {
+	if !s.running.TryLock() {
+		return &FlashSaleRunResult{}, nil
+	}
+	defer s.running.Unlock()
+
+	ctx := context.Background()
+	lockToken, ok := acquireJobLock(ctx, s.redis, flashSaleSchedulerLockKey, s.cfg.Interval)
+	if !ok {
+		return &FlashSaleRunResult{}, nil
+	}
+	defer releaseJobLock(ctx, s.redis, flashSaleSchedulerLockKey, lockToken)
+
+	now := time.Now()
+	result := &FlashSaleRunResult{}
+
+	// End first, so a sale whose whole window passed while we were down goes straight to ended
+	due, err := s.flashSaleRepo.GetDueToEnd(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load flash sales to end: %w", err)
+	}
+	for i := range due {
+		ok, err := s.transition(ctx, &due[i], models.FlashSaleEnded, models.FlashSaleEventEnded)
+		if err != nil {
+			return result, err
+		}
+		if ok {
+			result.Ended++
+		}
+	}
+
+	due, err = s.flashSaleRepo.GetDueToStart(now)
+	if err != nil {
+		return result, fmt.Errorf("failed to load flash sales to start: %w", err)
+	}
+	for i := range due {
+		ok, err := s.transition(ctx, &due[i], models.FlashSaleActive, models.FlashSaleEventStarted)
+		if err != nil {
+			return result, err
+		}
+		if ok {
+			result.Started++
+		}
+	}
+
+	return result, nil
+}
+
+func (s *flashSaleScheduler) transition(ctx context.Context, sale *models.FlashSale, to models.FlashSaleStatus, eventType models.FlashSaleEventType) (bool, error) {
+	ok, err := s.flashSaleRepo.TransitionStatus(sale.ID, sale.Status, to)
+	if err != nil {
+		return false, fmt.Errorf("failed to move flash sale %d to %s: %w", sale.ID, to, err)
+	}
+	if !ok {
+		return false, nil
+	}
+	sale.Status = to
+
+	productIDs := flashSaleProductIDs(sale)
+	invalidatePricingCache(ctx, s.redis, productIDs)
+	publishFlashSaleEvent(ctx, s.redis, newFlashSaleEvent(sale, eventType, productIDs))
+	log.Printf("[FlashSale] Sale %d (%s) is now %s", sale.ID, sale.Name, to)
+	return true, nil
+}
+
+// nextWait returns how long to sleep until the next start or end time, capped at the interval
+func (s *flashSaleScheduler) nextWait() time.Duration {
+	next, err := s.flashSaleRepo.GetNextTransitionTime(time.Now())
+	if err != nil || next == nil {
+		return s.cfg.Interval
+	}
+
+	wait := time.Until(*next)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	if wait > s.cfg.Interval {
+		wait = s.cfg.Interval
+	}
+	return wait
+}
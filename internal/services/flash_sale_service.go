@@ -0,0 +1,463 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"gorm.io/gorm"
+)
+
+// FlashSaleEventsChannel is the Redis pub/sub channel flash sale lifecycle events are published on
+const FlashSaleEventsChannel = "events:flash_sales"
+
+// FlashSaleService manages flash sales and the products in them
+type FlashSaleService interface {
+	ListFlashSales(status models.FlashSaleStatus) ([]models.FlashSale, error)
+	GetCurrentFlashSales() ([]models.FlashSale, error)
+	GetFlashSale(id uint) (*models.FlashSale, error)
+	CreateFlashSale(req *models.CreateFlashSaleRequest) (*models.FlashSale, error)
+	UpdateFlashSale(id uint, req *models.UpdateFlashSaleRequest) (*models.FlashSale, error)
+	CancelFlashSale(id uint) (*models.FlashSale, error)
+	DeleteFlashSale(id uint) error
+
+	GetProducts(flashSaleID uint) ([]models.FlashSaleProduct, error)
+	AddProduct(flashSaleID uint, req *models.FlashSaleProductRequest) (*models.FlashSaleProduct, error)
+	UpdateProduct(flashSaleID, productID uint, variantID *uint, req *models.UpdateFlashSaleProductRequest) (*models.FlashSaleProduct, error)
+	RemoveProduct(flashSaleID, productID uint, variantID *uint) error
+}
+
+type flashSaleService struct {
+	flashSaleRepo repository.FlashSaleRepository
+	productRepo   repository.ProductRepository
+	variantRepo   repository.VariantRepository
+	redis         database.RedisClient
+	scheduler     FlashSaleScheduler
+}
+
+// NewFlashSaleService creates the admin service. The scheduler (may be nil) is woken up after
+// changes so a new start or end time is picked up without waiting for its next tick.
+func NewFlashSaleService(
+	flashSaleRepo repository.FlashSaleRepository,
+	productRepo repository.ProductRepository,
+	variantRepo repository.VariantRepository,
+	redis database.RedisClient,
+	scheduler FlashSaleScheduler,
+) FlashSaleService {
+	return &flashSaleService{
+		flashSaleRepo: flashSaleRepo,
+		productRepo:   productRepo,
+		variantRepo:   variantRepo,
+		redis:         redis,
+		scheduler:     scheduler,
+	}
+}
+
+// ListFlashSales returns all flash sales, or only those with the given status
+func (s *flashSaleService) ListFlashSales(status models.FlashSaleStatus) ([]models.FlashSale, error) {
+	if status == "" {
+		return s.flashSaleRepo.GetAll()
+	}
+	return s.flashSaleRepo.GetByStatus(status)
+}
+
+// GetCurrentFlashSales returns the sales shoppers can see: running now or coming up
+func (s *flashSaleService) GetCurrentFlashSales() ([]models.FlashSale, error) {
+	active, err := s.flashSaleRepo.GetByStatus(models.FlashSaleActive)
+	if err != nil {
+		return nil, err
+	}
+	upcoming, err := s.flashSaleRepo.GetByStatus(models.FlashSaleUpcoming)
+	if err != nil {
+		return nil, err
+	}
+	return append(active, upcoming...), nil
+}
+
+func (s *flashSaleService) GetFlashSale(id uint) (*models.FlashSale, error) {
+	sale, err := s.flashSaleRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("flash sale not found")
+		}
+		return nil, err
+	}
+	return sale, nil
+}
+
+// CreateFlashSale schedules a new sale. It always starts as upcoming; the scheduler makes it
+// active once StartTime is reached.
+func (s *flashSaleService) CreateFlashSale(req *models.CreateFlashSaleRequest) (*models.FlashSale, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("flash sale name is required")
+	}
+	if err := validateFlashSaleWindow(req.StartTime, req.EndTime); err != nil {
+		return nil, err
+	}
+	if !req.EndTime.After(time.Now()) {
+		return nil, errors.New("end time must be in the future")
+	}
+
+	maxPerUser := req.MaxQuantityPerUser
+	if maxPerUser <= 0 {
+		maxPerUser = 1
+	}
+
+	sale := &models.FlashSale{
+		Name:               name,
+		Description:        req.Description,
+		Status:             models.FlashSaleUpcoming,
+		StartTime:          req.StartTime,
+		EndTime:            req.EndTime,
+		DiscountPercentage: req.DiscountPercentage,
+		MaxQuantityPerUser: maxPerUser,
+		TotalStockLimit:    req.TotalStockLimit,
+	}
+
+	if err := s.flashSaleRepo.Create(sale); err != nil {
+		return nil, fmt.Errorf("failed to create flash sale: %w", err)
+	}
+
+	s.wakeScheduler()
+	return sale, nil
+}
+
+// UpdateFlashSale changes a sale that has not ended. Moving the start of a running sale into
+// the future puts it back to upcoming.
+func (s *flashSaleService) UpdateFlashSale(id uint, req *models.UpdateFlashSaleRequest) (*models.FlashSale, error) {
+	sale, err := s.GetFlashSale(id)
+	if err != nil {
+		return nil, err
+	}
+	if isFlashSaleClosed(sale) {
+		return nil, errors.New("flash sale has already ended")
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("flash sale name is required")
+		}
+		sale.Name = name
+	}
+	if req.Description != nil {
+		sale.Description = *req.Description
+	}
+	if req.StartTime != nil {
+		sale.StartTime = *req.StartTime
+	}
+	if req.EndTime != nil {
+		sale.EndTime = *req.EndTime
+	}
+	if err := validateFlashSaleWindow(sale.StartTime, sale.EndTime); err != nil {
+		return nil, err
+	}
+	if req.DiscountPercentage != nil {
+		sale.DiscountPercentage = *req.DiscountPercentage
+	}
+	if req.MaxQuantityPerUser != nil {
+		sale.MaxQuantityPerUser = *req.MaxQuantityPerUser
+		if sale.MaxQuantityPerUser <= 0 {
+			sale.MaxQuantityPerUser = 1
+		}
+	}
+	if req.TotalStockLimit != nil {
+		sale.TotalStockLimit = *req.TotalStockLimit
+	}
+
+	if sale.Status == models.FlashSaleActive && sale.StartTime.After(time.Now()) {
+		sale.Status = models.FlashSaleUpcoming
+	}
+
+	if err := s.flashSaleRepo.Update(sale); err != nil {
+		return nil, fmt.Errorf("failed to update flash sale: %w", err)
+	}
+
+	s.saleChanged(sale, models.FlashSaleEventUpdated)
+	return sale, nil
+}
+
+// CancelFlashSale stops an upcoming or running sale; prices return to normal immediately
+func (s *flashSaleService) CancelFlashSale(id uint) (*models.FlashSale, error) {
+	sale, err := s.GetFlashSale(id)
+	if err != nil {
+		return nil, err
+	}
+	if isFlashSaleClosed(sale) {
+		return nil, errors.New("flash sale has already ended")
+	}
+
+	ok, err := s.flashSaleRepo.TransitionStatus(sale.ID, sale.Status, models.FlashSaleCancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel flash sale: %w", err)
+	}
+	if !ok {
+		return nil, errors.New("flash sale status changed, please retry")
+	}
+	sale.Status = models.FlashSaleCancelled
+
+	s.saleChanged(sale, models.FlashSaleEventEnded)
+	return sale, nil
+}
+
+// DeleteFlashSale removes a sale that is not running
+func (s *flashSaleService) DeleteFlashSale(id uint) error {
+	sale, err := s.GetFlashSale(id)
+	if err != nil {
+		return err
+	}
+	if sale.Status == models.FlashSaleActive {
+		return errors.New("cannot delete an active flash sale, cancel it first")
+	}
+
+	if err := s.flashSaleRepo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete flash sale: %w", err)
+	}
+
+	s.wakeScheduler()
+	return nil
+}
+
+func (s *flashSaleService) GetProducts(flashSaleID uint) ([]models.FlashSaleProduct, error) {
+	if _, err := s.GetFlashSale(flashSaleID); err != nil {
+		return nil, err
+	}
+	return s.flashSaleRepo.GetFlashSaleProducts(flashSaleID)
+}
+
+// AddProduct puts a product, or one of its variants, in a sale at a price below its regular price
+func (s *flashSaleService) AddProduct(flashSaleID uint, req *models.FlashSaleProductRequest) (*models.FlashSaleProduct, error) {
+	sale, err := s.GetFlashSale(flashSaleID)
+	if err != nil {
+		return nil, err
+	}
+	if isFlashSaleClosed(sale) {
+		return nil, errors.New("flash sale has already ended")
+	}
+
+	product, err := s.productRepo.GetByID(req.ProductID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+	if product.Status == models.StatusDiscontinued {
+		return nil, errors.New("product is discontinued")
+	}
+
+	var variant *models.ProductVariant
+	if req.VariantID != nil {
+		variant, err = s.variantRepo.GetByID(*req.VariantID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("variant not found")
+			}
+			return nil, err
+		}
+		if variant.ProductID != product.ID {
+			return nil, errors.New("variant does not belong to the specified product")
+		}
+	}
+	if req.FlashSalePrice >= entryRegularPrice(product, variant) {
+		return nil, errors.New("flash sale price must be lower than the product price")
+	}
+
+	if _, err := s.flashSaleRepo.GetFlashSaleProduct(flashSaleID, req.ProductID, req.VariantID); err == nil {
+		if req.VariantID != nil {
+			return nil, errors.New("variant is already in this flash sale")
+		}
+		return nil, errors.New("product is already in this flash sale")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	item := &models.FlashSaleProduct{
+		FlashSaleID:    flashSaleID,
+		ProductID:      req.ProductID,
+		VariantID:      req.VariantID,
+		FlashSalePrice: req.FlashSalePrice,
+		FlashSaleStock: req.FlashSaleStock,
+	}
+	if err := s.flashSaleRepo.AddProductToFlashSale(item); err != nil {
+		return nil, fmt.Errorf("failed to add product to flash sale: %w", err)
+	}
+
+	item.Product = *product
+	item.Variant = variant
+	s.productsChanged(sale, req.ProductID)
+	return item, nil
+}
+
+// UpdateProduct changes the sale price or stock of a product or variant entry; a stock limit cannot
+// drop below what was sold
+func (s *flashSaleService) UpdateProduct(flashSaleID, productID uint, variantID *uint, req *models.UpdateFlashSaleProductRequest) (*models.FlashSaleProduct, error) {
+	sale, err := s.GetFlashSale(flashSaleID)
+	if err != nil {
+		return nil, err
+	}
+	if isFlashSaleClosed(sale) {
+		return nil, errors.New("flash sale has already ended")
+	}
+
+	item, err := s.flashSaleRepo.GetFlashSaleProduct(flashSaleID, productID, variantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("flash sale product not found")
+		}
+		return nil, err
+	}
+
+	if req.FlashSalePrice != nil {
+		if *req.FlashSalePrice >= entryRegularPrice(&item.Product, item.Variant) {
+			return nil, errors.New("flash sale price must be lower than the product price")
+		}
+		item.FlashSalePrice = *req.FlashSalePrice
+	}
+	if req.FlashSaleStock != nil {
+		if *req.FlashSaleStock != 0 && *req.FlashSaleStock < item.SoldCount {
+			return nil, fmt.Errorf("flash sale stock cannot be lower than the %d already sold", item.SoldCount)
+		}
+		item.FlashSaleStock = *req.FlashSaleStock
+	}
+
+	if err := s.flashSaleRepo.UpdateFlashSaleProduct(item); err != nil {
+		return nil, fmt.Errorf("failed to update flash sale product: %w", err)
+	}
+
+	s.productsChanged(sale, productID)
+	return item, nil
+}
+
+// RemoveProduct takes a product or variant entry out of a sale
+func (s *flashSaleService) RemoveProduct(flashSaleID, productID uint, variantID *uint) error {
+	sale, err := s.GetFlashSale(flashSaleID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.flashSaleRepo.GetFlashSaleProduct(flashSaleID, productID, variantID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("flash sale product not found")
+		}
+		return err
+	}
+
+	if err := s.flashSaleRepo.RemoveProductFromFlashSale(flashSaleID, productID, variantID); err != nil {
+		return fmt.Errorf("failed to remove product from flash sale: %w", err)
+	}
+
+	s.productsChanged(sale, productID)
+	return nil
+}
+
+// saleChanged refreshes prices and tells listeners about a change to a sale
+func (s *flashSaleService) saleChanged(sale *models.FlashSale, eventType models.FlashSaleEventType) {
+	ctx := context.Background()
+	productIDs := flashSaleProductIDs(sale)
+	invalidatePricingCache(ctx, s.redis, productIDs)
+	publishFlashSaleEvent(ctx, s.redis, newFlashSaleEvent(sale, eventType, productIDs))
+	s.wakeScheduler()
+}
+
+// productsChanged refreshes a product's price when its sale terms change while the sale is visible
+func (s *flashSaleService) productsChanged(sale *models.FlashSale, productID uint) {
+	ctx := context.Background()
+	invalidatePricingCache(ctx, s.redis, []uint{productID})
+	publishFlashSaleEvent(ctx, s.redis, newFlashSaleEvent(sale, models.FlashSaleEventUpdated, []uint{productID}))
+}
+
+func (s *flashSaleService) wakeScheduler() {
+	if s.scheduler != nil {
+		s.scheduler.Wake()
+	}
+}
+
+func validateFlashSaleWindow(start, end time.Time) error {
+	if start.IsZero() || end.IsZero() {
+		return errors.New("start time and end time are required")
+	}
+	if !end.After(start) {
+		return errors.New("end time must be after start time")
+	}
+	return nil
+}
+
+// entryRegularPrice is the price a flash sale entry has to beat: the variant's own price for a
+// variant entry, otherwise the product price
+func entryRegularPrice(product *models.Product, variant *models.ProductVariant) float64 {
+	if variant != nil {
+		return variant.Price
+	}
+	return product.Price
+}
+
+func isFlashSaleClosed(sale *models.FlashSale) bool {
+	return sale.Status == models.FlashSaleEnded || sale.Status == models.FlashSaleCancelled
+}
+
+func flashSaleProductIDs(sale *models.FlashSale) []uint {
+	ids := make([]uint, 0, len(sale.Products))
+	for _, product := range sale.Products {
+		ids = append(ids, product.ID)
+	}
+	return ids
+}
+
+func newFlashSaleEvent(sale *models.FlashSale, eventType models.FlashSaleEventType, productIDs []uint) models.FlashSaleEvent {
+	return models.FlashSaleEvent{
+		Type:        eventType,
+		FlashSaleID: sale.ID,
+		Name:        sale.Name,
+		StartTime:   sale.StartTime,
+		EndTime:     sale.EndTime,
+		ProductIDs:  productIDs,
+		OccurredAt:  time.Now(),
+	}
+}
+
+// invalidatePricingCache drops the cached pricing info served by the pricing handler
+func invalidatePricingCache(ctx context.Context, redis database.RedisClient, productIDs []uint) {
+	if redis == nil || len(productIDs) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(productIDs))
+	for _, id := range productIDs {
+		keys = append(keys, fmt.Sprintf("pricing:%d", id))
+	}
+	if err := redis.Delete(ctx, keys...); err != nil {
+		log.Printf("Failed to invalidate pricing cache: %v", err)
+	}
+	for _, id := range productIDs {
+		if err := redis.DeleteByPattern(ctx, fmt.Sprintf("pricing:%d:variant:*", id)); err != nil {
+			log.Printf("Failed to invalidate variant pricing cache: %v", err)
+		}
+	}
+}
+
+// publishFlashSaleEvent announces a lifecycle change on FlashSaleEventsChannel. Without Redis
+// the event is only logged.
+func publishFlashSaleEvent(ctx context.Context, redis database.RedisClient, event models.FlashSaleEvent) {
+	if redis == nil || redis.Client() == nil {
+		log.Printf("[FlashSale] %s: sale %d (%s)", event.Type, event.FlashSaleID, event.Name)
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode flash sale event: %v", err)
+		return
+	}
+	if err := redis.Client().Publish(ctx, FlashSaleEventsChannel, payload).Err(); err != nil {
+		log.Printf("Failed to publish flash sale event: %v", err)
+	}
+}
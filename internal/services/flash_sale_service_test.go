@@ -0,0 +1,104 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestFlashSaleScheduler_RunOnce_TransitionsDueSales(t *testing.T) {
+	flashSaleRepo := new(MockFlashSaleRepository)
+	scheduler := NewFlashSaleScheduler(flashSaleRepo, nil, FlashSaleSchedulerConfig{})
+
+	ending := models.FlashSale{ID: 1, Name: "Midnight", Status: models.FlashSaleActive, Products: []models.Product{{ID: 7}}}
+	starting := models.FlashSale{ID: 2, Name: "Payday", Status: models.FlashSaleUpcoming, Products: []models.Product{{ID: 8}}}
+	raced := models.FlashSale{ID: 3, Name: "Raced", Status: models.FlashSaleUpcoming}
+
+	flashSaleRepo.On("GetDueToEnd", mock.Anything).Return([]models.FlashSale{ending}, nil)
+	flashSaleRepo.On("GetDueToStart", mock.Anything).Return([]models.FlashSale{starting, raced}, nil)
+	flashSaleRepo.On("TransitionStatus", uint(1), models.FlashSaleActive, models.FlashSaleEnded).Return(true, nil).Once()
+	flashSaleRepo.On("TransitionStatus", uint(2), models.FlashSaleUpcoming, models.FlashSaleActive).Return(true, nil).Once()
+	// Another instance got there first
+	flashSaleRepo.On("TransitionStatus", uint(3), models.FlashSaleUpcoming, models.FlashSaleActive).Return(false, nil).Once()
+
+	result, err := scheduler.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, &FlashSaleRunResult{Started: 1, Ended: 1}, result)
+	flashSaleRepo.AssertExpectations(t)
+}
+
+func TestFlashSaleService_CreateFlashSale_ValidatesWindow(t *testing.T) {
+	flashSaleRepo := new(MockFlashSaleRepository)
+	service := NewFlashSaleService(flashSaleRepo, new(MockProductRepository), new(MockVariantRepository), nil, nil)
+	now := time.Now()
+
+	_, err := service.CreateFlashSale(&models.CreateFlashSaleRequest{Name: "Sale", StartTime: now.Add(2 * time.Hour), EndTime: now.Add(time.Hour)})
+	assert.EqualError(t, err, "end time must be after start time")
+
+	_, err = service.CreateFlashSale(&models.CreateFlashSaleRequest{Name: "Sale", StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Hour)})
+	assert.EqualError(t, err, "end time must be in the future")
+
+	flashSaleRepo.On("Create", mock.MatchedBy(func(fs *models.FlashSale) bool {
+		return fs.Status == models.FlashSaleUpcoming && fs.MaxQuantityPerUser == 1
+	})).Return(nil).Once()
+	sale, err := service.CreateFlashSale(&models.CreateFlashSaleRequest{Name: " Sale ", StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour)})
+	assert.NoError(t, err)
+	assert.Equal(t, "Sale", sale.Name)
+	flashSaleRepo.AssertExpectations(t)
+}
+
+func TestFlashSaleService_AddProduct_RequiresLowerPrice(t *testing.T) {
+	flashSaleRepo := new(MockFlashSaleRepository)
+	productRepo := new(MockProductRepository)
+	service := NewFlashSaleService(flashSaleRepo, productRepo, new(MockVariantRepository), nil, nil)
+
+	flashSaleRepo.On("GetByID", uint(1)).Return(&models.FlashSale{ID: 1, Status: models.FlashSaleUpcoming}, nil)
+	productRepo.On("GetByID", uint(5)).Return(&models.Product{ID: 5, Price: 100000, Status: models.StatusAvailable}, nil)
+
+	_, err := service.AddProduct(1, &models.FlashSaleProductRequest{ProductID: 5, FlashSalePrice: 100000, FlashSaleStock: 10})
+	assert.EqualError(t, err, "flash sale price must be lower than the product price")
+
+	flashSaleRepo.On("GetFlashSaleProduct", uint(1), uint(5), (*uint)(nil)).Return(nil, gorm.ErrRecordNotFound).Once()
+	flashSaleRepo.On("AddProductToFlashSale", mock.AnythingOfType("*models.FlashSaleProduct")).Return(nil).Once()
+	item, err := service.AddProduct(1, &models.FlashSaleProductRequest{ProductID: 5, FlashSalePrice: 75000, FlashSaleStock: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 75000.0, item.FlashSalePrice)
+	flashSaleRepo.AssertExpectations(t)
+}
+
+func TestFlashSaleService_UpdateProduct_Stock(t *testing.T) {
+	flashSaleRepo := new(MockFlashSaleRepository)
+	service := NewFlashSaleService(flashSaleRepo, new(MockProductRepository), new(MockVariantRepository), nil, nil)
+
+	flashSaleRepo.On("GetByID", uint(1)).Return(&models.FlashSale{ID: 1, Status: models.FlashSaleActive, EndTime: time.Now().Add(time.Hour)}, nil)
+	flashSaleRepo.On("GetFlashSaleProduct", uint(1), uint(5), (*uint)(nil)).Return(&models.FlashSaleProduct{ID: 10, FlashSaleID: 1, ProductID: 5, FlashSaleStock: 20, SoldCount: 8}, nil)
+
+	stock := 5
+	_, err := service.UpdateProduct(1, 5, nil, &models.UpdateFlashSaleProductRequest{FlashSaleStock: &stock})
+	assert.EqualError(t, err, "flash sale stock cannot be lower than the 8 already sold")
+
+	// 0 lifts the limit, whatever was sold
+	stock = 0
+	flashSaleRepo.On("UpdateFlashSaleProduct", mock.MatchedBy(func(item *models.FlashSaleProduct) bool {
+		return item.FlashSaleStock == 0
+	})).Return(nil).Once()
+	item, err := service.UpdateProduct(1, 5, nil, &models.UpdateFlashSaleProductRequest{FlashSaleStock: &stock})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, item.FlashSaleStock)
+	flashSaleRepo.AssertExpectations(t)
+}
+
+func TestFlashSaleService_DeleteFlashSale_RejectsActiveSale(t *testing.T) {
+	flashSaleRepo := new(MockFlashSaleRepository)
+	service := NewFlashSaleService(flashSaleRepo, new(MockProductRepository), new(MockVariantRepository), nil, nil)
+
+	flashSaleRepo.On("GetByID", uint(1)).Return(&models.FlashSale{ID: 1, Status: models.FlashSaleActive}, nil)
+
+	err := service.DeleteFlashSale(1)
+	assert.EqualError(t, err, "cannot delete an active flash sale, cancel it first")
+	flashSaleRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// flashSaleReservationTTL is how long reservation data outlives the sale it belongs to
+const flashSaleReservationTTL = 24 * time.Hour
+
+// FlashSaleReservationItem is one order line bought at a flash sale price. FlashSaleProductID is
+// the product or variant entry the line was priced with.
+type FlashSaleReservationItem struct {
+	FlashSaleID        uint
+	FlashSaleProductID uint
+	ProductName        string
+	Quantity           int
+}
+
+// FlashSaleStockService guards flash sale stock so a sale cannot sell more than its stock, its
+// total limit or a customer's per-user limit, however many checkouts run at once
+type FlashSaleStockService interface {
+	// Reserve takes the units of an order out of the flash sale counters, all or nothing
+	Reserve(orderNumber string, userID uint, items []FlashSaleReservationItem) error
+	// Release gives an order's reserved units back. Releasing twice is a no-op.
+	Release(orderNumber string) error
+	// Reconcile writes the sold counts and revenue of paid orders to the flash sale rows and
+	// announces the change, so promotion indexes drop entries that are now sold out
+	Reconcile(flashSaleIDs []uint) error
+}
+
+type flashSaleStockService struct {
+	flashSaleRepo repository.FlashSaleRepository
+	redis         database.RedisClient
+}
+
+func NewFlashSaleStockService(flashSaleRepo repository.FlashSaleRepository, redis database.RedisClient) FlashSaleStockService {
+	return &flashSaleStockService{
+		flashSaleRepo: flashSaleRepo,
+		redis:         redis,
+	}
+}
+
+// reserveFlashSaleScript checks every counter against its limit and only then increments them,
+// recording what it took in the reservation hash (KEYS[1]) so it can be given back.
+// A missing counter is seeded from Postgres first, so a Redis restart does not reset the stock.
+//
+// KEYS[2..n]: counters. ARGV[1]: expiry (unix seconds), then per counter: quantity, limit, seed.
+// Returns {0} on success, {-1} when the order already holds a reservation, or
+// {index, current} for the first counter that would go over its limit.
+var reserveFlashSaleScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return {-1}
+end
+local expireAt = tonumber(ARGV[1])
+for i = 2, #KEYS do
+	local base = (i - 2) * 3 + 1
+	if redis.call('SETNX', KEYS[i], ARGV[base + 3]) == 1 then
+		redis.call('EXPIREAT', KEYS[i], expireAt)
+	end
+	local current = tonumber(redis.call('GET', KEYS[i]))
+	local limit = tonumber(ARGV[base + 2])
+	if limit > 0 and current + tonumber(ARGV[base + 1]) > limit then
+		return {i - 1, current}
+	end
+end
+for i = 2, #KEYS do
+	local quantity = ARGV[(i - 2) * 3 + 2]
+	redis.call('INCRBY', KEYS[i], quantity)
+	redis.call('HSET', KEYS[1], KEYS[i], quantity)
+end
+redis.call('EXPIREAT', KEYS[1], expireAt)
+return {0}
+`)
+
+// releaseFlashSaleScript gives back what a reservation took and deletes it. Counters that already
+// expired with their sale are left alone.
+var releaseFlashSaleScript = redis.NewScript(`
+local entries = redis.call('HGETALL', KEYS[1])
+for i = 1, #entries, 2 do
+	if redis.call('EXISTS', entries[i]) == 1 then
+		redis.call('DECRBY', entries[i], entries[i + 1])
+	end
+end
+redis.call('DEL', KEYS[1])
+return #entries / 2
+`)
+
+type flashSaleCounterKind int
+
+const (
+	counterProduct flashSaleCounterKind = iota
+	counterSale
+	counterUser
+)
+
+// flashSaleCounter is one Redis counter touched by a reservation
+type flashSaleCounter struct {
+	key      string
+	kind     flashSaleCounterKind
+	quantity int
+	limit    int // 0 = unlimited
+	seed     int // units already held by pending or paid orders
+	label    string
+}
+
+// exceededError explains to the customer which limit the order ran into
+func (c *flashSaleCounter) exceededError(current int) error {
+	left := c.limit - current
+	switch c.kind {
+	case counterUser:
+		return fmt.Errorf("flash sale %s allows at most %d per customer", c.label, c.limit)
+	case counterSale:
+		if left <= 0 {
+			return fmt.Errorf("flash sale %s is sold out", c.label)
+		}
+		return fmt.Errorf("only %d items left in flash sale %s", left, c.label)
+	default:
+		if left <= 0 {
+			return fmt.Errorf("flash sale stock for %s is sold out", c.label)
+		}
+		return fmt.Errorf("only %d of %s left in the flash sale", left, c.label)
+	}
+}
+
+func (s *flashSaleStockService) Reserve(orderNumber string, userID uint, items []FlashSaleReservationItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	counters, expireAt, err := s.planReservation(userID, items)
+	if err != nil {
+		return err
+	}
+
+	// Without Redis the limits are checked against Postgres only, which cannot stop two
+	// concurrent checkouts from both taking the last units
+	if s.redis == nil || s.redis.Client() == nil {
+		for i := range counters {
+			c := &counters[i]
+			if c.limit > 0 && c.seed+c.quantity > c.limit {
+				return c.exceededError(c.seed)
+			}
+		}
+		return nil
+	}
+
+	keys := make([]string, 0, len(counters)+1)
+	args := make([]interface{}, 0, len(counters)*3+1)
+	keys = append(keys, flashSaleReservationKey(orderNumber))
+	args = append(args, expireAt.Unix())
+	for _, c := range counters {
+		keys = append(keys, c.key)
+		args = append(args, c.quantity, c.limit, c.seed)
+	}
+
+	res, err := reserveFlashSaleScript.Run(context.Background(), s.redis.Client(), keys, args...).Int64Slice()
+	if err != nil {
+		return fmt.Errorf("failed to reserve flash sale stock: %w", err)
+	}
+	switch {
+	case res[0] == 0:
+		return nil
+	case res[0] < 0:
+		return fmt.Errorf("order %s already holds a flash sale reservation", orderNumber)
+	default:
+		return counters[res[0]-1].exceededError(int(res[1]))
+	}
+}
+
+// planReservation turns order lines into the counters they touch: one per product or variant
+// entry, one per sale and one per customer per sale. Lines sharing a counter are added together.
+func (s *flashSaleStockService) planReservation(userID uint, items []FlashSaleReservationItem) ([]flashSaleCounter, time.Time, error) {
+	sales := make(map[uint]*models.FlashSale)
+	byKey := make(map[string]*flashSaleCounter)
+	var order []string
+	var expireAt time.Time
+	now := time.Now()
+
+	add := func(c flashSaleCounter) {
+		if existing, ok := byKey[c.key]; ok {
+			existing.quantity += c.quantity
+			return
+		}
+		byKey[c.key] = &c
+		order = append(order, c.key)
+	}
+
+	for _, item := range items {
+		sale, ok := sales[item.FlashSaleID]
+		if !ok {
+			var err error
+			sale, err = s.flashSaleRepo.GetByID(item.FlashSaleID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, expireAt, errors.New("flash sale not found")
+				}
+				return nil, expireAt, err
+			}
+			if sale.Status != models.FlashSaleActive || now.Before(sale.StartTime) || !now.Before(sale.EndTime) {
+				return nil, expireAt, fmt.Errorf("flash sale %s is not running", sale.Name)
+			}
+			sales[item.FlashSaleID] = sale
+			if end := sale.EndTime.Add(flashSaleReservationTTL); end.After(expireAt) {
+				expireAt = end
+			}
+		}
+
+		saleProduct, err := s.flashSaleRepo.GetFlashSaleProductByID(item.FlashSaleProductID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, expireAt, err
+		}
+		if err != nil || saleProduct.FlashSaleID != item.FlashSaleID {
+			return nil, expireAt, fmt.Errorf("%s is no longer in flash sale %s", item.ProductName, sale.Name)
+		}
+
+		usage, err := s.flashSaleRepo.GetUsage(item.FlashSaleID, item.FlashSaleProductID, userID)
+		if err != nil {
+			return nil, expireAt, fmt.Errorf("failed to load flash sale usage: %w", err)
+		}
+
+		add(flashSaleCounter{
+			key:      fmt.Sprintf("flash_sale:%d:entry:%d:reserved", item.FlashSaleID, item.FlashSaleProductID),
+			kind:     counterProduct,
+			quantity: item.Quantity,
+			limit:    saleProduct.FlashSaleStock,
+			seed:     usage.Product,
+			label:    item.ProductName,
+		})
+		add(flashSaleCounter{
+			key:      fmt.Sprintf("flash_sale:%d:reserved", item.FlashSaleID),
+			kind:     counterSale,
+			quantity: item.Quantity,
+			limit:    sale.TotalStockLimit,
+			seed:     usage.Sale,
+			label:    sale.Name,
+		})
+		add(flashSaleCounter{
+			key:      fmt.Sprintf("flash_sale:%d:user:%d:reserved", item.FlashSaleID, userID),
+			kind:     counterUser,
+			quantity: item.Quantity,
+			limit:    sale.MaxQuantityPerUser,
+			seed:     usage.User,
+			label:    sale.Name,
+		})
+	}
+
+	counters := make([]flashSaleCounter, 0, len(order))
+	for _, key := range order {
+		counters = append(counters, *byKey[key])
+	}
+	return counters, expireAt, nil
+}
+
+func (s *flashSaleStockService) Release(orderNumber string) error {
+	if s.redis == nil || s.redis.Client() == nil {
+		return nil
+	}
+
+	if err := releaseFlashSaleScript.Run(context.Background(), s.redis.Client(), []string{flashSaleReservationKey(orderNumber)}).Err(); err != nil {
+		return fmt.Errorf("failed to release flash sale stock: %w", err)
+	}
+	return nil
+}
+
+func (s *flashSaleStockService) Reconcile(flashSaleIDs []uint) error {
+	ids := uniqueIDs(flashSaleIDs)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var failed error
+	for _, id := range ids {
+		if err := s.flashSaleRepo.ReconcileSales(id); err != nil {
+			log.Printf("[FlashSale] Failed to reconcile sale %d: %v", id, err)
+			failed = fmt.Errorf("failed to reconcile flash sale %d: %w", id, err)
+			continue
+		}
+		publishFlashSaleEvent(context.Background(), s.redis, models.FlashSaleEvent{
+			Type:        models.FlashSaleEventUpdated,
+			FlashSaleID: id,
+			OccurredAt:  time.Now(),
+		})
+	}
+	return failed
+}
+
+func flashSaleReservationKey(orderNumber string) string {
+	return "flash_sale:reservation:" + orderNumber
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFlashSaleStock() (*flashSaleStockService, *MockFlashSaleRepository) {
+	flashSaleRepo := new(MockFlashSaleRepository)
+	flashSaleRepo.On("GetByID", uint(1)).Return(&models.FlashSale{
+		ID:                 1,
+		Name:               "Payday",
+		Status:             models.FlashSaleActive,
+		StartTime:          time.Now().Add(-time.Hour),
+		EndTime:            time.Now().Add(time.Hour),
+		MaxQuantityPerUser: 3,
+	}, nil)
+	flashSaleRepo.On("GetFlashSaleProductByID", uint(100)).Return(&models.FlashSaleProduct{ID: 100, FlashSaleID: 1, ProductID: 10, FlashSaleStock: 5}, nil)
+	flashSaleRepo.On("GetFlashSaleProductByID", uint(101)).Return(&models.FlashSaleProduct{ID: 101, FlashSaleID: 1, ProductID: 11, FlashSaleStock: 50}, nil)
+
+	return NewFlashSaleStockService(flashSaleRepo, nil).(*flashSaleStockService), flashSaleRepo
+}
+
+func TestFlashSaleStock_PlanReservation_SharesSaleAndUserCounters(t *testing.T) {
+	service, flashSaleRepo := newTestFlashSaleStock()
+	flashSaleRepo.On("GetUsage", uint(1), uint(100), uint(7)).Return(&models.FlashSaleUsage{Product: 4, Sale: 20, User: 1}, nil)
+	flashSaleRepo.On("GetUsage", uint(1), uint(101), uint(7)).Return(&models.FlashSaleUsage{Product: 16, Sale: 20, User: 1}, nil)
+
+	counters, expireAt, err := service.planReservation(7, []FlashSaleReservationItem{
+		{FlashSaleID: 1, FlashSaleProductID: 100, ProductName: "Batik Shirt", Quantity: 1},
+		{FlashSaleID: 1, FlashSaleProductID: 101, ProductName: "Tote Bag", Quantity: 1},
+	})
+	assert.NoError(t, err)
+	assert.True(t, expireAt.After(time.Now().Add(time.Hour)))
+
+	// Two product counters, but one sale counter and one customer counter holding both lines
+	assert.Len(t, counters, 4)
+	quantities := make(map[string]int)
+	for _, c := range counters {
+		quantities[c.key] = c.quantity
+	}
+	assert.Equal(t, 1, quantities["flash_sale:1:entry:100:reserved"])
+	assert.Equal(t, 1, quantities["flash_sale:1:entry:101:reserved"])
+	assert.Equal(t, 2, quantities["flash_sale:1:reserved"])
+	assert.Equal(t, 2, quantities["flash_sale:1:user:7:reserved"])
+}
+
+func TestFlashSaleStock_Reserve_EnforcesLimits(t *testing.T) {
+	service, flashSaleRepo := newTestFlashSaleStock()
+	flashSaleRepo.On("GetUsage", uint(1), uint(100), uint(7)).Return(&models.FlashSaleUsage{Product: 4, Sale: 4, User: 0}, nil)
+	flashSaleRepo.On("GetUsage", uint(1), uint(101), uint(7)).Return(&models.FlashSaleUsage{Product: 0, Sale: 4, User: 2}, nil)
+
+	err := service.Reserve("ORD1", 7, []FlashSaleReservationItem{{FlashSaleID: 1, FlashSaleProductID: 100, ProductName: "Batik Shirt", Quantity: 2}})
+	assert.EqualError(t, err, "only 1 of Batik Shirt left in the flash sale")
+
+	err = service.Reserve("ORD1", 7, []FlashSaleReservationItem{{FlashSaleID: 1, FlashSaleProductID: 101, ProductName: "Tote Bag", Quantity: 2}})
+	assert.EqualError(t, err, "flash sale Payday allows at most 3 per customer")
+
+	err = service.Reserve("ORD1", 7, []FlashSaleReservationItem{{FlashSaleID: 1, FlashSaleProductID: 100, ProductName: "Batik Shirt", Quantity: 1}})
+	assert.NoError(t, err)
+}
+
+func TestFlashSaleStock_Reserve_RejectsEndedSale(t *testing.T) {
+	flashSaleRepo := new(MockFlashSaleRepository)
+	flashSaleRepo.On("GetByID", uint(2)).Return(&models.FlashSale{ID: 2, Name: "Midnight", Status: models.FlashSaleEnded}, nil)
+	service := NewFlashSaleStockService(flashSaleRepo, nil)
+
+	err := service.Reserve("ORD1", 7, []FlashSaleReservationItem{{FlashSaleID: 2, FlashSaleProductID: 100, Quantity: 1}})
+	assert.EqualError(t, err, "flash sale Midnight is not running")
+}
+
+func TestFlashSaleStock_Reconcile_OncePerSale(t *testing.T) {
+	flashSaleRepo := new(MockFlashSaleRepository)
+	flashSaleRepo.On("ReconcileSales", uint(1)).Return(nil).Once()
+	flashSaleRepo.On("ReconcileSales", uint(2)).Return(nil).Once()
+	service := NewFlashSaleStockService(flashSaleRepo, nil)
+
+	assert.NoError(t, service.Reconcile([]uint{2, 1, 2}))
+	flashSaleRepo.AssertExpectations(t)
+}
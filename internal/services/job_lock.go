@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/karima-store/internal/database"
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseJobLockScript deletes a job lock only while it still holds the caller's token, so a run
+// that outlived its TTL cannot release the lock another instance has taken since.
+//
+// KEYS[1]: lock key. ARGV[1]: token. Returns 1 when the lock was released.
+var releaseJobLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// acquireJobLock makes sure only one API instance runs a scheduled job at a time. It returns the
+// token to release the lock with; the token is empty when no lock was taken.
+// Without Redis (or when it is unreachable) the job runs anyway.
+func acquireJobLock(ctx context.Context, redis database.RedisClient, key string, ttl time.Duration) (string, bool) {
+	if redis == nil || redis.Client() == nil {
+		return "", true
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Printf("Failed to generate job lock token for %s: %v", key, err)
+		return "", true
+	}
+	token := hex.EncodeToString(b)
+
+	ok, err := redis.Client().SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		log.Printf("Failed to acquire job lock %s: %v", key, err)
+		return "", true
+	}
+	if !ok {
+		return "", false
+	}
+	return token, true
+}
+
+func releaseJobLock(ctx context.Context, redis database.RedisClient, key, token string) {
+	if token == "" || redis == nil || redis.Client() == nil {
+		return
+	}
+	if err := releaseJobLockScript.Run(ctx, redis.Client(), []string{key}, token).Err(); err != nil {
+		log.Printf("Failed to release job lock %s: %v", key, err)
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/karima-store/internal/komerce"
 	"github.com/karima-store/internal/models"
@@ -304,11 +305,15 @@ func (s *komerceService) TrackOrder(shipping, airwayBill string) (*models.Komerc
 	return &response, nil
 }
 
-// Helper function to safely get string from map
+// Helper function to safely get string from map. Komerce sends some IDs and zip codes as
+// numbers, so those are formatted too.
 func getString(m map[string]interface{}, key string) string {
 	if val, exists := m[key]; exists {
-		if str, ok := val.(string); ok {
-			return str
+		switch v := val.(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
 		}
 	}
 	return ""
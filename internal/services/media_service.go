@@ -26,6 +26,8 @@ type MediaService interface {
 	GetMediaByProduct(productID uint) ([]models.Media, error)
 	SetPrimaryMedia(mediaID, productID uint) error
 	ValidateImageFile(fileHeader *multipart.FileHeader) error
+	StoreImage(fileHeader *multipart.FileHeader, folder string) (*StoredImage, error)
+	DeleteStoredImage(image *StoredImage) error
 }
 
 type mediaService struct {
@@ -44,6 +46,16 @@ type UploadResponse struct {
 	Height   int    `json:"height"`
 }
 
+// StoredImage describes an image file written to storage by StoreImage
+type StoredImage struct {
+	URL             string `json:"url"`
+	FileName        string `json:"file_name"`
+	FileSize        int64  `json:"file_size"`
+	ContentType     string `json:"content_type"`
+	StorageProvider string `json:"storage_provider"`
+	StoragePath     string `json:"storage_path"`
+}
+
 func NewMediaService(
 	mediaRepo repository.MediaRepository,
 	productRepo repository.ProductRepository,
@@ -79,6 +91,52 @@ func NewMediaService(
 // UploadImage uploads an image file and creates a media record
 // Supports both local storage and Cloudflare R2
 func (s *mediaService) UploadImage(fileHeader *multipart.FileHeader, productID uint, position int, isPrimary bool) (*UploadResponse, error) {
+	stored, err := s.StoreImage(fileHeader, fmt.Sprintf("products/%d", productID))
+	if err != nil {
+		return nil, err
+	}
+	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+
+	// Create media record
+	media := &models.Media{
+		Type:            models.MediaTypeImage,
+		URL:             stored.URL,
+		AltText:         strings.TrimSuffix(fileHeader.Filename, ext),
+		Status:          models.MediaStatusActive,
+		Position:        position,
+		IsPrimary:       isPrimary,
+		FileName:        stored.FileName,
+		FileSize:        stored.FileSize,
+		ContentType:     stored.ContentType,
+		StorageProvider: stored.StorageProvider,
+		StoragePath:     stored.StoragePath,
+		ProductID:       productID,
+	}
+
+	if err := s.mediaRepo.Create(media); err != nil {
+		// Clean up file if database insert fails
+		_ = s.DeleteStoredImage(stored)
+		return nil, fmt.Errorf("failed to create media record: %w", err)
+	}
+
+	// Set as primary if requested
+	if isPrimary {
+		if err := s.mediaRepo.SetAsPrimary(media.ID); err != nil {
+			return nil, fmt.Errorf("failed to set media as primary: %w", err)
+		}
+	}
+
+	return &UploadResponse{
+		MediaID:  media.ID,
+		URL:      media.URL,
+		FileName: stored.FileName,
+		FileSize: stored.FileSize,
+	}, nil
+}
+
+// StoreImage validates an image and writes it to R2 (under folder) or local storage without
+// creating a media record, for images that belong to something other than a product gallery
+func (s *mediaService) StoreImage(fileHeader *multipart.FileHeader, folder string) (*StoredImage, error) {
 	// Validate file
 	if err := s.ValidateImageFile(fileHeader); err != nil {
 		return nil, err
@@ -109,22 +167,22 @@ func (s *mediaService) UploadImage(fileHeader *multipart.FileHeader, productID u
 		contentType = "image/jpeg" // default
 	}
 
-	var filePath string
-	var storageProvider string
-	var storagePath string
-	var publicURL string
+	stored := &StoredImage{
+		FileName:    uniqueFilename,
+		FileSize:    fileHeader.Size,
+		ContentType: contentType,
+	}
 
 	// Upload to storage based on configuration
 	if s.r2Storage != nil {
 		// Upload to R2
-		key := fmt.Sprintf("products/%d/%s", productID, uniqueFilename)
-		publicURL, err = s.r2Storage.UploadFile(context.Background(), key, fileBytes, contentType)
+		key := fmt.Sprintf("%s/%s", folder, uniqueFilename)
+		stored.URL, err = s.r2Storage.UploadFile(context.Background(), key, fileBytes, contentType)
 		if err != nil {
 			return nil, fmt.Errorf("failed to upload to R2: %w", err)
 		}
-		filePath = key
-		storageProvider = "r2"
-		storagePath = key
+		stored.StorageProvider = "r2"
+		stored.StoragePath = key
 	} else {
 		// Upload to local storage
 		uploadDir := "uploads"
@@ -132,54 +190,27 @@ func (s *mediaService) UploadImage(fileHeader *multipart.FileHeader, productID u
 			return nil, fmt.Errorf("failed to create upload directory: %w", err)
 		}
 
-		filePath = filepath.Join(uploadDir, uniqueFilename)
+		filePath := filepath.Join(uploadDir, uniqueFilename)
 		if err := os.WriteFile(filePath, fileBytes, 0644); err != nil {
 			return nil, fmt.Errorf("failed to save file: %w", err)
 		}
-		publicURL = "/" + filePath
-		storageProvider = "local"
-		storagePath = filePath
-	}
-
-	// Create media record
-	media := &models.Media{
-		Type:            models.MediaTypeImage,
-		URL:             publicURL,
-		AltText:         strings.TrimSuffix(fileHeader.Filename, ext),
-		Status:          models.MediaStatusActive,
-		Position:        position,
-		IsPrimary:       isPrimary,
-		FileName:        uniqueFilename,
-		FileSize:        fileHeader.Size,
-		ContentType:     contentType,
-		StorageProvider: storageProvider,
-		StoragePath:     storagePath,
-		ProductID:       productID,
+		stored.URL = "/" + filePath
+		stored.StorageProvider = "local"
+		stored.StoragePath = filePath
 	}
 
-	if err := s.mediaRepo.Create(media); err != nil {
-		// Clean up file if database insert fails
-		if storageProvider == "local" {
-			os.Remove(filePath)
-		} else if s.r2Storage != nil {
-			s.r2Storage.DeleteFile(context.Background(), storagePath)
-		}
-		return nil, fmt.Errorf("failed to create media record: %w", err)
-	}
+	return stored, nil
+}
 
-	// Set as primary if requested
-	if isPrimary {
-		if err := s.mediaRepo.SetAsPrimary(media.ID); err != nil {
-			return nil, fmt.Errorf("failed to set media as primary: %w", err)
-		}
+// DeleteStoredImage removes a file written by StoreImage
+func (s *mediaService) DeleteStoredImage(image *StoredImage) error {
+	switch {
+	case image.StorageProvider == "local" && image.StoragePath != "":
+		return os.Remove(image.StoragePath)
+	case image.StorageProvider == "r2" && s.r2Storage != nil && image.StoragePath != "":
+		return s.r2Storage.DeleteFile(context.Background(), image.StoragePath)
 	}
-
-	return &UploadResponse{
-		MediaID:  media.ID,
-		URL:      media.URL,
-		FileName: uniqueFilename,
-		FileSize: fileHeader.Size,
-	}, nil
+	return nil
 }
 
 // DeleteMedia deletes a media record and its file
@@ -99,6 +99,10 @@ func (m *MockProductRepositoryForMedia) Update(product *models.Product) error {
 	return args.Error(0)
 }
 
+func (m *MockProductRepositoryForMedia) UpdateWithPriceHistory(product *models.Product, changedBy *uint) error {
+	return m.Called(product, changedBy).Error(0)
+}
+
 func (m *MockProductRepositoryForMedia) Delete(id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
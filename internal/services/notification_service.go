@@ -3,7 +3,9 @@ package services
 import (
 	"fmt"
 	"log"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/karima-store/internal/config"
 	"github.com/karima-store/internal/database"
@@ -17,6 +19,9 @@ type NotificationService interface {
 	SendOrderCreatedNotification(order *models.Order) error
 	SendPaymentSuccessNotification(order *models.Order) error
 	SendShippingNotification(order *models.Order, trackingNumber string) error
+	SendAbandonedCartNotification(user *models.User, cart *models.Cart, coupon *models.Coupon) error
+	SendBackInStockNotification(user *models.User, products []models.Product) error
+	SendPriceDropNotification(user *models.User, product *models.Product, oldPrice float64, price *PriceCalculationResponse) error
 	GetWhatsAppStatus() (string, error)
 	SendTestWhatsAppMessage(phoneNumber string, message string) error
 	ProcessWhatsAppWebhook(data map[string]interface{}) error
@@ -115,7 +120,7 @@ func (s *notificationService) SendOrderCreatedNotification(order *models.Order)
 			"Silakan selesaikan pembayaran Anda.\n\n"+
 			"Terima kasih telah berbelanja di Karima Store! 🙏",
 		order.OrderNumber,
-		formatCurrency(order.TotalAmount),
+		order.TotalAmount,
 	)
 
 	// Get customer phone from order
@@ -147,7 +152,7 @@ func (s *notificationService) SendPaymentSuccessNotification(order *models.Order
 			"Pesanan Anda sedang diproses dan akan segera dikirim.\n\n"+
 			"Terima kasih! 🙏",
 		order.OrderNumber,
-		formatCurrency(order.TotalAmount),
+		order.TotalAmount,
 	)
 
 	// Get customer phone
@@ -191,6 +196,76 @@ func (s *notificationService) SendShippingNotification(order *models.Order, trac
 	return nil
 }
 
+// SendAbandonedCartNotification reminds a customer about items left in their cart (SYNC).
+// It is sent synchronously so the caller can record whether the reminder was delivered.
+func (s *notificationService) SendAbandonedCartNotification(user *models.User, cart *models.Cart, coupon *models.Coupon) error {
+	if s.fonnteClient == nil {
+		return fmt.Errorf("fonnte client not configured")
+	}
+	if user.Phone == "" {
+		return fmt.Errorf("user has no phone number")
+	}
+
+	formattedPhone := formatPhoneNumber(user.Phone)
+	resp, err := s.fonnteClient.SendMessage(formattedPhone, buildAbandonedCartMessage(user, cart, coupon, s.cfg.StoreURL))
+	if err != nil {
+		return fmt.Errorf("failed to send WhatsApp message: %w", err)
+	}
+
+	if !resp.Status {
+		return fmt.Errorf("WhatsApp message failed: %s", resp.Detail)
+	}
+
+	log.Printf("[WhatsApp] Abandoned cart reminder sent to %s for cart %d", formattedPhone, cart.ID)
+	return nil
+}
+
+// SendBackInStockNotification tells a customer that wishlisted products are available again (SYNC)
+func (s *notificationService) SendBackInStockNotification(user *models.User, products []models.Product) error {
+	if s.fonnteClient == nil {
+		return fmt.Errorf("fonnte client not configured")
+	}
+	if user.Phone == "" {
+		return fmt.Errorf("user has no phone number")
+	}
+
+	formattedPhone := formatPhoneNumber(user.Phone)
+	resp, err := s.fonnteClient.SendMessage(formattedPhone, buildBackInStockMessage(user, products, s.cfg.StoreURL))
+	if err != nil {
+		return fmt.Errorf("failed to send WhatsApp message: %w", err)
+	}
+
+	if !resp.Status {
+		return fmt.Errorf("WhatsApp message failed: %s", resp.Detail)
+	}
+
+	log.Printf("[WhatsApp] Back-in-stock alert sent to %s for %d products", formattedPhone, len(products))
+	return nil
+}
+
+// SendPriceDropNotification tells a customer that a wishlisted product got cheaper (SYNC)
+func (s *notificationService) SendPriceDropNotification(user *models.User, product *models.Product, oldPrice float64, price *PriceCalculationResponse) error {
+	if s.fonnteClient == nil {
+		return fmt.Errorf("fonnte client not configured")
+	}
+	if user.Phone == "" {
+		return fmt.Errorf("user has no phone number")
+	}
+
+	formattedPhone := formatPhoneNumber(user.Phone)
+	resp, err := s.fonnteClient.SendMessage(formattedPhone, buildPriceDropMessage(user, product, oldPrice, price, s.cfg.StoreURL))
+	if err != nil {
+		return fmt.Errorf("failed to send WhatsApp message: %w", err)
+	}
+
+	if !resp.Status {
+		return fmt.Errorf("WhatsApp message failed: %s", resp.Detail)
+	}
+
+	log.Printf("[WhatsApp] Price-drop alert sent to %s for product %d", formattedPhone, product.ID)
+	return nil
+}
+
 // GetWhatsAppStatus checks WhatsApp service status
 func (s *notificationService) GetWhatsAppStatus() (string, error) {
 	if s.fonnteClient == nil {
@@ -271,6 +346,99 @@ func removeNonDigits(s string) string {
 	return result
 }
 
+// buildAbandonedCartMessage renders the abandoned cart reminder template
+func buildAbandonedCartMessage(user *models.User, cart *models.Cart, coupon *models.Coupon, storeURL string) string {
+	var items strings.Builder
+	for i, item := range cart.Items {
+		if i == 3 {
+			items.WriteString(fmt.Sprintf("• dan %d produk lainnya\n", len(cart.Items)-i))
+			break
+		}
+		name := item.ProductName
+		if item.VariantName != "" {
+			name += " (" + item.VariantName + ")"
+		}
+		items.WriteString(fmt.Sprintf("• %s x%d\n", name, item.Quantity))
+	}
+
+	message := fmt.Sprintf(
+		"🛒 *Keranjang Anda Menunggu!*\n\n"+
+			"Halo %s, masih ada produk di keranjang Anda:\n\n"+
+			"%s\n"+
+			"Total: *Rp %s*\n\n",
+		user.FullName,
+		items.String(),
+		cart.Subtotal(),
+	)
+
+	if coupon != nil {
+		message += fmt.Sprintf("🎁 Gunakan kode *%s* untuk diskon %s%%", coupon.Code, formatCurrency(coupon.DiscountValue))
+		if coupon.ValidUntil != nil {
+			message += fmt.Sprintf(" (berlaku sampai %s)", coupon.ValidUntil.Format("02 Jan 2006 15:04"))
+		}
+		message += ".\n\n"
+	}
+
+	message += fmt.Sprintf(
+		"Selesaikan pesanan Anda di %s/cart\n\n"+
+			"Terima kasih telah berbelanja di Karima Store! 🙏",
+		strings.TrimRight(storeURL, "/"),
+	)
+	return message
+}
+
+// buildBackInStockMessage renders the back-in-stock template
+func buildBackInStockMessage(user *models.User, products []models.Product, storeURL string) string {
+	storeURL = strings.TrimRight(storeURL, "/")
+
+	var items strings.Builder
+	for _, product := range products {
+		items.WriteString(fmt.Sprintf("• %s - Rp %s\n  %s/products/%s\n", product.Name, formatCurrency(product.Price), storeURL, product.Slug))
+	}
+
+	return fmt.Sprintf(
+		"🔔 *Produk Favorit Anda Tersedia Lagi!*\n\n"+
+			"Halo %s, produk di wishlist Anda sudah kembali tersedia:\n\n"+
+			"%s\n"+
+			"Stok terbatas, segera pesan sebelum kehabisan!\n\n"+
+			"Terima kasih telah berbelanja di Karima Store! 🙏",
+		user.FullName,
+		items.String(),
+	)
+}
+
+// buildPriceDropMessage renders the price-drop template
+func buildPriceDropMessage(user *models.User, product *models.Product, oldPrice float64, price *PriceCalculationResponse, storeURL string) string {
+	message := fmt.Sprintf(
+		"📉 *Harga Turun!*\n\n"+
+			"Halo %s, produk di wishlist Anda sekarang lebih murah:\n\n"+
+			"*%s*\n"+
+			"Sebelumnya: ~Rp %s~\n"+
+			"Sekarang: *Rp %s* (hemat %.0f%%)\n",
+		user.FullName,
+		product.Name,
+		formatCurrency(oldPrice),
+		price.FinalPrice,
+		(oldPrice-price.FinalPrice.Float64())/oldPrice*100,
+	)
+
+	if price.FlashSaleActive && price.FlashSaleEnd != nil {
+		end := *price.FlashSaleEnd
+		if t, err := time.Parse(time.RFC3339, end); err == nil {
+			end = t.Format("02 Jan 2006 15:04")
+		}
+		message += fmt.Sprintf("⚡ Harga flash sale berlaku sampai %s\n", end)
+	}
+
+	message += fmt.Sprintf(
+		"\nLihat produk: %s/products/%s\n\n"+
+			"Terima kasih telah berbelanja di Karima Store! 🙏",
+		strings.TrimRight(storeURL, "/"),
+		product.Slug,
+	)
+	return message
+}
+
 // formatCurrency formats number to Indonesian currency format
 func formatCurrency(amount float64) string {
 	// Simple formatting without external lib
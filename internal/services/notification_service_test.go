@@ -530,7 +530,7 @@ func TestNotificationService_CurrencyFormatting(t *testing.T) {
 
 			order := &models.Order{
 				OrderNumber:   "ORD-CURRENCY",
-				TotalAmount:   tc.amount,
+				TotalAmount:   models.NewMoney(tc.amount),
 				ShippingPhone: "08123456789",
 			}
 
@@ -803,7 +803,7 @@ func TestNotificationService_SendOrderCreatedNotification_VeryLargeAmount(t *tes
 
 	order := &models.Order{
 		OrderNumber:   "ORD-LARGE",
-		TotalAmount:   999999999.99,
+		TotalAmount:   999999999,
 		ShippingPhone: "08123456789",
 	}
 
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+)
+
+const priceDropLockKey = "lock:price_drop_job"
+
+// PriceDropConfig controls when a lower price is worth an alert
+type PriceDropConfig struct {
+	Interval       time.Duration
+	MinDropPercent float64 // drop relative to the user's baseline price
+	DailyCap       int     // alerts per user per rolling 24 hours
+}
+
+// PriceDropRunResult summarises a single job run
+type PriceDropRunResult struct {
+	Products int `json:"products"`
+	Sent     int `json:"sent"`
+	Failed   int `json:"failed"`
+	Capped   int `json:"capped"` // alerts held back by the daily cap
+}
+
+// PriceDropService alerts users over WhatsApp when a wishlisted product gets cheaper,
+// whether through a lower price, a bigger product discount or a flash sale starting
+type PriceDropService interface {
+	Start()
+	Stop()
+	RunOnce() (*PriceDropRunResult, error)
+}
+
+type priceDropService struct {
+	wishlistRepo        repository.WishlistRepository
+	productRepo         repository.ProductRepository
+	variantRepo         repository.VariantRepository
+	pricingService      PricingService
+	notificationService NotificationService
+	redis               database.RedisClient
+	cfg                 PriceDropConfig
+
+	ticker   *time.Ticker
+	done     chan struct{}
+	stopOnce sync.Once
+	running  sync.Mutex
+}
+
+// priceDropCandidate is a wishlist entry whose current price is far enough below its baseline
+type priceDropCandidate struct {
+	entry       models.Wishlist
+	product     *models.Product
+	price       *PriceCalculationResponse
+	dropPercent float64
+}
+
+func NewPriceDropService(
+	wishlistRepo repository.WishlistRepository,
+	productRepo repository.ProductRepository,
+	variantRepo repository.VariantRepository,
+	pricingService PricingService,
+	notificationService NotificationService,
+	redis database.RedisClient,
+	cfg PriceDropConfig,
+) PriceDropService {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 15 * time.Minute
+	}
+	if cfg.DailyCap <= 0 {
+		cfg.DailyCap = 3
+	}
+
+	return &priceDropService{
+		wishlistRepo:        wishlistRepo,
+		productRepo:         productRepo,
+		variantRepo:         variantRepo,
+		pricingService:      pricingService,
+		notificationService: notificationService,
+		redis:               redis,
+		cfg:                 cfg,
+		done:                make(chan struct{}),
+	}
+}
+
+// Start checks wishlisted prices on a ticker until Stop is called
+func (s *priceDropService) Start() {
+	s.ticker = time.NewTicker(s.cfg.Interval)
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				result, err := s.RunOnce()
+				if err != nil {
+					log.Printf("[PriceDrop] Run failed: %v", err)
+					continue
+				}
+				if result.Sent+result.Failed > 0 {
+					log.Printf("[PriceDrop] Alerts sent: %d, failed: %d, capped: %d", result.Sent, result.Failed, result.Capped)
+				}
+			case <-s.done:
+				return
+			}
+		}
+	}()
+
+	log.Printf("[PriceDrop] Job started (every %s, min drop %.0f%%, cap %d/day)", s.cfg.Interval, s.cfg.MinDropPercent, s.cfg.DailyCap)
+}
+
+// Stop stops the job
+func (s *priceDropService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+		if s.ticker != nil {
+			s.ticker.Stop()
+		}
+	})
+}
+
+// RunOnce reprices every wishlisted product with wishlistPrice and compares the result with
+// each wishlist entry's baseline. Baselines follow price increases so a later drop is measured
+// from what the user last saw; they only move down when the user is alerted.
+func (s *priceDropService) RunOnce() (*PriceDropRunResult, error) {
+	if !s.running.TryLock() {
+		return &PriceDropRunResult{}, nil
+	}
+	defer s.running.Unlock()
+
+	ctx := context.Background()
+	lockToken, ok := acquireJobLock(ctx, s.redis, priceDropLockKey, s.cfg.Interval)
+	if !ok {
+		return &PriceDropRunResult{}, nil
+	}
+	defer releaseJobLock(ctx, s.redis, priceDropLockKey, lockToken)
+
+	productIDs, err := s.wishlistRepo.GetWishlistedProductIDs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wishlisted products: %w", err)
+	}
+
+	result := &PriceDropRunResult{Products: len(productIDs)}
+	var candidates []priceDropCandidate
+	for _, productID := range productIDs {
+		found, err := s.checkProduct(productID)
+		if err != nil {
+			return result, err
+		}
+		candidates = append(candidates, found...)
+	}
+
+	// Biggest drops first so the daily cap keeps the most interesting alerts
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].dropPercent > candidates[j].dropPercent
+	})
+
+	sentToday := make(map[uint]int64)
+	since := time.Now().Add(-24 * time.Hour)
+	for _, c := range candidates {
+		user := c.entry.User
+		if !user.IsActive || user.WhatsAppOptOut || user.Phone == "" {
+			// Nobody to tell; move the baseline so an opt-in later does not replay old drops
+			if err := s.wishlistRepo.UpdatePriceBaseline([]uint{c.entry.ID}, c.price.FinalPrice.Float64()); err != nil {
+				return result, fmt.Errorf("failed to update price baseline: %w", err)
+			}
+			continue
+		}
+
+		count, ok := sentToday[user.ID]
+		if !ok {
+			count, err = s.wishlistRepo.CountPriceAlertsSince(user.ID, since)
+			if err != nil {
+				return result, fmt.Errorf("failed to count price alerts: %w", err)
+			}
+		}
+		if count >= int64(s.cfg.DailyCap) {
+			// Baseline stays put, so the alert goes out tomorrow if the price is still low
+			sentToday[user.ID] = count
+			result.Capped++
+			continue
+		}
+
+		if err := s.notificationService.SendPriceDropNotification(&user, c.product, c.entry.PriceBaseline, c.price); err != nil {
+			log.Printf("[PriceDrop] Failed to alert user %d about product %d: %v", user.ID, c.product.ID, err)
+			sentToday[user.ID] = count
+			result.Failed++
+			continue
+		}
+
+		entryID := c.entry.ID
+		alert := &models.PriceAlert{
+			UserID:       user.ID,
+			ProductID:    c.product.ID,
+			WishlistID:   &entryID,
+			OldPrice:     c.entry.PriceBaseline,
+			NewPrice:     c.price.FinalPrice.Float64(),
+			DropPercent:  c.dropPercent,
+			DiscountType: c.price.DiscountType,
+		}
+		if err := s.wishlistRepo.CreatePriceAlert(alert); err != nil {
+			return result, fmt.Errorf("failed to record price alert: %w", err)
+		}
+		if err := s.wishlistRepo.UpdatePriceBaseline([]uint{entryID}, c.price.FinalPrice.Float64()); err != nil {
+			return result, fmt.Errorf("failed to update price baseline: %w", err)
+		}
+		sentToday[user.ID] = count + 1
+		result.Sent++
+	}
+
+	return result, nil
+}
+
+// checkProduct prices one product and returns the wishlist entries due an alert,
+// updating baselines that need no alert along the way
+func (s *priceDropService) checkProduct(productID uint) ([]priceDropCandidate, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil || product.Status != models.StatusAvailable {
+		return nil, nil
+	}
+
+	price, err := wishlistPrice(s.pricingService, s.variantRepo, product)
+	if err != nil || price == nil {
+		return nil, nil
+	}
+
+	entries, err := s.wishlistRepo.GetByProductID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wishlist entries for product %d: %w", productID, err)
+	}
+
+	current := price.FinalPrice.Float64()
+	var rebase []uint
+	var candidates []priceDropCandidate
+	for _, entry := range entries {
+		switch {
+		case entry.PriceBaseline <= 0 || current > entry.PriceBaseline:
+			rebase = append(rebase, entry.ID)
+		case current < entry.PriceBaseline:
+			drop := (entry.PriceBaseline - current) / entry.PriceBaseline * 100
+			if drop >= s.cfg.MinDropPercent {
+				candidates = append(candidates, priceDropCandidate{
+					entry:       entry,
+					product:     product,
+					price:       price,
+					dropPercent: drop,
+				})
+			}
+		}
+	}
+
+	if err := s.wishlistRepo.UpdatePriceBaseline(rebase, current); err != nil {
+		return nil, fmt.Errorf("failed to update price baseline: %w", err)
+	}
+	return candidates, nil
+}
+
+// wishlistPrice is the lowest retail unit price of the product or any of its variants in stock,
+// so a flash sale on a single size or color counts as a drop. Outside a flash sale the
+// product's discount percentage is taken off the quote, as the storefront shows it; checkout
+// quotes do not apply it. Returns nil when nothing has a price.
+func wishlistPrice(pricingService PricingService, variantRepo repository.VariantRepository, product *models.Product) (*PriceCalculationResponse, error) {
+	variantIDs := []*uint{nil}
+	if variantRepo != nil {
+		variants, err := variantRepo.GetByProductID(product.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load variants for product %d: %w", product.ID, err)
+		}
+		for i := range variants {
+			if variants[i].Stock > 0 {
+				variantIDs = append(variantIDs, &variants[i].ID)
+			}
+		}
+	}
+
+	var lowest *PriceCalculationResponse
+	for _, variantID := range variantIDs {
+		price, err := pricingService.CalculatePrice(PriceCalculationRequest{
+			ProductID:    product.ID,
+			VariantID:    variantID,
+			Quantity:     1,
+			CustomerType: CustomerRetail,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !price.FlashSaleActive && product.Discount > 0 && product.Discount < 100 {
+			off := price.FinalPrice.Percent(product.Discount)
+			price.FinalPrice -= off
+			price.Discount += off
+			price.Savings += off
+			price.DiscountType = "product_discount"
+		}
+		if price.FinalPrice > 0 && (lowest == nil || price.FinalPrice < lowest.FinalPrice) {
+			lowest = price
+		}
+	}
+	return lowest, nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestPriceDropService builds the job over mocks; every product gets the given variants
+func newTestPriceDropService(variants ...models.ProductVariant) (*priceDropService, *MockWishlistRepository, *MockProductRepository, *MockNotificationService) {
+	return newTestPriceDropServiceWithPromotions(NewPromotionIndex(emptyFlashSaleRepo(), nil, PromotionIndexConfig{}), variants...)
+}
+
+func newTestPriceDropServiceWithPromotions(promotions PromotionIndex, variants ...models.ProductVariant) (*priceDropService, *MockWishlistRepository, *MockProductRepository, *MockNotificationService) {
+	wishlistRepo := new(MockWishlistRepository)
+	productRepo := new(MockProductRepository)
+	variantRepo := new(MockVariantRepository)
+	variantRepo.On("GetByProductID", mock.Anything).Return(variants, nil)
+	for i := range variants {
+		variantRepo.On("GetByID", variants[i].ID).Return(&variants[i], nil)
+	}
+	notifier := new(MockNotificationService)
+
+	pricing := NewPricingService(productRepo, variantRepo, promotions, newTestDiscountTiers(), new(MockCouponRepository), nil, new(MockShippingZoneRepository), nil)
+	service := NewPriceDropService(wishlistRepo, productRepo, variantRepo, pricing, notifier, nil, PriceDropConfig{
+		MinDropPercent: 10,
+		DailyCap:       2,
+	}).(*priceDropService)
+	return service, wishlistRepo, productRepo, notifier
+}
+
+func emptyFlashSaleRepo() *MockFlashSaleRepository {
+	flashSaleRepo := new(MockFlashSaleRepository)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil)
+	return flashSaleRepo
+}
+
+func TestPriceDropService_RunOnce_AlertsAboveThreshold(t *testing.T) {
+	service, wishlistRepo, productRepo, notifier := newTestPriceDropService()
+
+	// Discount raised to 20%: 200,000 -> 160,000
+	product := &models.Product{ID: 3, Name: "Linen Shirt", Price: 200000, Discount: 20, Status: models.StatusAvailable}
+	productRepo.On("GetByID", uint(3)).Return(product, nil)
+	wishlistRepo.On("GetWishlistedProductIDs").Return([]uint{3}, nil)
+
+	alice := models.User{ID: 1, FullName: "Alice", Phone: "0811", IsActive: true}
+	bob := models.User{ID: 2, FullName: "Bob", Phone: "0812", IsActive: true}
+	carol := models.User{ID: 3, FullName: "Carol", Phone: "0813", IsActive: true}
+	wishlistRepo.On("GetByProductID", uint(3)).Return([]models.Wishlist{
+		{ID: 10, UserID: 1, User: alice, ProductID: 3, PriceBaseline: 200000}, // 20% drop
+		{ID: 11, UserID: 2, User: bob, ProductID: 3, PriceBaseline: 170000},   // ~6% drop, below threshold
+		{ID: 12, UserID: 3, User: carol, ProductID: 3, PriceBaseline: 0},      // no baseline yet
+	}, nil)
+
+	wishlistRepo.On("UpdatePriceBaseline", []uint{12}, 160000.0).Return(nil).Once()
+	wishlistRepo.On("CountPriceAlertsSince", uint(1), mock.Anything).Return(int64(0), nil)
+	notifier.On("SendPriceDropNotification", mock.MatchedBy(func(u *models.User) bool { return u.ID == 1 }), product, 200000.0,
+		mock.MatchedBy(func(p *PriceCalculationResponse) bool { return p.FinalPrice == 160000 })).Return(nil).Once()
+	wishlistRepo.On("CreatePriceAlert", mock.MatchedBy(func(a *models.PriceAlert) bool {
+		return a.UserID == 1 && a.OldPrice == 200000 && a.NewPrice == 160000 && a.DropPercent == 20 && a.DiscountType == "product_discount"
+	})).Return(nil).Once()
+	wishlistRepo.On("UpdatePriceBaseline", []uint{10}, 160000.0).Return(nil).Once()
+
+	result, err := service.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, &PriceDropRunResult{Products: 1, Sent: 1}, result)
+	wishlistRepo.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestPriceDropService_RunOnce_RespectsDailyCap(t *testing.T) {
+	service, wishlistRepo, productRepo, notifier := newTestPriceDropService()
+
+	productRepo.On("GetByID", uint(3)).Return(&models.Product{ID: 3, Price: 100000, Status: models.StatusAvailable}, nil)
+	productRepo.On("GetByID", uint(4)).Return(&models.Product{ID: 4, Price: 50000, Status: models.StatusAvailable}, nil)
+	wishlistRepo.On("GetWishlistedProductIDs").Return([]uint{3, 4}, nil)
+
+	alice := models.User{ID: 1, FullName: "Alice", Phone: "0811", IsActive: true}
+	wishlistRepo.On("GetByProductID", uint(3)).Return([]models.Wishlist{{ID: 10, UserID: 1, User: alice, ProductID: 3, PriceBaseline: 150000}}, nil)
+	wishlistRepo.On("GetByProductID", uint(4)).Return([]models.Wishlist{{ID: 11, UserID: 1, User: alice, ProductID: 4, PriceBaseline: 60000}}, nil)
+	wishlistRepo.On("UpdatePriceBaseline", []uint(nil), mock.Anything).Return(nil)
+
+	// One alert already went out today; the cap of 2 leaves room for the biggest drop only
+	wishlistRepo.On("CountPriceAlertsSince", uint(1), mock.Anything).Return(int64(1), nil).Once()
+	notifier.On("SendPriceDropNotification", mock.Anything, mock.MatchedBy(func(p *models.Product) bool { return p.ID == 3 }), 150000.0, mock.Anything).Return(nil).Once()
+	wishlistRepo.On("CreatePriceAlert", mock.Anything).Return(nil).Once()
+	wishlistRepo.On("UpdatePriceBaseline", []uint{10}, 100000.0).Return(nil).Once()
+
+	result, err := service.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Sent)
+	assert.Equal(t, 1, result.Capped)
+	notifier.AssertNumberOfCalls(t, "SendPriceDropNotification", 1)
+}
+
+func TestPriceDropService_RunOnce_SendFailureKeepsBaseline(t *testing.T) {
+	service, wishlistRepo, productRepo, notifier := newTestPriceDropService()
+
+	productRepo.On("GetByID", uint(3)).Return(&models.Product{ID: 3, Price: 100000, Status: models.StatusAvailable}, nil)
+	wishlistRepo.On("GetWishlistedProductIDs").Return([]uint{3}, nil)
+	alice := models.User{ID: 1, Phone: "0811", IsActive: true}
+	wishlistRepo.On("GetByProductID", uint(3)).Return([]models.Wishlist{{ID: 10, UserID: 1, User: alice, ProductID: 3, PriceBaseline: 150000}}, nil)
+	wishlistRepo.On("UpdatePriceBaseline", []uint(nil), mock.Anything).Return(nil)
+	wishlistRepo.On("CountPriceAlertsSince", uint(1), mock.Anything).Return(int64(0), nil)
+	notifier.On("SendPriceDropNotification", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("device offline"))
+
+	result, err := service.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	wishlistRepo.AssertNotCalled(t, "UpdatePriceBaseline", []uint{10}, mock.Anything)
+	wishlistRepo.AssertNotCalled(t, "CreatePriceAlert", mock.Anything)
+}
+
+func TestPriceDropService_RunOnce_VariantFlashSale(t *testing.T) {
+	// Only the red variant is on sale: 100,000 -> 70,000
+	variantID := uint(30)
+	flashSaleRepo := new(MockFlashSaleRepository)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{{
+		ID:        1,
+		Status:    models.FlashSaleActive,
+		StartTime: time.Now().Add(-time.Hour),
+		EndTime:   time.Now().Add(time.Hour),
+	}}, nil)
+	flashSaleRepo.On("GetFlashSaleProducts", uint(1)).Return([]models.FlashSaleProduct{
+		{ID: 10, ProductID: 3, VariantID: &variantID, FlashSalePrice: 70000, FlashSaleStock: 10},
+	}, nil)
+	promotions := NewPromotionIndex(flashSaleRepo, nil, PromotionIndexConfig{})
+	assert.NoError(t, promotions.Refresh())
+
+	service, wishlistRepo, productRepo, notifier := newTestPriceDropServiceWithPromotions(promotions,
+		models.ProductVariant{ID: 30, ProductID: 3, Color: "Red", Price: 100000, Stock: 4},
+		models.ProductVariant{ID: 31, ProductID: 3, Color: "Blue", Price: 100000, Stock: 4},
+	)
+
+	productRepo.On("GetByID", uint(3)).Return(&models.Product{ID: 3, Price: 100000, Status: models.StatusAvailable}, nil)
+	wishlistRepo.On("GetWishlistedProductIDs").Return([]uint{3}, nil)
+	alice := models.User{ID: 1, Phone: "0811", IsActive: true}
+	wishlistRepo.On("GetByProductID", uint(3)).Return([]models.Wishlist{{ID: 10, UserID: 1, User: alice, ProductID: 3, PriceBaseline: 100000}}, nil)
+	wishlistRepo.On("UpdatePriceBaseline", []uint(nil), mock.Anything).Return(nil)
+	wishlistRepo.On("CountPriceAlertsSince", uint(1), mock.Anything).Return(int64(0), nil)
+	notifier.On("SendPriceDropNotification", mock.Anything, mock.Anything, 100000.0,
+		mock.MatchedBy(func(p *PriceCalculationResponse) bool { return p.FinalPrice == 70000 && p.FlashSaleActive })).Return(nil).Once()
+	wishlistRepo.On("CreatePriceAlert", mock.MatchedBy(func(a *models.PriceAlert) bool {
+		return a.NewPrice == 70000 && a.DiscountType == "flash_sale"
+	})).Return(nil).Once()
+	wishlistRepo.On("UpdatePriceBaseline", []uint{10}, 70000.0).Return(nil).Once()
+
+	result, err := service.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Sent)
+	wishlistRepo.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
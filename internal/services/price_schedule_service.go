@@ -0,0 +1,238 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PriceHistoryResponse is a product's price history over a window together with the lowest
+// selling price it had in that window. Selling prices include the product discount and are whole
+// rupiah.
+type PriceHistoryResponse struct {
+	ProductID    uint                  `json:"product_id"`
+	VariantID    *uint                 `json:"variant_id,omitempty"`
+	Days         int                   `json:"days"`
+	Since        time.Time             `json:"since"`
+	CurrentPrice models.Money          `json:"current_price"`
+	LowestPrice  models.Money          `json:"lowest_price"`
+	Entries      []models.PriceHistory `json:"entries"`
+}
+
+// PriceScheduleService lets merchandisers schedule price changes and exposes the price history
+// the scheduler writes
+type PriceScheduleService interface {
+	SchedulePriceChange(req *models.ScheduledPriceChangeRequest, actorID *uint) (*models.ScheduledPriceChange, error)
+	ListPriceChanges(filter models.PriceChangeListFilter, limit, offset int) ([]models.ScheduledPriceChange, int64, error)
+	GetPriceChange(id uint) (*models.ScheduledPriceChange, error)
+	CancelPriceChange(id uint) error
+	// GetPriceHistory returns the changes of the last days days, 30 by default
+	GetPriceHistory(productID uint, variantID *uint, days int) (*PriceHistoryResponse, error)
+}
+
+type priceScheduleService struct {
+	scheduleRepo repository.PriceScheduleRepository
+	productRepo  repository.ProductRepository
+	variantRepo  repository.VariantRepository
+	scheduler    PriceScheduler
+}
+
+func NewPriceScheduleService(
+	scheduleRepo repository.PriceScheduleRepository,
+	productRepo repository.ProductRepository,
+	variantRepo repository.VariantRepository,
+	scheduler PriceScheduler,
+) PriceScheduleService {
+	return &priceScheduleService{
+		scheduleRepo: scheduleRepo,
+		productRepo:  productRepo,
+		variantRepo:  variantRepo,
+		scheduler:    scheduler,
+	}
+}
+
+func (s *priceScheduleService) SchedulePriceChange(req *models.ScheduledPriceChangeRequest, actorID *uint) (*models.ScheduledPriceChange, error) {
+	if req.Price == nil && req.ComparePrice == nil && req.Discount == nil {
+		return nil, errors.New("price change must set price, compare_price or discount")
+	}
+	if req.Price != nil && *req.Price <= 0 {
+		return nil, errors.New("price must be greater than 0")
+	}
+	if req.Discount != nil && (*req.Discount < 0 || *req.Discount >= 100) {
+		return nil, errors.New("discount must be between 0 and 100")
+	}
+	if req.EffectiveAt.IsZero() {
+		return nil, errors.New("effective_at is required")
+	}
+
+	if _, err := s.productRepo.GetByID(req.ProductID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if req.VariantID != nil {
+		if req.ComparePrice != nil || req.Discount != nil {
+			return nil, errors.New("variant price changes can only set price")
+		}
+		variant, err := s.variantRepo.GetByID(*req.VariantID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("variant not found")
+			}
+			return nil, fmt.Errorf("failed to get variant: %w", err)
+		}
+		if variant.ProductID != req.ProductID {
+			return nil, errors.New("variant does not belong to the specified product")
+		}
+	}
+
+	change := &models.ScheduledPriceChange{
+		ProductID:    req.ProductID,
+		VariantID:    req.VariantID,
+		Price:        req.Price,
+		ComparePrice: req.ComparePrice,
+		Discount:     req.Discount,
+		EffectiveAt:  req.EffectiveAt,
+		Status:       models.PriceChangePending,
+		Reason:       strings.TrimSpace(req.Reason),
+		CreatedBy:    actorID,
+	}
+	if err := s.scheduleRepo.Create(change); err != nil {
+		return nil, fmt.Errorf("failed to create price change: %w", err)
+	}
+
+	// The scheduler sleeps until the next effective time it knows about
+	if s.scheduler != nil {
+		s.scheduler.Wake()
+	}
+	return change, nil
+}
+
+func (s *priceScheduleService) ListPriceChanges(filter models.PriceChangeListFilter, limit, offset int) ([]models.ScheduledPriceChange, int64, error) {
+	limit, offset = clampCouponPage(limit, offset)
+	return s.scheduleRepo.List(filter, limit, offset)
+}
+
+func (s *priceScheduleService) GetPriceChange(id uint) (*models.ScheduledPriceChange, error) {
+	change, err := s.scheduleRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("price change not found")
+		}
+		return nil, err
+	}
+	return change, nil
+}
+
+func (s *priceScheduleService) CancelPriceChange(id uint) error {
+	change, err := s.GetPriceChange(id)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.scheduleRepo.Cancel(id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel price change: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("price change is already %s", change.Status)
+	}
+	return nil
+}
+
+// GetPriceHistory rewinds the current prices through the window's entries to find the prices at
+// its start, then replays them to find the lowest selling price
+func (s *priceScheduleService) GetPriceHistory(productID uint, variantID *uint, days int) (*PriceHistoryResponse, error) {
+	if days <= 0 {
+		days = 30
+	}
+	if days > 365 {
+		days = 365
+	}
+
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	price := product.Price
+	if variantID != nil {
+		variant, err := s.variantRepo.GetByID(*variantID)
+		if err != nil || variant.ProductID != productID {
+			if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("variant not found")
+			}
+			return nil, fmt.Errorf("failed to get variant: %w", err)
+		}
+		price = variant.Price
+	}
+	discount := product.Discount
+
+	since := time.Now().AddDate(0, 0, -days)
+	entries, err := s.scheduleRepo.GetHistory(productID, variantID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+
+	response := &PriceHistoryResponse{
+		ProductID:    productID,
+		VariantID:    variantID,
+		Days:         days,
+		Since:        since,
+		CurrentPrice: sellingPrice(price, discount),
+		Entries:      entries,
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		price, discount = rewindPriceEntry(entries[i], variantID, price, discount)
+	}
+	response.LowestPrice = sellingPrice(price, discount)
+	for _, entry := range entries {
+		price, discount = replayPriceEntry(entry, variantID, price, discount)
+		if selling := sellingPrice(price, discount); selling < response.LowestPrice {
+			response.LowestPrice = selling
+		}
+	}
+
+	return response, nil
+}
+
+// rewindPriceEntry returns the price and discount before the entry. Product-level price changes
+// only move the price of a variant-less history.
+func rewindPriceEntry(entry models.PriceHistory, variantID *uint, price, discount float64) (float64, float64) {
+	if entry.VariantID != nil {
+		return entry.OldPrice, discount
+	}
+	if variantID == nil {
+		price = entry.OldPrice
+	}
+	return price, entry.OldDiscount
+}
+
+func replayPriceEntry(entry models.PriceHistory, variantID *uint, price, discount float64) (float64, float64) {
+	if entry.VariantID != nil {
+		return entry.NewPrice, discount
+	}
+	if variantID == nil {
+		price = entry.NewPrice
+	}
+	return price, entry.NewDiscount
+}
+
+// sellingPrice applies the product discount the way PricingService does
+func sellingPrice(price, discount float64) models.Money {
+	base := models.NewMoney(price)
+	if discount > 0 && discount < 100 {
+		return base - base.Percent(discount)
+	}
+	return base
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockPriceScheduleRepository
+type MockPriceScheduleRepository struct {
+	mock.Mock
+}
+
+func (m *MockPriceScheduleRepository) Create(change *models.ScheduledPriceChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockPriceScheduleRepository) GetByID(id uint) (*models.ScheduledPriceChange, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ScheduledPriceChange), args.Error(1)
+}
+
+func (m *MockPriceScheduleRepository) List(filter models.PriceChangeListFilter, limit, offset int) ([]models.ScheduledPriceChange, int64, error) {
+	args := m.Called(filter, limit, offset)
+	return args.Get(0).([]models.ScheduledPriceChange), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockPriceScheduleRepository) Cancel(id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPriceScheduleRepository) GetDue(now time.Time, limit int) ([]models.ScheduledPriceChange, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]models.ScheduledPriceChange), args.Error(1)
+}
+
+func (m *MockPriceScheduleRepository) GetNextEffectiveTime(now time.Time) (*time.Time, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockPriceScheduleRepository) Apply(change *models.ScheduledPriceChange, at time.Time) (*models.PriceHistory, error) {
+	args := m.Called(change, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PriceHistory), args.Error(1)
+}
+
+func (m *MockPriceScheduleRepository) MarkFailed(id uint, reason string) error {
+	args := m.Called(id, reason)
+	return args.Error(0)
+}
+
+func (m *MockPriceScheduleRepository) GetHistory(productID uint, variantID *uint, since time.Time) ([]models.PriceHistory, error) {
+	args := m.Called(productID, variantID, since)
+	return args.Get(0).([]models.PriceHistory), args.Error(1)
+}
+
+func TestPriceScheduler_RunOnce(t *testing.T) {
+	price := 90000.0
+	repo := new(MockPriceScheduleRepository)
+	repo.On("GetDue", mock.Anything, priceSchedulerBatchSize).Return([]models.ScheduledPriceChange{
+		{ID: 1, ProductID: 10, Price: &price, Status: models.PriceChangePending},
+		{ID: 2, ProductID: 11, Price: &price, Status: models.PriceChangePending},
+		{ID: 3, ProductID: 12, Price: &price, Status: models.PriceChangePending},
+	}, nil)
+	repo.On("Apply", mock.MatchedBy(func(c *models.ScheduledPriceChange) bool { return c.ID == 1 }), mock.Anything).
+		Return(&models.PriceHistory{ProductID: 10, OldPrice: 100000, NewPrice: price}, nil)
+	// Applied by another instance in the meantime
+	repo.On("Apply", mock.MatchedBy(func(c *models.ScheduledPriceChange) bool { return c.ID == 2 }), mock.Anything).
+		Return(nil, nil)
+	repo.On("Apply", mock.MatchedBy(func(c *models.ScheduledPriceChange) bool { return c.ID == 3 }), mock.Anything).
+		Return(nil, gorm.ErrRecordNotFound)
+	repo.On("MarkFailed", uint(3), "product or variant not found").Return(nil)
+
+	scheduler := NewPriceScheduler(repo, nil, nil, PriceSchedulerConfig{})
+	result, err := scheduler.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Applied)
+	assert.Equal(t, 1, result.Failed)
+	repo.AssertExpectations(t)
+}
+
+func TestPriceScheduleService_SchedulePriceChange(t *testing.T) {
+	price := 75000.0
+	discount := 10.0
+	productRepo := new(MockProductRepository)
+	productRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1, Price: 100000}, nil)
+	variantRepo := new(MockVariantRepository)
+	variantRepo.On("GetByID", uint(5)).Return(&models.ProductVariant{ID: 5, ProductID: 2, Price: 100000}, nil)
+	repo := new(MockPriceScheduleRepository)
+	repo.On("Create", mock.AnythingOfType("*models.ScheduledPriceChange")).Return(nil)
+	service := NewPriceScheduleService(repo, productRepo, variantRepo, nil)
+
+	actorID := uint(9)
+	effectiveAt := time.Now().Add(24 * time.Hour)
+	change, err := service.SchedulePriceChange(&models.ScheduledPriceChangeRequest{
+		ProductID: 1, Price: &price, Discount: &discount, EffectiveAt: effectiveAt, Reason: " Payday sale ",
+	}, &actorID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.PriceChangePending, change.Status)
+	assert.Equal(t, "Payday sale", change.Reason)
+	assert.Equal(t, &actorID, change.CreatedBy)
+
+	_, err = service.SchedulePriceChange(&models.ScheduledPriceChangeRequest{ProductID: 1, EffectiveAt: effectiveAt}, nil)
+	assert.EqualError(t, err, "price change must set price, compare_price or discount")
+
+	variantID := uint(5)
+	_, err = service.SchedulePriceChange(&models.ScheduledPriceChangeRequest{ProductID: 1, VariantID: &variantID, Discount: &discount, EffectiveAt: effectiveAt}, nil)
+	assert.EqualError(t, err, "variant price changes can only set price")
+
+	_, err = service.SchedulePriceChange(&models.ScheduledPriceChangeRequest{ProductID: 1, VariantID: &variantID, Price: &price, EffectiveAt: effectiveAt}, nil)
+	assert.EqualError(t, err, "variant does not belong to the specified product")
+}
+
+func TestPriceScheduleService_GetPriceHistory_LowestPrice(t *testing.T) {
+	productRepo := new(MockProductRepository)
+	productRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1, Price: 120000, Discount: 0}, nil)
+	repo := new(MockPriceScheduleRepository)
+	repo.On("GetHistory", uint(1), (*uint)(nil), mock.Anything).Return([]models.PriceHistory{
+		// Payday sale: 10% off 100.000, then back to 100.000, then raised to 120.000
+		{ID: 1, ProductID: 1, OldPrice: 100000, NewPrice: 100000, OldDiscount: 0, NewDiscount: 10},
+		{ID: 2, ProductID: 1, OldPrice: 100000, NewPrice: 100000, OldDiscount: 10, NewDiscount: 0},
+		{ID: 3, ProductID: 1, OldPrice: 100000, NewPrice: 120000},
+	}, nil)
+	service := NewPriceScheduleService(repo, productRepo, new(MockVariantRepository), nil)
+
+	history, err := service.GetPriceHistory(1, nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 30, history.Days)
+	assert.Equal(t, models.Money(120000), history.CurrentPrice)
+	assert.Equal(t, models.Money(90000), history.LowestPrice)
+	assert.Len(t, history.Entries, 3)
+}
+
+func TestPriceScheduleService_GetPriceHistory_Variant(t *testing.T) {
+	variantID := uint(5)
+	productRepo := new(MockProductRepository)
+	productRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1, Price: 100000, Discount: 20}, nil)
+	variantRepo := new(MockVariantRepository)
+	variantRepo.On("GetByID", variantID).Return(&models.ProductVariant{ID: variantID, ProductID: 1, Price: 150000}, nil)
+	repo := new(MockPriceScheduleRepository)
+	repo.On("GetHistory", uint(1), &variantID, mock.Anything).Return([]models.PriceHistory{
+		// The product discount went from 0 to 20%, then the variant price from 200.000 to 150.000
+		{ID: 1, ProductID: 1, OldPrice: 100000, NewPrice: 100000, OldDiscount: 0, NewDiscount: 20},
+		{ID: 2, ProductID: 1, VariantID: &variantID, OldPrice: 200000, NewPrice: 150000},
+	}, nil)
+	service := NewPriceScheduleService(repo, productRepo, variantRepo, nil)
+
+	history, err := service.GetPriceHistory(1, &variantID, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Money(120000), history.CurrentPrice)
+	assert.Equal(t, models.Money(120000), history.LowestPrice)
+}
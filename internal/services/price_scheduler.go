@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/repository"
+	"gorm.io/gorm"
+)
+
+const priceSchedulerLockKey = "lock:price_scheduler"
+
+// priceSchedulerBatchSize caps the changes applied per run; the next run follows right away
+const priceSchedulerBatchSize = 100
+
+// PriceSchedulerConfig controls how often the scheduler checks for due price changes. It also
+// sleeps until the next known effective time, so Interval is only an upper bound.
+type PriceSchedulerConfig struct {
+	Interval time.Duration
+}
+
+// PriceScheduleRunResult summarises a single scheduler run
+type PriceScheduleRunResult struct {
+	Applied int `json:"applied"`
+	Failed  int `json:"failed"`
+}
+
+// PriceScheduler applies scheduled price changes at their effective time, records each one in
+// the price history and invalidates the product and pricing caches
+type PriceScheduler interface {
+	Start()
+	Stop()
+	Wake()
+	RunOnce() (*PriceScheduleRunResult, error)
+}
+
+type priceScheduler struct {
+	scheduleRepo   repository.PriceScheduleRepository
+	productService ProductService
+	redis          database.RedisClient
+	cfg            PriceSchedulerConfig
+
+	wake     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+	running  sync.Mutex
+}
+
+func NewPriceScheduler(
+	scheduleRepo repository.PriceScheduleRepository,
+	productService ProductService,
+	redis database.RedisClient,
+	cfg PriceSchedulerConfig,
+) PriceScheduler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+
+	return &priceScheduler{
+		scheduleRepo:   scheduleRepo,
+		productService: productService,
+		redis:          redis,
+		cfg:            cfg,
+		wake:           make(chan struct{}, 1),
+		done:           make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler until Stop is called. Changes that became due while the API was down
+// are applied on the first run, in effective order.
+func (s *priceScheduler) Start() {
+	go func() {
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+			case <-s.wake:
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+			case <-s.done:
+				return
+			}
+
+			result, err := s.RunOnce()
+			if err != nil {
+				log.Printf("[PriceScheduler] Run failed: %v", err)
+			} else if result.Applied+result.Failed > 0 {
+				log.Printf("[PriceScheduler] Price changes applied: %d, failed: %d", result.Applied, result.Failed)
+			}
+
+			wait := s.nextWait()
+			if result != nil && result.Applied+result.Failed >= priceSchedulerBatchSize {
+				wait = 0
+			}
+			timer.Reset(wait)
+		}
+	}()
+
+	log.Printf("[PriceScheduler] Scheduler started (checks at least every %s)", s.cfg.Interval)
+}
+
+// Stop stops the scheduler
+func (s *priceScheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// Wake makes the scheduler run now and recompute its next wake-up, e.g. after a change was
+// scheduled
+func (s *priceScheduler) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// RunOnce applies the changes that are due. A change whose product or variant is gone is marked
+// failed; any other error stops the run so the change is retried next time.
+func (s *priceScheduler) RunOnce() (*PriceScheduleRunResult, error) {
+	if !s.running.TryLock() {
+		return &PriceScheduleRunResult{}, nil
+	}
+	defer s.running.Unlock()
+
+	ctx := context.Background()
+	lockToken, ok := acquireJobLock(ctx, s.redis, priceSchedulerLockKey, s.cfg.Interval)
+	if !ok {
+		return &PriceScheduleRunResult{}, nil
+	}
+	defer releaseJobLock(ctx, s.redis, priceSchedulerLockKey, lockToken)
+
+	now := time.Now()
+	result := &PriceScheduleRunResult{}
+
+	due, err := s.scheduleRepo.GetDue(now, priceSchedulerBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load due price changes: %w", err)
+	}
+
+	for i := range due {
+		change := &due[i]
+		entry, err := s.scheduleRepo.Apply(change, now)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := s.scheduleRepo.MarkFailed(change.ID, "product or variant not found"); err != nil {
+				return result, fmt.Errorf("failed to mark price change %d failed: %w", change.ID, err)
+			}
+			result.Failed++
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to apply price change %d: %w", change.ID, err)
+		}
+		if entry == nil {
+			continue
+		}
+
+		if s.productService != nil {
+			if err := s.productService.InvalidateProductCache(change.ProductID); err != nil {
+				log.Printf("[PriceScheduler] Failed to invalidate product %d cache: %v", change.ProductID, err)
+			}
+		}
+		invalidatePricingCache(ctx, s.redis, []uint{change.ProductID})
+		result.Applied++
+	}
+
+	return result, nil
+}
+
+// nextWait returns how long to sleep until the next effective time, capped at the interval
+func (s *priceScheduler) nextWait() time.Duration {
+	next, err := s.scheduleRepo.GetNextEffectiveTime(time.Now())
+	if err != nil || next == nil {
+		return s.cfg.Interval
+	}
+
+	wait := time.Until(*next)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	if wait > s.cfg.Interval {
+		wait = s.cfg.Interval
+	}
+	return wait
+}
@@ -3,6 +3,8 @@ package services
 import (
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/karima-store/internal/models"
@@ -12,19 +14,22 @@ import (
 type PricingService interface {
 	CalculatePrice(req PriceCalculationRequest) (*PriceCalculationResponse, error)
 	CalculateShippingCost(req ShippingCalculationRequest) (*ShippingCalculationResponse, error)
-	CheckFreeShipping(orderAmount float64, regionCode string) (bool, error)
+	CheckFreeShipping(orderAmount models.Money, regionCode string) (bool, error)
 	CalculateOrderSummary(items []PriceCalculationRequest, shippingReq ShippingCalculationRequest, customerType CustomerType) (*OrderSummary, error)
-	CalculateCouponDiscount(req CouponCalculationRequest) (float64, string, error)
+	CalculateCouponDiscount(req CouponCalculationRequest) (*CouponDiscount, error)
 	ApplyCouponToPriceCalculation(resp *PriceCalculationResponse, couponReq CouponCalculationRequest) error
+	ApplyCouponToOrderSummary(summary *OrderSummary, couponReq CouponCalculationRequest) error
 }
 
 type pricingService struct {
 	productRepo      repository.ProductRepository
 	variantRepo      repository.VariantRepository
-	flashSaleRepo    repository.FlashSaleRepository
+	promotions       PromotionIndex
+	discountTiers    DiscountTierIndex
 	couponRepo       repository.CouponRepository
+	promotionRepo    repository.PromotionRepository
 	shippingZoneRepo repository.ShippingZoneRepository
-	taxRate          float64 // Default tax rate (e.g., 0.11 for 11% VAT)
+	taxService       TaxService
 }
 
 type CustomerType string
@@ -34,82 +39,199 @@ const (
 	CustomerReseller CustomerType = "reseller"
 )
 
+// CustomerTypeFor returns the prices a user buys at; guests and everyone outside the reseller
+// customer group pay retail
+func CustomerTypeFor(user *models.User) CustomerType {
+	if user != nil && user.IsReseller() {
+		return CustomerReseller
+	}
+	return CustomerRetail
+}
+
 type PriceCalculationRequest struct {
-	ProductID    uint         `json:"product_id"`
-	VariantID    *uint        `json:"variant_id,omitempty"`
-	Quantity     int          `json:"quantity"`
-	CustomerType CustomerType `json:"customer_type"`
+	ProductID uint  `json:"product_id"`
+	VariantID *uint `json:"variant_id,omitempty"`
+	Quantity  int   `json:"quantity"`
+	// CustomerType is set by the server from the signed-in user
+	CustomerType CustomerType `json:"-"`
 }
 
+// PriceCalculationResponse amounts are whole rupiah. BasePrice, FinalPrice and Savings are line
+// totals; OriginalPrice and Discount are per unit.
 type PriceCalculationResponse struct {
-	BasePrice       float64 `json:"base_price"`
-	FinalPrice      float64 `json:"final_price"`
-	Discount        float64 `json:"discount"`
-	DiscountType    string  `json:"discount_type"` // "none", "flash_sale", "reseller", "bulk", "coupon"
-	OriginalPrice   float64 `json:"original_price"`
-	Savings         float64 `json:"savings"`
-	FlashSaleActive bool    `json:"flash_sale_active"`
-	FlashSaleEnd    *string `json:"flash_sale_end,omitempty"`
-	CouponApplied   bool    `json:"coupon_applied"`
-	CouponCode      *string `json:"coupon_code,omitempty"`
-	CouponDiscount  float64 `json:"coupon_discount"`
+	BasePrice       models.Money `json:"base_price"`
+	FinalPrice      models.Money `json:"final_price"`
+	Discount        models.Money `json:"discount"`
+	DiscountType    string       `json:"discount_type"` // "none", "flash_sale", "reseller", "bulk", "coupon"
+	OriginalPrice   models.Money `json:"original_price"`
+	Savings         models.Money `json:"savings"`
+	FlashSaleActive bool         `json:"flash_sale_active"`
+	FlashSaleEnd    *string      `json:"flash_sale_end,omitempty"`
+	FlashSaleID     *uint        `json:"flash_sale_id,omitempty"`
+	CouponApplied   bool         `json:"coupon_applied"`
+	CouponCode      *string      `json:"coupon_code,omitempty"`
+	CouponDiscount  models.Money `json:"coupon_discount"`
+
+	// FlashSaleProductID is the matched flash sale entry; FlashSaleMatch says whether it was
+	// the variant's own entry ("variant") or the product-level one ("product")
+	FlashSaleProductID *uint  `json:"flash_sale_product_id,omitempty"`
+	FlashSaleMatch     string `json:"flash_sale_match,omitempty"`
+
+	// DiscountTierID is the reseller or bulk tier that priced the item
+	DiscountTierID *uint `json:"discount_tier_id,omitempty"`
 }
 
 type CouponCalculationRequest struct {
 	Code           string
 	UserID         uint
-	PurchaseAmount float64
+	PurchaseAmount models.Money // used when Items is empty
 	CustomerType   CustomerType
+	Items          []CouponLineItem
+}
+
+// CouponLineItem is one cart line a coupon is checked against. Subtotal is the line total after
+// item discounts; when it is zero the line is priced with CalculatePrice.
+type CouponLineItem struct {
+	ProductID uint         `json:"product_id"`
+	VariantID *uint        `json:"variant_id,omitempty"`
+	Quantity  int          `json:"quantity"`
+	Subtotal  models.Money `json:"subtotal"`
+}
+
+// CouponDiscount is the result of applying a coupon to a set of lines. The discount is worked
+// out on the eligible lines only and split across them in Lines; ExcludedItems says why the
+// other lines did not qualify.
+type CouponDiscount struct {
+	CouponID         uint                 `json:"coupon_id"`
+	Code             string               `json:"code"`
+	Name             string               `json:"name"`
+	Discount         models.Money         `json:"discount"`
+	EligibleSubtotal models.Money         `json:"eligible_subtotal"`
+	Lines            []CouponLineDiscount `json:"lines,omitempty"`
+	ExcludedItems    []CouponExclusion    `json:"excluded_items,omitempty"`
+}
+
+// CouponLineDiscount is the share of a coupon discount taken off one eligible line
+type CouponLineDiscount struct {
+	ProductID uint         `json:"product_id"`
+	VariantID *uint        `json:"variant_id,omitempty"`
+	Subtotal  models.Money `json:"subtotal"`
+	Discount  models.Money `json:"discount"`
+}
+
+// CouponExclusion is a line the coupon does not apply to
+type CouponExclusion struct {
+	ProductID uint   `json:"product_id"`
+	VariantID *uint  `json:"variant_id,omitempty"`
+	Reason    string `json:"reason"`
 }
 
 type ShippingCalculationRequest struct {
 	Items        []ShippingItem `json:"items"`
 	Destination  string         `json:"destination"`   // subdistrict_id for RajaOngkir
 	ShippingType string         `json:"shipping_type"` // "jne", "tiki", "pos", etc.
+	// QuotedCost is a courier rate already quoted for the parcel, such as the Komerce rate
+	// checkout quotes. It replaces the zone rates and is never read from a request body.
+	QuotedCost *models.Money `json:"-"`
 }
 
+// ShippingItem is one line of a parcel. Weight and Dimensions left out are taken from the product.
 type ShippingItem struct {
 	ProductID  uint
 	VariantID  *uint
 	Quantity   int
 	Weight     float64 // in kg
-	Dimensions string  // LxWxH format
+	Dimensions string  // LxWxH format, in cm
 }
 
+// ShippingCalculationResponse weights are in kg. Couriers bill each item by the larger of its
+// actual and volumetric weight; TotalWeight is the sum of those chargeable weights.
 type ShippingCalculationResponse struct {
-	TotalWeight   float64 `json:"total_weight"` // in kg
-	ShippingCost  float64 `json:"shipping_cost"`
-	ShippingType  string  `json:"shipping_type"`
-	EstimatedDays int     `json:"estimated_days"`
+	TotalWeight      float64      `json:"total_weight"`
+	ActualWeight     float64      `json:"actual_weight"`
+	VolumetricWeight float64      `json:"volumetric_weight"`
+	ShippingCost     models.Money `json:"shipping_cost"`
+	ShippingType     string       `json:"shipping_type"`
+	EstimatedDays    int          `json:"estimated_days"`
 }
 
+// OrderSummary amounts are whole rupiah. Total is exactly the sum of its parts:
+// Subtotal - TotalDiscount - PromotionDiscount - CouponDiscount + ShippingCost - ShippingDiscount + TaxAmount.
 type OrderSummary struct {
-	Subtotal       float64 `json:"subtotal"`
-	ShippingCost   float64 `json:"shipping_cost"`
-	TotalWeight    float64 `json:"total_weight"`
-	Total          float64 `json:"total"`
-	ItemCount      int     `json:"item_count"`
-	TotalDiscount  float64 `json:"total_discount"`
-	CouponDiscount float64 `json:"coupon_discount"`
-	TaxAmount      float64 `json:"tax_amount"`
-	CouponApplied  bool    `json:"coupon_applied"`
-	CouponCode     *string `json:"coupon_code,omitempty"`
+	Subtotal          models.Money `json:"subtotal"`
+	ShippingCost      models.Money `json:"shipping_cost"`
+	TotalWeight       float64      `json:"total_weight"`
+	ActualWeight      float64      `json:"actual_weight"`
+	VolumetricWeight  float64      `json:"volumetric_weight"`
+	Total             models.Money `json:"total"`
+	ItemCount         int          `json:"item_count"`
+	TotalDiscount     models.Money `json:"total_discount"`
+	PromotionDiscount models.Money `json:"promotion_discount"`
+	ShippingDiscount  models.Money `json:"shipping_discount"`
+	CouponDiscount    models.Money `json:"coupon_discount"`
+	TaxAmount         models.Money `json:"tax_amount"`
+	CouponApplied     bool         `json:"coupon_applied"`
+	CouponCode        *string      `json:"coupon_code,omitempty"`
+
+	// TaxRegion is the destination region the taxes were resolved for
+	TaxRegion string    `json:"tax_region,omitempty"`
+	Taxes     []TaxLine `json:"taxes"`
+
+	Items []OrderSummaryItem `json:"items"`
+
+	// Promotions lists every automatic promotion applied, in the order it was applied
+	Promotions []AppliedPromotion `json:"promotions,omitempty"`
+
+	// Coupon explains how the coupon discount was split and which items it did not cover
+	Coupon *CouponDiscount `json:"coupon,omitempty"`
+
+	// FreeShipping is set when the destination's shipping zone ships the order free. The zone's
+	// threshold is checked against the goods amount after every discount, coupons included.
+	FreeShipping bool `json:"free_shipping"`
+
+	freeShippingThreshold *models.Money // nil outside a free shipping zone
+	zoneShippingDiscount  models.Money  // part of ShippingDiscount given by the zone
+}
+
+// goodsAmount is what the customer pays for the goods, after every discount and before
+// shipping and taxes
+func (s *OrderSummary) goodsAmount() models.Money {
+	return s.Subtotal - s.TotalDiscount - s.PromotionDiscount - s.CouponDiscount
+}
+
+// OrderSummaryItem is the priced form of one line of an order summary
+type OrderSummaryItem struct {
+	ProductID    uint         `json:"product_id"`
+	VariantID    *uint        `json:"variant_id,omitempty"`
+	Quantity     int          `json:"quantity"`
+	UnitPrice    models.Money `json:"unit_price"`
+	TotalPrice   models.Money `json:"total_price"`
+	Savings      models.Money `json:"savings"`
+	DiscountType string       `json:"discount_type"`
+	FlashSaleID  *uint        `json:"flash_sale_id,omitempty"`
+
+	FlashSaleProductID *uint `json:"flash_sale_product_id,omitempty"`
 }
 
 func NewPricingService(
 	productRepo repository.ProductRepository,
 	variantRepo repository.VariantRepository,
-	flashSaleRepo repository.FlashSaleRepository,
+	promotions PromotionIndex,
+	discountTiers DiscountTierIndex,
 	couponRepo repository.CouponRepository,
+	promotionRepo repository.PromotionRepository,
 	shippingZoneRepo repository.ShippingZoneRepository,
+	taxService TaxService,
 ) PricingService {
 	return &pricingService{
 		productRepo:      productRepo,
 		variantRepo:      variantRepo,
-		flashSaleRepo:    flashSaleRepo,
+		promotions:       promotions,
+		discountTiers:    discountTiers,
 		couponRepo:       couponRepo,
+		promotionRepo:    promotionRepo,
 		shippingZoneRepo: shippingZoneRepo,
-		taxRate:          0.11, // Default 11% VAT for Indonesia
+		taxService:       taxService,
 	}
 }
 
@@ -126,7 +248,7 @@ func (s *pricingService) CalculatePrice(req PriceCalculationRequest) (*PriceCalc
 		return nil, fmt.Errorf("product not found: %w", err)
 	}
 
-	var basePrice float64
+	var basePrice models.Money
 	var variant *models.ProductVariant
 
 	// Use variant price if variant ID is provided
@@ -138,13 +260,13 @@ func (s *pricingService) CalculatePrice(req PriceCalculationRequest) (*PriceCalc
 		if variant.ProductID != req.ProductID {
 			return nil, errors.New("variant does not belong to the specified product")
 		}
-		basePrice = variant.Price
+		basePrice = models.NewMoney(variant.Price)
 	} else {
-		basePrice = product.Price
+		basePrice = models.NewMoney(product.Price)
 	}
 
-	// Check for flash sale
-	flashSalePrice, flashSaleEnd, isFlashSaleActive := s.checkFlashSale(product.ID, req.VariantID)
+	// Check for flash sale (served from the in-memory promotions index)
+	flashSale := s.promotions.FlashSale(product.ID, req.VariantID)
 
 	// Calculate final price
 	response := &PriceCalculationResponse{
@@ -153,25 +275,30 @@ func (s *pricingService) CalculatePrice(req PriceCalculationRequest) (*PriceCalc
 	}
 
 	// Priority: Flash Sale > Reseller Tiering > Bulk Discount > Retail
-	if isFlashSaleActive && flashSalePrice > 0 {
+	if flashSale != nil && flashSale.Price > 0 {
 		// Flash sale price takes precedence
-		response.FinalPrice = flashSalePrice
-		response.Discount = basePrice - flashSalePrice
+		response.FinalPrice = models.NewMoney(flashSale.Price)
+		response.Discount = basePrice - response.FinalPrice
 		response.DiscountType = "flash_sale"
 		response.FlashSaleActive = true
-		response.FlashSaleEnd = flashSaleEnd
+		response.FlashSaleEnd = flashSale.EndTime
+		response.FlashSaleID = &flashSale.FlashSaleID
+		response.FlashSaleProductID = &flashSale.FlashSaleProductID
+		response.FlashSaleMatch = flashSale.Match
 	} else if req.CustomerType == CustomerReseller {
 		// Apply reseller tiering
-		resellerPrice, discount := s.calculateResellerPrice(basePrice, req.Quantity)
+		resellerPrice, discount, tier := s.calculateTierPrice(models.DiscountTierReseller, product, basePrice, req.Quantity)
 		response.FinalPrice = resellerPrice
 		response.Discount = discount
 		response.DiscountType = "reseller"
+		response.DiscountTierID = tier
 	} else {
 		// Apply bulk discount for retail customers
-		bulkPrice, discount := s.calculateBulkDiscount(basePrice, req.Quantity)
+		bulkPrice, discount, tier := s.calculateTierPrice(models.DiscountTierBulk, product, basePrice, req.Quantity)
 		response.FinalPrice = bulkPrice
 		response.Discount = discount
 		if discount > 0 {
+			response.DiscountTierID = tier
 			response.DiscountType = "bulk"
 		} else {
 			response.DiscountType = "none"
@@ -181,97 +308,45 @@ func (s *pricingService) CalculatePrice(req PriceCalculationRequest) (*PriceCalc
 	// Calculate savings
 	response.Savings = response.OriginalPrice - response.FinalPrice
 
-	// Apply quantity multiplier
-	response.FinalPrice = response.FinalPrice * float64(req.Quantity)
-	response.BasePrice = response.BasePrice * float64(req.Quantity)
-	response.Savings = response.Savings * float64(req.Quantity)
+	// Apply quantity multiplier to the rounded unit amounts
+	response.FinalPrice = response.FinalPrice.Times(req.Quantity)
+	response.BasePrice = response.BasePrice.Times(req.Quantity)
+	response.Savings = response.Savings.Times(req.Quantity)
 
 	return response, nil
 }
 
-// checkFlashSale checks if there's an active flash sale for the product/variant
-func (s *pricingService) checkFlashSale(productID uint, variantID *uint) (float64, *string, bool) {
-	flashSales, err := s.flashSaleRepo.GetActiveFlashSales()
-	if err != nil || len(flashSales) == 0 {
-		return 0, nil, false
-	}
-
-	now := time.Now()
-
-	for _, fs := range flashSales {
-		// Check if flash sale is currently active
-		if fs.Status != models.FlashSaleActive {
-			continue
-		}
-		if now.Before(fs.StartTime) || now.After(fs.EndTime) {
-			continue
-		}
-
-		// Check if product is in flash sale
-		for _, fsp := range fs.Products {
-			if fsp.ID == productID {
-				// Get flash sale product details
-				flashSaleProducts, err := s.flashSaleRepo.GetFlashSaleProducts(fs.ID)
-				if err != nil {
-					continue
-				}
-
-				for _, fspDetail := range flashSaleProducts {
-					if fspDetail.ProductID == productID {
-						return fspDetail.FlashSalePrice, formatTime(fs.EndTime), true
-					}
-				}
-			}
-		}
-	}
+// Flash sale rules reported in PriceCalculationResponse.FlashSaleMatch
+const (
+	FlashSaleMatchVariant = "variant" // the variant has its own flash sale entry
+	FlashSaleMatchProduct = "product" // the product-level entry covers the variant
+)
 
-	return 0, nil, false
+// ActiveFlashSale is the flash sale entry that sets an item's price
+type ActiveFlashSale struct {
+	Price              float64
+	EndTime            *string
+	FlashSaleID        uint
+	FlashSaleProductID uint
+	Match              string // FlashSaleMatchVariant or FlashSaleMatchProduct
 }
 
-// calculateResellerPrice applies reseller tiering based on quantity
-func (s *pricingService) calculateResellerPrice(basePrice float64, quantity int) (float64, float64) {
-	var discountPercent float64
-
-	// Reseller tiering based on quantity
-	switch {
-	case quantity >= 100:
-		discountPercent = 0.30 // 30% discount for 100+ items
-	case quantity >= 50:
-		discountPercent = 0.25 // 25% discount for 50-99 items
-	case quantity >= 20:
-		discountPercent = 0.20 // 20% discount for 20-49 items
-	case quantity >= 10:
-		discountPercent = 0.15 // 15% discount for 10-19 items
-	case quantity >= 5:
-		discountPercent = 0.10 // 10% discount for 5-9 items
-	default:
-		discountPercent = 0.05 // 5% discount for 1-4 items
+// calculateTierPrice applies the reseller or bulk tier the quantity reaches for the product and
+// returns the unit price, the unit discount and the tier used
+func (s *pricingService) calculateTierPrice(kind models.DiscountTierKind, product *models.Product, basePrice models.Money, quantity int) (models.Money, models.Money, *uint) {
+	if s.discountTiers == nil {
+		return basePrice, 0, nil
 	}
 
-	discountAmount := basePrice * discountPercent
-	finalPrice := basePrice - discountAmount
-
-	return finalPrice, discountAmount
-}
-
-// calculateBulkDiscount applies bulk discount for retail customers
-func (s *pricingService) calculateBulkDiscount(basePrice float64, quantity int) (float64, float64) {
-	var discountPercent float64
-
-	// Bulk discount for retail customers (less aggressive than reseller)
-	switch {
-	case quantity >= 10:
-		discountPercent = 0.10 // 10% discount for 10+ items
-	case quantity >= 5:
-		discountPercent = 0.05 // 5% discount for 5-9 items
-	default:
-		discountPercent = 0.00 // No discount for less than 5 items
+	tier := s.discountTiers.Tier(kind, product.ID, product.Category, quantity)
+	if tier == nil {
+		return basePrice, 0, nil
 	}
 
-	discountAmount := basePrice * discountPercent
+	discountAmount := basePrice.Percent(tier.DiscountPercent)
 	finalPrice := basePrice - discountAmount
 
-	return finalPrice, discountAmount
+	return finalPrice, discountAmount, &tier.ID
 }
 
 // CalculateShippingCost calculates shipping cost based on weight and destination
@@ -280,45 +355,112 @@ func (s *pricingService) CalculateShippingCost(req ShippingCalculationRequest) (
 		return nil, errors.New("no items provided")
 	}
 
-	// Calculate total weight
-	totalWeight := s.calculateTotalWeight(req.Items)
+	weights, err := s.calculateWeights(req.Items, req.ShippingType)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get shipping zone for destination (if region is provided)
-	var shippingZone *models.ShippingZone
-	if req.Destination != "" {
-		zone, err := s.shippingZoneRepo.GetByRegion(req.Destination)
-		if err == nil {
-			shippingZone = zone
+	var shippingCost models.Money
+	if req.QuotedCost != nil {
+		shippingCost = *req.QuotedCost
+	} else {
+		// Get shipping zone for destination (if region is provided)
+		var shippingZone *models.ShippingZone
+		if req.Destination != "" {
+			zone, err := s.shippingZoneRepo.GetByRegion(req.Destination)
+			if err == nil {
+				shippingZone = zone
+			}
 		}
-	}
 
-	// Calculate shipping cost using zone or default rates
-	shippingCost := s.calculateShippingCostWithZone(totalWeight, req.ShippingType, shippingZone)
+		// Calculate shipping cost using zone or default rates
+		shippingCost = s.calculateShippingCostWithZone(weights.chargeable, req.ShippingType, shippingZone)
+	}
 
 	// Estimate delivery days based on shipping type
 	estimatedDays := s.estimateDeliveryDays(req.ShippingType)
 
 	return &ShippingCalculationResponse{
-		TotalWeight:   totalWeight,
-		ShippingCost:  shippingCost,
-		ShippingType:  req.ShippingType,
-		EstimatedDays: estimatedDays,
+		TotalWeight:      weights.chargeable,
+		ActualWeight:     weights.actual,
+		VolumetricWeight: weights.volumetric,
+		ShippingCost:     shippingCost,
+		ShippingType:     req.ShippingType,
+		EstimatedDays:    estimatedDays,
 	}, nil
 }
 
-// calculateTotalWeight calculates the total weight of all items
-func (s *pricingService) calculateTotalWeight(items []ShippingItem) float64 {
-	var totalWeight float64
+// defaultVolumetricDivisor is the cm³ per kg couriers without an entry in volumetricDivisors
+// bill bulky parcels by
+const defaultVolumetricDivisor = 6000
+
+// volumetricDivisors holds each courier's volumetric divisor in cm³ per kg
+var volumetricDivisors = map[string]float64{
+	"jne":     6000,
+	"tiki":    6000,
+	"pos":     6000,
+	"sicepat": 6000,
+}
+
+// VolumetricDivisor returns the cm³ per kg the courier bills bulky parcels by
+func VolumetricDivisor(courier string) float64 {
+	if divisor, ok := volumetricDivisors[strings.ToLower(courier)]; ok {
+		return divisor
+	}
+	return defaultVolumetricDivisor
+}
+
+// parcelWeights are the weights of a parcel in kg
+type parcelWeights struct {
+	actual     float64
+	volumetric float64
+	chargeable float64
+}
+
+// calculateWeights works out the weights of the items for the courier. Items without a weight or
+// dimensions take them from their product.
+func (s *pricingService) calculateWeights(items []ShippingItem, courier string) (parcelWeights, error) {
+	resolved := make([]ShippingItem, len(items))
+	for i, item := range items {
+		if item.ProductID != 0 && (item.Weight <= 0 || item.Dimensions == "") {
+			product, err := s.productRepo.GetByID(item.ProductID)
+			if err != nil {
+				return parcelWeights{}, fmt.Errorf("product not found: %w", err)
+			}
+			if item.Weight <= 0 {
+				item.Weight = product.Weight
+			}
+			if item.Dimensions == "" {
+				item.Dimensions = product.Dimensions
+			}
+		}
+		resolved[i] = item
+	}
+	return weighParcel(resolved, courier), nil
+}
+
+// weighParcel works out the actual, volumetric and chargeable weight of the items for the courier
+func weighParcel(items []ShippingItem, courier string) parcelWeights {
+	var weights parcelWeights
+	divisor := VolumetricDivisor(courier)
 
 	for _, item := range items {
-		totalWeight += item.Weight * float64(item.Quantity)
+		quantity := float64(item.Quantity)
+		chargeable := item.Weight
+		if dimensions, ok := models.ParseDimensions(item.Dimensions); ok {
+			volumetric := dimensions.VolumetricWeight(divisor)
+			weights.volumetric += volumetric * quantity
+			chargeable = math.Max(chargeable, volumetric)
+		}
+		weights.actual += item.Weight * quantity
+		weights.chargeable += chargeable * quantity
 	}
 
-	return totalWeight
+	return weights
 }
 
 // calculateShippingCostWithZone calculates shipping cost using zone-specific rates
-func (s *pricingService) calculateShippingCostWithZone(weight float64, shippingType string, zone *models.ShippingZone) float64 {
+func (s *pricingService) calculateShippingCostWithZone(weight float64, shippingType string, zone *models.ShippingZone) models.Money {
 	var baseCostPerKg float64
 	var minCost float64
 	var handlingFee float64
@@ -343,17 +485,17 @@ func (s *pricingService) calculateShippingCostWithZone(weight float64, shippingT
 		// Default rates
 		switch shippingType {
 		case "jne":
-			baseCostPerKg = 15000 // IDR 15,000 per kg
+			baseCostPerKg = defaultJNEBaseRate
 		case "tiki":
-			baseCostPerKg = 16000 // IDR 16,000 per kg
+			baseCostPerKg = defaultTIKIBaseRate
 		case "pos":
-			baseCostPerKg = 14000 // IDR 14,000 per kg
+			baseCostPerKg = defaultPOSBaseRate
 		case "sicepat":
-			baseCostPerKg = 13000 // IDR 13,000 per kg
+			baseCostPerKg = defaultSiCepatBaseRate
 		default:
-			baseCostPerKg = 15000 // Default: IDR 15,000 per kg
+			baseCostPerKg = defaultJNEBaseRate
 		}
-		minCost = 9000.0 // IDR 9,000 minimum
+		minCost = defaultMinimumCost
 		handlingFee = 0.0
 	}
 
@@ -368,25 +510,29 @@ func (s *pricingService) calculateShippingCostWithZone(weight float64, shippingT
 	// Add handling fee
 	cost += handlingFee
 
-	return cost
+	return models.NewMoney(cost)
 }
 
 // CheckFreeShipping checks if shipping is free based on order amount and zone
-func (s *pricingService) CheckFreeShipping(orderAmount float64, regionCode string) (bool, error) {
-	if regionCode == "" {
-		return false, nil
-	}
+func (s *pricingService) CheckFreeShipping(orderAmount models.Money, regionCode string) (bool, error) {
+	threshold := s.freeShippingThreshold(regionCode)
+	return threshold != nil && orderAmount >= *threshold, nil
+}
 
-	zone, err := s.shippingZoneRepo.GetByRegion(regionCode)
-	if err != nil {
-		return false, nil
+// freeShippingThreshold returns the goods amount the region's zone ships free from, or nil when
+// the region is not in a zone with free shipping
+func (s *pricingService) freeShippingThreshold(regionCode string) *models.Money {
+	if regionCode == "" || s.shippingZoneRepo == nil {
+		return nil
 	}
 
-	if zone.FreeShippingEnabled && orderAmount >= zone.FreeShippingThreshold {
-		return true, nil
+	zone, err := s.shippingZoneRepo.GetByRegion(regionCode)
+	if err != nil || !zone.FreeShippingEnabled {
+		return nil
 	}
 
-	return false, nil
+	threshold := models.NewMoney(zone.FreeShippingThreshold)
+	return &threshold
 }
 
 // estimateDeliveryDays estimates delivery days based on shipping type
@@ -415,12 +561,14 @@ func (s *pricingService) CalculateOrderSummary(
 		return nil, errors.New("no items provided")
 	}
 
-	var subtotal float64
-	var totalDiscount float64
+	var subtotal models.Money
+	var totalDiscount models.Money
 	var itemCount int
+	summaryItems := make([]OrderSummaryItem, 0, len(items))
 
-	// Calculate price for each item
+	// Calculate price for each item at the customer's type
 	for _, item := range items {
+		item.CustomerType = customerType
 		itemCount += item.Quantity
 		priceResp, err := s.CalculatePrice(item)
 		if err != nil {
@@ -429,6 +577,18 @@ func (s *pricingService) CalculateOrderSummary(
 
 		subtotal += priceResp.BasePrice
 		totalDiscount += priceResp.Savings
+		summaryItems = append(summaryItems, OrderSummaryItem{
+			ProductID:    item.ProductID,
+			VariantID:    item.VariantID,
+			Quantity:     item.Quantity,
+			UnitPrice:    priceResp.FinalPrice / models.Money(item.Quantity),
+			TotalPrice:   priceResp.FinalPrice,
+			Savings:      priceResp.Savings,
+			DiscountType: priceResp.DiscountType,
+			FlashSaleID:  priceResp.FlashSaleID,
+
+			FlashSaleProductID: priceResp.FlashSaleProductID,
+		})
 	}
 
 	// Calculate shipping cost
@@ -437,57 +597,210 @@ func (s *pricingService) CalculateOrderSummary(
 		return nil, fmt.Errorf("error calculating shipping cost: %w", err)
 	}
 
-	// Calculate tax amount (11% VAT by default)
-	taxAmount := (subtotal - totalDiscount) * s.taxRate
+	summary := &OrderSummary{
+		Subtotal:         subtotal,
+		ShippingCost:     shippingResp.ShippingCost,
+		TotalWeight:      shippingResp.TotalWeight,
+		ActualWeight:     shippingResp.ActualWeight,
+		VolumetricWeight: shippingResp.VolumetricWeight,
+		ItemCount:        itemCount,
+		TotalDiscount:    totalDiscount,
+		TaxRegion:        shippingReq.Destination,
+		Items:            summaryItems,
+	}
 
-	// Calculate total
-	total := subtotal - totalDiscount + shippingResp.ShippingCost + taxAmount
+	if err := s.applyPromotions(summary, customerType); err != nil {
+		return nil, err
+	}
+	summary.freeShippingThreshold = s.freeShippingThreshold(shippingReq.Destination)
 
-	return &OrderSummary{
-		Subtotal:      subtotal,
-		ShippingCost:  shippingResp.ShippingCost,
-		TotalWeight:   shippingResp.TotalWeight,
-		Total:         total,
-		ItemCount:     itemCount,
-		TotalDiscount: totalDiscount,
-		TaxAmount:     taxAmount,
-	}, nil
+	if err := s.totalOrderSummary(summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// totalOrderSummary applies the zone's free shipping and works out the taxes of the destination
+// region on the discounted amount, then the total
+func (s *pricingService) totalOrderSummary(summary *OrderSummary) error {
+	goods := summary.goodsAmount()
+
+	// A coupon can take the goods below the threshold, so the zone discount is worked out again
+	summary.ShippingDiscount -= summary.zoneShippingDiscount
+	summary.zoneShippingDiscount = 0
+	summary.FreeShipping = false
+	if threshold := summary.freeShippingThreshold; threshold != nil && goods >= *threshold {
+		summary.zoneShippingDiscount = summary.ShippingCost - summary.ShippingDiscount
+		summary.ShippingDiscount = summary.ShippingCost
+		summary.FreeShipping = true
+	}
+	shipping := summary.ShippingCost - summary.ShippingDiscount
+
+	summary.Taxes = []TaxLine{}
+	summary.TaxAmount = 0
+	if s.taxService != nil {
+		taxes, total, err := s.taxService.Calculate(TaxCalculationRequest{
+			Region:   summary.TaxRegion,
+			Amount:   goods,
+			Shipping: shipping,
+		})
+		if err != nil {
+			return err
+		}
+		summary.Taxes = taxes
+		summary.TaxAmount = total
+	}
+
+	summary.Total = goods + shipping + summary.TaxAmount
+	return nil
 }
 
-// CalculateCouponDiscount calculates discount for a coupon code
-func (s *pricingService) CalculateCouponDiscount(req CouponCalculationRequest) (float64, string, error) {
+// CalculateCouponDiscount validates a coupon and works out its discount line by line. Only lines
+// allowed by the coupon's product and category restrictions count toward the discount; the
+// minimum purchase amount is checked against the whole purchase.
+func (s *pricingService) CalculateCouponDiscount(req CouponCalculationRequest) (*CouponDiscount, error) {
 	if req.Code == "" {
-		return 0, "", nil
+		return &CouponDiscount{}, nil
+	}
+
+	lines := make([]CouponLineItem, len(req.Items))
+	purchaseAmount := req.PurchaseAmount
+	if len(req.Items) > 0 {
+		purchaseAmount = 0
+		for i, item := range req.Items {
+			lines[i] = item
+			if item.Subtotal == 0 {
+				priceResp, err := s.CalculatePrice(PriceCalculationRequest{
+					ProductID:    item.ProductID,
+					VariantID:    item.VariantID,
+					Quantity:     item.Quantity,
+					CustomerType: req.CustomerType,
+				})
+				if err != nil {
+					return nil, fmt.Errorf("error calculating price for item: %w", err)
+				}
+				lines[i].Subtotal = priceResp.FinalPrice
+			}
+			purchaseAmount += lines[i].Subtotal
+		}
 	}
 
 	coupon, err := s.couponRepo.ValidateCoupon(
 		req.Code,
 		req.UserID,
-		req.PurchaseAmount,
+		purchaseAmount,
 		string(req.CustomerType),
 	)
 	if err != nil {
-		return 0, "", errors.New("invalid or expired coupon code")
+		return nil, errors.New("invalid or expired coupon code")
 	}
 
-	var discountAmount float64
+	result := &CouponDiscount{
+		CouponID: coupon.ID,
+		Code:     coupon.Code,
+		Name:     coupon.Name,
+	}
+
+	var eligible []CouponLineItem
+	if len(lines) == 0 {
+		// Without lines there is nothing to check restrictions against
+		if coupon.HasRestrictions() {
+			return nil, errors.New("coupon only applies to selected products, send the cart items to check it")
+		}
+		result.EligibleSubtotal = purchaseAmount
+	} else {
+		for _, line := range lines {
+			reason, err := s.couponExclusionReason(coupon, line.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			if reason != "" {
+				result.ExcludedItems = append(result.ExcludedItems, CouponExclusion{
+					ProductID: line.ProductID,
+					VariantID: line.VariantID,
+					Reason:    reason,
+				})
+				continue
+			}
+			eligible = append(eligible, line)
+			result.EligibleSubtotal += line.Subtotal
+		}
+		if len(eligible) == 0 {
+			return nil, errors.New("coupon does not apply to any item in the cart")
+		}
+	}
 
 	switch coupon.Type {
 	case models.CouponTypePercentage:
-		discountAmount = req.PurchaseAmount * (coupon.DiscountValue / 100)
+		result.Discount = result.EligibleSubtotal.Percent(coupon.DiscountValue)
 		// Apply max discount limit if set
-		if coupon.MaxDiscount > 0 && discountAmount > coupon.MaxDiscount {
-			discountAmount = coupon.MaxDiscount
+		if maxDiscount := models.NewMoney(coupon.MaxDiscount); maxDiscount > 0 && result.Discount > maxDiscount {
+			result.Discount = maxDiscount
 		}
 	case models.CouponTypeFixed:
-		discountAmount = coupon.DiscountValue
-		// Ensure discount doesn't exceed purchase amount
-		if discountAmount > req.PurchaseAmount {
-			discountAmount = req.PurchaseAmount
+		result.Discount = models.NewMoney(coupon.DiscountValue)
+	}
+	// Ensure discount doesn't exceed the eligible amount
+	if result.Discount > result.EligibleSubtotal {
+		result.Discount = result.EligibleSubtotal
+	}
+
+	result.Lines = splitCouponDiscount(result.Discount, eligible)
+	return result, nil
+}
+
+// couponExclusionReason returns why the coupon does not apply to a product, or "" if it does
+func (s *pricingService) couponExclusionReason(coupon *models.Coupon, productID uint) (string, error) {
+	for _, id := range coupon.ExcludeProducts {
+		if id == productID {
+			return "product is excluded from this coupon", nil
 		}
 	}
+	if len(coupon.ApplicableProducts) == 0 && len(coupon.ApplicableCategories) == 0 {
+		return "", nil
+	}
+	for _, id := range coupon.ApplicableProducts {
+		if id == productID {
+			return "", nil
+		}
+	}
+	if len(coupon.ApplicableCategories) > 0 {
+		product, err := s.productRepo.GetByID(productID)
+		if err != nil {
+			return "", fmt.Errorf("product not found: %w", err)
+		}
+		for _, category := range coupon.ApplicableCategories {
+			if category == string(product.Category) {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("coupon does not apply to %s", product.Category), nil
+	}
+	return "product is not eligible for this coupon", nil
+}
+
+// splitCouponDiscount shares a discount across lines in proportion to their subtotals. The last
+// line takes what rounding leaves over, so the shares always add up to the discount.
+func splitCouponDiscount(discount models.Money, lines []CouponLineItem) []CouponLineDiscount {
+	if len(lines) == 0 {
+		return nil
+	}
 
-	return discountAmount, coupon.Name, nil
+	weights := make([]models.Money, len(lines))
+	for i, line := range lines {
+		weights[i] = line.Subtotal
+	}
+
+	shares := make([]CouponLineDiscount, len(lines))
+	for i, share := range discount.Allocate(weights) {
+		shares[i] = CouponLineDiscount{
+			ProductID: lines[i].ProductID,
+			VariantID: lines[i].VariantID,
+			Subtotal:  lines[i].Subtotal,
+			Discount:  share,
+		}
+	}
+	return shares
 }
 
 // ApplyCouponToPriceCalculation applies coupon discount to price calculation
@@ -499,10 +812,11 @@ func (s *pricingService) ApplyCouponToPriceCalculation(
 		return nil
 	}
 
-	discountAmount, _, err := s.CalculateCouponDiscount(couponReq)
+	coupon, err := s.CalculateCouponDiscount(couponReq)
 	if err != nil {
 		return err
 	}
+	discountAmount := coupon.Discount
 
 	resp.FinalPrice = resp.FinalPrice - discountAmount
 	resp.Discount = resp.Discount + discountAmount
@@ -515,6 +829,35 @@ func (s *pricingService) ApplyCouponToPriceCalculation(
 	return nil
 }
 
+// ApplyCouponToOrderSummary checks the coupon against the summary's priced lines and takes its
+// discount off the total. Tax is worked out again on the discounted amount.
+func (s *pricingService) ApplyCouponToOrderSummary(summary *OrderSummary, couponReq CouponCalculationRequest) error {
+	if couponReq.Code == "" {
+		return nil
+	}
+
+	couponReq.Items = make([]CouponLineItem, 0, len(summary.Items))
+	for _, item := range summary.Items {
+		couponReq.Items = append(couponReq.Items, CouponLineItem{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			Subtotal:  item.TotalPrice,
+		})
+	}
+
+	coupon, err := s.CalculateCouponDiscount(couponReq)
+	if err != nil {
+		return err
+	}
+
+	summary.CouponDiscount = coupon.Discount
+	summary.CouponApplied = true
+	summary.CouponCode = &coupon.Code
+	summary.Coupon = coupon
+	return s.totalOrderSummary(summary)
+}
+
 // formatTime formats time to ISO 8601 string
 func formatTime(t time.Time) *string {
 	formatted := t.Format(time.RFC3339)
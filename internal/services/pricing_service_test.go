@@ -4,10 +4,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/karima-store/internal/models"
 	"github.com/karima-store/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
@@ -51,8 +53,8 @@ func (m *MockFlashSaleRepository) AddProductToFlashSale(flashSaleProduct *models
 	args := m.Called(flashSaleProduct)
 	return args.Error(0)
 }
-func (m *MockFlashSaleRepository) RemoveProductFromFlashSale(flashSaleID, productID uint) error {
-	args := m.Called(flashSaleID, productID)
+func (m *MockFlashSaleRepository) RemoveProductFromFlashSale(flashSaleID, productID uint, variantID *uint) error {
+	args := m.Called(flashSaleID, productID, variantID)
 	return args.Error(0)
 }
 func (m *MockFlashSaleRepository) GetFlashSaleProducts(flashSaleID uint) ([]models.FlashSaleProduct, error) {
@@ -63,6 +65,53 @@ func (m *MockFlashSaleRepository) UpdateFlashSaleProduct(flashSaleProduct *model
 	args := m.Called(flashSaleProduct)
 	return args.Error(0)
 }
+func (m *MockFlashSaleRepository) GetFlashSaleProduct(flashSaleID, productID uint, variantID *uint) (*models.FlashSaleProduct, error) {
+	args := m.Called(flashSaleID, productID, variantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FlashSaleProduct), args.Error(1)
+}
+func (m *MockFlashSaleRepository) GetFlashSaleProductByID(id uint) (*models.FlashSaleProduct, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FlashSaleProduct), args.Error(1)
+}
+func (m *MockFlashSaleRepository) GetByStatus(status models.FlashSaleStatus) ([]models.FlashSale, error) {
+	args := m.Called(status)
+	return args.Get(0).([]models.FlashSale), args.Error(1)
+}
+func (m *MockFlashSaleRepository) GetDueToStart(now time.Time) ([]models.FlashSale, error) {
+	args := m.Called(now)
+	return args.Get(0).([]models.FlashSale), args.Error(1)
+}
+func (m *MockFlashSaleRepository) GetDueToEnd(now time.Time) ([]models.FlashSale, error) {
+	args := m.Called(now)
+	return args.Get(0).([]models.FlashSale), args.Error(1)
+}
+func (m *MockFlashSaleRepository) GetNextTransitionTime(now time.Time) (*time.Time, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+func (m *MockFlashSaleRepository) TransitionStatus(id uint, from, to models.FlashSaleStatus) (bool, error) {
+	args := m.Called(id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockFlashSaleRepository) GetUsage(flashSaleID, flashSaleProductID, userID uint) (*models.FlashSaleUsage, error) {
+	args := m.Called(flashSaleID, flashSaleProductID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FlashSaleUsage), args.Error(1)
+}
+func (m *MockFlashSaleRepository) ReconcileSales(flashSaleID uint) error {
+	return m.Called(flashSaleID).Error(0)
+}
 
 // MockCouponRepository
 type MockCouponRepository struct {
@@ -72,8 +121,11 @@ type MockCouponRepository struct {
 func (m *MockCouponRepository) Create(coupon *models.Coupon) error { return m.Called(coupon).Error(0) }
 func (m *MockCouponRepository) GetByID(id uint) (*models.Coupon, error) {
 	args := m.Called(id)
-	return nil, args.Error(1)
-} // Simplified
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Coupon), args.Error(1)
+}
 func (m *MockCouponRepository) GetByCode(code string) (*models.Coupon, error) {
 	args := m.Called(code)
 	return nil, args.Error(1)
@@ -88,20 +140,84 @@ func (m *MockCouponRepository) GetActive() ([]models.Coupon, error) {
 }
 func (m *MockCouponRepository) Update(coupon *models.Coupon) error { return m.Called(coupon).Error(0) }
 func (m *MockCouponRepository) Delete(id uint) error               { return m.Called(id).Error(0) }
-func (m *MockCouponRepository) ValidateCoupon(code string, userID uint, purchaseAmount float64, customerType string) (*models.Coupon, error) {
+func (m *MockCouponRepository) ValidateCoupon(code string, userID uint, purchaseAmount models.Money, customerType string) (*models.Coupon, error) {
 	args := m.Called(code, userID, purchaseAmount, customerType)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Coupon), args.Error(1)
 }
-func (m *MockCouponRepository) RecordUsage(couponID, userID, orderID uint, discountAmount float64) error {
+func (m *MockCouponRepository) RecordUsage(couponID, userID, orderID uint, discountAmount models.Money) error {
 	return m.Called(couponID, userID, orderID, discountAmount).Error(0)
 }
 func (m *MockCouponRepository) GetUserUsageCount(couponID, userID uint) (int, error) {
 	args := m.Called(couponID, userID)
 	return args.Int(0), args.Error(1)
 }
+func (m *MockCouponRepository) List(filter models.CouponListFilter, limit, offset int) ([]models.Coupon, int64, error) {
+	args := m.Called(filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.Coupon), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockCouponRepository) GetUsageStats(couponID uint) (*models.CouponUsageStats, error) {
+	args := m.Called(couponID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CouponUsageStats), args.Error(1)
+}
+func (m *MockCouponRepository) ExistingCodes(codes []string) ([]string, error) {
+	args := m.Called(codes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+func (m *MockCouponRepository) CreateBatch(batch *models.CouponBatch, coupons []models.Coupon) error {
+	return m.Called(batch, coupons).Error(0)
+}
+func (m *MockCouponRepository) GetBatchByID(id uint) (*models.CouponBatch, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CouponBatch), args.Error(1)
+}
+func (m *MockCouponRepository) GetBatches(limit, offset int) ([]models.CouponBatch, int64, error) {
+	args := m.Called(limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.CouponBatch), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockCouponRepository) GetBatchCoupons(batchID uint) ([]models.Coupon, error) {
+	args := m.Called(batchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Coupon), args.Error(1)
+}
+func (m *MockCouponRepository) LockByCode(code string) (*models.Coupon, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Coupon), args.Error(1)
+}
+func (m *MockCouponRepository) RevokeUsage(orderID uint) error { return m.Called(orderID).Error(0) }
+func (m *MockCouponRepository) WithTx(tx *gorm.DB) repository.CouponRepository {
+	args := m.Called(tx)
+	return args.Get(0).(repository.CouponRepository)
+}
+func (m *MockCouponRepository) GetBatchUsageStats(batchID uint) (*models.CouponUsageStats, error) {
+	args := m.Called(batchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CouponUsageStats), args.Error(1)
+}
 
 // MockShippingZoneRepository
 type MockShippingZoneRepository struct {
@@ -113,7 +229,10 @@ func (m *MockShippingZoneRepository) Create(zone *models.ShippingZone) error {
 }
 func (m *MockShippingZoneRepository) GetByID(id uint) (*models.ShippingZone, error) {
 	args := m.Called(id)
-	return nil, args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ShippingZone), args.Error(1)
 }
 func (m *MockShippingZoneRepository) GetByRegion(regionCode string) (*models.ShippingZone, error) {
 	args := m.Called(regionCode)
@@ -124,11 +243,17 @@ func (m *MockShippingZoneRepository) GetByRegion(regionCode string) (*models.Shi
 }
 func (m *MockShippingZoneRepository) GetAll() ([]models.ShippingZone, error) {
 	args := m.Called()
-	return nil, args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ShippingZone), args.Error(1)
 }
 func (m *MockShippingZoneRepository) GetActive() ([]models.ShippingZone, error) {
 	args := m.Called()
-	return nil, args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ShippingZone), args.Error(1)
 }
 func (m *MockShippingZoneRepository) Update(zone *models.ShippingZone) error {
 	return m.Called(zone).Error(0)
@@ -139,6 +264,34 @@ func (m *MockShippingZoneRepository) WithTx(tx *gorm.DB) repository.ShippingZone
 	return args.Get(0).(repository.ShippingZoneRepository)
 }
 
+// MockPromotionRepository
+type MockPromotionRepository struct {
+	mock.Mock
+}
+
+func (m *MockPromotionRepository) Create(promotion *models.Promotion) error {
+	return m.Called(promotion).Error(0)
+}
+func (m *MockPromotionRepository) Update(promotion *models.Promotion) error {
+	return m.Called(promotion).Error(0)
+}
+func (m *MockPromotionRepository) Delete(id uint) error { return m.Called(id).Error(0) }
+func (m *MockPromotionRepository) GetByID(id uint) (*models.Promotion, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Promotion), args.Error(1)
+}
+func (m *MockPromotionRepository) List(limit, offset int) ([]models.Promotion, int64, error) {
+	args := m.Called(limit, offset)
+	return args.Get(0).([]models.Promotion), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockPromotionRepository) GetRunning(at time.Time) ([]models.Promotion, error) {
+	args := m.Called(at)
+	return args.Get(0).([]models.Promotion), args.Error(1)
+}
+
 // MockProductRepo (Assume it's in the same package services from product_service_test.go)
 // MockVariantRepo (Assume it's in the same package services from product_service_test.go)
 
@@ -149,7 +302,8 @@ func TestPricingService_CalculatePrice(t *testing.T) {
 	mockCouponRepo := new(MockCouponRepository)
 	mockZoneRepo := new(MockShippingZoneRepository)
 
-	service := NewPricingService(mockProductRepo, mockVariantRepo, mockFlashSaleRepo, mockCouponRepo, mockZoneRepo)
+	promotions := NewPromotionIndex(mockFlashSaleRepo, nil, PromotionIndexConfig{})
+	service := NewPricingService(mockProductRepo, mockVariantRepo, promotions, newTestDiscountTiers(), mockCouponRepo, nil, mockZoneRepo, nil)
 
 	// Test 1: Basic Retail Price (No discount)
 	t.Run("Basic Retail", func(t *testing.T) {
@@ -162,11 +316,12 @@ func TestPricingService_CalculatePrice(t *testing.T) {
 
 		mockProductRepo.On("GetByID", uint(1)).Return(product, nil).Once()
 		mockFlashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil).Once()
+		assert.NoError(t, promotions.Refresh())
 
 		resp, err := service.CalculatePrice(req)
 		assert.NoError(t, err)
-		assert.Equal(t, 100000.0, resp.FinalPrice)
-		assert.Equal(t, 0.0, resp.Savings)
+		assert.Equal(t, models.Money(100000), resp.FinalPrice)
+		assert.Equal(t, models.Money(0), resp.Savings)
 	})
 
 	// Test 2: Reseller Tiering (Quantity 20 -> 20% discount)
@@ -180,6 +335,7 @@ func TestPricingService_CalculatePrice(t *testing.T) {
 
 		mockProductRepo.On("GetByID", uint(1)).Return(product, nil).Once()
 		mockFlashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil).Once()
+		assert.NoError(t, promotions.Refresh())
 
 		resp, err := service.CalculatePrice(req)
 		assert.NoError(t, err)
@@ -189,7 +345,7 @@ func TestPricingService_CalculatePrice(t *testing.T) {
 		// Final per item: 80,000
 		// Total: 1,600,000
 
-		expectedFinal := 1600000.0
+		expectedFinal := models.Money(1600000)
 		assert.Equal(t, expectedFinal, resp.FinalPrice)
 		assert.Equal(t, "reseller", resp.DiscountType)
 	})
@@ -206,16 +362,298 @@ func TestPricingService_CalculatePrice(t *testing.T) {
 			EndTime:   time.Now().Add(1 * time.Hour),
 			Products:  []models.Product{{ID: 2}},
 		}
-		fsProduct := models.FlashSaleProduct{ProductID: 2, FlashSalePrice: 50000}
+		fsProduct := models.FlashSaleProduct{ProductID: 2, FlashSalePrice: 50000, FlashSaleStock: 10}
 
 		mockProductRepo.On("GetByID", uint(2)).Return(product, nil).Once()
 		mockFlashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{flashSale}, nil).Once()
 		mockFlashSaleRepo.On("GetFlashSaleProducts", uint(1)).Return([]models.FlashSaleProduct{fsProduct}, nil).Once()
+		assert.NoError(t, promotions.Refresh())
 
 		resp, err := service.CalculatePrice(req)
 		assert.NoError(t, err)
-		assert.Equal(t, 50000.0, resp.FinalPrice)
+		assert.Equal(t, models.Money(50000), resp.FinalPrice)
 		assert.Equal(t, "flash_sale", resp.DiscountType)
 		assert.True(t, resp.FlashSaleActive)
+		assert.Equal(t, uint(1), *resp.FlashSaleID)
+
+		// Once the flash sale stock is sold the regular price applies again
+		fsProduct.SoldCount = 10
+		mockProductRepo.On("GetByID", uint(2)).Return(product, nil).Once()
+		mockFlashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{flashSale}, nil).Once()
+		mockFlashSaleRepo.On("GetFlashSaleProducts", uint(1)).Return([]models.FlashSaleProduct{fsProduct}, nil).Once()
+		assert.NoError(t, promotions.Refresh())
+
+		resp, err = service.CalculatePrice(req)
+		assert.NoError(t, err)
+		assert.Equal(t, models.Money(100000), resp.FinalPrice)
+		assert.False(t, resp.FlashSaleActive)
+		assert.Nil(t, resp.FlashSaleID)
+	})
+
+	// Test 4: The product's discount percentage is not applied to quotes
+	t.Run("Product Discount", func(t *testing.T) {
+		req := PriceCalculationRequest{ProductID: 3, Quantity: 2, CustomerType: CustomerRetail}
+		product := &models.Product{ID: 3, Price: 100000, Discount: 20}
+
+		mockProductRepo.On("GetByID", uint(3)).Return(product, nil).Once()
+		mockFlashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil).Once()
+		assert.NoError(t, promotions.Refresh())
+
+		resp, err := service.CalculatePrice(req)
+		assert.NoError(t, err)
+		assert.Equal(t, models.Money(200000), resp.FinalPrice)
+		assert.Equal(t, models.Money(0), resp.Savings)
+		assert.Equal(t, "none", resp.DiscountType)
+	})
+}
+
+func TestPricingService_CalculatePrice_VariantFlashSale(t *testing.T) {
+	mockProductRepo := new(MockProductRepository)
+	mockVariantRepo := new(MockVariantRepository)
+	mockFlashSaleRepo := new(MockFlashSaleRepository)
+	promotions := NewPromotionIndex(mockFlashSaleRepo, nil, PromotionIndexConfig{})
+	service := NewPricingService(mockProductRepo, mockVariantRepo, promotions, newTestDiscountTiers(), new(MockCouponRepository), nil, new(MockShippingZoneRepository), nil)
+
+	product := &models.Product{ID: 2, Price: 100000}
+	mockProductRepo.On("GetByID", uint(2)).Return(product, nil)
+	mockVariantRepo.On("GetByID", uint(20)).Return(&models.ProductVariant{ID: 20, ProductID: 2, Price: 100000}, nil)
+	mockVariantRepo.On("GetByID", uint(21)).Return(&models.ProductVariant{ID: 21, ProductID: 2, Price: 110000}, nil)
+
+	flashSale := models.FlashSale{
+		ID:        1,
+		Status:    models.FlashSaleActive,
+		StartTime: time.Now().Add(-1 * time.Hour),
+		EndTime:   time.Now().Add(1 * time.Hour),
+	}
+	variantID := uint(20)
+	productEntry := models.FlashSaleProduct{ID: 10, ProductID: 2, FlashSalePrice: 80000, FlashSaleStock: 10}
+	variantEntry := models.FlashSaleProduct{ID: 11, ProductID: 2, VariantID: &variantID, FlashSalePrice: 60000, FlashSaleStock: 5}
+	mockFlashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{flashSale}, nil)
+
+	t.Run("Variant entry wins", func(t *testing.T) {
+		mockFlashSaleRepo.On("GetFlashSaleProducts", uint(1)).Return([]models.FlashSaleProduct{productEntry, variantEntry}, nil).Once()
+		assert.NoError(t, promotions.Refresh())
+
+		resp, err := service.CalculatePrice(PriceCalculationRequest{ProductID: 2, VariantID: &variantID, Quantity: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, models.Money(60000), resp.FinalPrice)
+		assert.Equal(t, FlashSaleMatchVariant, resp.FlashSaleMatch)
+		assert.Equal(t, uint(11), *resp.FlashSaleProductID)
+	})
+
+	t.Run("Other variants use the product entry", func(t *testing.T) {
+		mockFlashSaleRepo.On("GetFlashSaleProducts", uint(1)).Return([]models.FlashSaleProduct{productEntry, variantEntry}, nil).Once()
+		assert.NoError(t, promotions.Refresh())
+
+		otherID := uint(21)
+		resp, err := service.CalculatePrice(PriceCalculationRequest{ProductID: 2, VariantID: &otherID, Quantity: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, models.Money(80000), resp.FinalPrice)
+		assert.Equal(t, FlashSaleMatchProduct, resp.FlashSaleMatch)
+		assert.Equal(t, uint(10), *resp.FlashSaleProductID)
+	})
+
+	t.Run("Sold out variant falls back to the product entry", func(t *testing.T) {
+		soldOut := variantEntry
+		soldOut.SoldCount = 5
+		mockFlashSaleRepo.On("GetFlashSaleProducts", uint(1)).Return([]models.FlashSaleProduct{soldOut, productEntry}, nil).Once()
+		assert.NoError(t, promotions.Refresh())
+
+		resp, err := service.CalculatePrice(PriceCalculationRequest{ProductID: 2, VariantID: &variantID, Quantity: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, models.Money(80000), resp.FinalPrice)
+		assert.Equal(t, FlashSaleMatchProduct, resp.FlashSaleMatch)
+	})
+}
+
+func TestPricingService_CalculateCouponDiscount_Restrictions(t *testing.T) {
+	mockProductRepo := new(MockProductRepository)
+	mockCouponRepo := new(MockCouponRepository)
+	promotions := NewPromotionIndex(new(MockFlashSaleRepository), nil, PromotionIndexConfig{})
+	service := NewPricingService(mockProductRepo, new(MockVariantRepository), promotions, newTestDiscountTiers(), mockCouponRepo, nil, new(MockShippingZoneRepository), nil)
+
+	coupon := &models.Coupon{
+		ID:                   3,
+		Code:                 "DRESS20",
+		Name:                 "Dress week",
+		Type:                 models.CouponTypePercentage,
+		DiscountValue:        20,
+		ApplicableProducts:   []uint{4},
+		ExcludeProducts:      []uint{2},
+		ApplicableCategories: []string{"dresses"},
+	}
+	mockCouponRepo.On("ValidateCoupon", "DRESS20", uint(7), models.Money(400000), "retail").Return(coupon, nil)
+	mockProductRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1, Category: models.CategoryDresses}, nil)
+	mockProductRepo.On("GetByID", uint(3)).Return(&models.Product{ID: 3, Category: models.CategoryFootwear}, nil)
+
+	result, err := service.CalculateCouponDiscount(CouponCalculationRequest{
+		Code:         "DRESS20",
+		UserID:       7,
+		CustomerType: CustomerRetail,
+		Items: []CouponLineItem{
+			{ProductID: 1, Quantity: 1, Subtotal: 150000}, // dress: eligible by category
+			{ProductID: 2, Quantity: 1, Subtotal: 100000}, // excluded
+			{ProductID: 3, Quantity: 1, Subtotal: 100000}, // footwear: not in the categories
+			{ProductID: 4, Quantity: 1, Subtotal: 50000},  // listed product
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.Money(200000), result.EligibleSubtotal)
+	assert.Equal(t, models.Money(40000), result.Discount)
+	assert.Equal(t, []CouponLineDiscount{
+		{ProductID: 1, Subtotal: 150000, Discount: 30000},
+		{ProductID: 4, Subtotal: 50000, Discount: 10000},
+	}, result.Lines)
+	assert.Equal(t, []CouponExclusion{
+		{ProductID: 2, Reason: "product is excluded from this coupon"},
+		{ProductID: 3, Reason: "coupon does not apply to footwear"},
+	}, result.ExcludedItems)
+
+	// Restricted coupons cannot be checked against an amount alone
+	mockCouponRepo.On("ValidateCoupon", "DRESS20", uint(7), models.Money(100000), "retail").Return(coupon, nil)
+	_, err = service.CalculateCouponDiscount(CouponCalculationRequest{Code: "DRESS20", UserID: 7, PurchaseAmount: 100000, CustomerType: CustomerRetail})
+	assert.EqualError(t, err, "coupon only applies to selected products, send the cart items to check it")
+}
+
+func TestPricingService_ApplyCouponToOrderSummary(t *testing.T) {
+	mockCouponRepo := new(MockCouponRepository)
+	promotions := NewPromotionIndex(new(MockFlashSaleRepository), nil, PromotionIndexConfig{})
+	service := NewPricingService(new(MockProductRepository), new(MockVariantRepository), promotions, newTestDiscountTiers(), mockCouponRepo, nil, new(MockShippingZoneRepository), newTestTaxService())
+
+	coupon := &models.Coupon{ID: 5, Code: "HEMAT10", Type: models.CouponTypePercentage, DiscountValue: 10}
+	mockCouponRepo.On("ValidateCoupon", "HEMAT10", uint(7), models.Money(200000), "retail").Return(coupon, nil)
+
+	summary := &OrderSummary{
+		Subtotal:      220000,
+		TotalDiscount: 20000,
+		ShippingCost:  15000,
+		Items: []OrderSummaryItem{
+			{ProductID: 1, Quantity: 2, TotalPrice: 150000},
+			{ProductID: 2, Quantity: 1, TotalPrice: 50000},
+		},
+	}
+	err := service.ApplyCouponToOrderSummary(summary, CouponCalculationRequest{Code: "HEMAT10", UserID: 7, CustomerType: CustomerRetail})
+	assert.NoError(t, err)
+	assert.True(t, summary.CouponApplied)
+	assert.Equal(t, "HEMAT10", *summary.CouponCode)
+	assert.Equal(t, models.Money(20000), summary.CouponDiscount)
+	assert.Equal(t, models.Money(19800), summary.TaxAmount) // 11% of 180000
+	assert.Equal(t, models.Money(214800), summary.Total)
+	assert.Len(t, summary.Coupon.Lines, 2)
+}
+
+func TestPricingService_CalculateShippingCost_VolumetricWeight(t *testing.T) {
+	mockProductRepo := new(MockProductRepository)
+	mockZoneRepo := new(MockShippingZoneRepository)
+	service := NewPricingService(mockProductRepo, new(MockVariantRepository), nil, newTestDiscountTiers(), new(MockCouponRepository), nil, mockZoneRepo, nil)
+
+	// A padded jacket weighs 0.8 kg but packs to 40x30x15 cm, which JNE bills as 3 kg
+	mockProductRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1, Weight: 0.8, Dimensions: "40 x 30 x 15 cm"}, nil).Once()
+
+	resp, err := service.CalculateShippingCost(ShippingCalculationRequest{
+		Items: []ShippingItem{
+			{ProductID: 1, Quantity: 2},
+			// Dense items are billed by their actual weight
+			{Quantity: 1, Weight: 2, Dimensions: "20x10x10"},
+		},
+		ShippingType: "jne",
 	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 3.6, resp.ActualWeight, 1e-9)
+	assert.InDelta(t, 6+2000.0/6000, resp.VolumetricWeight, 1e-9)
+	assert.InDelta(t, 8, resp.TotalWeight, 1e-9)
+	assert.Equal(t, models.Money(120000), resp.ShippingCost)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestPricingService_CalculateOrderSummary_Reseller(t *testing.T) {
+	mockProductRepo := new(MockProductRepository)
+	flashSaleRepo := new(MockFlashSaleRepository)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil)
+	promotions := NewPromotionIndex(flashSaleRepo, nil, PromotionIndexConfig{})
+	service := NewPricingService(mockProductRepo, new(MockVariantRepository), promotions, newTestDiscountTiers(), new(MockCouponRepository), nil, new(MockShippingZoneRepository), nil)
+
+	mockProductRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1, Price: 100000, Weight: 0.5}, nil)
+
+	items := []PriceCalculationRequest{{ProductID: 1, Quantity: 20}}
+	shipping := ShippingCalculationRequest{
+		Items:        []ShippingItem{{ProductID: 1, Quantity: 20}},
+		ShippingType: "jne",
+	}
+
+	// The line is priced at the customer's type, whatever the item request says
+	summary, err := service.CalculateOrderSummary(items, shipping, CustomerReseller)
+	assert.NoError(t, err)
+	assert.Equal(t, "reseller", summary.Items[0].DiscountType)
+	assert.Equal(t, models.Money(80000), summary.Items[0].UnitPrice)
+	assert.Equal(t, models.Money(400000), summary.TotalDiscount)
+
+	summary, err = service.CalculateOrderSummary(items, shipping, CustomerRetail)
+	assert.NoError(t, err)
+	assert.Equal(t, "bulk", summary.Items[0].DiscountType)
+	assert.Equal(t, models.Money(90000), summary.Items[0].UnitPrice)
+}
+
+func TestPricingService_CalculateOrderSummary_ZoneFreeShipping(t *testing.T) {
+	sqlDB, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer sqlDB.Close()
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, DriverName: "postgres"}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+
+	mockProductRepo := new(MockProductRepository)
+	flashSaleRepo := new(MockFlashSaleRepository)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil)
+	promotions := NewPromotionIndex(flashSaleRepo, nil, PromotionIndexConfig{})
+	zoneRepo := repository.NewShippingZoneRepository(gormDB)
+	service := NewPricingService(mockProductRepo, new(MockVariantRepository), promotions, newTestDiscountTiers(), new(MockCouponRepository), nil, zoneRepo, nil)
+
+	mockProductRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1, Price: 100000, Weight: 0.5}, nil)
+
+	// The zone is found through its region rows
+	expectZone := func() {
+		sqlMock.ExpectQuery(`JOIN shipping_zone_regions`).
+			WithArgs("ID-JK", models.ShippingZoneActive, sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status", "free_shipping_enabled", "free_shipping_threshold"}).
+				AddRow(3, "Jakarta", "active", true, 250000))
+		sqlMock.ExpectQuery(`FROM "shipping_zone_regions"`).
+			WillReturnRows(sqlmock.NewRows([]string{"zone_id", "region_code", "excluded"}).
+				AddRow(3, "ID-JK", false))
+	}
+
+	quoted := models.Money(20000)
+	order := func(quantity int) *OrderSummary {
+		summary, err := service.CalculateOrderSummary(
+			[]PriceCalculationRequest{{ProductID: 1, Quantity: quantity}},
+			ShippingCalculationRequest{
+				Items:        []ShippingItem{{ProductID: 1, Quantity: quantity}},
+				Destination:  "id-jk",
+				ShippingType: "jne",
+				QuotedCost:   &quoted,
+			},
+			CustomerRetail,
+		)
+		assert.NoError(t, err)
+		return summary
+	}
+
+	// Rp 300.000 of goods reaches the zone threshold, so shipping is free
+	expectZone()
+	summary := order(3)
+	assert.True(t, summary.FreeShipping)
+	assert.Equal(t, models.Money(20000), summary.ShippingDiscount)
+	assert.Equal(t, models.Money(300000), summary.Total)
+
+	// Rp 200.000 does not
+	expectZone()
+	summary = order(2)
+	assert.False(t, summary.FreeShipping)
+	assert.Equal(t, models.Money(0), summary.ShippingDiscount)
+	assert.Equal(t, models.Money(220000), summary.Total)
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
 }
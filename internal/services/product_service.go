@@ -20,7 +20,9 @@ type ProductService interface {
 	GetProductByID(id uint) (*models.Product, error)
 	GetProductBySlug(slug string) (*models.Product, error)
 	GetProducts(limit, offset int, filters map[string]interface{}) ([]models.Product, int64, error)
-	UpdateProduct(id uint, product *models.Product) error
+	// UpdateProduct saves the product; a price, compare price or discount change is recorded in
+	// the price history as made by actorID
+	UpdateProduct(id uint, product *models.Product, actorID *uint) error
 	DeleteProduct(id uint) error
 	UpdateProductStock(id uint, quantity int) error
 	SearchProducts(query string, limit, offset int) ([]models.Product, int64, error)
@@ -28,6 +30,7 @@ type ProductService interface {
 	GetFeaturedProducts(limit int) ([]models.Product, error)
 	GetBestSellers(limit int) ([]models.Product, error)
 	GenerateSlug(name string) string
+	InvalidateProductCache(id uint) error
 }
 
 type productService struct {
@@ -60,6 +63,11 @@ func (s *productService) CreateProduct(product *models.Product) error {
 	if product.Category == "" {
 		return errors.New("product category is required")
 	}
+	if product.Dimensions != "" {
+		if _, ok := models.ParseDimensions(product.Dimensions); !ok {
+			return models.ErrInvalidDimensions
+		}
+	}
 
 	// Set default status if not provided
 	if product.Status == "" {
@@ -178,7 +186,7 @@ func (s *productService) GetProducts(limit, offset int, filters map[string]inter
 	return products, total, nil
 }
 
-func (s *productService) UpdateProduct(id uint, product *models.Product) error {
+func (s *productService) UpdateProduct(id uint, product *models.Product, actorID *uint) error {
 	// Check if product exists
 	existingProduct, err := s.productRepo.GetByID(id)
 	if err != nil {
@@ -187,6 +195,11 @@ func (s *productService) UpdateProduct(id uint, product *models.Product) error {
 		}
 		return err
 	}
+	if product.Dimensions != "" {
+		if _, ok := models.ParseDimensions(product.Dimensions); !ok {
+			return models.ErrInvalidDimensions
+		}
+	}
 
 	// Update slug if name changed
 	if product.Name != "" && product.Name != existingProduct.Name {
@@ -196,7 +209,7 @@ func (s *productService) UpdateProduct(id uint, product *models.Product) error {
 	// Ensure ID is set
 	product.ID = id
 
-	if err := s.productRepo.Update(product); err != nil {
+	if err := s.productRepo.UpdateWithPriceHistory(product, actorID); err != nil {
 		return err
 	}
 
@@ -239,6 +252,27 @@ func (s *productService) DeleteProduct(id uint) error {
 	return nil
 }
 
+// InvalidateProductCache drops the cached copies of a product after it was changed outside
+// this service, e.g. when review moderation recomputes its rating
+func (s *productService) InvalidateProductCache(id uint) error {
+	product, err := s.productRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("product not found")
+		}
+		return err
+	}
+
+	ctx := context.Background()
+	_ = s.redis.Delete(ctx, fmt.Sprintf("product:id:%d", id))
+	_ = s.redis.Delete(ctx, fmt.Sprintf("product:slug:%s", product.Slug))
+
+	// Invalidate list caches
+	_ = s.redis.DeleteByPattern(ctx, "products:*")
+
+	return nil
+}
+
 func (s *productService) UpdateProductStock(id uint, quantity int) error {
 	// Check if product exists
 	product, err := s.productRepo.GetByID(id)
@@ -50,6 +50,10 @@ func (m *MockProductRepository) Update(product *models.Product) error {
 	return args.Error(0)
 }
 
+func (m *MockProductRepository) UpdateWithPriceHistory(product *models.Product, changedBy *uint) error {
+	return m.Called(product, changedBy).Error(0)
+}
+
 func (m *MockProductRepository) Delete(id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -126,6 +130,10 @@ func (m *MockVariantRepository) Update(variant *models.ProductVariant) error {
 	return args.Error(0)
 }
 
+func (m *MockVariantRepository) UpdateWithPriceHistory(variant *models.ProductVariant, changedBy *uint) error {
+	return m.Called(variant, changedBy).Error(0)
+}
+
 func (m *MockVariantRepository) Delete(id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -276,9 +284,9 @@ func TestProductService_UpdateProduct_SQLInjection(t *testing.T) {
 	}
 
 	mockRepo.On("GetByID", uint(1)).Return(existingProduct, nil)
-	mockRepo.On("Update", product).Return(nil)
+	mockRepo.On("UpdateWithPriceHistory", product, (*uint)(nil)).Return(nil)
 
-	err := service.UpdateProduct(1, product)
+	err := service.UpdateProduct(1, product, nil)
 
 	assert.NoError(t, err)
 	// Verify that the name was stored (sanitization should happen at repository level)
@@ -306,9 +314,9 @@ func TestProductService_UpdateProduct_InvalidPrice(t *testing.T) {
 	}
 
 	mockRepo.On("GetByID", uint(1)).Return(existingProduct, nil)
-	mockRepo.On("Update", product).Return(nil)
+	mockRepo.On("UpdateWithPriceHistory", product, (*uint)(nil)).Return(nil)
 
-	err := service.UpdateProduct(1, product)
+	err := service.UpdateProduct(1, product, nil)
 
 	assert.NoError(t, err) // Service doesn't validate price on update
 }
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/karima-store/internal/models"
+)
+
+// AppliedPromotion is a promotion that changed the order and what it gave
+type AppliedPromotion struct {
+	PromotionID      uint                 `json:"promotion_id"`
+	Name             string               `json:"name"`
+	Type             models.PromotionType `json:"type"`
+	Reason           string               `json:"reason"`
+	Discount         models.Money         `json:"discount"`
+	ShippingDiscount models.Money         `json:"shipping_discount,omitempty"`
+	Gifts            []PromotionGift      `json:"gifts,omitempty"`
+}
+
+// PromotionGift is a free product added to the order by a gift promotion
+type PromotionGift struct {
+	ProductID uint   `json:"product_id"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+}
+
+// applyPromotions evaluates the running promotions against a priced summary, highest priority
+// first, and adds what each one gives. A promotion that is not stackable is skipped once another
+// one applied, and ends the evaluation when it applies itself.
+func (s *pricingService) applyPromotions(summary *OrderSummary, customerType CustomerType) error {
+	if s.promotionRepo == nil {
+		return nil
+	}
+
+	now := time.Now()
+	promotions, err := s.promotionRepo.GetRunning(now)
+	if err != nil {
+		return fmt.Errorf("failed to load promotions: %w", err)
+	}
+
+	for i := range promotions {
+		promotion := &promotions[i]
+		if !promotion.IsRunning(now) || !promotionForCustomer(promotion, customerType) {
+			continue
+		}
+		if len(summary.Promotions) > 0 && !promotion.Stackable {
+			continue
+		}
+
+		applied := s.evaluatePromotion(promotion, summary)
+		if applied == nil {
+			continue
+		}
+		summary.Promotions = append(summary.Promotions, *applied)
+		summary.PromotionDiscount += applied.Discount
+		summary.ShippingDiscount += applied.ShippingDiscount
+
+		if !promotion.Stackable {
+			break
+		}
+	}
+	return nil
+}
+
+// evaluatePromotion returns what the promotion gives the order, or nil when the order does not
+// meet it. Spend thresholds are checked against the amount left after earlier promotions.
+func (s *pricingService) evaluatePromotion(promotion *models.Promotion, summary *OrderSummary) *AppliedPromotion {
+	applied := &AppliedPromotion{
+		PromotionID: promotion.ID,
+		Name:        promotion.Name,
+		Type:        promotion.Type,
+	}
+	spent := summary.Subtotal - summary.TotalDiscount - summary.PromotionDiscount
+
+	switch promotion.Type {
+	case models.PromotionBuyXGetY:
+		discount, units := buyXGetYDiscount(promotion, summary.Items)
+		if units == 0 || discount <= 0 {
+			return nil
+		}
+		applied.Discount = discount
+		if promotion.GetDiscountPercent >= 100 {
+			applied.Reason = fmt.Sprintf("buy %d get %d free: %d item(s) free", promotion.BuyQuantity, promotion.GetQuantity, units)
+		} else {
+			applied.Reason = fmt.Sprintf("buy %d get %d at %g%% off: %d item(s) discounted",
+				promotion.BuyQuantity, promotion.GetQuantity, promotion.GetDiscountPercent, units)
+		}
+
+	case models.PromotionTieredSpend:
+		tier := reachedTier(promotion.Tiers, spent)
+		if tier == nil {
+			return nil
+		}
+		discount := spent.Percent(tier.DiscountPercent)
+		if maxDiscount := models.NewMoney(tier.MaxDiscount); maxDiscount > 0 && discount > maxDiscount {
+			discount = maxDiscount
+		}
+		applied.Discount = discount
+		applied.Reason = fmt.Sprintf("%g%% off for spending Rp %s or more", tier.DiscountPercent, formatCurrency(tier.MinSubtotal))
+
+	case models.PromotionFreeShipping:
+		shipping := summary.ShippingCost - summary.ShippingDiscount
+		if spent < models.NewMoney(promotion.MinSubtotal) || shipping <= 0 {
+			return nil
+		}
+		if maxDiscount := models.NewMoney(promotion.MaxShippingDiscount); maxDiscount > 0 && shipping > maxDiscount {
+			shipping = maxDiscount
+		}
+		applied.ShippingDiscount = shipping
+		applied.Reason = "free shipping" + spendCondition(promotion.MinSubtotal)
+
+	case models.PromotionGift:
+		if spent < models.NewMoney(promotion.MinSubtotal) || promotion.GiftProductID == nil || promotion.GiftQuantity <= 0 {
+			return nil
+		}
+		// A gift that is out of stock is left out rather than failing the checkout
+		product, err := s.productRepo.GetByID(*promotion.GiftProductID)
+		if err != nil || product.Status != models.StatusAvailable || product.Stock < promotion.GiftQuantity {
+			return nil
+		}
+		applied.Gifts = []PromotionGift{{ProductID: product.ID, Name: product.Name, Quantity: promotion.GiftQuantity}}
+		applied.Reason = "free " + product.Name + spendCondition(promotion.MinSubtotal)
+
+	default:
+		return nil
+	}
+
+	return applied
+}
+
+// buyXGetYDiscount works out the discount and the number of discounted units. When the free item
+// is the bought product itself, every BuyQuantity+GetQuantity units hold GetQuantity discounted
+// ones. The cheapest units are the ones discounted, each rounded to whole rupiah.
+func buyXGetYDiscount(promotion *models.Promotion, items []OrderSummaryItem) (models.Money, int) {
+	if promotion.BuyProductID == nil || promotion.BuyQuantity <= 0 || promotion.GetQuantity <= 0 {
+		return 0, 0
+	}
+	buyProductID := *promotion.BuyProductID
+	getProductID := buyProductID
+	if promotion.GetProductID != nil {
+		getProductID = *promotion.GetProductID
+	}
+
+	var bought, available int
+	var lines []OrderSummaryItem
+	for _, item := range items {
+		if item.ProductID == buyProductID {
+			bought += item.Quantity
+		}
+		if item.ProductID == getProductID {
+			available += item.Quantity
+			lines = append(lines, item)
+		}
+	}
+
+	var units int
+	if getProductID == buyProductID {
+		units = bought / (promotion.BuyQuantity + promotion.GetQuantity) * promotion.GetQuantity
+	} else {
+		units = min(bought/promotion.BuyQuantity*promotion.GetQuantity, available)
+	}
+	if units == 0 {
+		return 0, 0
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].UnitPrice < lines[j].UnitPrice })
+	var discount models.Money
+	left := units
+	for _, line := range lines {
+		n := min(line.Quantity, left)
+		discount += line.UnitPrice.Percent(promotion.GetDiscountPercent).Times(n)
+		left -= n
+		if left == 0 {
+			break
+		}
+	}
+	return discount, units
+}
+
+// reachedTier returns the highest tier the amount reaches, or nil
+func reachedTier(tiers []models.PromotionTier, amount models.Money) *models.PromotionTier {
+	var reached *models.PromotionTier
+	for i := range tiers {
+		tier := &tiers[i]
+		if amount >= models.NewMoney(tier.MinSubtotal) && (reached == nil || tier.MinSubtotal > reached.MinSubtotal) {
+			reached = tier
+		}
+	}
+	return reached
+}
+
+func promotionForCustomer(promotion *models.Promotion, customerType CustomerType) bool {
+	switch customerType {
+	case CustomerReseller:
+		return promotion.ForReseller
+	default:
+		return promotion.ForRetail
+	}
+}
+
+func spendCondition(minSubtotal float64) string {
+	if minSubtotal <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" on orders of Rp %s or more", formatCurrency(minSubtotal))
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestPromotionPricing(promotions ...models.Promotion) (*pricingService, *MockProductRepository) {
+	productRepo := new(MockProductRepository)
+	promotionRepo := new(MockPromotionRepository)
+	promotionRepo.On("GetRunning", mock.Anything).Return(promotions, nil)
+	service := NewPricingService(productRepo, new(MockVariantRepository), nil, newTestDiscountTiers(), new(MockCouponRepository), promotionRepo, new(MockShippingZoneRepository), newTestTaxService())
+	return service.(*pricingService), productRepo
+}
+
+func uintPtr(v uint) *uint { return &v }
+
+func TestPricingService_ApplyPromotions_PriorityAndStacking(t *testing.T) {
+	service, _ := newTestPromotionPricing(
+		models.Promotion{
+			ID: 1, Name: "Big spender", Type: models.PromotionTieredSpend, Status: models.PromotionStatusActive,
+			Priority: 30, Stackable: false, ForRetail: true,
+			Tiers: []models.PromotionTier{{MinSubtotal: 1000000, DiscountPercent: 25}},
+		},
+		models.Promotion{
+			ID: 2, Name: "Socks BOGO", Type: models.PromotionBuyXGetY, Status: models.PromotionStatusActive,
+			Priority: 20, Stackable: true, ForRetail: true,
+			BuyProductID: uintPtr(5), BuyQuantity: 1, GetQuantity: 1, GetDiscountPercent: 100,
+		},
+		models.Promotion{
+			ID: 3, Name: "Payday", Type: models.PromotionTieredSpend, Status: models.PromotionStatusActive,
+			Priority: 10, Stackable: true, ForRetail: true,
+			Tiers: []models.PromotionTier{
+				{MinSubtotal: 200000, DiscountPercent: 5},
+				{MinSubtotal: 500000, DiscountPercent: 15},
+			},
+		},
+		models.Promotion{
+			ID: 4, Name: "Ongkir gratis", Type: models.PromotionFreeShipping, Status: models.PromotionStatusActive,
+			Priority: 5, Stackable: true, ForRetail: true, MinSubtotal: 300000, MaxShippingDiscount: 20000,
+		},
+		models.Promotion{
+			ID: 5, Name: "Members only", Type: models.PromotionTieredSpend, Status: models.PromotionStatusActive,
+			Priority: 1, Stackable: false, ForRetail: true,
+			Tiers: []models.PromotionTier{{MinSubtotal: 100000, DiscountPercent: 50}},
+		},
+	)
+
+	summary := &OrderSummary{
+		Subtotal:     600000,
+		ShippingCost: 25000,
+		Items: []OrderSummaryItem{
+			{ProductID: 5, Quantity: 3, UnitPrice: 20000, TotalPrice: 60000},
+			{ProductID: 5, Quantity: 1, UnitPrice: 10000, TotalPrice: 10000},
+			{ProductID: 6, Quantity: 1, UnitPrice: 530000, TotalPrice: 530000},
+		},
+	}
+	assert.NoError(t, service.applyPromotions(summary, CustomerRetail))
+
+	// The exclusive 25% tier is not reached; the second exclusive one is skipped because others applied
+	if assert.Len(t, summary.Promotions, 3) {
+		// 4 socks: 2 free, the cheapest ones (10000 + 20000)
+		assert.Equal(t, uint(2), summary.Promotions[0].PromotionID)
+		assert.Equal(t, models.Money(30000), summary.Promotions[0].Discount)
+		assert.Equal(t, "buy 1 get 1 free: 2 item(s) free", summary.Promotions[0].Reason)
+
+		// 570000 left after the BOGO reaches the 15% tier
+		assert.Equal(t, uint(3), summary.Promotions[1].PromotionID)
+		assert.Equal(t, models.Money(85500), summary.Promotions[1].Discount)
+		assert.Equal(t, "15% off for spending Rp 500000 or more", summary.Promotions[1].Reason)
+
+		assert.Equal(t, uint(4), summary.Promotions[2].PromotionID)
+		assert.Equal(t, models.Money(20000), summary.Promotions[2].ShippingDiscount)
+	}
+	assert.Equal(t, models.Money(115500), summary.PromotionDiscount)
+	assert.Equal(t, models.Money(20000), summary.ShippingDiscount)
+
+	assert.NoError(t, service.totalOrderSummary(summary))
+	assert.Equal(t, models.Money(53295), summary.TaxAmount) // 11% of 484500
+	assert.Equal(t, models.Money(542795), summary.Total)
+}
+
+func TestPricingService_ApplyPromotions_GiftNeedsStock(t *testing.T) {
+	service, productRepo := newTestPromotionPricing(
+		models.Promotion{
+			ID: 1, Name: "Free tote", Type: models.PromotionGift, Status: models.PromotionStatusActive,
+			Stackable: true, ForRetail: true, MinSubtotal: 250000,
+			GiftProductID: uintPtr(9), GiftQuantity: 1,
+		},
+		models.Promotion{
+			ID: 2, Name: "Reseller gift", Type: models.PromotionGift, Status: models.PromotionStatusActive,
+			Stackable: true, ForReseller: true,
+			GiftProductID: uintPtr(9), GiftQuantity: 1,
+		},
+	)
+	productRepo.On("GetByID", uint(9)).Return(&models.Product{ID: 9, Name: "Tote Bag", Status: models.StatusAvailable, Stock: 1}, nil).Once()
+	productRepo.On("GetByID", uint(9)).Return(&models.Product{ID: 9, Name: "Tote Bag", Status: models.StatusAvailable, Stock: 0}, nil)
+
+	summary := &OrderSummary{Subtotal: 300000}
+	assert.NoError(t, service.applyPromotions(summary, CustomerRetail))
+	if assert.Len(t, summary.Promotions, 1) {
+		assert.Equal(t, []PromotionGift{{ProductID: 9, Name: "Tote Bag", Quantity: 1}}, summary.Promotions[0].Gifts)
+		assert.Equal(t, "free Tote Bag on orders of Rp 250000 or more", summary.Promotions[0].Reason)
+	}
+
+	// Out of stock gifts are left out
+	summary = &OrderSummary{Subtotal: 300000}
+	assert.NoError(t, service.applyPromotions(summary, CustomerRetail))
+	assert.Empty(t, summary.Promotions)
+}
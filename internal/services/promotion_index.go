@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/karima-store/internal/database"
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+)
+
+// PromotionIndexConfig controls how stale the index may get when no change event arrives
+type PromotionIndexConfig struct {
+	// MaxAge is the longest the index goes without a reload. It also reloads at every sale start
+	// and end time and whenever a flash sale event is published.
+	MaxAge time.Duration
+}
+
+// PromotionIndex keeps the flash sale entries of running sales in memory, keyed by product and
+// variant, so pricing an item needs no database query
+type PromotionIndex interface {
+	// FlashSale returns the flash sale entry that prices the product/variant right now, or nil
+	FlashSale(productID uint, variantID *uint) *ActiveFlashSale
+	Refresh() error
+	Start()
+	Stop()
+}
+
+// indexedFlashSale is one flash sale entry as held by the index
+type indexedFlashSale struct {
+	flashSaleID uint
+	entryID     uint
+	price       float64
+	stock       int // 0 = unlimited
+	sold        int
+	start       time.Time
+	end         time.Time
+}
+
+// productPromotions holds a product's entries; each slice is sorted by price, cheapest first
+type productPromotions struct {
+	product  []indexedFlashSale
+	variants map[uint][]indexedFlashSale
+}
+
+type promotionIndex struct {
+	flashSaleRepo repository.FlashSaleRepository
+	redis         database.RedisClient
+	cfg           PromotionIndexConfig
+
+	mu       sync.RWMutex
+	products map[uint]*productPromotions
+	loaded   bool
+
+	reload   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewPromotionIndex(
+	flashSaleRepo repository.FlashSaleRepository,
+	redis database.RedisClient,
+	cfg PromotionIndexConfig,
+) PromotionIndex {
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 5 * time.Minute
+	}
+
+	return &promotionIndex{
+		flashSaleRepo: flashSaleRepo,
+		redis:         redis,
+		cfg:           cfg,
+		products:      make(map[uint]*productPromotions),
+		reload:        make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+}
+
+// FlashSale prefers the variant's own entry over the product-level one and skips entries whose
+// stock has been sold, so a sold-out variant falls back to the product-level price
+func (p *promotionIndex) FlashSale(productID uint, variantID *uint) *ActiveFlashSale {
+	p.mu.RLock()
+	loaded := p.loaded
+	p.mu.RUnlock()
+	if !loaded {
+		if err := p.Refresh(); err != nil {
+			log.Printf("[Promotions] Failed to load index: %v", err)
+			return nil
+		}
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	promotions, ok := p.products[productID]
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	if variantID != nil {
+		if entry := firstAvailable(promotions.variants[*variantID], now); entry != nil {
+			return entry.match(FlashSaleMatchVariant)
+		}
+	}
+	if entry := firstAvailable(promotions.product, now); entry != nil {
+		return entry.match(FlashSaleMatchProduct)
+	}
+	return nil
+}
+
+// Refresh reloads the entries of all running flash sales and swaps them in at once
+func (p *promotionIndex) Refresh() error {
+	sales, err := p.flashSaleRepo.GetActiveFlashSales()
+	if err != nil {
+		return err
+	}
+
+	products := make(map[uint]*productPromotions)
+	for _, sale := range sales {
+		if sale.Status != models.FlashSaleActive {
+			continue
+		}
+
+		entries, err := p.flashSaleRepo.GetFlashSaleProducts(sale.ID)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			promotions, ok := products[entry.ProductID]
+			if !ok {
+				promotions = &productPromotions{variants: make(map[uint][]indexedFlashSale)}
+				products[entry.ProductID] = promotions
+			}
+
+			indexed := indexedFlashSale{
+				flashSaleID: sale.ID,
+				entryID:     entry.ID,
+				price:       entry.FlashSalePrice,
+				stock:       entry.FlashSaleStock,
+				sold:        entry.SoldCount,
+				start:       sale.StartTime,
+				end:         sale.EndTime,
+			}
+			if entry.VariantID != nil {
+				promotions.variants[*entry.VariantID] = append(promotions.variants[*entry.VariantID], indexed)
+			} else {
+				promotions.product = append(promotions.product, indexed)
+			}
+		}
+	}
+
+	for _, promotions := range products {
+		sortByPrice(promotions.product)
+		for _, entries := range promotions.variants {
+			sortByPrice(entries)
+		}
+	}
+
+	p.mu.Lock()
+	p.products = products
+	p.loaded = true
+	p.mu.Unlock()
+	return nil
+}
+
+// Start keeps the index current until Stop is called: it reloads on every flash sale event
+// published by any instance, at each sale start and end time, and at least every MaxAge
+func (p *promotionIndex) Start() {
+	if err := p.Refresh(); err != nil {
+		log.Printf("[Promotions] Failed to load index: %v", err)
+	}
+
+	if p.redis != nil && p.redis.Client() != nil {
+		go p.listen()
+	}
+
+	go func() {
+		timer := time.NewTimer(p.nextWait())
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+			case <-p.reload:
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+			case <-p.done:
+				return
+			}
+
+			if err := p.Refresh(); err != nil {
+				log.Printf("[Promotions] Failed to reload index: %v", err)
+			}
+			timer.Reset(p.nextWait())
+		}
+	}()
+
+	log.Printf("[Promotions] Index started (reloads at least every %s)", p.cfg.MaxAge)
+}
+
+// Stop stops the reload loop and the event subscription
+func (p *promotionIndex) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+	})
+}
+
+// listen asks for a reload whenever a flash sale event comes in. Bursts of events, e.g. one per
+// paid order, collapse into a single pending reload.
+func (p *promotionIndex) listen() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pubsub := p.redis.Client().Subscribe(ctx, FlashSaleEventsChannel)
+	defer pubsub.Close()
+
+	go func() {
+		<-p.done
+		cancel()
+	}()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case _, ok := <-messages:
+			if !ok {
+				return
+			}
+			select {
+			case p.reload <- struct{}{}:
+			default:
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// nextWait returns how long to wait until the next sale start or end time, capped at MaxAge
+func (p *promotionIndex) nextWait() time.Duration {
+	next, err := p.flashSaleRepo.GetNextTransitionTime(time.Now())
+	if err != nil || next == nil {
+		return p.cfg.MaxAge
+	}
+
+	wait := time.Until(*next)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	if wait > p.cfg.MaxAge {
+		wait = p.cfg.MaxAge
+	}
+	return wait
+}
+
+func (e *indexedFlashSale) match(rule string) *ActiveFlashSale {
+	return &ActiveFlashSale{
+		Price:              e.price,
+		EndTime:            formatTime(e.end),
+		FlashSaleID:        e.flashSaleID,
+		FlashSaleProductID: e.entryID,
+		Match:              rule,
+	}
+}
+
+// firstAvailable returns the cheapest entry that is inside its sale window and not sold out.
+// An entry without stock has no limit, like in FlashSaleStockService.
+func firstAvailable(entries []indexedFlashSale, now time.Time) *indexedFlashSale {
+	for i := range entries {
+		entry := &entries[i]
+		if now.Before(entry.start) || now.After(entry.end) || (entry.stock > 0 && entry.sold >= entry.stock) {
+			continue
+		}
+		return entry
+	}
+	return nil
+}
+
+func sortByPrice(entries []indexedFlashSale) {
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].price < entries[j].price })
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotionIndex_LoadsOnceForManyLookups(t *testing.T) {
+	flashSaleRepo := new(MockFlashSaleRepository)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{{
+		ID:        1,
+		Status:    models.FlashSaleActive,
+		StartTime: time.Now().Add(-time.Hour),
+		EndTime:   time.Now().Add(time.Hour),
+	}}, nil).Once()
+	flashSaleRepo.On("GetFlashSaleProducts", uint(1)).Return([]models.FlashSaleProduct{
+		{ID: 10, FlashSaleID: 1, ProductID: 2, FlashSalePrice: 80000, FlashSaleStock: 10},
+	}, nil).Once()
+	index := NewPromotionIndex(flashSaleRepo, nil, PromotionIndexConfig{})
+
+	for i := 0; i < 20; i++ {
+		match := index.FlashSale(2, nil)
+		if assert.NotNil(t, match) {
+			assert.Equal(t, 80000.0, match.Price)
+			assert.Equal(t, uint(10), match.FlashSaleProductID)
+		}
+		assert.Nil(t, index.FlashSale(3, nil))
+	}
+	flashSaleRepo.AssertExpectations(t)
+}
+
+func TestPromotionIndex_SkipsEntriesOutsideTheirWindow(t *testing.T) {
+	flashSaleRepo := new(MockFlashSaleRepository)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{
+		// Still marked active, but its end time has passed since the index was loaded
+		{ID: 1, Status: models.FlashSaleActive, StartTime: time.Now().Add(-2 * time.Hour), EndTime: time.Now().Add(-time.Minute)},
+		{ID: 2, Status: models.FlashSaleActive, StartTime: time.Now().Add(-time.Hour), EndTime: time.Now().Add(time.Hour)},
+	}, nil)
+	flashSaleRepo.On("GetFlashSaleProducts", uint(1)).Return([]models.FlashSaleProduct{
+		{ID: 10, FlashSaleID: 1, ProductID: 2, FlashSalePrice: 50000, FlashSaleStock: 10},
+	}, nil)
+	flashSaleRepo.On("GetFlashSaleProducts", uint(2)).Return([]models.FlashSaleProduct{
+		{ID: 20, FlashSaleID: 2, ProductID: 2, FlashSalePrice: 70000, FlashSaleStock: 10},
+	}, nil)
+	index := NewPromotionIndex(flashSaleRepo, nil, PromotionIndexConfig{})
+	assert.NoError(t, index.Refresh())
+
+	match := index.FlashSale(2, nil)
+	if assert.NotNil(t, match) {
+		assert.Equal(t, uint(2), match.FlashSaleID)
+		assert.Equal(t, 70000.0, match.Price)
+	}
+}
+
+func TestPromotionIndex_ZeroStockIsUnlimited(t *testing.T) {
+	flashSaleRepo := new(MockFlashSaleRepository)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{
+		{ID: 1, Status: models.FlashSaleActive, StartTime: time.Now().Add(-time.Hour), EndTime: time.Now().Add(time.Hour)},
+	}, nil)
+	flashSaleRepo.On("GetFlashSaleProducts", uint(1)).Return([]models.FlashSaleProduct{
+		{ID: 10, FlashSaleID: 1, ProductID: 2, FlashSalePrice: 60000, FlashSaleStock: 0, SoldCount: 25},
+		{ID: 11, FlashSaleID: 1, ProductID: 3, FlashSalePrice: 60000, FlashSaleStock: 5, SoldCount: 5},
+	}, nil)
+	index := NewPromotionIndex(flashSaleRepo, nil, PromotionIndexConfig{})
+	assert.NoError(t, index.Refresh())
+
+	match := index.FlashSale(2, nil)
+	if assert.NotNil(t, match) {
+		assert.Equal(t, uint(10), match.FlashSaleProductID)
+	}
+	assert.Nil(t, index.FlashSale(3, nil))
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PromotionService manages the automatic promotions applied by PricingService
+type PromotionService interface {
+	ListPromotions(limit, offset int) ([]models.Promotion, int64, error)
+	GetPromotion(id uint) (*models.Promotion, error)
+	CreatePromotion(req *models.PromotionRequest) (*models.Promotion, error)
+	// UpdatePromotion replaces every field of the promotion with the request
+	UpdatePromotion(id uint, req *models.PromotionRequest) (*models.Promotion, error)
+	DeletePromotion(id uint) error
+}
+
+type promotionService struct {
+	promotionRepo repository.PromotionRepository
+	productRepo   repository.ProductRepository
+}
+
+func NewPromotionService(promotionRepo repository.PromotionRepository, productRepo repository.ProductRepository) PromotionService {
+	return &promotionService{
+		promotionRepo: promotionRepo,
+		productRepo:   productRepo,
+	}
+}
+
+func (s *promotionService) ListPromotions(limit, offset int) ([]models.Promotion, int64, error) {
+	limit, offset = clampCouponPage(limit, offset)
+	return s.promotionRepo.List(limit, offset)
+}
+
+func (s *promotionService) GetPromotion(id uint) (*models.Promotion, error) {
+	promotion, err := s.promotionRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("promotion not found")
+		}
+		return nil, err
+	}
+	return promotion, nil
+}
+
+func (s *promotionService) CreatePromotion(req *models.PromotionRequest) (*models.Promotion, error) {
+	promotion := &models.Promotion{}
+	if err := s.applyRequest(promotion, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotionRepo.Create(promotion); err != nil {
+		return nil, fmt.Errorf("failed to create promotion: %w", err)
+	}
+	return promotion, nil
+}
+
+func (s *promotionService) UpdatePromotion(id uint, req *models.PromotionRequest) (*models.Promotion, error) {
+	promotion, err := s.GetPromotion(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRequest(promotion, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotionRepo.Update(promotion); err != nil {
+		return nil, fmt.Errorf("failed to update promotion: %w", err)
+	}
+	return promotion, nil
+}
+
+func (s *promotionService) DeletePromotion(id uint) error {
+	if _, err := s.GetPromotion(id); err != nil {
+		return err
+	}
+	if err := s.promotionRepo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete promotion: %w", err)
+	}
+	return nil
+}
+
+// applyRequest copies the request onto the promotion and checks that its type has what it needs
+func (s *promotionService) applyRequest(promotion *models.Promotion, req *models.PromotionRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.New("promotion name is required")
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return errors.New("promotion must end after it starts")
+	}
+
+	status := req.Status
+	if status == "" {
+		status = models.PromotionStatusActive
+	}
+
+	*promotion = models.Promotion{
+		ID:          promotion.ID,
+		CreatedAt:   promotion.CreatedAt,
+		Name:        name,
+		Description: req.Description,
+		Type:        req.Type,
+		Status:      status,
+		Priority:    req.Priority,
+		Stackable:   boolOr(req.Stackable, true),
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		ForRetail:   boolOr(req.ForRetail, true),
+		ForReseller: boolOr(req.ForReseller, true),
+		MinSubtotal: req.MinSubtotal,
+	}
+
+	switch req.Type {
+	case models.PromotionBuyXGetY:
+		if req.BuyProductID == nil || req.BuyQuantity <= 0 || req.GetQuantity <= 0 {
+			return errors.New("buy x get y needs buy_product_id, buy_quantity and get_quantity")
+		}
+		if req.GetDiscountPercent <= 0 {
+			return errors.New("get_discount_percent must be greater than 0")
+		}
+		if err := s.checkProducts(req.BuyProductID, req.GetProductID); err != nil {
+			return err
+		}
+		promotion.BuyProductID = req.BuyProductID
+		promotion.BuyQuantity = req.BuyQuantity
+		promotion.GetProductID = req.GetProductID
+		promotion.GetQuantity = req.GetQuantity
+		promotion.GetDiscountPercent = req.GetDiscountPercent
+
+	case models.PromotionTieredSpend:
+		if len(req.Tiers) == 0 {
+			return errors.New("tiered spend needs at least one tier")
+		}
+		seen := make(map[float64]bool, len(req.Tiers))
+		for _, tier := range req.Tiers {
+			if seen[tier.MinSubtotal] {
+				return errors.New("tiers must have different min_subtotal")
+			}
+			seen[tier.MinSubtotal] = true
+			promotion.Tiers = append(promotion.Tiers, models.PromotionTier{
+				MinSubtotal:     tier.MinSubtotal,
+				DiscountPercent: tier.DiscountPercent,
+				MaxDiscount:     tier.MaxDiscount,
+			})
+		}
+
+	case models.PromotionFreeShipping:
+		promotion.MaxShippingDiscount = req.MaxShippingDiscount
+
+	case models.PromotionGift:
+		if req.GiftProductID == nil || req.GiftQuantity <= 0 {
+			return errors.New("gift needs gift_product_id and gift_quantity")
+		}
+		if err := s.checkProducts(req.GiftProductID); err != nil {
+			return err
+		}
+		promotion.GiftProductID = req.GiftProductID
+		promotion.GiftQuantity = req.GiftQuantity
+
+	default:
+		return errors.New("invalid promotion type")
+	}
+	return nil
+}
+
+// checkProducts makes sure every product the promotion points at exists
+func (s *promotionService) checkProducts(ids ...*uint) error {
+	for _, id := range ids {
+		if id == nil {
+			continue
+		}
+		if _, err := s.productRepo.GetByID(*id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("product %d not found", *id)
+			}
+			return fmt.Errorf("failed to check product %d: %w", *id, err)
+		}
+	}
+	return nil
+}
+
+func boolOr(value *bool, fallback bool) bool {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ResellerService runs the reseller application flow. Approving an application moves the user
+// to the reseller customer group, which is what pricing and checkout read.
+type ResellerService interface {
+	Apply(user *models.User, req *models.ResellerApplicationRequest) (*models.ResellerApplication, error)
+	GetMyApplication(userID uint) (*models.ResellerApplication, error)
+	ListApplications(status models.ResellerApplicationStatus, limit, offset int) ([]models.ResellerApplication, int64, error)
+	Approve(id, reviewerID uint, note string) (*models.ResellerApplication, error)
+	Reject(id, reviewerID uint, note string) (*models.ResellerApplication, error)
+	// Revoke moves a reseller back to retail prices
+	Revoke(userID uint) error
+}
+
+type resellerService struct {
+	applicationRepo repository.ResellerApplicationRepository
+}
+
+func NewResellerService(applicationRepo repository.ResellerApplicationRepository) ResellerService {
+	return &resellerService{
+		applicationRepo: applicationRepo,
+	}
+}
+
+func (s *resellerService) Apply(user *models.User, req *models.ResellerApplicationRequest) (*models.ResellerApplication, error) {
+	if user.IsReseller() {
+		return nil, errors.New("user is already a reseller")
+	}
+
+	latest, err := s.applicationRepo.GetLatestByUser(user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get reseller application: %w", err)
+	}
+	if latest != nil && latest.Status == models.ResellerApplicationPending {
+		return nil, errors.New("reseller application is already pending review")
+	}
+
+	application := &models.ResellerApplication{
+		UserID:       user.ID,
+		BusinessName: strings.TrimSpace(req.BusinessName),
+		BusinessType: strings.TrimSpace(req.BusinessType),
+		TaxNumber:    strings.TrimSpace(req.TaxNumber),
+		Phone:        strings.TrimSpace(req.Phone),
+		City:         strings.TrimSpace(req.City),
+		Province:     strings.TrimSpace(req.Province),
+		Notes:        req.Notes,
+		Status:       models.ResellerApplicationPending,
+	}
+	if err := s.applicationRepo.Create(application); err != nil {
+		return nil, fmt.Errorf("failed to create reseller application: %w", err)
+	}
+	return application, nil
+}
+
+func (s *resellerService) GetMyApplication(userID uint) (*models.ResellerApplication, error) {
+	application, err := s.applicationRepo.GetLatestByUser(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("reseller application not found")
+		}
+		return nil, fmt.Errorf("failed to get reseller application: %w", err)
+	}
+	return application, nil
+}
+
+func (s *resellerService) ListApplications(status models.ResellerApplicationStatus, limit, offset int) ([]models.ResellerApplication, int64, error) {
+	limit, offset = clampCouponPage(limit, offset)
+	return s.applicationRepo.List(status, limit, offset)
+}
+
+func (s *resellerService) Approve(id, reviewerID uint, note string) (*models.ResellerApplication, error) {
+	return s.review(id, reviewerID, note, models.ResellerApplicationApproved)
+}
+
+// Reject leaves the user's customer group as it is
+func (s *resellerService) Reject(id, reviewerID uint, note string) (*models.ResellerApplication, error) {
+	return s.review(id, reviewerID, note, models.ResellerApplicationRejected)
+}
+
+func (s *resellerService) review(id, reviewerID uint, note string, status models.ResellerApplicationStatus) (*models.ResellerApplication, error) {
+	application, err := s.applicationRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("reseller application not found")
+		}
+		return nil, fmt.Errorf("failed to get reseller application: %w", err)
+	}
+	if application.Status != models.ResellerApplicationPending {
+		return nil, fmt.Errorf("reseller application is already %s", application.Status)
+	}
+
+	now := time.Now()
+	application.Status = status
+	application.ReviewedBy = &reviewerID
+	application.ReviewedAt = &now
+	application.ReviewNote = note
+
+	if err := s.applicationRepo.Review(application); err != nil {
+		return nil, fmt.Errorf("failed to review reseller application: %w", err)
+	}
+	if status == models.ResellerApplicationApproved && application.User != nil {
+		application.User.CustomerGroup = models.CustomerGroupReseller
+	}
+	return application, nil
+}
+
+func (s *resellerService) Revoke(userID uint) error {
+	if err := s.applicationRepo.SetCustomerGroup(userID, models.CustomerGroupRetail); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return fmt.Errorf("failed to revoke reseller: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockResellerApplicationRepository
+type MockResellerApplicationRepository struct {
+	mock.Mock
+}
+
+func (m *MockResellerApplicationRepository) Create(application *models.ResellerApplication) error {
+	args := m.Called(application)
+	return args.Error(0)
+}
+
+func (m *MockResellerApplicationRepository) GetByID(id uint) (*models.ResellerApplication, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ResellerApplication), args.Error(1)
+}
+
+func (m *MockResellerApplicationRepository) GetLatestByUser(userID uint) (*models.ResellerApplication, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ResellerApplication), args.Error(1)
+}
+
+func (m *MockResellerApplicationRepository) List(status models.ResellerApplicationStatus, limit, offset int) ([]models.ResellerApplication, int64, error) {
+	args := m.Called(status, limit, offset)
+	return args.Get(0).([]models.ResellerApplication), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockResellerApplicationRepository) Review(application *models.ResellerApplication) error {
+	args := m.Called(application)
+	return args.Error(0)
+}
+
+func (m *MockResellerApplicationRepository) SetCustomerGroup(userID uint, group models.CustomerGroup) error {
+	args := m.Called(userID, group)
+	return args.Error(0)
+}
+
+func TestResellerService_Apply(t *testing.T) {
+	req := &models.ResellerApplicationRequest{BusinessName: " Toko Karima ", Phone: "08123456789", City: "Bandung"}
+
+	t.Run("Creates a pending application", func(t *testing.T) {
+		repo := new(MockResellerApplicationRepository)
+		repo.On("GetLatestByUser", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+		repo.On("Create", mock.AnythingOfType("*models.ResellerApplication")).Return(nil)
+
+		application, err := NewResellerService(repo).Apply(&models.User{ID: 1, IsActive: true}, req)
+		assert.NoError(t, err)
+		assert.Equal(t, models.ResellerApplicationPending, application.Status)
+		assert.Equal(t, "Toko Karima", application.BusinessName)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Rejects a second pending application", func(t *testing.T) {
+		repo := new(MockResellerApplicationRepository)
+		repo.On("GetLatestByUser", uint(1)).Return(&models.ResellerApplication{ID: 4, Status: models.ResellerApplicationPending}, nil)
+
+		_, err := NewResellerService(repo).Apply(&models.User{ID: 1, IsActive: true}, req)
+		assert.EqualError(t, err, "reseller application is already pending review")
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Rejects existing resellers", func(t *testing.T) {
+		repo := new(MockResellerApplicationRepository)
+
+		_, err := NewResellerService(repo).Apply(&models.User{ID: 1, IsActive: true, CustomerGroup: models.CustomerGroupReseller}, req)
+		assert.EqualError(t, err, "user is already a reseller")
+	})
+}
+
+func TestResellerService_Review(t *testing.T) {
+	t.Run("Approve promotes the applicant", func(t *testing.T) {
+		repo := new(MockResellerApplicationRepository)
+		application := &models.ResellerApplication{ID: 4, UserID: 1, Status: models.ResellerApplicationPending, User: &models.User{ID: 1, IsActive: true}}
+		repo.On("GetByID", uint(4)).Return(application, nil)
+		repo.On("Review", application).Return(nil)
+
+		result, err := NewResellerService(repo).Approve(4, 9, "welcome")
+		assert.NoError(t, err)
+		assert.Equal(t, models.ResellerApplicationApproved, result.Status)
+		assert.Equal(t, uint(9), *result.ReviewedBy)
+		assert.NotNil(t, result.ReviewedAt)
+		assert.True(t, result.User.IsReseller())
+		assert.Equal(t, CustomerReseller, CustomerTypeFor(result.User))
+	})
+
+	t.Run("Reviewed applications cannot be reviewed again", func(t *testing.T) {
+		repo := new(MockResellerApplicationRepository)
+		repo.On("GetByID", uint(4)).Return(&models.ResellerApplication{ID: 4, Status: models.ResellerApplicationRejected}, nil)
+
+		_, err := NewResellerService(repo).Approve(4, 9, "")
+		assert.EqualError(t, err, "reseller application is already rejected")
+		repo.AssertNotCalled(t, "Review", mock.Anything)
+	})
+
+	t.Run("Missing application", func(t *testing.T) {
+		repo := new(MockResellerApplicationRepository)
+		repo.On("GetByID", uint(5)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := NewResellerService(repo).Reject(5, 9, "")
+		assert.EqualError(t, err, "reseller application not found")
+	})
+}
+
+func TestCustomerTypeFor(t *testing.T) {
+	assert.Equal(t, CustomerRetail, CustomerTypeFor(nil))
+	assert.Equal(t, CustomerRetail, CustomerTypeFor(&models.User{IsActive: true, CustomerGroup: models.CustomerGroupRetail}))
+	assert.Equal(t, CustomerReseller, CustomerTypeFor(&models.User{IsActive: true, CustomerGroup: models.CustomerGroupReseller}))
+}
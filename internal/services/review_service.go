@@ -0,0 +1,303 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ReviewService manages product reviews written by customers
+type ReviewService interface {
+	CreateReview(userID uint, req *models.CreateReviewRequest) (*models.Review, error)
+	GetProductReviews(productID uint, limit, offset int) ([]models.Review, int64, error)
+	GetUserReviews(userID uint, limit, offset int) ([]models.Review, int64, error)
+	VoteReview(userID, reviewID uint, helpful bool) (*models.Review, error)
+	AddReviewImage(userID, reviewID uint, fileHeader *multipart.FileHeader) (*models.ReviewImage, error)
+
+	// Moderation
+	GetPendingReviews(limit, offset int) ([]models.Review, int64, error)
+	ApproveReview(id uint, adminNotes string) (*models.Review, error)
+	RejectReview(id uint, adminNotes string) (*models.Review, error)
+	BulkModerate(req *models.BulkModerateReviewsRequest) (*BulkModerationResult, error)
+	DeleteReview(id uint) error
+}
+
+// maxReviewImages caps the photos attached to a single review
+const maxReviewImages = 5
+
+// BulkModerationResult lists which reviews a bulk action updated and which do not exist
+type BulkModerationResult struct {
+	Action   models.ReviewModerationAction `json:"action"`
+	Updated  []uint                        `json:"updated"`
+	NotFound []uint                        `json:"not_found"`
+}
+
+type reviewService struct {
+	reviewRepo     repository.ReviewRepository
+	productRepo    repository.ProductRepository
+	productService ProductService
+	mediaService   MediaService
+}
+
+func NewReviewService(
+	reviewRepo repository.ReviewRepository,
+	productRepo repository.ProductRepository,
+	productService ProductService,
+	mediaService MediaService,
+) ReviewService {
+	return &reviewService{
+		reviewRepo:     reviewRepo,
+		productRepo:    productRepo,
+		productService: productService,
+		mediaService:   mediaService,
+	}
+}
+
+// CreateReview records a review for a product the user has received. Only customers with
+// a delivered order containing the product may review it, so every review is a verified
+// purchase. New reviews wait for moderation before they are shown.
+func (s *reviewService) CreateReview(userID uint, req *models.CreateReviewRequest) (*models.Review, error) {
+	if req.Rating < 1 || req.Rating > 5 {
+		return nil, errors.New("rating must be between 1 and 5")
+	}
+
+	if _, err := s.productRepo.GetByID(req.ProductID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	delivered, err := s.reviewRepo.HasDeliveredPurchase(userID, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !delivered {
+		return nil, errors.New("only customers who received this product can review it")
+	}
+
+	if _, err := s.reviewRepo.FindByUserAndProduct(userID, req.ProductID); err == nil {
+		return nil, errors.New("you have already reviewed this product")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	review := &models.Review{
+		ProductID:  req.ProductID,
+		UserID:     userID,
+		Rating:     req.Rating,
+		Title:      strings.TrimSpace(req.Title),
+		Comment:    strings.TrimSpace(req.Comment),
+		IsVerified: true,
+		IsApproved: false,
+	}
+
+	if err := s.reviewRepo.Create(review); err != nil {
+		// A concurrent request may have won the race to the unique (user_id, product_id) constraint
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "uq_reviews_user_product") {
+			return nil, errors.New("you have already reviewed this product")
+		}
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (s *reviewService) GetProductReviews(productID uint, limit, offset int) ([]models.Review, int64, error) {
+	limit, offset = normalizeReviewPage(limit, offset)
+	return s.reviewRepo.GetApprovedByProductID(productID, limit, offset)
+}
+
+func (s *reviewService) GetUserReviews(userID uint, limit, offset int) ([]models.Review, int64, error) {
+	limit, offset = normalizeReviewPage(limit, offset)
+	return s.reviewRepo.GetByUserID(userID, limit, offset)
+}
+
+// VoteReview records whether a published review was helpful. Each user votes once per review
+// and cannot vote on their own review.
+func (s *reviewService) VoteReview(userID, reviewID uint, helpful bool) (*models.Review, error) {
+	review, err := s.reviewRepo.GetByID(reviewID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("review not found")
+		}
+		return nil, err
+	}
+	if !review.IsApproved {
+		return nil, errors.New("review not found")
+	}
+	if review.UserID == userID {
+		return nil, errors.New("you cannot vote on your own review")
+	}
+
+	updated, err := s.reviewRepo.Vote(reviewID, userID, helpful)
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, errors.New("you have already voted on this review")
+		}
+		return nil, err
+	}
+	return updated, nil
+}
+
+// AddReviewImage attaches a photo to the user's own review. The file goes through the same
+// validation and storage (R2 or local) as product media. Photos can only be added while the
+// review is waiting for moderation, so every published photo has been seen by an admin.
+func (s *reviewService) AddReviewImage(userID, reviewID uint, fileHeader *multipart.FileHeader) (*models.ReviewImage, error) {
+	review, err := s.reviewRepo.GetByID(reviewID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("review not found")
+		}
+		return nil, err
+	}
+	if review.UserID != userID {
+		return nil, errors.New("review not found")
+	}
+	if review.ModeratedAt != nil {
+		return nil, errors.New("photos can only be added before the review is moderated")
+	}
+
+	count, err := s.reviewRepo.CountImages(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxReviewImages {
+		return nil, fmt.Errorf("a review can have at most %d photos", maxReviewImages)
+	}
+
+	if err := s.mediaService.ValidateImageFile(fileHeader); err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+
+	stored, err := s.mediaService.StoreImage(fileHeader, fmt.Sprintf("reviews/%d", reviewID))
+	if err != nil {
+		return nil, err
+	}
+
+	image := &models.ReviewImage{
+		ReviewID: reviewID,
+		URL:      stored.URL,
+		AltText:  strings.TrimSuffix(fileHeader.Filename, filepath.Ext(fileHeader.Filename)),
+	}
+	if err := s.reviewRepo.AddImage(image); err != nil {
+		_ = s.mediaService.DeleteStoredImage(stored)
+		return nil, fmt.Errorf("failed to save review image: %w", err)
+	}
+
+	return image, nil
+}
+
+func (s *reviewService) GetPendingReviews(limit, offset int) ([]models.Review, int64, error) {
+	limit, offset = normalizeReviewPage(limit, offset)
+	return s.reviewRepo.GetPending(limit, offset)
+}
+
+// ApproveReview publishes a review and recomputes its product's rating
+func (s *reviewService) ApproveReview(id uint, adminNotes string) (*models.Review, error) {
+	return s.moderateOne(id, true, adminNotes)
+}
+
+// RejectReview hides a review; a previously approved review stops counting towards the rating
+func (s *reviewService) RejectReview(id uint, adminNotes string) (*models.Review, error) {
+	return s.moderateOne(id, false, adminNotes)
+}
+
+func (s *reviewService) moderateOne(id uint, approve bool, adminNotes string) (*models.Review, error) {
+	reviews, err := s.moderate([]uint{id}, approve, adminNotes)
+	if err != nil {
+		return nil, err
+	}
+	if len(reviews) == 0 {
+		return nil, errors.New("review not found")
+	}
+	return &reviews[0], nil
+}
+
+// BulkModerate applies one decision to several reviews in a single transaction
+func (s *reviewService) BulkModerate(req *models.BulkModerateReviewsRequest) (*BulkModerationResult, error) {
+	var approve bool
+	switch req.Action {
+	case models.ReviewActionApprove:
+		approve = true
+	case models.ReviewActionReject:
+		approve = false
+	default:
+		return nil, errors.New("action must be approve or reject")
+	}
+
+	reviews, err := s.moderate(req.ReviewIDs, approve, req.AdminNotes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BulkModerationResult{Action: req.Action, Updated: []uint{}, NotFound: []uint{}}
+	updated := make(map[uint]bool, len(reviews))
+	for _, review := range reviews {
+		updated[review.ID] = true
+		result.Updated = append(result.Updated, review.ID)
+	}
+	for _, id := range req.ReviewIDs {
+		if !updated[id] {
+			result.NotFound = append(result.NotFound, id)
+			updated[id] = true // report duplicates once
+		}
+	}
+	return result, nil
+}
+
+// DeleteReview removes a review and recomputes its product's rating
+func (s *reviewService) DeleteReview(id uint) error {
+	review, err := s.reviewRepo.Delete(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("review not found")
+		}
+		return err
+	}
+
+	s.invalidateProducts([]models.Review{*review})
+	return nil
+}
+
+func (s *reviewService) moderate(ids []uint, approve bool, adminNotes string) ([]models.Review, error) {
+	reviews, err := s.reviewRepo.Moderate(ids, approve, strings.TrimSpace(adminNotes), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateProducts(reviews)
+	return reviews, nil
+}
+
+// invalidateProducts drops cached products whose rating was recomputed
+func (s *reviewService) invalidateProducts(reviews []models.Review) {
+	seen := make(map[uint]bool)
+	for _, review := range reviews {
+		if seen[review.ProductID] {
+			continue
+		}
+		seen[review.ProductID] = true
+		if err := s.productService.InvalidateProductCache(review.ProductID); err != nil {
+			log.Printf("Failed to invalidate cache for product %d: %v", review.ProductID, err)
+		}
+	}
+}
+
+func normalizeReviewPage(limit, offset int) (int, int) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"errors"
+	"mime/multipart"
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockReviewRepository for testing
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) Create(review *models.Review) error {
+	return m.Called(review).Error(0)
+}
+
+func (m *MockReviewRepository) GetByID(id uint) (*models.Review, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) FindByUserAndProduct(userID, productID uint) (*models.Review, error) {
+	args := m.Called(userID, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetApprovedByProductID(productID uint, limit, offset int) ([]models.Review, int64, error) {
+	args := m.Called(productID, limit, offset)
+	return args.Get(0).([]models.Review), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockReviewRepository) GetByUserID(userID uint, limit, offset int) ([]models.Review, int64, error) {
+	args := m.Called(userID, limit, offset)
+	return args.Get(0).([]models.Review), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockReviewRepository) HasDeliveredPurchase(userID, productID uint) (bool, error) {
+	args := m.Called(userID, productID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetPending(limit, offset int) ([]models.Review, int64, error) {
+	args := m.Called(limit, offset)
+	return args.Get(0).([]models.Review), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockReviewRepository) Moderate(ids []uint, approve bool, adminNotes string, at time.Time) ([]models.Review, error) {
+	args := m.Called(ids, approve, adminNotes, at)
+	return args.Get(0).([]models.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) Delete(id uint) (*models.Review, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Review), args.Error(1)
+}
+
+// MockProductService records cache invalidations; other methods are not used by reviews
+type MockProductService struct {
+	ProductService
+	mock.Mock
+}
+
+func (m *MockProductService) InvalidateProductCache(id uint) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockReviewRepository) Vote(reviewID, userID uint, helpful bool) (*models.Review, error) {
+	args := m.Called(reviewID, userID, helpful)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) CountImages(reviewID uint) (int64, error) {
+	args := m.Called(reviewID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReviewRepository) AddImage(image *models.ReviewImage) error {
+	return m.Called(image).Error(0)
+}
+
+// MockMediaService covers the storage calls used for review photos
+type MockMediaService struct {
+	MediaService
+	mock.Mock
+}
+
+func (m *MockMediaService) ValidateImageFile(fileHeader *multipart.FileHeader) error {
+	return m.Called(fileHeader).Error(0)
+}
+
+func (m *MockMediaService) StoreImage(fileHeader *multipart.FileHeader, folder string) (*StoredImage, error) {
+	args := m.Called(fileHeader, folder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*StoredImage), args.Error(1)
+}
+
+func (m *MockMediaService) DeleteStoredImage(image *StoredImage) error {
+	return m.Called(image).Error(0)
+}
+
+func newTestReviewService() (ReviewService, *MockReviewRepository, *MockProductRepository, *MockProductService) {
+	service, reviewRepo, productRepo, productService, _ := newTestReviewServiceWithMedia()
+	return service, reviewRepo, productRepo, productService
+}
+
+func newTestReviewServiceWithMedia() (ReviewService, *MockReviewRepository, *MockProductRepository, *MockProductService, *MockMediaService) {
+	reviewRepo := new(MockReviewRepository)
+	productRepo := new(MockProductRepository)
+	productService := new(MockProductService)
+	mediaService := new(MockMediaService)
+	return NewReviewService(reviewRepo, productRepo, productService, mediaService), reviewRepo, productRepo, productService, mediaService
+}
+
+func TestReviewService_CreateReview(t *testing.T) {
+	req := &models.CreateReviewRequest{ProductID: 3, Rating: 5, Title: " Great fit ", Comment: "Soft fabric"}
+
+	t.Run("creates verified review after delivery", func(t *testing.T) {
+		service, reviewRepo, productRepo, _ := newTestReviewService()
+
+		productRepo.On("GetByID", uint(3)).Return(&models.Product{ID: 3}, nil)
+		reviewRepo.On("HasDeliveredPurchase", uint(1), uint(3)).Return(true, nil)
+		reviewRepo.On("FindByUserAndProduct", uint(1), uint(3)).Return(nil, gorm.ErrRecordNotFound)
+		reviewRepo.On("Create", mock.MatchedBy(func(r *models.Review) bool {
+			return r.UserID == 1 && r.ProductID == 3 && r.IsVerified && !r.IsApproved && r.Title == "Great fit"
+		})).Return(nil)
+
+		review, err := service.CreateReview(1, req)
+		assert.NoError(t, err)
+		assert.True(t, review.IsVerified)
+		reviewRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects product that was not delivered", func(t *testing.T) {
+		service, reviewRepo, productRepo, _ := newTestReviewService()
+
+		productRepo.On("GetByID", uint(3)).Return(&models.Product{ID: 3}, nil)
+		reviewRepo.On("HasDeliveredPurchase", uint(1), uint(3)).Return(false, nil)
+
+		_, err := service.CreateReview(1, req)
+		assert.EqualError(t, err, "only customers who received this product can review it")
+		reviewRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("rejects second review", func(t *testing.T) {
+		service, reviewRepo, productRepo, _ := newTestReviewService()
+
+		productRepo.On("GetByID", uint(3)).Return(&models.Product{ID: 3}, nil)
+		reviewRepo.On("HasDeliveredPurchase", uint(1), uint(3)).Return(true, nil)
+		reviewRepo.On("FindByUserAndProduct", uint(1), uint(3)).Return(&models.Review{ID: 9}, nil)
+
+		_, err := service.CreateReview(1, req)
+		assert.EqualError(t, err, "you have already reviewed this product")
+		reviewRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("product not found", func(t *testing.T) {
+		service, _, productRepo, _ := newTestReviewService()
+
+		productRepo.On("GetByID", uint(3)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.CreateReview(1, req)
+		assert.EqualError(t, err, "product not found")
+	})
+}
+
+func TestReviewService_ApproveReview(t *testing.T) {
+	t.Run("approves and invalidates product cache", func(t *testing.T) {
+		service, reviewRepo, _, productService := newTestReviewService()
+
+		reviewRepo.On("Moderate", []uint{7}, true, "Looks good", mock.AnythingOfType("time.Time")).
+			Return([]models.Review{{ID: 7, ProductID: 3, IsApproved: true, AdminNotes: "Looks good"}}, nil)
+		productService.On("InvalidateProductCache", uint(3)).Return(nil)
+
+		review, err := service.ApproveReview(7, "  Looks good ")
+		assert.NoError(t, err)
+		assert.True(t, review.IsApproved)
+		productService.AssertExpectations(t)
+	})
+
+	t.Run("review not found", func(t *testing.T) {
+		service, reviewRepo, _, _ := newTestReviewService()
+
+		reviewRepo.On("Moderate", []uint{7}, true, "", mock.Anything).Return([]models.Review{}, nil)
+
+		_, err := service.ApproveReview(7, "")
+		assert.EqualError(t, err, "review not found")
+	})
+}
+
+func TestReviewService_BulkModerate(t *testing.T) {
+	service, reviewRepo, _, productService := newTestReviewService()
+
+	reviewRepo.On("Moderate", []uint{1, 2, 9}, false, "Spam", mock.Anything).Return([]models.Review{
+		{ID: 1, ProductID: 3},
+		{ID: 2, ProductID: 3},
+	}, nil)
+	productService.On("InvalidateProductCache", uint(3)).Return(nil)
+
+	result, err := service.BulkModerate(&models.BulkModerateReviewsRequest{
+		ReviewIDs:  []uint{1, 2, 9},
+		Action:     models.ReviewActionReject,
+		AdminNotes: "Spam",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1, 2}, result.Updated)
+	assert.Equal(t, []uint{9}, result.NotFound)
+	// One product, invalidated once
+	productService.AssertNumberOfCalls(t, "InvalidateProductCache", 1)
+}
+
+func TestReviewService_DeleteReview_NotFound(t *testing.T) {
+	service, reviewRepo, _, _ := newTestReviewService()
+
+	reviewRepo.On("Delete", uint(7)).Return(nil, gorm.ErrRecordNotFound)
+
+	assert.EqualError(t, service.DeleteReview(7), "review not found")
+}
+
+func TestReviewService_VoteReview(t *testing.T) {
+	t.Run("records helpful vote", func(t *testing.T) {
+		service, reviewRepo, _, _ := newTestReviewService()
+
+		reviewRepo.On("GetByID", uint(7)).Return(&models.Review{ID: 7, UserID: 2, IsApproved: true}, nil)
+		reviewRepo.On("Vote", uint(7), uint(1), true).Return(&models.Review{ID: 7, HelpfulCount: 4}, nil)
+
+		review, err := service.VoteReview(1, 7, true)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, review.HelpfulCount)
+	})
+
+	t.Run("rejects second vote", func(t *testing.T) {
+		service, reviewRepo, _, _ := newTestReviewService()
+
+		reviewRepo.On("GetByID", uint(7)).Return(&models.Review{ID: 7, UserID: 2, IsApproved: true}, nil)
+		reviewRepo.On("Vote", uint(7), uint(1), false).Return(nil, gorm.ErrDuplicatedKey)
+
+		_, err := service.VoteReview(1, 7, false)
+		assert.EqualError(t, err, "you have already voted on this review")
+	})
+
+	t.Run("rejects vote on own review", func(t *testing.T) {
+		service, reviewRepo, _, _ := newTestReviewService()
+
+		reviewRepo.On("GetByID", uint(7)).Return(&models.Review{ID: 7, UserID: 1, IsApproved: true}, nil)
+
+		_, err := service.VoteReview(1, 7, true)
+		assert.EqualError(t, err, "you cannot vote on your own review")
+		reviewRepo.AssertNotCalled(t, "Vote", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("hides unapproved review", func(t *testing.T) {
+		service, reviewRepo, _, _ := newTestReviewService()
+
+		reviewRepo.On("GetByID", uint(7)).Return(&models.Review{ID: 7, UserID: 2}, nil)
+
+		_, err := service.VoteReview(1, 7, true)
+		assert.EqualError(t, err, "review not found")
+	})
+}
+
+func TestReviewService_AddReviewImage(t *testing.T) {
+	file := &multipart.FileHeader{Filename: "fit-check.jpg", Size: 1024}
+	stored := &StoredImage{URL: "https://cdn.example.com/reviews/7/1.jpg", StorageProvider: "r2", StoragePath: "reviews/7/1.jpg"}
+
+	t.Run("stores photo through media service", func(t *testing.T) {
+		service, reviewRepo, _, _, mediaService := newTestReviewServiceWithMedia()
+
+		reviewRepo.On("GetByID", uint(7)).Return(&models.Review{ID: 7, UserID: 1}, nil)
+		reviewRepo.On("CountImages", uint(7)).Return(int64(0), nil)
+		mediaService.On("ValidateImageFile", file).Return(nil)
+		mediaService.On("StoreImage", file, "reviews/7").Return(stored, nil)
+		reviewRepo.On("AddImage", mock.MatchedBy(func(img *models.ReviewImage) bool {
+			return img.ReviewID == 7 && img.URL == stored.URL && img.AltText == "fit-check"
+		})).Return(nil)
+
+		image, err := service.AddReviewImage(1, 7, file)
+		assert.NoError(t, err)
+		assert.Equal(t, stored.URL, image.URL)
+	})
+
+	t.Run("removes stored file when saving fails", func(t *testing.T) {
+		service, reviewRepo, _, _, mediaService := newTestReviewServiceWithMedia()
+
+		reviewRepo.On("GetByID", uint(7)).Return(&models.Review{ID: 7, UserID: 1}, nil)
+		reviewRepo.On("CountImages", uint(7)).Return(int64(0), nil)
+		mediaService.On("ValidateImageFile", file).Return(nil)
+		mediaService.On("StoreImage", file, "reviews/7").Return(stored, nil)
+		reviewRepo.On("AddImage", mock.Anything).Return(errors.New("db down"))
+		mediaService.On("DeleteStoredImage", stored).Return(nil)
+
+		_, err := service.AddReviewImage(1, 7, file)
+		assert.Error(t, err)
+		mediaService.AssertCalled(t, "DeleteStoredImage", stored)
+	})
+
+	t.Run("rejects moderated review", func(t *testing.T) {
+		service, reviewRepo, _, _, mediaService := newTestReviewServiceWithMedia()
+
+		moderatedAt := time.Now()
+		reviewRepo.On("GetByID", uint(7)).Return(&models.Review{ID: 7, UserID: 1, ModeratedAt: &moderatedAt}, nil)
+
+		_, err := service.AddReviewImage(1, 7, file)
+		assert.EqualError(t, err, "photos can only be added before the review is moderated")
+		mediaService.AssertNotCalled(t, "StoreImage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects someone else's review", func(t *testing.T) {
+		service, reviewRepo, _, _, _ := newTestReviewServiceWithMedia()
+
+		reviewRepo.On("GetByID", uint(7)).Return(&models.Review{ID: 7, UserID: 2}, nil)
+
+		_, err := service.AddReviewImage(1, 7, file)
+		assert.EqualError(t, err, "review not found")
+	})
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Rates a zone gets when the request leaves them out, matching the rates used outside any zone
+const (
+	defaultJNEBaseRate     = 15000
+	defaultTIKIBaseRate    = 16000
+	defaultPOSBaseRate     = 14000
+	defaultSiCepatBaseRate = 13000
+	defaultMinimumCost     = 9000
+)
+
+// ShippingZoneService manages the shipping zones PricingService quotes shipping and free
+// shipping with
+type ShippingZoneService interface {
+	// ListZones lists every zone, or only those with the given status
+	ListZones(status models.ShippingZoneStatus) ([]models.ShippingZone, error)
+	GetZone(id uint) (*models.ShippingZone, error)
+	CreateZone(req *models.ShippingZoneRequest) (*models.ShippingZone, error)
+	// UpdateZone replaces every field and region of the zone with the request
+	UpdateZone(id uint, req *models.ShippingZoneRequest) (*models.ShippingZone, error)
+	DeleteZone(id uint) error
+}
+
+type shippingZoneService struct {
+	zoneRepo repository.ShippingZoneRepository
+}
+
+func NewShippingZoneService(zoneRepo repository.ShippingZoneRepository) ShippingZoneService {
+	return &shippingZoneService{
+		zoneRepo: zoneRepo,
+	}
+}
+
+func (s *shippingZoneService) ListZones(status models.ShippingZoneStatus) ([]models.ShippingZone, error) {
+	switch status {
+	case "":
+		return s.zoneRepo.GetAll()
+	case models.ShippingZoneActive:
+		return s.zoneRepo.GetActive()
+	}
+
+	zones, err := s.zoneRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]models.ShippingZone, 0, len(zones))
+	for _, zone := range zones {
+		if zone.Status == status {
+			filtered = append(filtered, zone)
+		}
+	}
+	return filtered, nil
+}
+
+func (s *shippingZoneService) GetZone(id uint) (*models.ShippingZone, error) {
+	zone, err := s.zoneRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("shipping zone not found")
+		}
+		return nil, err
+	}
+	return zone, nil
+}
+
+func (s *shippingZoneService) CreateZone(req *models.ShippingZoneRequest) (*models.ShippingZone, error) {
+	zone := &models.ShippingZone{}
+	if err := applyShippingZoneRequest(zone, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.zoneRepo.Create(zone); err != nil {
+		return nil, fmt.Errorf("failed to create shipping zone: %w", err)
+	}
+	return zone, nil
+}
+
+func (s *shippingZoneService) UpdateZone(id uint, req *models.ShippingZoneRequest) (*models.ShippingZone, error) {
+	zone, err := s.GetZone(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyShippingZoneRequest(zone, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.zoneRepo.Update(zone); err != nil {
+		return nil, fmt.Errorf("failed to update shipping zone: %w", err)
+	}
+	return zone, nil
+}
+
+func (s *shippingZoneService) DeleteZone(id uint) error {
+	if _, err := s.GetZone(id); err != nil {
+		return err
+	}
+	if err := s.zoneRepo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete shipping zone: %w", err)
+	}
+	return nil
+}
+
+// applyShippingZoneRequest copies the request onto the zone, filling in default rates and
+// normalizing region codes
+func applyShippingZoneRequest(zone *models.ShippingZone, req *models.ShippingZoneRequest) error {
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidUntil.After(*req.ValidFrom) {
+		return errors.New("valid_until must be after valid_from")
+	}
+	if req.FreeShippingThreshold < 0 {
+		return errors.New("free_shipping_threshold cannot be negative")
+	}
+
+	regions := normalizeRegionCodes(req.Regions)
+	if len(regions) == 0 {
+		return errors.New("at least one region is required")
+	}
+
+	status := req.Status
+	if status == "" {
+		status = models.ShippingZoneActive
+	}
+
+	*zone = models.ShippingZone{
+		ID:                    zone.ID,
+		CreatedAt:             zone.CreatedAt,
+		Name:                  req.Name,
+		Description:           req.Description,
+		Status:                status,
+		Regions:               regions,
+		ExcludeRegions:        normalizeRegionCodes(req.ExcludeRegions),
+		FreeShippingEnabled:   req.FreeShippingEnabled,
+		FreeShippingThreshold: req.FreeShippingThreshold,
+		JNEBaseRate:           floatOr(req.JNEBaseRate, defaultJNEBaseRate),
+		TIKIBaseRate:          floatOr(req.TIKIBaseRate, defaultTIKIBaseRate),
+		POSBaseRate:           floatOr(req.POSBaseRate, defaultPOSBaseRate),
+		SiCepatBaseRate:       floatOr(req.SiCepatBaseRate, defaultSiCepatBaseRate),
+		HandlingFee:           req.HandlingFee,
+		MinimumCost:           floatOr(req.MinimumCost, defaultMinimumCost),
+		ValidFrom:             req.ValidFrom,
+		ValidUntil:            req.ValidUntil,
+	}
+	return nil
+}
+
+// normalizeRegionCodes normalizes the codes and drops blanks and duplicates
+func normalizeRegionCodes(codes []string) []string {
+	normalized := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		code = models.NormalizeRegionCode(code)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		normalized = append(normalized, code)
+	}
+	return normalized
+}
+
+func floatOr(value *float64, fallback float64) float64 {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
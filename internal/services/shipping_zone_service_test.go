@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestShippingZoneService_CreateZone_Defaults(t *testing.T) {
+	zoneRepo := new(MockShippingZoneRepository)
+	zoneRepo.On("Create", mock.AnythingOfType("*models.ShippingZone")).Return(nil)
+	service := NewShippingZoneService(zoneRepo)
+
+	tikiRate := 18000.0
+	zone, err := service.CreateZone(&models.ShippingZoneRequest{
+		Name:           "Jabodetabek",
+		Regions:        []string{" id-jk", "ID-JK", "id-jb"},
+		ExcludeRegions: []string{"id-jb-bgr"},
+		TIKIBaseRate:   &tikiRate,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ShippingZoneActive, zone.Status)
+	assert.Equal(t, []string{"ID-JK", "ID-JB"}, zone.Regions)
+	assert.Equal(t, []string{"ID-JB-BGR"}, zone.ExcludeRegions)
+	assert.Equal(t, 15000.0, zone.JNEBaseRate)
+	assert.Equal(t, 18000.0, zone.TIKIBaseRate)
+	assert.Equal(t, 9000.0, zone.MinimumCost)
+	zoneRepo.AssertExpectations(t)
+}
+
+func TestShippingZoneService_CreateZone_Invalid(t *testing.T) {
+	service := NewShippingZoneService(new(MockShippingZoneRepository))
+
+	_, err := service.CreateZone(&models.ShippingZoneRequest{Name: "Empty", Regions: []string{" "}})
+	assert.EqualError(t, err, "at least one region is required")
+
+	from := time.Now()
+	until := from.Add(-time.Hour)
+	_, err = service.CreateZone(&models.ShippingZoneRequest{Name: "Backwards", Regions: []string{"ID-JK"}, ValidFrom: &from, ValidUntil: &until})
+	assert.EqualError(t, err, "valid_until must be after valid_from")
+}
+
+func TestShippingZoneService_UpdateZone(t *testing.T) {
+	created := time.Now().Add(-24 * time.Hour)
+	zoneRepo := new(MockShippingZoneRepository)
+	zoneRepo.On("GetByID", uint(3)).Return(&models.ShippingZone{ID: 3, CreatedAt: created, Name: "Old", JNEBaseRate: 20000, Regions: []string{"ID-JK"}}, nil)
+	zoneRepo.On("GetByID", uint(4)).Return(nil, gorm.ErrRecordNotFound)
+	zoneRepo.On("Update", mock.AnythingOfType("*models.ShippingZone")).Return(nil)
+	service := NewShippingZoneService(zoneRepo)
+
+	zone, err := service.UpdateZone(3, &models.ShippingZoneRequest{
+		Name:                  "Java",
+		Status:                models.ShippingZoneInactive,
+		Regions:               []string{"ID-JT"},
+		FreeShippingEnabled:   true,
+		FreeShippingThreshold: 250000,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), zone.ID)
+	assert.Equal(t, created, zone.CreatedAt)
+	assert.Equal(t, models.ShippingZoneInactive, zone.Status)
+	assert.Equal(t, []string{"ID-JT"}, zone.Regions)
+	// The update replaces the zone, so rates left out go back to the defaults
+	assert.Equal(t, 15000.0, zone.JNEBaseRate)
+	assert.Equal(t, 250000.0, zone.FreeShippingThreshold)
+
+	_, err = service.UpdateZone(4, &models.ShippingZoneRequest{Name: "Missing", Regions: []string{"ID-JK"}})
+	assert.EqualError(t, err, "shipping zone not found")
+}
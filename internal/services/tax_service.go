@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+)
+
+// TaxCalculationRequest is an order amount to be taxed
+type TaxCalculationRequest struct {
+	Region   string       // destination region code, the same code shipping zones use
+	At       time.Time    // order date; zero means now
+	Amount   models.Money // goods after every discount
+	Shipping models.Money // shipping charged to the customer
+}
+
+// TaxLine is one tax charged on an order
+type TaxLine struct {
+	TaxID         uint           `json:"tax_id"`
+	Name          string         `json:"name"`
+	Type          models.TaxType `json:"type"`
+	Rate          float64        `json:"rate"` // fraction for percentage taxes, e.g. 0.11; rupiah for fixed ones
+	TaxableAmount models.Money   `json:"taxable_amount"`
+	Amount        models.Money   `json:"amount"`
+}
+
+// TaxService works out the taxes of an order from the tax rules in the taxes table
+type TaxService interface {
+	// Calculate returns every tax that applies to the order and their total. Each tax is rounded
+	// to whole rupiah on its own, so the total is the sum of the lines.
+	Calculate(req TaxCalculationRequest) ([]TaxLine, models.Money, error)
+}
+
+type taxService struct {
+	taxRepo repository.TaxRepository
+}
+
+func NewTaxService(taxRepo repository.TaxRepository) TaxService {
+	return &taxService{
+		taxRepo: taxRepo,
+	}
+}
+
+func (s *taxService) Calculate(req TaxCalculationRequest) ([]TaxLine, models.Money, error) {
+	at := req.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	taxes, err := s.taxRepo.GetActive(at)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load taxes: %w", err)
+	}
+
+	lines := make([]TaxLine, 0, len(taxes))
+	var total models.Money
+	for i := range taxes {
+		tax := &taxes[i]
+		if !tax.AppliesTo(req.Region, at) {
+			continue
+		}
+
+		base := req.Amount
+		if tax.ApplyToShipping {
+			base += req.Shipping
+		}
+		if base < 0 {
+			base = 0
+		}
+
+		line := TaxLine{
+			TaxID:         tax.ID,
+			Name:          tax.Name,
+			Type:          tax.Type,
+			Rate:          tax.Rate,
+			TaxableAmount: base,
+		}
+		switch tax.Type {
+		case models.TaxTypePercentage:
+			line.Amount = base.MulRate(tax.Rate)
+		case models.TaxTypeFixed:
+			line.Amount = models.NewMoney(tax.Rate)
+		default:
+			continue
+		}
+
+		lines = append(lines, line)
+		total += line.Amount
+	}
+	return lines, total, nil
+}
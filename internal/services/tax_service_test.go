@@ -0,0 +1,61 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTaxRepository
+type MockTaxRepository struct {
+	mock.Mock
+}
+
+func (m *MockTaxRepository) GetActive(at time.Time) ([]models.Tax, error) {
+	args := m.Called(at)
+	return args.Get(0).([]models.Tax), args.Error(1)
+}
+
+// newTestTaxService charges the seeded 11% PPN on goods everywhere
+func newTestTaxService() TaxService {
+	taxRepo := new(MockTaxRepository)
+	taxRepo.On("GetActive", mock.Anything).Return([]models.Tax{
+		{ID: 1, Name: "PPN", Type: models.TaxTypePercentage, Status: models.TaxStatusActive, Rate: 0.11, IsDefault: true},
+	}, nil)
+	return NewTaxService(taxRepo)
+}
+
+func TestTaxService_Calculate_ResolvesRulesForRegion(t *testing.T) {
+	lastYear := time.Now().AddDate(-1, 0, 0)
+	taxRepo := new(MockTaxRepository)
+	taxRepo.On("GetActive", mock.Anything).Return([]models.Tax{
+		{ID: 1, Name: "PPN", Type: models.TaxTypePercentage, Status: models.TaxStatusActive, Rate: 0.11, IsDefault: true, ApplyToShipping: true, ExcludeRegions: []string{"ID-BT"}},
+		{ID: 2, Name: "Retribusi DKI", Type: models.TaxTypeFixed, Status: models.TaxStatusActive, Rate: 2000, ApplicableRegions: []string{"ID-JK"}},
+		{ID: 3, Name: "Old levy", Type: models.TaxTypePercentage, Status: models.TaxStatusActive, Rate: 0.01, ValidUntil: &lastYear},
+	}, nil)
+	service := NewTaxService(taxRepo)
+
+	lines, total, err := service.Calculate(TaxCalculationRequest{Region: "ID-JK", Amount: 100000, Shipping: 20000})
+	assert.NoError(t, err)
+	assert.Equal(t, []TaxLine{
+		{TaxID: 1, Name: "PPN", Type: models.TaxTypePercentage, Rate: 0.11, TaxableAmount: 120000, Amount: 13200},
+		{TaxID: 2, Name: "Retribusi DKI", Type: models.TaxTypeFixed, Rate: 2000, TaxableAmount: 100000, Amount: 2000},
+	}, lines)
+	assert.Equal(t, models.Money(15200), total)
+
+	// Excluded region
+	lines, total, err = service.Calculate(TaxCalculationRequest{Region: "ID-BT", Amount: 100000})
+	assert.NoError(t, err)
+	assert.Empty(t, lines)
+	assert.Equal(t, models.Money(0), total)
+
+	// Unknown destination: only default taxes
+	lines, _, err = service.Calculate(TaxCalculationRequest{Amount: 100000})
+	assert.NoError(t, err)
+	if assert.Len(t, lines, 1) {
+		assert.Equal(t, "PPN", lines[0].Name)
+	}
+}
@@ -14,6 +14,7 @@ type UserService interface {
 	UpdateUserRole(id uint, role models.UserRole) error
 	DeactivateUser(id uint) error
 	ActivateUser(id uint) error
+	SetWhatsAppOptOut(id uint, optOut bool) (*models.User, error)
 	GetUserStats() (map[string]interface{}, error)
 }
 
@@ -122,6 +123,25 @@ func (s *userService) ActivateUser(id uint) error {
 	return nil
 }
 
+// SetWhatsAppOptOut records whether the user wants marketing WhatsApp messages.
+// Order and payment notifications are not affected.
+func (s *userService) SetWhatsAppOptOut(id uint, optOut bool) (*models.User, error) {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	user.WhatsAppOptOut = optOut
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to update notification preferences: %w", err)
+	}
+
+	return user, nil
+}
+
 // GetUserStats returns user statistics
 func (s *userService) GetUserStats() (map[string]interface{}, error) {
 	// This is a placeholder implementation
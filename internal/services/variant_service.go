@@ -15,7 +15,9 @@ type VariantService interface {
 	GetVariantByID(id uint) (*models.ProductVariant, error)
 	GetVariantBySKU(sku string) (*models.ProductVariant, error)
 	GetVariantsByProductID(productID uint) ([]models.ProductVariant, error)
-	UpdateVariant(id uint, variant *models.ProductVariant) error
+	// UpdateVariant saves the variant; a price change is recorded in the price history as made
+	// by actorID
+	UpdateVariant(id uint, variant *models.ProductVariant, actorID *uint) error
 	DeleteVariant(id uint) error
 	UpdateVariantStock(id uint, quantity int) error
 	GenerateSKU(productName, size, color string) string
@@ -107,7 +109,7 @@ func (s *variantService) GetVariantsByProductID(productID uint) ([]models.Produc
 	return s.variantRepo.GetByProductID(productID)
 }
 
-func (s *variantService) UpdateVariant(id uint, variant *models.ProductVariant) error {
+func (s *variantService) UpdateVariant(id uint, variant *models.ProductVariant, actorID *uint) error {
 	// Check if variant exists
 	existingVariant, err := s.variantRepo.GetByID(id)
 	if err != nil {
@@ -131,7 +133,7 @@ func (s *variantService) UpdateVariant(id uint, variant *models.ProductVariant)
 		}
 	}
 
-	return s.variantRepo.Update(variant)
+	return s.variantRepo.UpdateWithPriceHistory(variant, actorID)
 }
 
 func (s *variantService) DeleteVariant(id uint) error {
@@ -722,7 +722,7 @@ func TestVariantService_UpdateVariant_Success(t *testing.T) {
 
 	// Update variant
 	updatedVariant := createTestVariant(product.ID, "Updated Name", "L", "Blue", 120.00, 15)
-	err = service.UpdateVariant(variant.ID, updatedVariant)
+	err = service.UpdateVariant(variant.ID, updatedVariant, nil)
 	require.NoError(t, err)
 
 	// Verify update
@@ -735,12 +735,27 @@ func TestVariantService_UpdateVariant_Success(t *testing.T) {
 	assert.Equal(t, 15, fetched.Stock)
 }
 
+func TestVariantService_UpdateVariant_RecordsActor(t *testing.T) {
+	variantRepo := new(MockVariantRepository)
+	service := NewVariantService(variantRepo, new(MockProductRepository))
+
+	adminID := uint(4)
+	variant := &models.ProductVariant{ProductID: 1, Name: "M / Red", Price: 99.00}
+	variantRepo.On("GetByID", uint(8)).Return(&models.ProductVariant{ID: 8, ProductID: 1, Price: 110.00}, nil)
+	variantRepo.On("UpdateWithPriceHistory", variant, &adminID).Return(nil)
+
+	err := service.UpdateVariant(8, variant, &adminID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(8), variant.ID)
+	variantRepo.AssertExpectations(t)
+}
+
 func TestVariantService_UpdateVariant_NotFound(t *testing.T) {
 	_, product, service, cleanup := setupVariantServiceTest(t)
 	defer cleanup()
 
 	variant := createTestVariant(product.ID, "Update Test", "M", "Red", 110.00, 10)
-	err := service.UpdateVariant(99999, variant)
+	err := service.UpdateVariant(99999, variant, nil)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "variant not found")
@@ -771,7 +786,7 @@ func TestVariantService_UpdateVariant_ChangeProduct(t *testing.T) {
 
 	// Update to second product
 	updatedVariant := createTestVariant(product2.ID, "Changed Product", "M", "Red", 110.00, 10)
-	err = service.UpdateVariant(variant.ID, updatedVariant)
+	err = service.UpdateVariant(variant.ID, updatedVariant, nil)
 	require.NoError(t, err)
 
 	// Verify
@@ -791,7 +806,7 @@ func TestVariantService_UpdateVariant_ChangeToNonExistentProduct(t *testing.T) {
 
 	// Try to update to non-existent product
 	updatedVariant := createTestVariant(99999, "Non-existent", "M", "Red", 110.00, 10)
-	err = service.UpdateVariant(variant.ID, updatedVariant)
+	err = service.UpdateVariant(variant.ID, updatedVariant, nil)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "product not found")
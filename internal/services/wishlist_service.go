@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/karima-store/internal/models"
+	"github.com/karima-store/internal/repository"
+	"gorm.io/gorm"
+)
+
+// WishlistService manages the products a user has saved for later
+type WishlistService interface {
+	GetWishlist(userID uint, limit, offset int) ([]models.Wishlist, int64, error)
+	AddItem(userID, productID uint) (*models.Wishlist, bool, error)
+	RemoveItem(userID, productID uint) error
+}
+
+type wishlistService struct {
+	wishlistRepo   repository.WishlistRepository
+	productRepo    repository.ProductRepository
+	variantRepo    repository.VariantRepository
+	pricingService PricingService
+}
+
+func NewWishlistService(wishlistRepo repository.WishlistRepository, productRepo repository.ProductRepository, variantRepo repository.VariantRepository, pricingService PricingService) WishlistService {
+	return &wishlistService{
+		wishlistRepo:   wishlistRepo,
+		productRepo:    productRepo,
+		variantRepo:    variantRepo,
+		pricingService: pricingService,
+	}
+}
+
+func (s *wishlistService) GetWishlist(userID uint, limit, offset int) ([]models.Wishlist, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.wishlistRepo.GetByUserID(userID, limit, offset)
+}
+
+// AddItem wishlists a product. Adding a product twice is not an error; the existing
+// entry is returned and the boolean reports whether a new entry was created.
+func (s *wishlistService) AddItem(userID, productID uint) (*models.Wishlist, bool, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, errors.New("product not found")
+		}
+		return nil, false, err
+	}
+	if product.Status == models.StatusDiscontinued {
+		return nil, false, errors.New("product is discontinued")
+	}
+
+	existing, err := s.wishlistRepo.Find(userID, productID)
+	if err == nil {
+		existing.Product = *product
+		return existing, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	wishlist := &models.Wishlist{
+		UserID:    userID,
+		ProductID: productID,
+	}
+
+	// Remember today's price so later drops can be measured against it
+	if price, err := wishlistPrice(s.pricingService, s.variantRepo, product); err == nil && price != nil {
+		wishlist.PriceBaseline = price.FinalPrice.Float64()
+	}
+
+	if err := s.wishlistRepo.Create(wishlist); err != nil {
+		return nil, false, err
+	}
+
+	wishlist.Product = *product
+	return wishlist, true, nil
+}
+
+func (s *wishlistService) RemoveItem(userID, productID uint) error {
+	if err := s.wishlistRepo.Delete(userID, productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("wishlist item not found")
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/karima-store/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockWishlistRepository for testing
+type MockWishlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWishlistRepository) GetByUserID(userID uint, limit, offset int) ([]models.Wishlist, int64, error) {
+	args := m.Called(userID, limit, offset)
+	return args.Get(0).([]models.Wishlist), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockWishlistRepository) Find(userID, productID uint) (*models.Wishlist, error) {
+	args := m.Called(userID, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) Create(wishlist *models.Wishlist) error {
+	return m.Called(wishlist).Error(0)
+}
+
+func (m *MockWishlistRepository) Delete(userID, productID uint) error {
+	return m.Called(userID, productID).Error(0)
+}
+
+func (m *MockWishlistRepository) GetRestockRecipients(productIDs []uint, notifiedBefore time.Time) ([]models.Wishlist, error) {
+	args := m.Called(productIDs, notifiedBefore)
+	return args.Get(0).([]models.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) MarkRestockNotified(ids []uint, at time.Time) error {
+	return m.Called(ids, at).Error(0)
+}
+
+func (m *MockWishlistRepository) GetWishlistedProductIDs() ([]uint, error) {
+	args := m.Called()
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetByProductID(productID uint) ([]models.Wishlist, error) {
+	args := m.Called(productID)
+	return args.Get(0).([]models.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) UpdatePriceBaseline(ids []uint, price float64) error {
+	return m.Called(ids, price).Error(0)
+}
+
+func (m *MockWishlistRepository) CreatePriceAlert(alert *models.PriceAlert) error {
+	return m.Called(alert).Error(0)
+}
+
+func (m *MockWishlistRepository) CountPriceAlertsSince(userID uint, since time.Time) (int64, error) {
+	args := m.Called(userID, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func newTestWishlistService() (WishlistService, *MockWishlistRepository, *MockProductRepository) {
+	wishlistRepo := new(MockWishlistRepository)
+	productRepo := new(MockProductRepository)
+	flashSaleRepo := new(MockFlashSaleRepository)
+	flashSaleRepo.On("GetActiveFlashSales").Return([]models.FlashSale{}, nil)
+
+	variantRepo := new(MockVariantRepository)
+	variantRepo.On("GetByProductID", mock.Anything).Return([]models.ProductVariant{}, nil)
+
+	pricing := NewPricingService(productRepo, variantRepo, NewPromotionIndex(flashSaleRepo, nil, PromotionIndexConfig{}), newTestDiscountTiers(), new(MockCouponRepository), nil, new(MockShippingZoneRepository), nil)
+	return NewWishlistService(wishlistRepo, productRepo, variantRepo, pricing), wishlistRepo, productRepo
+}
+
+func TestWishlistService_AddItem(t *testing.T) {
+	t.Run("creates new entry", func(t *testing.T) {
+		service, wishlistRepo, productRepo := newTestWishlistService()
+
+		productRepo.On("GetByID", uint(3)).Return(&models.Product{ID: 3, Name: "Linen Shirt", Price: 200000, Discount: 10, Status: models.StatusOutOfStock}, nil)
+		wishlistRepo.On("Find", uint(1), uint(3)).Return(nil, gorm.ErrRecordNotFound)
+		wishlistRepo.On("Create", mock.MatchedBy(func(w *models.Wishlist) bool {
+			return w.UserID == 1 && w.ProductID == 3 && w.PriceBaseline == 180000
+		})).Return(nil)
+
+		item, created, err := service.AddItem(1, 3)
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, "Linen Shirt", item.Product.Name)
+	})
+
+	t.Run("returns existing entry", func(t *testing.T) {
+		service, wishlistRepo, productRepo := newTestWishlistService()
+
+		productRepo.On("GetByID", uint(3)).Return(&models.Product{ID: 3, Status: models.StatusAvailable}, nil)
+		wishlistRepo.On("Find", uint(1), uint(3)).Return(&models.Wishlist{ID: 8, UserID: 1, ProductID: 3}, nil)
+
+		item, created, err := service.AddItem(1, 3)
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, uint(8), item.ID)
+		wishlistRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("product not found", func(t *testing.T) {
+		service, _, productRepo := newTestWishlistService()
+
+		productRepo.On("GetByID", uint(3)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, _, err := service.AddItem(1, 3)
+		assert.EqualError(t, err, "product not found")
+	})
+}
+
+func TestWishlistService_RemoveItem_NotFound(t *testing.T) {
+	service, wishlistRepo, _ := newTestWishlistService()
+
+	wishlistRepo.On("Delete", uint(1), uint(3)).Return(gorm.ErrRecordNotFound)
+
+	assert.EqualError(t, service.RemoveItem(1, 3), "wishlist item not found")
+}
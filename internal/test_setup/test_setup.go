@@ -109,6 +109,7 @@ func CleanupTestData(t *testing.T, db *gorm.DB) {
 		"coupon_usages",
 		"flash_sale_products",
 		"order_items",
+		"order_taxes",
 		"cart_items",
 		"stock_logs",
 		"media",
@@ -122,6 +123,7 @@ func CleanupTestData(t *testing.T, db *gorm.DB) {
 		"flash_sales",
 		"users",
 		"shipping_zones",
+		"tax_regions",
 		"taxes",
 	}
 
@@ -216,10 +218,22 @@ func RunMigrations(db *gorm.DB) error {
 		&models.Wishlist{},
 		&models.Coupon{},
 		&models.CouponUsage{},
+		&models.CouponBatch{},
+		&models.CouponProduct{},
+		&models.CouponCategory{},
+		&models.Promotion{},
+		&models.PromotionTier{},
+		&models.DiscountTier{},
+		&models.ResellerApplication{},
+		&models.ScheduledPriceChange{},
+		&models.PriceHistory{},
 		&models.FlashSale{},
 		&models.FlashSaleProduct{},
 		&models.ShippingZone{},
+		&models.ShippingZoneRegion{},
 		&models.Tax{},
+		&models.TaxRegion{},
+		&models.OrderTax{},
 		&models.StockLog{},
 	)
 }
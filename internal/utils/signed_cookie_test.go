@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignedCookie_SignAndVerify(t *testing.T) {
+	cookie := NewSignedCookie("guest_cart_id", "secret", time.Hour, false)
+
+	signed := cookie.Sign("abc123")
+	value, ok := cookie.Verify(signed)
+	assert.True(t, ok)
+	assert.Equal(t, "abc123", value)
+
+	// Tampered value
+	_, ok = cookie.Verify("abc124" + signed[len("abc123"):])
+	assert.False(t, ok)
+
+	// Signed with a different secret
+	other := NewSignedCookie("guest_cart_id", "other-secret", time.Hour, false)
+	_, ok = other.Verify(signed)
+	assert.False(t, ok)
+
+	// Malformed values
+	for _, v := range []string{"", "abc123", ".sig", "abc123."} {
+		_, ok = cookie.Verify(v)
+		assert.False(t, ok, v)
+	}
+}
@@ -0,0 +1,3 @@
+-- Remember which pricing rule produced a cart line's snapshot price
+-- so revalidation can tell when a flash sale has ended
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS discount_type VARCHAR(20) NOT NULL DEFAULT 'none';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS cart_recoveries;
+ALTER TABLE users DROP COLUMN IF EXISTS whatsapp_opt_out;
@@ -0,0 +1,36 @@
+-- Let customers opt out of marketing WhatsApp messages
+ALTER TABLE users ADD COLUMN IF NOT EXISTS whatsapp_opt_out BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Abandoned cart reminders and whether they led to an order
+CREATE TABLE IF NOT EXISTS cart_recoveries (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    cart_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'sent' CHECK (status IN ('sent', 'failed', 'converted')),
+
+    -- Reminder details
+    phone VARCHAR(20) NOT NULL,
+    item_count INTEGER NOT NULL DEFAULT 0,
+    cart_value DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    coupon_id BIGINT,
+    coupon_code VARCHAR(50),
+    error_message TEXT,
+    sent_at TIMESTAMPTZ NOT NULL,
+
+    -- Conversion
+    order_id BIGINT,
+    order_total DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    converted_at TIMESTAMPTZ,
+
+    CONSTRAINT fk_cart_recoveries_cart FOREIGN KEY (cart_id) REFERENCES carts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_cart_recoveries_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_cart_recoveries_coupon FOREIGN KEY (coupon_id) REFERENCES coupons(id) ON DELETE SET NULL,
+    CONSTRAINT fk_cart_recoveries_order FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cart_recoveries_cart_sent ON cart_recoveries(cart_id, sent_at);
+CREATE INDEX IF NOT EXISTS idx_cart_recoveries_user_status ON cart_recoveries(user_id, status);
+CREATE INDEX IF NOT EXISTS idx_cart_recoveries_sent_at ON cart_recoveries(sent_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS restock_events;
+ALTER TABLE wishlists DROP COLUMN IF EXISTS restock_notified_at;
@@ -0,0 +1,18 @@
+-- When a wishlisted product was last announced as back in stock to this user
+ALTER TABLE wishlists ADD COLUMN IF NOT EXISTS restock_notified_at TIMESTAMPTZ;
+
+-- Outbox of 0 -> positive stock transitions, written in the same transaction
+-- as the stock update and processed by the back-in-stock notifier
+CREATE TABLE IF NOT EXISTS restock_events (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT,
+    new_stock INTEGER NOT NULL,
+    processed_at TIMESTAMPTZ,
+
+    CONSTRAINT fk_restock_events_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_restock_events_pending ON restock_events(created_at) WHERE processed_at IS NULL;
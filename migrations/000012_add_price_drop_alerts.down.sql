@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS price_alerts;
+ALTER TABLE wishlists DROP COLUMN IF EXISTS price_baseline;
@@ -0,0 +1,22 @@
+-- Unit price the user last saw for a wishlisted product (when added, alerted, or when it went up)
+ALTER TABLE wishlists ADD COLUMN IF NOT EXISTS price_baseline DECIMAL(12, 2) NOT NULL DEFAULT 0;
+
+-- Price-drop alerts sent over WhatsApp, also used for the per-user daily cap
+CREATE TABLE IF NOT EXISTS price_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    user_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    wishlist_id BIGINT,
+    old_price DECIMAL(12, 2) NOT NULL,
+    new_price DECIMAL(12, 2) NOT NULL,
+    drop_percent DECIMAL(5, 2) NOT NULL,
+    discount_type VARCHAR(20) NOT NULL DEFAULT 'none',
+
+    CONSTRAINT fk_price_alerts_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_price_alerts_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_price_alerts_wishlist FOREIGN KEY (wishlist_id) REFERENCES wishlists(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_alerts_user_created ON price_alerts(user_id, created_at);
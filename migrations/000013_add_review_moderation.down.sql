@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_reviews_pending;
+ALTER TABLE reviews DROP COLUMN IF EXISTS moderated_at;
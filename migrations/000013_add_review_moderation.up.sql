@@ -0,0 +1,12 @@
+-- When an admin approved or rejected the review; NULL means it is waiting in the moderation queue
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMPTZ;
+
+-- Reviews approved before moderation was tracked count as moderated
+UPDATE reviews SET moderated_at = updated_at WHERE is_approved = TRUE AND moderated_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_reviews_pending ON reviews(created_at) WHERE moderated_at IS NULL AND deleted_at IS NULL;
+
+-- Bring product ratings in line with the reviews that are already approved
+UPDATE products p SET
+    rating = COALESCE((SELECT ROUND(AVG(r.rating)::numeric, 2) FROM reviews r WHERE r.product_id = p.id AND r.is_approved = TRUE AND r.deleted_at IS NULL), 0),
+    review_count = (SELECT COUNT(*) FROM reviews r WHERE r.product_id = p.id AND r.is_approved = TRUE AND r.deleted_at IS NULL);
@@ -0,0 +1,15 @@
+-- One helpful / not helpful vote per user per review
+CREATE TABLE IF NOT EXISTS review_votes (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    review_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    is_helpful BOOLEAN NOT NULL,
+
+    CONSTRAINT fk_review_votes_review FOREIGN KEY (review_id) REFERENCES reviews(id) ON DELETE CASCADE,
+    CONSTRAINT fk_review_votes_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT uq_review_votes_review_user UNIQUE (review_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_votes_user_id ON review_votes(user_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_order_items_flash_sale_id;
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS fk_order_items_flash_sale;
+ALTER TABLE order_items DROP COLUMN IF EXISTS flash_sale_id;
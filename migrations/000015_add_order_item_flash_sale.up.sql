@@ -0,0 +1,8 @@
+-- Remember which flash sale an order item was bought in, so sold counts and revenue can be
+-- reconciled from paid orders
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS flash_sale_id BIGINT;
+
+ALTER TABLE order_items
+    ADD CONSTRAINT fk_order_items_flash_sale FOREIGN KEY (flash_sale_id) REFERENCES flash_sales(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_order_items_flash_sale_id ON order_items(flash_sale_id) WHERE flash_sale_id IS NOT NULL;
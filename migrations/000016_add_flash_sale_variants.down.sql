@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_order_items_flash_sale_product_id;
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS fk_order_items_flash_sale_product;
+ALTER TABLE order_items DROP COLUMN IF EXISTS flash_sale_product_id;
+
+-- Variant entries cannot be represented once the column is gone
+DELETE FROM flash_sale_products WHERE variant_id IS NOT NULL;
+
+DROP INDEX IF EXISTS uq_flash_sale_products_variant;
+DROP INDEX IF EXISTS uq_flash_sale_products_product;
+ALTER TABLE flash_sale_products ADD CONSTRAINT uq_flash_sale_products UNIQUE (flash_sale_id, product_id);
+
+ALTER TABLE flash_sale_products DROP CONSTRAINT IF EXISTS fk_flash_sale_products_variant;
+ALTER TABLE flash_sale_products DROP COLUMN IF EXISTS variant_id;
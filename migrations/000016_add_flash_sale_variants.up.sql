@@ -0,0 +1,26 @@
+-- Flash sale entries can target a single variant (size / color) of a product
+ALTER TABLE flash_sale_products ADD COLUMN IF NOT EXISTS variant_id BIGINT;
+
+ALTER TABLE flash_sale_products
+    ADD CONSTRAINT fk_flash_sale_products_variant FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE;
+
+-- One product-level entry and one entry per variant in each sale
+ALTER TABLE flash_sale_products DROP CONSTRAINT IF EXISTS uq_flash_sale_products;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_flash_sale_products_product ON flash_sale_products(flash_sale_id, product_id) WHERE variant_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_flash_sale_products_variant ON flash_sale_products(flash_sale_id, variant_id) WHERE variant_id IS NOT NULL;
+
+-- Order items point at the entry they were priced with, so stock is counted per entry
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS flash_sale_product_id BIGINT;
+
+ALTER TABLE order_items
+    ADD CONSTRAINT fk_order_items_flash_sale_product FOREIGN KEY (flash_sale_product_id) REFERENCES flash_sale_products(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_order_items_flash_sale_product_id ON order_items(flash_sale_product_id) WHERE flash_sale_product_id IS NOT NULL;
+
+-- Until now every entry was product-level
+UPDATE order_items oi
+SET flash_sale_product_id = fsp.id
+FROM flash_sale_products fsp
+WHERE oi.flash_sale_id = fsp.flash_sale_id
+  AND oi.product_id = fsp.product_id
+  AND oi.flash_sale_product_id IS NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_coupons_batch_id;
+ALTER TABLE coupons DROP CONSTRAINT IF EXISTS fk_coupons_batch;
+ALTER TABLE coupons DROP COLUMN IF EXISTS batch_id;
+DROP TABLE IF EXISTS coupon_batches;
@@ -0,0 +1,18 @@
+-- Batches of single-use coupon codes created by the bulk generator
+CREATE TABLE IF NOT EXISTS coupon_batches (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    name VARCHAR(200) NOT NULL,
+    prefix VARCHAR(20),
+    code_length INTEGER NOT NULL,
+    quantity INTEGER NOT NULL
+);
+
+ALTER TABLE coupons ADD COLUMN IF NOT EXISTS batch_id BIGINT;
+
+ALTER TABLE coupons
+    ADD CONSTRAINT fk_coupons_batch FOREIGN KEY (batch_id) REFERENCES coupon_batches(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_coupons_batch_id ON coupons(batch_id) WHERE batch_id IS NOT NULL;
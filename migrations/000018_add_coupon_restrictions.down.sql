@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS coupon_categories;
+DROP INDEX IF EXISTS idx_coupon_products_product_id;
+DROP TABLE IF EXISTS coupon_products;
@@ -0,0 +1,21 @@
+-- Products a coupon is limited to (excluded = false) or never applies to (excluded = true)
+CREATE TABLE IF NOT EXISTS coupon_products (
+    coupon_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    excluded BOOLEAN NOT NULL DEFAULT FALSE,
+
+    PRIMARY KEY (coupon_id, product_id),
+    CONSTRAINT fk_coupon_products_coupon FOREIGN KEY (coupon_id) REFERENCES coupons(id) ON DELETE CASCADE,
+    CONSTRAINT fk_coupon_products_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_coupon_products_product_id ON coupon_products(product_id);
+
+-- Product categories a coupon is limited to
+CREATE TABLE IF NOT EXISTS coupon_categories (
+    coupon_id BIGINT NOT NULL,
+    category VARCHAR(50) NOT NULL,
+
+    PRIMARY KEY (coupon_id, category),
+    CONSTRAINT fk_coupon_categories_coupon FOREIGN KEY (coupon_id) REFERENCES coupons(id) ON DELETE CASCADE
+);
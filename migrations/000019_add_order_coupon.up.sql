@@ -0,0 +1,9 @@
+-- Coupon redeemed on an order; the usage itself is recorded in coupon_usages
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS coupon_id BIGINT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS coupon_code VARCHAR(50);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS coupon_discount DECIMAL(12, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE orders
+    ADD CONSTRAINT fk_orders_coupon FOREIGN KEY (coupon_id) REFERENCES coupons(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_orders_coupon_id ON orders(coupon_id) WHERE coupon_id IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_promotion_tiers_promotion_id;
+DROP TABLE IF EXISTS promotion_tiers;
+DROP INDEX IF EXISTS idx_promotions_active;
+DROP INDEX IF EXISTS idx_promotions_deleted_at;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,49 @@
+-- Automatic promotions: buy X get Y, tiered spend, free shipping and gift with purchase
+CREATE TABLE IF NOT EXISTS promotions (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+
+    name VARCHAR(200) NOT NULL,
+    description TEXT,
+    type VARCHAR(30) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    priority INTEGER NOT NULL DEFAULT 0,
+    stackable BOOLEAN NOT NULL DEFAULT TRUE,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    for_retail BOOLEAN DEFAULT TRUE,
+    for_reseller BOOLEAN DEFAULT TRUE,
+    min_subtotal DECIMAL(12,2) DEFAULT 0,
+
+    buy_product_id BIGINT,
+    buy_quantity INTEGER DEFAULT 0,
+    get_product_id BIGINT,
+    get_quantity INTEGER DEFAULT 0,
+    get_discount_percent DECIMAL(5,2) DEFAULT 0,
+
+    max_shipping_discount DECIMAL(12,2) DEFAULT 0,
+
+    gift_product_id BIGINT,
+    gift_quantity INTEGER DEFAULT 0,
+
+    CONSTRAINT fk_promotions_buy_product FOREIGN KEY (buy_product_id) REFERENCES products(id) ON DELETE SET NULL,
+    CONSTRAINT fk_promotions_get_product FOREIGN KEY (get_product_id) REFERENCES products(id) ON DELETE SET NULL,
+    CONSTRAINT fk_promotions_gift_product FOREIGN KEY (gift_product_id) REFERENCES products(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_deleted_at ON promotions(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_promotions_active ON promotions(priority DESC) WHERE status = 'active' AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS promotion_tiers (
+    id BIGSERIAL PRIMARY KEY,
+    promotion_id BIGINT NOT NULL,
+    min_subtotal DECIMAL(12,2) NOT NULL,
+    discount_percent DECIMAL(5,2) NOT NULL,
+    max_discount DECIMAL(12,2) DEFAULT 0,
+
+    CONSTRAINT fk_promotion_tiers_promotion FOREIGN KEY (promotion_id) REFERENCES promotions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_tiers_promotion_id ON promotion_tiers(promotion_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_order_taxes_tax_id;
+DROP INDEX IF EXISTS idx_order_taxes_order_id;
+DROP TABLE IF EXISTS order_taxes;
+DROP INDEX IF EXISTS idx_tax_regions_region_code;
+DROP TABLE IF EXISTS tax_regions;
+ALTER TABLE taxes ALTER COLUMN rate TYPE DECIMAL(5, 4);
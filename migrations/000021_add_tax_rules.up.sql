@@ -0,0 +1,34 @@
+-- Fixed taxes are amounts in rupiah, too large for the old DECIMAL(5,4)
+ALTER TABLE taxes ALTER COLUMN rate TYPE DECIMAL(12, 4);
+
+-- Regions a tax is limited to (excluded = false) or never charged in (excluded = true)
+CREATE TABLE IF NOT EXISTS tax_regions (
+    tax_id INTEGER NOT NULL,
+    region_code VARCHAR(20) NOT NULL,
+    excluded BOOLEAN NOT NULL DEFAULT FALSE,
+
+    PRIMARY KEY (tax_id, region_code),
+    CONSTRAINT fk_tax_regions_tax FOREIGN KEY (tax_id) REFERENCES taxes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_regions_region_code ON tax_regions(region_code);
+
+-- Per-tax breakdown of orders.tax, kept as charged for PPN reporting
+CREATE TABLE IF NOT EXISTS order_taxes (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    order_id BIGINT NOT NULL,
+    tax_id INTEGER,
+    name VARCHAR(200) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    rate DECIMAL(12, 4) NOT NULL,
+    taxable_amount DECIMAL(12, 2) NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL,
+
+    CONSTRAINT fk_order_taxes_order FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_taxes_tax FOREIGN KEY (tax_id) REFERENCES taxes(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_taxes_order_id ON order_taxes(order_id);
+CREATE INDEX IF NOT EXISTS idx_order_taxes_tax_id ON order_taxes(tax_id);
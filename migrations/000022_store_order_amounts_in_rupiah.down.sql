@@ -0,0 +1,15 @@
+ALTER TABLE order_taxes
+    ALTER COLUMN taxable_amount TYPE DECIMAL(12, 2),
+    ALTER COLUMN amount TYPE DECIMAL(12, 2);
+
+ALTER TABLE order_items
+    ALTER COLUMN unit_price TYPE DECIMAL(10, 2),
+    ALTER COLUMN total_price TYPE DECIMAL(10, 2);
+
+ALTER TABLE orders
+    ALTER COLUMN subtotal TYPE DECIMAL(10, 2),
+    ALTER COLUMN discount TYPE DECIMAL(10, 2),
+    ALTER COLUMN shipping_cost TYPE DECIMAL(10, 2),
+    ALTER COLUMN tax TYPE DECIMAL(10, 2),
+    ALTER COLUMN total_amount TYPE DECIMAL(10, 2),
+    ALTER COLUMN coupon_discount TYPE DECIMAL(12, 2);
@@ -0,0 +1,16 @@
+-- Order amounts are whole rupiah (models.Money); round what was stored with fractions
+ALTER TABLE orders
+    ALTER COLUMN subtotal TYPE BIGINT USING ROUND(subtotal)::BIGINT,
+    ALTER COLUMN discount TYPE BIGINT USING ROUND(discount)::BIGINT,
+    ALTER COLUMN shipping_cost TYPE BIGINT USING ROUND(shipping_cost)::BIGINT,
+    ALTER COLUMN tax TYPE BIGINT USING ROUND(tax)::BIGINT,
+    ALTER COLUMN total_amount TYPE BIGINT USING ROUND(total_amount)::BIGINT,
+    ALTER COLUMN coupon_discount TYPE BIGINT USING ROUND(coupon_discount)::BIGINT;
+
+ALTER TABLE order_items
+    ALTER COLUMN unit_price TYPE BIGINT USING ROUND(unit_price)::BIGINT,
+    ALTER COLUMN total_price TYPE BIGINT USING ROUND(total_price)::BIGINT;
+
+ALTER TABLE order_taxes
+    ALTER COLUMN taxable_amount TYPE BIGINT USING ROUND(taxable_amount)::BIGINT,
+    ALTER COLUMN amount TYPE BIGINT USING ROUND(amount)::BIGINT;
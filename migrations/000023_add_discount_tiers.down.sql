@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_discount_tiers_current;
+DROP INDEX IF EXISTS idx_discount_tiers_product_id;
+DROP TABLE IF EXISTS discount_tiers;
@@ -0,0 +1,39 @@
+-- Reseller and retail bulk quantity tiers, scoped globally, to a category or to a product
+CREATE TABLE IF NOT EXISTS discount_tiers (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    kind VARCHAR(20) NOT NULL,
+    scope VARCHAR(20) NOT NULL,
+    category VARCHAR(50),
+    product_id BIGINT,
+
+    min_quantity INTEGER NOT NULL,
+    discount_percent DECIMAL(5,2) NOT NULL,
+
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+
+    CONSTRAINT fk_discount_tiers_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT chk_discount_tiers_scope CHECK (
+        (scope = 'global' AND COALESCE(category, '') = '' AND product_id IS NULL) OR
+        (scope = 'category' AND COALESCE(category, '') <> '' AND product_id IS NULL) OR
+        (scope = 'product' AND product_id IS NOT NULL AND COALESCE(category, '') = '')
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_discount_tiers_product_id ON discount_tiers(product_id);
+CREATE INDEX IF NOT EXISTS idx_discount_tiers_current ON discount_tiers(kind, scope) WHERE is_active;
+
+-- The tiers that used to be hardcoded in the pricing service
+INSERT INTO discount_tiers (kind, scope, min_quantity, discount_percent) VALUES
+    ('reseller', 'global', 1, 5),
+    ('reseller', 'global', 5, 10),
+    ('reseller', 'global', 10, 15),
+    ('reseller', 'global', 20, 20),
+    ('reseller', 'global', 50, 25),
+    ('reseller', 'global', 100, 30),
+    ('bulk', 'global', 5, 5),
+    ('bulk', 'global', 10, 10);
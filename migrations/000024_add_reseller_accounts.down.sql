@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reseller_applications;
+ALTER TABLE users DROP COLUMN IF EXISTS customer_group;
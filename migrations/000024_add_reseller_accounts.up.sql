@@ -0,0 +1,33 @@
+-- Customer group a user buys from; only approved resellers get reseller prices
+ALTER TABLE users ADD COLUMN IF NOT EXISTS customer_group VARCHAR(20) NOT NULL DEFAULT 'retail';
+
+-- Applications customers send to become resellers
+CREATE TABLE IF NOT EXISTS reseller_applications (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    user_id BIGINT NOT NULL,
+
+    business_name VARCHAR(200) NOT NULL,
+    business_type VARCHAR(100),
+    tax_number VARCHAR(30),
+    phone VARCHAR(20) NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    province VARCHAR(100),
+    notes TEXT,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewed_by BIGINT,
+    reviewed_at TIMESTAMPTZ,
+    review_note TEXT,
+
+    CONSTRAINT fk_reseller_applications_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_reseller_applications_reviewer FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_reseller_applications_status CHECK (status IN ('pending', 'approved', 'rejected'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_reseller_applications_user_id ON reseller_applications(user_id);
+CREATE INDEX IF NOT EXISTS idx_reseller_applications_status ON reseller_applications(status, created_at);
+-- A user has at most one application waiting for review
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reseller_applications_one_pending ON reseller_applications(user_id) WHERE status = 'pending';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS price_history;
+DROP TABLE IF EXISTS scheduled_price_changes;
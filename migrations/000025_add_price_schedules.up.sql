@@ -0,0 +1,57 @@
+-- Price changes merchandisers schedule in advance, applied by the price scheduler
+CREATE TABLE IF NOT EXISTS scheduled_price_changes (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT,
+
+    price DECIMAL(10, 2),
+    compare_price DECIMAL(10, 2),
+    discount DECIMAL(5, 2),
+
+    effective_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reason VARCHAR(255),
+    created_by BIGINT,
+
+    applied_at TIMESTAMPTZ,
+    error VARCHAR(255),
+
+    CONSTRAINT fk_scheduled_price_changes_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_scheduled_price_changes_variant FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE,
+    CONSTRAINT fk_scheduled_price_changes_creator FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_scheduled_price_changes_status CHECK (status IN ('pending', 'applied', 'cancelled', 'failed')),
+    CONSTRAINT chk_scheduled_price_changes_variant CHECK (variant_id IS NULL OR (compare_price IS NULL AND discount IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_price_changes_product_id ON scheduled_price_changes(product_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_price_changes_variant_id ON scheduled_price_changes(variant_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_price_changes_due ON scheduled_price_changes(effective_at) WHERE status = 'pending';
+
+-- Every applied price change, kept to back "lowest price in 30 days" claims
+CREATE TABLE IF NOT EXISTS price_history (
+    id BIGSERIAL PRIMARY KEY,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT,
+
+    old_price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    new_price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    old_compare_price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    new_compare_price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    old_discount DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    new_discount DECIMAL(5, 2) NOT NULL DEFAULT 0,
+
+    changed_by BIGINT,
+    reason VARCHAR(255),
+    scheduled_change_id BIGINT,
+
+    CONSTRAINT fk_price_history_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_price_history_changed_by FOREIGN KEY (changed_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT fk_price_history_scheduled_change FOREIGN KEY (scheduled_change_id) REFERENCES scheduled_price_changes(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_product ON price_history(product_id, changed_at);
@@ -0,0 +1,12 @@
+-- Regions of each shipping zone; a region listed as excluded keeps the zone from matching it
+CREATE TABLE IF NOT EXISTS shipping_zone_regions (
+    zone_id INTEGER NOT NULL,
+    region_code VARCHAR(100) NOT NULL,
+    excluded BOOLEAN NOT NULL DEFAULT FALSE,
+
+    PRIMARY KEY (zone_id, region_code),
+    CONSTRAINT fk_shipping_zone_regions_zone FOREIGN KEY (zone_id) REFERENCES shipping_zones(id) ON DELETE CASCADE
+);
+
+-- Shipping cost and free-shipping checks look zones up by region
+CREATE INDEX IF NOT EXISTS idx_shipping_zone_regions_lookup ON shipping_zone_regions(region_code) WHERE NOT excluded;
@@ -0,0 +1,3 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_destination_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_etd;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_service;
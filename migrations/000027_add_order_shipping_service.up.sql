@@ -0,0 +1,5 @@
+-- Courier service chosen at checkout and the Komerce destination it was quoted for.
+-- shipping_provider already holds the courier and shipping_cost the quoted rate.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_service VARCHAR(100);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_etd VARCHAR(50);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_destination_id VARCHAR(50);
@@ -0,0 +1,10 @@
+ALTER TABLE cart_recoveries
+    ALTER COLUMN cart_value TYPE DECIMAL(12, 2),
+    ALTER COLUMN order_total TYPE DECIMAL(12, 2);
+
+ALTER TABLE coupon_usages
+    ALTER COLUMN discount_amount TYPE DECIMAL(10, 2);
+
+ALTER TABLE cart_items
+    ALTER COLUMN unit_price TYPE DECIMAL(10, 2),
+    ALTER COLUMN total_price TYPE DECIMAL(10, 2);
@@ -0,0 +1,11 @@
+-- Cart, coupon usage and recovery amounts are whole rupiah (models.Money); round what was stored with fractions
+ALTER TABLE cart_items
+    ALTER COLUMN unit_price TYPE BIGINT USING ROUND(unit_price)::BIGINT,
+    ALTER COLUMN total_price TYPE BIGINT USING ROUND(total_price)::BIGINT;
+
+ALTER TABLE coupon_usages
+    ALTER COLUMN discount_amount TYPE BIGINT USING ROUND(discount_amount)::BIGINT;
+
+ALTER TABLE cart_recoveries
+    ALTER COLUMN cart_value TYPE BIGINT USING ROUND(cart_value)::BIGINT,
+    ALTER COLUMN order_total TYPE BIGINT USING ROUND(order_total)::BIGINT;
@@ -0,0 +1,7 @@
+-- Order items keep the variant they were bought as, next to its name / size / color snapshot
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_id BIGINT;
+
+ALTER TABLE order_items
+    ADD CONSTRAINT fk_order_items_variant FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_order_items_variant_id ON order_items(variant_id) WHERE variant_id IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_coupons_user_id;
+ALTER TABLE coupons DROP CONSTRAINT IF EXISTS fk_coupons_user;
+ALTER TABLE coupons DROP COLUMN IF EXISTS user_id;
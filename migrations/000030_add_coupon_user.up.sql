@@ -0,0 +1,7 @@
+-- Coupons issued to a single customer (abandoned cart reminders) can only be redeemed by them
+ALTER TABLE coupons ADD COLUMN IF NOT EXISTS user_id BIGINT;
+
+ALTER TABLE coupons
+    ADD CONSTRAINT fk_coupons_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_coupons_user_id ON coupons(user_id) WHERE user_id IS NOT NULL;